@@ -2,9 +2,16 @@
 package all
 
 import (
+	"github.com/kamogelosekhukhune777/lms/app/domain/apikeyapp"
+	"github.com/kamogelosekhukhune777/lms/app/domain/auditapp"
+	"github.com/kamogelosekhukhune777/lms/app/domain/authapp"
+	"github.com/kamogelosekhukhune777/lms/app/domain/consentapp"
 	"github.com/kamogelosekhukhune777/lms/app/domain/courseapp"
+	"github.com/kamogelosekhukhune777/lms/app/domain/instructorapp"
 	"github.com/kamogelosekhukhune777/lms/app/domain/mediapp"
 	"github.com/kamogelosekhukhune777/lms/app/domain/orderapp"
+	"github.com/kamogelosekhukhune777/lms/app/domain/orgapp"
+	"github.com/kamogelosekhukhune777/lms/app/domain/privacyapp"
 	"github.com/kamogelosekhukhune777/lms/app/domain/testapp"
 	"github.com/kamogelosekhukhune777/lms/app/domain/userapp"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/mux"
@@ -24,28 +31,97 @@ func (add) Add(app *web.App, cfg mux.Config) {
 
 	testapp.Routes(app)
 
-	userapp.Routes(app, userapp.Config{
+	authapp.Routes(app, authapp.Config{
+		Log:  cfg.Log,
+		Auth: cfg.Auth,
+	})
+
+	auditapp.Routes(app, auditapp.Config{
+		Log:      cfg.Log,
+		AuditBus: cfg.BusConfig.AuditBus,
+		Auth:     cfg.Auth,
+	})
+
+	orgapp.Routes(app, orgapp.Config{
 		Log:     cfg.Log,
+		OrgBus:  cfg.BusConfig.OrgBus,
 		UserBus: cfg.BusConfig.UserBus,
+		DB:      cfg.DB,
 		Auth:    cfg.Auth,
 	})
 
-	courseapp.Routes(app, courseapp.Config{
+	userapp.Routes(app, userapp.Config{
+		Log:                  cfg.Log,
+		UserBus:              cfg.BusConfig.UserBus,
+		LoginBus:             cfg.BusConfig.LoginBus,
+		ConsentBus:           cfg.BusConfig.ConsentBus,
+		PrivacyBus:           cfg.BusConfig.PrivacyBus,
+		DB:                   cfg.DB,
+		Auth:                 cfg.Auth,
+		Mailer:               cfg.Mailer,
+		AppURL:               cfg.UserConfig.AppURL,
+		PasswordResetTTL:     cfg.UserConfig.PasswordResetTTL,
+		EmailVerificationTTL: cfg.UserConfig.EmailVerificationTTL,
+		ChallengeTTL:         cfg.UserConfig.ChallengeTTL,
+		TOTPIssuer:           cfg.UserConfig.TOTPIssuer,
+		OIDCProviders:        cfg.UserConfig.OIDCProviders,
+		OIDCStateTTL:         cfg.UserConfig.OIDCStateTTL,
+		MagicLinkTTL:         cfg.UserConfig.MagicLinkTTL,
+		MagicLinkLimit:       cfg.UserConfig.MagicLinkLimit,
+		MagicLinkWindow:      cfg.UserConfig.MagicLinkWindow,
+		RelyingParty:         cfg.UserConfig.RelyingParty,
+		WebAuthnTimeout:      cfg.UserConfig.WebAuthnTimeout,
+	})
+
+	consentapp.Routes(app, consentapp.Config{
+		Log:        cfg.Log,
+		ConsentBus: cfg.BusConfig.ConsentBus,
+		Auth:       cfg.Auth,
+	})
+
+	apikeyapp.Routes(app, apikeyapp.Config{
 		Log:       cfg.Log,
-		CourseBus: cfg.BusConfig.CourseBus,
-		UserBus:   cfg.BusConfig.UserBus,
-		DB:        cfg.DB,
+		APIKeyBus: cfg.BusConfig.APIKeyBus,
+		Auth:      cfg.Auth,
+	})
+
+	privacyapp.Routes(app, privacyapp.Config{
+		Log:                cfg.Log,
+		PrivacyBus:         cfg.BusConfig.PrivacyBus,
+		DB:                 cfg.DB,
+		Auth:               cfg.Auth,
+		ErasureGracePeriod: cfg.PrivacyConfig.ErasureGracePeriod,
+	})
+
+	courseapp.Routes(app, courseapp.Config{
+		Log:                  cfg.Log,
+		CourseBus:            cfg.BusConfig.CourseBus,
+		UserBus:              cfg.BusConfig.UserBus,
+		DB:                   cfg.DB,
+		Auth:                 cfg.Auth,
+		Mailer:               cfg.Mailer,
+		RequireVerifiedEmail: cfg.UserConfig.RequireVerifiedEmail,
+	})
+
+	instructorapp.Routes(app, instructorapp.Config{
+		Log:           cfg.Log,
+		InstructorBus: cfg.BusConfig.InstructorBus,
+		DB:            cfg.DB,
+		Auth:          cfg.Auth,
 	})
 
 	orderapp.Routes(app, orderapp.Config{
-		Log:       cfg.Log,
-		CourseBus: cfg.BusConfig.CourseBus,
-		UserBus:   cfg.BusConfig.UserBus,
-		Paypal:    cfg.Paypal,
+		Log:                  cfg.Log,
+		CourseBus:            cfg.BusConfig.CourseBus,
+		UserBus:              cfg.BusConfig.UserBus,
+		Paypal:               cfg.Paypal,
+		Auth:                 cfg.Auth,
+		RequireVerifiedEmail: cfg.UserConfig.RequireVerifiedEmail,
 	})
 
 	mediapp.Routes(app, mediapp.Config{
 		Log:              cfg.Log,
 		CloudinaryClient: cfg.CloudinaryClient,
+		Auth:             cfg.Auth,
 	})
 }
@@ -7,29 +7,52 @@ import (
 	"expvar"
 	"fmt"
 	"net/http"
+	"net/mail"
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Time zones of user preferences, for images without a zoneinfo database.
 
 	"github.com/ardanlabs/conf/v3"
+	"github.com/google/uuid"
 	"github.com/kamogelosekhukhune777/lms/api/services/lms-api/all"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth/keystore"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/cloudinary"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/debug"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mailer"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/mux"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/paypal"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus/stores/apikeydb"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus/stores/auditdb"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus/stores/consentdb"
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus/stores/coursedb"
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus/stores/instructordb"
+	"github.com/kamogelosekhukhune777/lms/business/domain/loginbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/loginbus/stores/logindb"
 	"github.com/kamogelosekhukhune777/lms/business/domain/orderbus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/orderbus/stores/orderdb"
+	"github.com/kamogelosekhukhune777/lms/business/domain/orgbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/orgbus/stores/orgdb"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus/stores/privacydb"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus/stores/userdb"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/migrate"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/oidc"
+	"github.com/kamogelosekhukhune777/lms/foundation/password"
 	"github.com/kamogelosekhukhune777/lms/foundation/web"
+	"github.com/kamogelosekhukhune777/lms/foundation/webauthn"
 )
 
 //go:embed static
@@ -82,6 +105,8 @@ func run(ctx context.Context, log *logger.Logger) error {
 			APIHost            string        `conf:"default:0.0.0.0:3000"`
 			DebugHost          string        `conf:"default:0.0.0.0:3010"`
 			CORSAllowedOrigins []string      `conf:"default:*"`
+			AppURL             string        `conf:"default:http://localhost:5173"`
+			BaseDomain         string        `conf:"help:domain whose subdomains select an organization"`
 		}
 		DB struct {
 			User         string `conf:"default:postgres"`
@@ -93,8 +118,60 @@ func run(ctx context.Context, log *logger.Logger) error {
 			DisableTLS   bool   `conf:"default:true"`
 		}
 		Auth struct {
-			Issuer string `conf:"default:lms project"`
-			Secret string `conf:"default:lms_jwt_secret,mask"`
+			KeysFolder           string        `conf:"default:"`
+			ActiveKID            string        `conf:"default:"`
+			Issuer               string        `conf:"default:lms project"`
+			AccessTokenTTL       time.Duration `conf:"default:15m"`
+			RefreshTokenTTL      time.Duration `conf:"default:720h"`
+			SessionCacheTTL      time.Duration `conf:"default:30s"`
+			ImpersonationTTL     time.Duration `conf:"default:15m"`
+			PasswordResetTTL     time.Duration `conf:"default:1h"`
+			EmailVerificationTTL time.Duration `conf:"default:48h"`
+			RequireVerifiedEmail bool          `conf:"default:false"`
+			ChallengeTTL         time.Duration `conf:"default:5m"`
+			TOTPIssuer           string        `conf:"default:LMS"`
+			MagicLinkTTL         time.Duration `conf:"default:15m"`
+			MagicLinkLimit       int           `conf:"default:3"`
+			MagicLinkWindow      time.Duration `conf:"default:1h"`
+		}
+		Password struct {
+			MinLength     int    `conf:"default:8"`
+			MaxLength     int    `conf:"default:128"`
+			CheckCommon   bool   `conf:"default:true"`
+			Argon2Memory  uint32 `conf:"default:19456"`
+			Argon2Time    uint32 `conf:"default:2"`
+			Argon2Threads uint8  `conf:"default:1"`
+		}
+		Login struct {
+			DelayAfter    int           `conf:"default:3"`
+			BaseDelay     time.Duration `conf:"default:1s"`
+			MaxDelay      time.Duration `conf:"default:30s"`
+			LockoutAfter  int           `conf:"default:10"`
+			LockoutWindow time.Duration `conf:"default:15m"`
+			IPLimit       int           `conf:"default:100"`
+		}
+		Privacy struct {
+			ErasureGracePeriod time.Duration `conf:"default:720h"`
+			ErasureInterval    time.Duration `conf:"default:1h"`
+			ErasureBatch       int           `conf:"default:50"`
+		}
+		Mail struct {
+			From         string `conf:"default:LMS <no-reply@localhost>"`
+			SMTPHost     string `conf:"default:"`
+			SMTPPort     int    `conf:"default:587"`
+			SMTPUser     string `conf:"default:"`
+			SMTPPassword string `conf:"default:,mask"`
+			OutboxDir    string `conf:"default:"`
+		}
+		WebAuthn struct {
+			RPID    string        `conf:"default:localhost"`
+			RPName  string        `conf:"default:LMS"`
+			Origins []string      `conf:"default:http://localhost:5173"`
+			Timeout time.Duration `conf:"default:5m"`
+		}
+		OIDC struct {
+			Providers []string      `conf:"mask,help:name|issuer_url|client_id|client_secret entries separated by ;"`
+			StateTTL  time.Duration `conf:"default:10m"`
 		}
 		Paypal struct {
 			ClientID string `conf:"default:,mask"`
@@ -102,7 +179,9 @@ func run(ctx context.Context, log *logger.Logger) error {
 			URL      string `conf:"default:,mask"`
 		}
 		Cloudinary struct {
-			URL string `conf:"default:,mask`
+			URL             string        `conf:"default:,mask"`
+			CleanupInterval time.Duration `conf:"default:10m"`
+			CleanupBatch    int           `conf:"default:50"`
 		}
 	}{
 		Version: conf.Version{
@@ -168,11 +247,34 @@ func run(ctx context.Context, log *logger.Logger) error {
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
 
+	var ks *keystore.KeyStore
+	switch cfg.Auth.KeysFolder {
+	case "":
+		log.Info(ctx, "startup", "status", "no keys folder configured, generating in memory signing key")
+
+		ks = keystore.NewMemory(cfg.Auth.AccessTokenTTL)
+		if err := ks.Generate(uuid.NewString()); err != nil {
+			return fmt.Errorf("generating keys: %w", err)
+		}
+
+	default:
+		ks, err = keystore.NewFile(os.DirFS(cfg.Auth.KeysFolder), cfg.Auth.ActiveKID)
+		if err != nil {
+			return fmt.Errorf("reading keys: %w", err)
+		}
+	}
+
+	log.Info(ctx, "startup", "status", "keys loaded", "kids", ks.KIDs())
+
 	authCfg := auth.Config{
-		Log:    log,
-		DB:     db,
-		Secret: cfg.Auth.Secret,
-		Issuer: cfg.Auth.Issuer,
+		Log:              log,
+		DB:               db,
+		KeyLookup:        ks,
+		Issuer:           cfg.Auth.Issuer,
+		AccessTokenTTL:   cfg.Auth.AccessTokenTTL,
+		RefreshTokenTTL:  cfg.Auth.RefreshTokenTTL,
+		SessionCacheTTL:  cfg.Auth.SessionCacheTTL,
+		ImpersonationTTL: cfg.Auth.ImpersonationTTL,
 	}
 
 	ath, err := auth.New(authCfg)
@@ -183,9 +285,38 @@ func run(ctx context.Context, log *logger.Logger) error {
 	// -------------------------------------------------------------------------
 	// Create Business Packages
 
-	userBus := userbus.NewBusiness(log, userdb.NewStore(log, db))
+	argon2Params := password.DefaultArgon2Params
+	argon2Params.Memory = cfg.Password.Argon2Memory
+	argon2Params.Iterations = cfg.Password.Argon2Time
+	argon2Params.Parallelism = cfg.Password.Argon2Threads
+
+	hasher := password.NewHasher(password.NewArgon2id(argon2Params), password.NewBcrypt(password.DefaultBcryptCost))
+
+	passwordPolicy := password.Policy{
+		MinLength:   cfg.Password.MinLength,
+		MaxLength:   cfg.Password.MaxLength,
+		CheckCommon: cfg.Password.CheckCommon,
+	}
+
+	userBus := userbus.NewBusiness(log, userdb.NewStore(log, db), hasher, passwordPolicy)
 	courseBus := coursebus.NewBusiness(log, userBus, coursedb.NewStore(log, db))
 	ordeBus := orderbus.NewBusiness(log, userBus, courseBus, orderdb.NewStore(log, db))
+	instructorBus := instructorbus.NewBusiness(log, userBus, instructordb.NewStore(log, db))
+	auditBus := auditbus.NewBusiness(log, auditdb.NewStore(log, db))
+	orgBus := orgbus.NewBusiness(log, orgdb.NewStore(log, db))
+	privacyBus := privacybus.NewBusiness(log, auditBus, privacydb.NewStore(log, db))
+	consentBus := consentbus.NewBusiness(log, consentdb.NewStore(log, db))
+	apiKeyBus := apikeybus.NewBusiness(log, apikeydb.NewStore(log, db))
+
+	loginPolicy := loginbus.Policy{
+		DelayAfter:    cfg.Login.DelayAfter,
+		BaseDelay:     cfg.Login.BaseDelay,
+		MaxDelay:      cfg.Login.MaxDelay,
+		LockoutAfter:  cfg.Login.LockoutAfter,
+		LockoutWindow: cfg.Login.LockoutWindow,
+		IPLimit:       cfg.Login.IPLimit,
+	}
+	loginBus := loginbus.NewBusiness(log, userBus, auditBus, logindb.NewStore(log, db), loginPolicy)
 
 	// -------------------------------------------------------------------------
 	// PayPal s
@@ -204,6 +335,53 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("cloudinary error: %w", err)
 	}
 
+	// -------------------------------------------------------------------------
+	// Mail Support
+
+	from, err := mail.ParseAddress(cfg.Mail.From)
+	if err != nil {
+		return fmt.Errorf("parsing mail from address: %w", err)
+	}
+
+	var mlr mailer.Mailer
+	switch cfg.Mail.SMTPHost {
+	case "":
+		log.Info(ctx, "startup", "status", "no smtp host configured, logging outgoing mail", "outbox", cfg.Mail.OutboxDir)
+
+		mlr = mailer.NewFile(log, cfg.Mail.OutboxDir, *from)
+
+	default:
+		mlr = mailer.NewSMTP(mailer.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUser,
+			Password: cfg.Mail.SMTPPassword,
+			From:     *from,
+		})
+	}
+
+	// -------------------------------------------------------------------------
+	// OIDC Support
+
+	oidcProviders := make(map[string]*oidc.Provider, len(cfg.OIDC.Providers))
+	for _, entry := range cfg.OIDC.Providers {
+		parts := strings.Split(entry, "|")
+		if len(parts) != 4 {
+			return errors.New("parsing oidc provider: expected name|issuer_url|client_id|client_secret")
+		}
+
+		name := parts[0]
+		oidcProviders[name] = oidc.NewProvider(oidc.Config{
+			Name:         name,
+			IssuerURL:    parts[1],
+			ClientID:     parts[2],
+			ClientSecret: parts[3],
+			RedirectURL:  fmt.Sprintf("%s/auth/callback/%s", cfg.Web.AppURL, name),
+		})
+
+		log.Info(ctx, "startup", "status", "oidc provider configured", "provider", name, "issuer", parts[1])
+	}
+
 	// -------------------------------------------------------------------------
 	// Start Debug Service
 
@@ -215,6 +393,70 @@ func run(ctx context.Context, log *logger.Logger) error {
 		}
 	}()
 
+	// -------------------------------------------------------------------------
+	// Start Media Cleanup Worker
+
+	stopCleanup := make(chan struct{})
+	defer close(stopCleanup)
+
+	go func() {
+		log.Info(ctx, "startup", "status", "media cleanup worker started", "interval", cfg.Cloudinary.CleanupInterval)
+
+		ticker := time.NewTicker(cfg.Cloudinary.CleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopCleanup:
+				log.Info(ctx, "shutdown", "status", "media cleanup worker stopped")
+				return
+
+			case <-ticker.C:
+				purged, err := courseBus.PurgeMedia(ctx, clodinary, cfg.Cloudinary.CleanupBatch)
+				if err != nil {
+					log.Error(ctx, "media cleanup worker", "status", "purging queued media", "purged", purged, "msg", err)
+					continue
+				}
+
+				if purged > 0 {
+					log.Info(ctx, "media cleanup worker", "status", "purged queued media", "purged", purged)
+				}
+			}
+		}
+	}()
+
+	// -------------------------------------------------------------------------
+	// Start Erasure Worker
+
+	stopErasure := make(chan struct{})
+	defer close(stopErasure)
+
+	go func() {
+		log.Info(ctx, "startup", "status", "erasure worker started", "interval", cfg.Privacy.ErasureInterval)
+
+		ticker := time.NewTicker(cfg.Privacy.ErasureInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopErasure:
+				log.Info(ctx, "shutdown", "status", "erasure worker stopped")
+				return
+
+			case <-ticker.C:
+				erased, err := privacyBus.EraseDue(ctx, sqldb.NewBeginner(db), time.Now(), cfg.Privacy.ErasureBatch)
+				if err != nil {
+					log.Error(ctx, "erasure worker", "status", "erasing due requests", "erased", erased, "msg", err)
+					continue
+				}
+
+				if erased > 0 {
+					log.Info(ctx, "erasure worker", "status", "erased due requests", "erased", erased)
+				}
+			}
+		}
+	}()
+
 	// -------------------------------------------------------------------------
 	// Start API Service
 
@@ -230,10 +472,41 @@ func run(ctx context.Context, log *logger.Logger) error {
 		Auth:             ath,
 		Paypal:           pay,
 		CloudinaryClient: clodinary,
+		Mailer:           mlr,
+		BaseDomain:       cfg.Web.BaseDomain,
 		BusConfig: mux.BusConfig{
-			UserBus:   userBus,
-			CourseBus: courseBus,
-			OrderBus:  ordeBus,
+			UserBus:       userBus,
+			CourseBus:     courseBus,
+			OrderBus:      ordeBus,
+			InstructorBus: instructorBus,
+			AuditBus:      auditBus,
+			LoginBus:      loginBus,
+			OrgBus:        orgBus,
+			PrivacyBus:    privacyBus,
+			ConsentBus:    consentBus,
+			APIKeyBus:     apiKeyBus,
+		},
+		UserConfig: mux.UserConfig{
+			AppURL:               cfg.Web.AppURL,
+			PasswordResetTTL:     cfg.Auth.PasswordResetTTL,
+			EmailVerificationTTL: cfg.Auth.EmailVerificationTTL,
+			RequireVerifiedEmail: cfg.Auth.RequireVerifiedEmail,
+			ChallengeTTL:         cfg.Auth.ChallengeTTL,
+			TOTPIssuer:           cfg.Auth.TOTPIssuer,
+			OIDCProviders:        oidcProviders,
+			OIDCStateTTL:         cfg.OIDC.StateTTL,
+			MagicLinkTTL:         cfg.Auth.MagicLinkTTL,
+			MagicLinkLimit:       cfg.Auth.MagicLinkLimit,
+			MagicLinkWindow:      cfg.Auth.MagicLinkWindow,
+			RelyingParty: webauthn.RelyingParty{
+				ID:      cfg.WebAuthn.RPID,
+				Name:    cfg.WebAuthn.RPName,
+				Origins: cfg.WebAuthn.Origins,
+			},
+			WebAuthnTimeout: cfg.WebAuthn.Timeout,
+		},
+		PrivacyConfig: mux.PrivacyConfig{
+			ErasureGracePeriod: cfg.Privacy.ErasureGracePeriod,
 		},
 	}
 
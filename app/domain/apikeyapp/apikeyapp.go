@@ -0,0 +1,182 @@
+// Package apikeyapp maintains the app layer api for the API keys other
+// systems use to call the API.
+package apikeyapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/query"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+type app struct {
+	apiKeyBus *apikeybus.Business
+}
+
+func newApp(apiKeyBus *apikeybus.Business) *app {
+	return &app{
+		apiKeyBus: apiKeyBus,
+	}
+}
+
+// queryMine returns the API keys of the user.
+func (a *app) queryMine(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	qp := parseQueryParams(r)
+	qp.UserID = userID.String()
+	qp.OrganizationOnly = ""
+
+	return a.queryKeys(ctx, qp)
+}
+
+// createMine creates an API key that acts as the user.
+func (a *app) createMine(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	return a.createKey(ctx, r, &userID)
+}
+
+// revokeMine revokes one of the API keys of the user.
+func (a *app) revokeMine(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	key, errEnc := a.apiKeyFromParam(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if key.UserID == nil || *key.UserID != userID {
+		return errs.New(errs.NotFound, apikeybus.ErrNotFound)
+	}
+
+	return a.revokeKey(ctx, key)
+}
+
+// query returns the API keys of the organization, including the keys of its
+// users.
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	return a.queryKeys(ctx, parseQueryParams(r))
+}
+
+// create creates an API key that belongs to the organization.
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	return a.createKey(ctx, r, nil)
+}
+
+// revoke revokes any API key of the organization.
+func (a *app) revoke(ctx context.Context, r *http.Request) web.Encoder {
+	key, errEnc := a.apiKeyFromParam(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	return a.revokeKey(ctx, key)
+}
+
+// =============================================================================
+
+func (a *app) queryKeys(ctx context.Context, qp queryParams) web.Encoder {
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, apikeybus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	keys, err := a.apiKeyBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.apiKeyBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppAPIKeys(keys, mid.GetLocation(ctx)), total, page)
+}
+
+func (a *app) createKey(ctx context.Context, r *http.Request, userID *uuid.UUID) web.Encoder {
+	var app NewAPIKey
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	nk, err := toBusNewAPIKey(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	createdBy, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	nk.UserID = userID
+	nk.CreatedBy = createdBy
+
+	key, value, err := a.apiKeyBus.Create(ctx, nk)
+	if err != nil {
+		return errs.Newf(errs.Internal, "create: %s", err)
+	}
+
+	return CreatedAPIKey{
+		APIKey: toAppAPIKey(key, mid.GetLocation(ctx)),
+		Key:    value,
+	}
+}
+
+func (a *app) revokeKey(ctx context.Context, key apikeybus.APIKey) web.Encoder {
+	key, err := a.apiKeyBus.Revoke(ctx, key)
+	if err != nil {
+		if errors.Is(err, apikeybus.ErrAlreadyRevoked) {
+			return errs.New(errs.FailedPrecondition, apikeybus.ErrAlreadyRevoked)
+		}
+		return errs.Newf(errs.Internal, "revoke: %s", err)
+	}
+
+	return toAppAPIKey(key, mid.GetLocation(ctx))
+}
+
+func (a *app) apiKeyFromParam(ctx context.Context, r *http.Request) (apikeybus.APIKey, *errs.Error) {
+	keyID, err := uuid.Parse(web.Param(r, "api_key_id"))
+	if err != nil {
+		return apikeybus.APIKey{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	key, err := a.apiKeyBus.QueryByID(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, apikeybus.ErrNotFound) {
+			return apikeybus.APIKey{}, errs.New(errs.NotFound, apikeybus.ErrNotFound)
+		}
+		return apikeybus.APIKey{}, errs.Newf(errs.Internal, "querybyid: keyID[%s]: %s", keyID, err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,72 @@
+package apikeyapp
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+)
+
+type queryParams struct {
+	Page             string
+	Rows             string
+	OrderBy          string
+	UserID           string
+	OrganizationOnly string
+	IncludeRevoked   string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+	return queryParams{
+		Page:             values.Get("page"),
+		Rows:             values.Get("rows"),
+		OrderBy:          values.Get("orderBy"),
+		UserID:           values.Get("user_id"),
+		OrganizationOnly: values.Get("organization_only"),
+		IncludeRevoked:   values.Get("include_revoked"),
+	}
+}
+
+func parseFilter(qp queryParams) (apikeybus.QueryFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter apikeybus.QueryFilter
+
+	if qp.UserID != "" {
+		id, err := uuid.Parse(qp.UserID)
+		switch err {
+		case nil:
+			filter.UserID = &id
+		default:
+			fieldErrors.Add("user_id", err)
+		}
+	}
+
+	if qp.OrganizationOnly != "" {
+		only, err := strconv.ParseBool(qp.OrganizationOnly)
+		switch err {
+		case nil:
+			filter.OrganizationOnly = only
+		default:
+			fieldErrors.Add("organization_only", err)
+		}
+	}
+
+	if qp.IncludeRevoked != "" {
+		include, err := strconv.ParseBool(qp.IncludeRevoked)
+		switch err {
+		case nil:
+			filter.IncludeRevoked = include
+		default:
+			fieldErrors.Add("include_revoked", err)
+		}
+	}
+
+	if fieldErrors != nil {
+		return apikeybus.QueryFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
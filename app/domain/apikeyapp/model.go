@@ -0,0 +1,138 @@
+package apikeyapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+)
+
+// APIKey represents an API key. The value of the key is never returned
+// after it was created, only its prefix.
+type APIKey struct {
+	ID         string   `json:"api_key_id"`
+	UserID     string   `json:"user_id,omitempty"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  string   `json:"created_by"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	RevokedAt  string   `json:"revoked_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// Encode implements the encoder interface.
+func (app APIKey) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppAPIKey(bus apikeybus.APIKey, loc *time.Location) APIKey {
+	app := APIKey{
+		ID:        bus.ID.String(),
+		Name:      bus.Name,
+		Prefix:    bus.Prefix,
+		Scopes:    apikeybus.ScopesToString(bus.Scopes),
+		CreatedBy: bus.CreatedBy.String(),
+		CreatedAt: bus.CreatedAt.In(loc).Format(time.RFC3339),
+	}
+
+	if bus.UserID != nil {
+		app.UserID = bus.UserID.String()
+	}
+
+	if !bus.LastUsedAt.IsZero() {
+		app.LastUsedAt = bus.LastUsedAt.In(loc).Format(time.RFC3339)
+	}
+
+	if !bus.ExpiresAt.IsZero() {
+		app.ExpiresAt = bus.ExpiresAt.In(loc).Format(time.RFC3339)
+	}
+
+	if !bus.RevokedAt.IsZero() {
+		app.RevokedAt = bus.RevokedAt.In(loc).Format(time.RFC3339)
+	}
+
+	return app
+}
+
+func toAppAPIKeys(keys []apikeybus.APIKey, loc *time.Location) []APIKey {
+	items := make([]APIKey, len(keys))
+	for i, key := range keys {
+		items[i] = toAppAPIKey(key, loc)
+	}
+
+	return items
+}
+
+// CreatedAPIKey represents a new API key along with its value, which is
+// only shown this once.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// Encode implements the encoder interface.
+func (app CreatedAPIKey) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// =============================================================================
+
+// NewAPIKey defines the data needed to create an API key. The expiry is
+// optional and in RFC3339 format.
+type NewAPIKey struct {
+	Name      string   `json:"name" validate:"required,max=100"`
+	Scopes    []string `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt string   `json:"expires_at"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewAPIKey) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewAPIKey) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewAPIKey(app NewAPIKey) (apikeybus.NewAPIKey, error) {
+	var fieldErrors errs.FieldErrors
+
+	scopes, err := apikeybus.ParseScopes(app.Scopes)
+	if err != nil {
+		fieldErrors.Add("scopes", err)
+	}
+
+	var expiresAt time.Time
+	if app.ExpiresAt != "" {
+		expiresAt, err = time.Parse(time.RFC3339, app.ExpiresAt)
+		switch {
+		case err != nil:
+			fieldErrors.Add("expires_at", err)
+		case !expiresAt.After(time.Now()):
+			fieldErrors.Add("expires_at", fmt.Errorf("expires_at must be in the future"))
+		}
+	}
+
+	if fieldErrors != nil {
+		return apikeybus.NewAPIKey{}, fieldErrors
+	}
+
+	bus := apikeybus.NewAPIKey{
+		Name:      app.Name,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,10 @@
+package apikeyapp
+
+import "github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+
+var orderByFields = map[string]string{
+	"api_key_id":   apikeybus.OrderByID,
+	"name":         apikeybus.OrderByName,
+	"last_used_at": apikeybus.OrderByLastUsedAt,
+	"created_at":   apikeybus.OrderByCreatedAt,
+}
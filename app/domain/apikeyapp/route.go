@@ -0,0 +1,38 @@
+package apikeyapp
+
+import (
+	"net/http"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log       *logger.Logger
+	APIKeyBus *apikeybus.Business
+	Auth      *auth.Auth
+}
+
+// Routes adds specific routes for this group. Keys can only be managed by a
+// signed in user, never with another API key.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.Auth)
+	denyImpersonation := mid.DenyImpersonation()
+	ruleAdmin := mid.Authorize(cfg.Auth, auth.RuleAdminOnly)
+
+	api := newApp(cfg.APIKeyBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/me/api-keys", api.queryMine, authen)
+	app.HandlerFunc(http.MethodPost, version, "/me/api-keys", api.createMine, authen, denyImpersonation)
+	app.HandlerFunc(http.MethodDelete, version, "/me/api-keys/{api_key_id}", api.revokeMine, authen, denyImpersonation)
+
+	app.HandlerFunc(http.MethodGet, version, "/organization/api-keys", api.query, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodPost, version, "/organization/api-keys", api.create, authen, denyImpersonation, ruleAdmin)
+	app.HandlerFunc(http.MethodDelete, version, "/organization/api-keys/{api_key_id}", api.revoke, authen, denyImpersonation, ruleAdmin)
+}
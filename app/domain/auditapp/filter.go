@@ -0,0 +1,84 @@
+package auditapp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+)
+
+type queryParams struct {
+	Page             string
+	Rows             string
+	OrderBy          string
+	ActorID          string
+	Action           string
+	Subject          string
+	StartCreatedDate string
+	EndCreatedDate   string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+	return queryParams{
+		Page:             values.Get("page"),
+		Rows:             values.Get("rows"),
+		OrderBy:          values.Get("orderBy"),
+		ActorID:          values.Get("actor_id"),
+		Action:           values.Get("action"),
+		Subject:          values.Get("subject"),
+		StartCreatedDate: values.Get("start_created_date"),
+		EndCreatedDate:   values.Get("end_created_date"),
+	}
+}
+
+func parseFilter(qp queryParams) (auditbus.QueryFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter auditbus.QueryFilter
+
+	if qp.ActorID != "" {
+		id, err := uuid.Parse(qp.ActorID)
+		switch err {
+		case nil:
+			filter.ActorID = &id
+		default:
+			fieldErrors.Add("actor_id", err)
+		}
+	}
+
+	if qp.Action != "" {
+		filter.Action = &qp.Action
+	}
+
+	if qp.Subject != "" {
+		filter.Subject = &qp.Subject
+	}
+
+	if qp.StartCreatedDate != "" {
+		t, err := time.Parse(time.RFC3339, qp.StartCreatedDate)
+		switch err {
+		case nil:
+			filter.StartCreatedDate = &t
+		default:
+			fieldErrors.Add("start_created_date", err)
+		}
+	}
+
+	if qp.EndCreatedDate != "" {
+		t, err := time.Parse(time.RFC3339, qp.EndCreatedDate)
+		switch err {
+		case nil:
+			filter.EndCreatedDate = &t
+		default:
+			fieldErrors.Add("end_created_date", err)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return auditbus.QueryFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,45 @@
+package auditapp
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+)
+
+// Audit represents an event recorded in the audit log.
+type Audit struct {
+	ID        string            `json:"audit_id"`
+	ActorID   string            `json:"actor_id,omitempty"`
+	Action    string            `json:"action"`
+	Subject   string            `json:"subject"`
+	IP        string            `json:"ip_address"`
+	Details   map[string]string `json:"details"`
+	CreatedAt string            `json:"created_at"`
+}
+
+func toAppAudit(bus auditbus.Audit, loc *time.Location) Audit {
+	app := Audit{
+		ID:        bus.ID.String(),
+		Action:    bus.Action,
+		Subject:   bus.Subject,
+		IP:        bus.IP,
+		Details:   bus.Details,
+		CreatedAt: bus.CreatedAt.In(loc).Format(time.RFC3339),
+	}
+
+	if bus.ActorID != uuid.Nil {
+		app.ActorID = bus.ActorID.String()
+	}
+
+	return app
+}
+
+func toAppAudits(adts []auditbus.Audit, loc *time.Location) []Audit {
+	items := make([]Audit, len(adts))
+	for i, adt := range adts {
+		items[i] = toAppAudit(adt, loc)
+	}
+
+	return items
+}
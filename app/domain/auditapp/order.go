@@ -0,0 +1,9 @@
+package auditapp
+
+import "github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+
+var orderByFields = map[string]string{
+	"audit_id":   auditbus.OrderByID,
+	"action":     auditbus.OrderByAction,
+	"created_at": auditbus.OrderByCreatedAt,
+}
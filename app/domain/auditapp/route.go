@@ -0,0 +1,30 @@
+package auditapp
+
+import (
+	"net/http"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log      *logger.Logger
+	AuditBus *auditbus.Business
+	Auth     *auth.Auth
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.Auth)
+	rulePlatformAdmin := mid.Authorize(cfg.Auth, auth.RulePlatformAdmin)
+
+	api := newApp(cfg.AuditBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/audit", api.query, authen, rulePlatformAdmin)
+}
@@ -0,0 +1,24 @@
+// Package authapp maintains the app layer api for the auth domain.
+package authapp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+type app struct {
+	auth *auth.Auth
+}
+
+func newApp(auth *auth.Auth) *app {
+	return &app{
+		auth: auth,
+	}
+}
+
+func (a *app) jwks(ctx context.Context, r *http.Request) web.Encoder {
+	return toAppJWKS(a.auth.JWKS())
+}
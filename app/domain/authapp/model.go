@@ -0,0 +1,27 @@
+package authapp
+
+import (
+	"encoding/json"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+)
+
+// JWKS represents the set of public keys used to verify tokens.
+type JWKS auth.JWKS
+
+// Encode implements the encoder interface.
+func (app JWKS) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Headers allows verifiers to cache the key set for a short time.
+func (app JWKS) Headers() map[string]string {
+	return map[string]string{
+		"Cache-Control": "public, max-age=300",
+	}
+}
+
+func toAppJWKS(jwks auth.JWKS) JWKS {
+	return JWKS(jwks)
+}
@@ -0,0 +1,22 @@
+package authapp
+
+import (
+	"net/http"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log  *logger.Logger
+	Auth *auth.Auth
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	api := newApp(cfg.Auth)
+
+	app.HandlerFunc(http.MethodGet, "", "/.well-known/jwks.json", api.jwks)
+}
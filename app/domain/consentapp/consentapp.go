@@ -0,0 +1,203 @@
+// Package consentapp maintains the app layer api for the legal documents
+// users accept and the record of their acceptance.
+package consentapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/query"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+type app struct {
+	consentBus *consentbus.Business
+	auth       *auth.Auth
+}
+
+func newApp(consentBus *consentbus.Business, ath *auth.Auth) *app {
+	return &app{
+		consentBus: consentBus,
+		auth:       ath,
+	}
+}
+
+// queryCurrent returns the versions of the legal documents users have to
+// accept. It is public so the registration form can show them.
+func (a *app) queryCurrent(ctx context.Context, r *http.Request) web.Encoder {
+	docs, err := a.consentBus.QueryCurrent(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querycurrent: %s", err)
+	}
+
+	return Documents(toAppDocuments(docs, mid.GetLocation(ctx)))
+}
+
+func (a *app) queryMine(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	acs, err := a.consentBus.QueryAcceptances(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryacceptances: userID[%s]: %s", userID, err)
+	}
+
+	return Acceptances(toAppAcceptances(acs, mid.GetLocation(ctx)))
+}
+
+func (a *app) queryOutstanding(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	docs, err := a.consentBus.QueryOutstanding(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryoutstanding: userID[%s]: %s", userID, err)
+	}
+
+	return Documents(toAppDocuments(docs, mid.GetLocation(ctx)))
+}
+
+func (a *app) accept(ctx context.Context, r *http.Request) web.Encoder {
+	var app Accept
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	documentIDs, err := toBusDocumentIDs(app.DocumentIDs)
+	if err != nil {
+		return errs.NewFieldErrors("document_ids", err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	acs, err := a.consentBus.Accept(ctx, userID, documentIDs, web.ClientIP(r), r.UserAgent())
+	if err != nil {
+		if errors.Is(err, consentbus.ErrNotCurrent) {
+			return errs.NewFieldErrors("document_ids", err)
+		}
+		return errs.Newf(errs.Internal, "accept: userID[%s]: %s", userID, err)
+	}
+
+	a.auth.ForgetConsent(userID)
+
+	return Acceptances(toAppAcceptances(acs, mid.GetLocation(ctx)))
+}
+
+func (a *app) queryCoverage(ctx context.Context, r *http.Request) web.Encoder {
+	cvs, err := a.consentBus.QueryCoverage(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querycoverage: %s", err)
+	}
+
+	return toAppCoverages(cvs, mid.GetLocation(ctx))
+}
+
+// =============================================================================
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewDocument
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	nd, err := toBusNewDocument(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	doc, err := a.consentBus.CreateDocument(ctx, nd)
+	if err != nil {
+		if errors.Is(err, consentbus.ErrUniqueVersion) {
+			return errs.New(errs.AlreadyExists, consentbus.ErrUniqueVersion)
+		}
+		return errs.Newf(errs.Internal, "create: doc[%+v]: %s", nd, err)
+	}
+
+	return toAppDocument(doc, mid.GetLocation(ctx))
+}
+
+// publish makes the document the current version of its kind, after which
+// users are refused until they accept it.
+func (a *app) publish(ctx context.Context, r *http.Request) web.Encoder {
+	doc, errEnc := a.documentFromParam(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	doc, err := a.consentBus.PublishDocument(ctx, doc)
+	if err != nil {
+		if errors.Is(err, consentbus.ErrAlreadyPublished) {
+			return errs.New(errs.FailedPrecondition, consentbus.ErrAlreadyPublished)
+		}
+		return errs.Newf(errs.Internal, "publish: documentID[%s]: %s", doc.ID, err)
+	}
+
+	return toAppDocument(doc, mid.GetLocation(ctx))
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, consentbus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	docs, err := a.consentBus.QueryDocuments(ctx, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.consentBus.CountDocuments(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppDocuments(docs, mid.GetLocation(ctx)), total, page)
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	doc, errEnc := a.documentFromParam(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	return toAppDocument(doc, mid.GetLocation(ctx))
+}
+
+func (a *app) documentFromParam(ctx context.Context, r *http.Request) (consentbus.Document, *errs.Error) {
+	documentID, err := uuid.Parse(web.Param(r, "document_id"))
+	if err != nil {
+		return consentbus.Document{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	doc, err := a.consentBus.QueryDocumentByID(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, consentbus.ErrNotFound) {
+			return consentbus.Document{}, errs.New(errs.NotFound, consentbus.ErrNotFound)
+		}
+		return consentbus.Document{}, errs.Newf(errs.Internal, "querybyid: documentID[%s]: %s", documentID, err)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,216 @@
+package consentapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+)
+
+// Document represents a version of a legal document.
+type Document struct {
+	ID          string `json:"document_id"`
+	Kind        string `json:"kind"`
+	Version     string `json:"version"`
+	Title       string `json:"title"`
+	URL         string `json:"url,omitempty"`
+	Content     string `json:"content,omitempty"`
+	PublishedAt string `json:"published_at,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// Encode implements the encoder interface.
+func (app Document) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppDocument(bus consentbus.Document, loc *time.Location) Document {
+	app := Document{
+		ID:        bus.ID.String(),
+		Kind:      bus.Kind.String(),
+		Version:   bus.Version,
+		Title:     bus.Title,
+		URL:       bus.URL,
+		Content:   bus.Content,
+		CreatedAt: bus.CreatedAt.In(loc).Format(time.RFC3339),
+	}
+
+	if bus.IsPublished() {
+		app.PublishedAt = bus.PublishedAt.In(loc).Format(time.RFC3339)
+	}
+
+	return app
+}
+
+func toAppDocuments(docs []consentbus.Document, loc *time.Location) []Document {
+	items := make([]Document, len(docs))
+	for i, doc := range docs {
+		items[i] = toAppDocument(doc, loc)
+	}
+
+	return items
+}
+
+// Documents represents a list of legal documents.
+type Documents []Document
+
+// Encode implements the encoder interface.
+func (app Documents) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// =============================================================================
+
+// NewDocument defines the data needed to add a version of a legal document.
+type NewDocument struct {
+	Kind    string `json:"kind" validate:"required,oneof=TERMS PRIVACY"`
+	Version string `json:"version" validate:"required,max=50"`
+	Title   string `json:"title" validate:"required"`
+	URL     string `json:"url" validate:"omitempty,url"`
+	Content string `json:"content" validate:"required_without=URL"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewDocument) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewDocument) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewDocument(app NewDocument) (consentbus.NewDocument, error) {
+	kind, err := consentbus.ParseKind(app.Kind)
+	if err != nil {
+		return consentbus.NewDocument{}, fmt.Errorf("parse: %w", err)
+	}
+
+	bus := consentbus.NewDocument{
+		Kind:    kind,
+		Version: app.Version,
+		Title:   app.Title,
+		URL:     app.URL,
+		Content: app.Content,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// Acceptance represents the acceptance of a legal document by the user.
+type Acceptance struct {
+	ID         string `json:"acceptance_id"`
+	DocumentID string `json:"document_id"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+	AcceptedAt string `json:"accepted_at"`
+}
+
+func toAppAcceptances(acs []consentbus.Acceptance, loc *time.Location) []Acceptance {
+	items := make([]Acceptance, len(acs))
+	for i, ac := range acs {
+		items[i] = Acceptance{
+			ID:         ac.ID.String(),
+			DocumentID: ac.DocumentID.String(),
+			IPAddress:  ac.IPAddress,
+			UserAgent:  ac.UserAgent,
+			AcceptedAt: ac.AcceptedAt.In(loc).Format(time.RFC3339),
+		}
+	}
+
+	return items
+}
+
+// Acceptances represents a list of acceptances.
+type Acceptances []Acceptance
+
+// Encode implements the encoder interface.
+func (app Acceptances) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Accept defines the documents the user accepts.
+type Accept struct {
+	DocumentIDs []string `json:"document_ids" validate:"required,min=1,dive,uuid"`
+}
+
+// Decode implements the decoder interface.
+func (app *Accept) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app Accept) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusDocumentIDs(ids []string) ([]uuid.UUID, error) {
+	documentIDs := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		var err error
+		documentIDs[i], err = uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("parse: %w", err)
+		}
+	}
+
+	return documentIDs, nil
+}
+
+// =============================================================================
+
+// Coverage represents how many users of the organization accepted a
+// published document.
+type Coverage struct {
+	Document Document `json:"document"`
+	Current  bool     `json:"current"`
+	Users    int      `json:"users"`
+	Accepted int      `json:"accepted"`
+	Percent  float64  `json:"percent"`
+}
+
+// Coverages represents the acceptance coverage of every published document.
+type Coverages []Coverage
+
+// Encode implements the encoder interface.
+func (app Coverages) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppCoverages(cvs []consentbus.Coverage, loc *time.Location) Coverages {
+	items := make(Coverages, len(cvs))
+	for i, cv := range cvs {
+		doc := toAppDocument(cv.Document, loc)
+		doc.Content = ""
+
+		items[i] = Coverage{
+			Document: doc,
+			Current:  cv.Current,
+			Users:    cv.Users,
+			Accepted: cv.Accepted,
+		}
+
+		if cv.Users > 0 {
+			items[i].Percent = float64(cv.Accepted) * 100 / float64(cv.Users)
+		}
+	}
+
+	return items
+}
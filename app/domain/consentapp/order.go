@@ -0,0 +1,11 @@
+package consentapp
+
+import "github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+
+var orderByFields = map[string]string{
+	"document_id":  consentbus.OrderByID,
+	"kind":         consentbus.OrderByKind,
+	"version":      consentbus.OrderByVersion,
+	"published_at": consentbus.OrderByPublishedAt,
+	"created_at":   consentbus.OrderByCreatedAt,
+}
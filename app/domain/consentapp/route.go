@@ -0,0 +1,44 @@
+package consentapp
+
+import (
+	"net/http"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	ConsentBus *consentbus.Business
+	Auth       *auth.Auth
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.Auth)
+	authenWithoutConsent := mid.AuthenticateWithoutConsent(cfg.Auth)
+	denyImpersonation := mid.DenyImpersonation()
+	ruleAdmin := mid.Authorize(cfg.Auth, auth.RuleAdminOnly)
+	rulePlatformAdmin := mid.Authorize(cfg.Auth, auth.RulePlatformAdmin)
+
+	api := newApp(cfg.ConsentBus, cfg.Auth)
+
+	app.HandlerFunc(http.MethodGet, version, "/legal/current", api.queryCurrent)
+
+	app.HandlerFunc(http.MethodGet, version, "/me/consents", api.queryMine, authenWithoutConsent)
+	app.HandlerFunc(http.MethodGet, version, "/me/consents/outstanding", api.queryOutstanding, authenWithoutConsent)
+	app.HandlerFunc(http.MethodPost, version, "/me/consents", api.accept, authenWithoutConsent, denyImpersonation)
+
+	app.HandlerFunc(http.MethodGet, version, "/legal/coverage", api.queryCoverage, authen, ruleAdmin)
+
+	app.HandlerFunc(http.MethodGet, version, "/legal/documents", api.query, authen, rulePlatformAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/legal/documents/{document_id}", api.queryByID, authen, rulePlatformAdmin)
+	app.HandlerFunc(http.MethodPost, version, "/legal/documents", api.create, authen, rulePlatformAdmin)
+	app.HandlerFunc(http.MethodPost, version, "/legal/documents/{document_id}/publish", api.publish, authen, rulePlatformAdmin)
+}
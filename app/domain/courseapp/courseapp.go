@@ -3,27 +3,38 @@ package courseapp
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mailer"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/query"
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/types/role"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
 	"github.com/kamogelosekhukhune777/lms/foundation/web"
 )
 
 type app struct {
+	log       *logger.Logger
+	mailer    mailer.Mailer
 	courseBus *coursebus.Business
 	userBus   *userbus.Business
 }
 
-func newApp(courseBus *coursebus.Business, userBus *userbus.Business) *app {
+func newApp(log *logger.Logger, mlr mailer.Mailer, courseBus *coursebus.Business, userBus *userbus.Business) *app {
 	return &app{
+		log:       log,
+		mailer:    mlr,
 		courseBus: courseBus,
 		userBus:   userBus,
 	}
@@ -48,6 +59,8 @@ func (a *app) newWithTx(ctx context.Context) (*app, error) {
 	}
 
 	app := app{
+		log:       a.log,
+		mailer:    a.mailer,
 		userBus:   userBus,
 		courseBus: courseBus,
 	}
@@ -73,6 +86,9 @@ func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 
 	prd, err := a.courseBus.Create(ctx, np)
 	if err != nil {
+		if errors.Is(err, coursebus.ErrNotInstructor) {
+			return errs.New(errs.PermissionDenied, coursebus.ErrNotInstructor)
+		}
 		return errs.Newf(errs.Internal, "create: prd[%+v]: %s", prd, err)
 	}
 
@@ -100,21 +116,310 @@ func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "product missing in context: %s", err)
 	}
 
-	updPrd, err := a.courseBus.Update(ctx, prd, up)
+	userID, err := mid.GetUserID(ctx)
 	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	updPrd, err := a.courseBus.Update(ctx, prd, up, userID)
+	if err != nil {
+		if errors.Is(err, coursebus.ErrCurriculum) {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		if errors.Is(err, coursebus.ErrPublished) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
 		return errs.Newf(errs.Internal, "update: productID[%s] up[%+v]: %s", prd.ID, app, err)
 	}
 
 	return toAppCourse(updPrd)
 }
 
+func (a *app) changeStatus(ctx context.Context, r *http.Request) web.Encoder {
+	var app ChangeStatus
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	cs, err := toBusChangeStatus(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	cor, err := mid.GetCourse(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "course missing in context: %s", err)
+	}
+
+	cor, err = a.courseBus.ChangeStatus(ctx, cor, cs)
+	if err != nil {
+		if errors.Is(err, coursebus.ErrTransition) || errors.Is(err, coursebus.ErrNotReady) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "changestatus: courseID[%s]: %s", cor.ID, err)
+	}
+
+	return toAppCourse(cor)
+}
+
+func (a *app) queryReviewQueue(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	cors, err := a.courseBus.QueryInReview(ctx, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryinreview: %s", err)
+	}
+
+	total, err := a.courseBus.CountInReview(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "countinreview: %s", err)
+	}
+
+	return query.NewResult(toAppCourses(cors), total, page)
+}
+
+func (a *app) review(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewReview
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	// The instructor is notified outside of the transaction, so the app
+	// without it is kept.
+	notify := a.notifyReview
+
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	reviewerID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	nr, err := toBusNewReview(reviewerID, app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	cor, err := mid.GetCourse(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "course missing in context: %s", err)
+	}
+
+	rv, cor, err := a.courseBus.Review(ctx, cor, nr)
+	if err != nil {
+		switch {
+		case errors.Is(err, coursebus.ErrNotInReview), errors.Is(err, coursebus.ErrNotReady):
+			return errs.New(errs.FailedPrecondition, err)
+		case errors.Is(err, coursebus.ErrLectureNotFound):
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.Newf(errs.Internal, "review: courseID[%s]: %s", cor.ID, err)
+	}
+
+	mid.AfterCommit(ctx, func(ctx context.Context) {
+		go notify(context.WithoutCancel(ctx), cor, rv)
+	})
+
+	return ReviewResult{
+		Review: toAppReview(rv),
+		Course: toAppCourse(cor),
+	}
+}
+
+func (a *app) queryReviews(ctx context.Context, r *http.Request) web.Encoder {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	cor, err := mid.GetCourse(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "course missing in context: %s", err)
+	}
+
+	rvs, err := a.courseBus.QueryReviews(ctx, cor.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryreviews: courseID[%s]: %s", cor.ID, err)
+	}
+
+	return toAppReviews(rvs)
+}
+
+// notifyReview tells the instructor of the course about the outcome of its
+// review, in their language, unless they opted out of course updates. It runs
+// once the review has been committed, so a failure to notify is only logged.
+func (a *app) notifyReview(ctx context.Context, cor coursebus.Course, rv coursebus.Review) {
+	usr, err := a.userBus.QueryByID(ctx, cor.InstructorID)
+	if err != nil {
+		a.log.Error(ctx, "review: notify: querybyid", "courseID", cor.ID, "instructorID", cor.InstructorID, "err", err)
+		return
+	}
+
+	prefs, err := a.userBus.QueryPreferences(ctx, usr.ID)
+	if err != nil {
+		a.log.Error(ctx, "review: notify: querypreferences", "courseID", cor.ID, "instructorID", cor.InstructorID, "err", err)
+		return
+	}
+
+	if !prefs.Wants(userbus.TopicCourseUpdates) {
+		return
+	}
+
+	msg, err := mailer.Render(usr.UserEmail, mailer.TemplateCourseReview, prefs.Locale, toReviewMail(cor, rv, prefs.TimeZone))
+	if err != nil {
+		a.log.Error(ctx, "review: notify: render", "courseID", cor.ID, "err", err)
+		return
+	}
+
+	if err := a.mailer.Send(ctx, msg); err != nil {
+		a.log.Error(ctx, "review: notify: send", "courseID", cor.ID, "instructorID", cor.InstructorID, "err", err)
+	}
+}
+
+func (a *app) queryRevisions(ctx context.Context, r *http.Request) web.Encoder {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	cor, err := mid.GetCourse(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "course missing in context: %s", err)
+	}
+
+	rvs, err := a.courseBus.QueryRevisions(ctx, cor.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryrevisions: courseID[%s]: %s", cor.ID, err)
+	}
+
+	return toAppRevisions(rvs)
+}
+
+func (a *app) queryRevision(ctx context.Context, r *http.Request) web.Encoder {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	rv, errEnc := a.revision(ctx, web.Param(r, "revision_number"))
+	if errEnc != nil {
+		return errEnc
+	}
+
+	return toAppRevisionWithSnapshot(rv)
+}
+
+func (a *app) diffRevisions(ctx context.Context, r *http.Request) web.Encoder {
+	values := r.URL.Query()
+
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	from, errEnc := a.revision(ctx, values.Get("from"))
+	if errEnc != nil {
+		return errEnc
+	}
+
+	to, errEnc := a.revision(ctx, values.Get("to"))
+	if errEnc != nil {
+		return errEnc
+	}
+
+	return toAppRevisionDiff(from, to, coursebus.Diff(from.Snapshot, to.Snapshot))
+}
+
+func (a *app) restoreRevision(ctx context.Context, r *http.Request) web.Encoder {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	rv, errEnc := a.revision(ctx, web.Param(r, "revision_number"))
+	if errEnc != nil {
+		return errEnc
+	}
+
+	cor, err := mid.GetCourse(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "course missing in context: %s", err)
+	}
+
+	cor, err = a.courseBus.RestoreRevision(ctx, cor, rv, userID)
+	if err != nil {
+		if errors.Is(err, coursebus.ErrPublished) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "restorerevision: courseID[%s] number[%d]: %s", cor.ID, rv.Number, err)
+	}
+
+	return toAppCourse(cor)
+}
+
+// revision finds the revision of the course in context by its number.
+func (a *app) revision(ctx context.Context, number string) (coursebus.Revision, *errs.Error) {
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 {
+		return coursebus.Revision{}, errs.Newf(errs.InvalidArgument, "invalid revision number %q", number)
+	}
+
+	cor, err := mid.GetCourse(ctx)
+	if err != nil {
+		return coursebus.Revision{}, errs.Newf(errs.Internal, "course missing in context: %s", err)
+	}
+
+	rv, err := a.courseBus.QueryRevisionByNumber(ctx, cor.ID, n)
+	if err != nil {
+		if errors.Is(err, coursebus.ErrRevisionNotFound) {
+			return coursebus.Revision{}, errs.New(errs.NotFound, coursebus.ErrRevisionNotFound)
+		}
+		return coursebus.Revision{}, errs.Newf(errs.Internal, "queryrevisionbynumber: courseID[%s] number[%d]: %s", cor.ID, n, err)
+	}
+
+	return rv, nil
+}
+
 func (a *app) queryAll(ctx context.Context, r *http.Request) web.Encoder {
 	a, err := a.newWithTx(ctx)
 	if err != nil {
 		return errs.New(errs.Internal, err)
 	}
 
-	cors, err := a.courseBus.QueryAll(ctx)
+	// Instructors only get to see their own courses, admins see all of them.
+	var filter coursebus.QueryFilter
+	if !mid.GetClaims(ctx).HasRole(role.Admin) {
+		userID, err := mid.GetUserID(ctx)
+		if err != nil {
+			return errs.New(errs.Unauthenticated, err)
+		}
+		filter.InstructorID = &userID
+	}
+
+	cors, err := a.courseBus.QueryAll(ctx, filter)
 	if err != nil {
 		return errs.Newf(errs.Internal, "query: %s", err)
 	}
@@ -133,9 +438,175 @@ func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Newf(errs.Internal, "product missing in context: %s", err)
 	}
 
+	lecs, err := a.courseBus.GetLectures(ctx, prd.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "%s", err)
+	}
+
+	prd.Curriculum = lecs
+
+	secs, err := a.courseBus.QuerySections(ctx, prd.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querysections: %s", err)
+	}
+
+	prd.Sections = secs
+
 	return toAppCourse(prd)
 }
 
+func (a *app) createSection(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewSection
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	cor, err := mid.GetCourse(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "course missing in context: %s", err)
+	}
+
+	sec, err := a.courseBus.CreateSection(ctx, toBusNewSection(cor.ID, app), userID)
+	if err != nil {
+		if errors.Is(err, coursebus.ErrPublished) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "createsection: courseID[%s]: %s", cor.ID, err)
+	}
+
+	return toAppSection(sec)
+}
+
+func (a *app) updateSection(ctx context.Context, r *http.Request) web.Encoder {
+	var app UpdateSection
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	sec, errEnc := a.sectionFromParam(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	sec, err = a.courseBus.UpdateSection(ctx, sec, toBusUpdateSection(app), userID)
+	if err != nil {
+		if errors.Is(err, coursebus.ErrPublished) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "updatesection: sectionID[%s]: %s", sec.ID, err)
+	}
+
+	return toAppSection(sec)
+}
+
+func (a *app) deleteSection(ctx context.Context, r *http.Request) web.Encoder {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	sec, errEnc := a.sectionFromParam(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.courseBus.DeleteSection(ctx, sec, userID); err != nil {
+		if errors.Is(err, coursebus.ErrSectionNotEmpty) || errors.Is(err, coursebus.ErrPublished) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "deletesection: sectionID[%s]: %s", sec.ID, err)
+	}
+
+	return nil
+}
+
+func (a *app) reorderCurriculum(ctx context.Context, r *http.Request) web.Encoder {
+	var app ReorderCurriculum
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	layout, err := toBusSectionLayouts(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	cor, err := mid.GetCourse(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "course missing in context: %s", err)
+	}
+
+	secs, err := a.courseBus.ReorderCurriculum(ctx, cor.ID, layout, userID)
+	if err != nil {
+		if errors.Is(err, coursebus.ErrCurriculum) {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		if errors.Is(err, coursebus.ErrPublished) {
+			return errs.New(errs.FailedPrecondition, err)
+		}
+		return errs.Newf(errs.Internal, "reordercurriculum: courseID[%s]: %s", cor.ID, err)
+	}
+
+	return toAppSections(secs)
+}
+
+func (a *app) sectionFromParam(ctx context.Context, r *http.Request) (coursebus.Section, *errs.Error) {
+	sectionID, err := uuid.Parse(web.Param(r, "section_id"))
+	if err != nil {
+		return coursebus.Section{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	cor, err := mid.GetCourse(ctx)
+	if err != nil {
+		return coursebus.Section{}, errs.Newf(errs.Internal, "course missing in context: %s", err)
+	}
+
+	sec, err := a.courseBus.QuerySectionByID(ctx, cor.ID, sectionID)
+	if err != nil {
+		if errors.Is(err, coursebus.ErrSectionNotFound) {
+			return coursebus.Section{}, errs.New(errs.NotFound, coursebus.ErrSectionNotFound)
+		}
+		return coursebus.Section{}, errs.Newf(errs.Internal, "querysectionbyid: sectionID[%s]: %s", sectionID, err)
+	}
+
+	return sec, nil
+}
+
 //==================================================================================================================
 
 func (a *app) getAllStudentViewCourses(ctx context.Context, r *http.Request) web.Encoder {
@@ -180,6 +651,27 @@ func (a *app) getStudentViewCourseDetails(ctx context.Context, r *http.Request)
 		return errs.Newf(errs.Internal, "course missing in context: %s", err)
 	}
 
+	// Archived courses stay available to the students enrolled in them.
+	if !cor.IsLive(time.Now()) {
+		if cor.Status != coursebus.StatusArchived {
+			return errs.New(errs.NotFound, coursebus.ErrNotFound)
+		}
+
+		userID, err := mid.GetUserID(ctx)
+		if err != nil {
+			return errs.New(errs.NotFound, coursebus.ErrNotFound)
+		}
+
+		enrolled, err := a.courseBus.CheckCoursePurchaseInfo(ctx, cor.ID, userID)
+		if err != nil {
+			return errs.Newf(errs.Internal, "purchase info: %s", err)
+		}
+
+		if !enrolled {
+			return errs.New(errs.NotFound, coursebus.ErrNotFound)
+		}
+	}
+
 	lecs, err := a.courseBus.GetLectures(ctx, cor.ID)
 	if err != nil {
 		return errs.Newf(errs.Internal, "%s", err)
@@ -187,6 +679,13 @@ func (a *app) getStudentViewCourseDetails(ctx context.Context, r *http.Request)
 
 	cor.Curriculum = lecs
 
+	secs, err := a.courseBus.QuerySections(ctx, cor.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querysections: %s", err)
+	}
+
+	cor.Sections = secs
+
 	return toAppCourse(cor)
 }
 
@@ -267,9 +766,9 @@ func (a *app) markLectureAsViewed(ctx context.Context, r *http.Request) web.Enco
 		return errs.New(errs.Internal, err)
 	}
 
-	userID, err := uuid.Parse(values.Get("user_Id"))
+	userID, err := mid.GetUserID(ctx)
 	if err != nil {
-		return errs.New(errs.Internal, err)
+		return errs.New(errs.Unauthenticated, err)
 	}
 
 	courseID, err := uuid.Parse(values.Get("course_Id"))
@@ -287,7 +786,7 @@ func (a *app) markLectureAsViewed(ctx context.Context, r *http.Request) web.Enco
 		return errs.New(errs.Internal, err)
 	}
 
-	return toAppCourseProgress(corp)
+	return toAppCourseProgress(corp, mid.GetLocation(ctx))
 }
 
 func (a *app) resetCurrentCourseProgress(ctx context.Context, r *http.Request) web.Encoder {
@@ -298,9 +797,9 @@ func (a *app) resetCurrentCourseProgress(ctx context.Context, r *http.Request) w
 		return errs.New(errs.Internal, err)
 	}
 
-	userID, err := uuid.Parse(values.Get("user_Id"))
+	userID, err := mid.GetUserID(ctx)
 	if err != nil {
-		return errs.New(errs.Internal, err)
+		return errs.New(errs.Unauthenticated, err)
 	}
 
 	courseID, err := uuid.Parse(values.Get("course_Id"))
@@ -313,5 +812,67 @@ func (a *app) resetCurrentCourseProgress(ctx context.Context, r *http.Request) w
 		return errs.New(errs.Internal, err)
 	}
 
-	return toAppCourseProgress(corp)
+	return toAppCourseProgress(corp, mid.GetLocation(ctx))
+}
+
+// enroll enrolls a student in a course without an order, so an organization
+// can enroll its employees.
+func (a *app) enroll(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewEnrollment
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ne, err := toBusNewEnrollment(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	stu, err := a.courseBus.Enroll(ctx, ne)
+	if err != nil {
+		switch {
+		case errors.Is(err, userbus.ErrNotFound):
+			return errs.New(errs.NotFound, userbus.ErrNotFound)
+		case errors.Is(err, coursebus.ErrNotFound):
+			return errs.New(errs.NotFound, coursebus.ErrNotFound)
+		case errors.Is(err, coursebus.ErrEnrolled):
+			return errs.New(errs.AlreadyExists, coursebus.ErrEnrolled)
+		}
+		return errs.Newf(errs.Internal, "enroll: studentID[%s] courseID[%s]: %s", ne.StudentID, ne.CourseID, err)
+	}
+
+	return toAppStudent(stu, mid.GetLocation(ctx))
+}
+
+// queryCompletions reports the progress of the students of the organization
+// through the courses they are enrolled in.
+func (a *app) queryCompletions(ctx context.Context, r *http.Request) web.Encoder {
+	cp := parseCompletionParams(r)
+
+	page, err := page.Parse(cp.Page, cp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseCompletionFilter(cp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	cmps, err := a.courseBus.QueryCompletions(ctx, filter, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querycompletions: %s", err)
+	}
+
+	total, err := a.courseBus.CountCompletions(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "countcompletions: %s", err)
+	}
+
+	return query.NewResult(toAppCompletions(cmps, mid.GetLocation(ctx)), total, page)
 }
@@ -2,6 +2,7 @@ package courseapp
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
@@ -63,3 +64,63 @@ func parseFilter(qp queryParams) (coursebus.QueryFilter, error) {
 
 	return filter, nil
 }
+
+type completionParams struct {
+	Page      string
+	Rows      string
+	CourseID  string
+	StudentID string
+	Completed string
+}
+
+func parseCompletionParams(r *http.Request) completionParams {
+	values := r.URL.Query()
+	return completionParams{
+		Page:      values.Get("page"),
+		Rows:      values.Get("rows"),
+		CourseID:  values.Get("course_id"),
+		StudentID: values.Get("student_id"),
+		Completed: values.Get("completed"),
+	}
+}
+
+func parseCompletionFilter(cp completionParams) (coursebus.CompletionFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter coursebus.CompletionFilter
+
+	if cp.CourseID != "" {
+		id, err := uuid.Parse(cp.CourseID)
+		switch err {
+		case nil:
+			filter.CourseID = &id
+		default:
+			fieldErrors.Add("course_id", err)
+		}
+	}
+
+	if cp.StudentID != "" {
+		id, err := uuid.Parse(cp.StudentID)
+		switch err {
+		case nil:
+			filter.StudentID = &id
+		default:
+			fieldErrors.Add("student_id", err)
+		}
+	}
+
+	if cp.Completed != "" {
+		completed, err := strconv.ParseBool(cp.Completed)
+		switch err {
+		case nil:
+			filter.Completed = &completed
+		default:
+			fieldErrors.Add("completed", err)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return coursebus.CompletionFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
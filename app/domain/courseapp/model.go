@@ -3,9 +3,11 @@ package courseapp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
@@ -14,21 +16,24 @@ import (
 
 // Course represents information about an individual course.
 type Course struct {
-	ID              string    `json:"course_id"`
-	InstructorID    string    `json:"instructor_id"`
-	Title           string    `json:"title"`
-	Category        string    `json:"category"`
-	Level           string    `json:"level"`
-	PrimaryLanguage string    `json:"primary_language"`
-	Subtitle        string    `json:"subtitle"`
-	Description     string    `json:"description"`
-	Image           string    `json:"image"`
-	WelcomeMessage  string    `json:"welcome_message"`
-	Pricing         float64   `json:"pricing"`
-	Objectives      string    `json:"objectives"`
-	Curriculum      []Lecture `json:"curriculum"`
-	IsPublished     bool      `json:"is_published"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID              string     `json:"course_id"`
+	InstructorID    string     `json:"instructor_id"`
+	Title           string     `json:"title"`
+	Category        string     `json:"category"`
+	Level           string     `json:"level"`
+	PrimaryLanguage string     `json:"primary_language"`
+	Subtitle        string     `json:"subtitle"`
+	Description     string     `json:"description"`
+	Image           string     `json:"image"`
+	WelcomeMessage  string     `json:"welcome_message"`
+	Pricing         float64    `json:"pricing"`
+	Objectives      string     `json:"objectives"`
+	Curriculum      []Lecture  `json:"curriculum"`
+	Sections        []Section  `json:"sections"`
+	Status          string     `json:"status"`
+	PublishAt       *time.Time `json:"publish_at,omitempty"`
+	IsPublished     bool       `json:"is_published"`
+	CreatedAt       time.Time  `json:"created_at"`
 }
 
 // Encode implements the encoder interface.
@@ -38,6 +43,12 @@ func (app Course) Encode() ([]byte, string, error) {
 }
 
 func toAppCourse(cor coursebus.Course) Course {
+	var publishAt *time.Time
+	if !cor.PublishAt.IsZero() {
+		t := cor.PublishAt.In(time.Local)
+		publishAt = &t
+	}
+
 	return Course{
 		ID:              cor.ID.String(),
 		InstructorID:    cor.InstructorID.String(),
@@ -51,6 +62,10 @@ func toAppCourse(cor coursebus.Course) Course {
 		WelcomeMessage:  cor.WelcomeMessage,
 		Pricing:         cor.Pricing.Value(),
 		Objectives:      cor.Objectives,
+		Curriculum:      toAppLectures(cor.Curriculum),
+		Sections:        toAppSections(cor.Sections),
+		Status:          cor.Status.String(),
+		PublishAt:       publishAt,
 		IsPublished:     cor.IsPublished,
 		CreatedAt:       cor.CreatedAt.In(time.Local),
 	}
@@ -83,17 +98,17 @@ func (app BoolResult) Encode() ([]byte, string, error) {
 
 // NewCourse defines the data needed to add a new course.
 type NewCourse struct {
-	Title           string  `json:"title" validate:"required"`
-	Category        string  `json:"category" validate:"required"`
-	Level           string  `json:"level" validate:"required"`
-	PrimaryLanguage string  `json:"primary_language" validate:"required"`
-	Subtitle        string  `json:"subtitle" validate:"required"`
-	Description     string  `json:"description" validate:"required"`
-	Image           string  `json:"image" validate:"required"`
-	WelcomeMessage  string  `json:"welcome_message" validate:"required"`
-	Pricing         float64 `json:"pricing" validate:"required,gte=0"`
-	Objectives      string  `json:"objectives" validate:"required"`
-	IsPublished     bool    `json:"is_published" validate:"required"`
+	Title           string       `json:"title" validate:"required"`
+	Category        string       `json:"category" validate:"required"`
+	Level           string       `json:"level" validate:"required"`
+	PrimaryLanguage string       `json:"primary_language" validate:"required"`
+	Subtitle        string       `json:"subtitle" validate:"required"`
+	Description     string       `json:"description" validate:"required"`
+	Image           string       `json:"image" validate:"required"`
+	WelcomeMessage  string       `json:"welcome_message" validate:"required"`
+	Pricing         float64      `json:"pricing" validate:"required,gte=0"`
+	Objectives      string       `json:"objectives" validate:"required"`
+	Curriculum      []NewLecture `json:"curriculum" validate:"dive"`
 }
 
 // Decode implements the decoder interface.
@@ -133,6 +148,7 @@ func toBusNewCourse(ctx context.Context, app NewCourse) (coursebus.NewCourse, er
 		WelcomeMessage:  app.WelcomeMessage,
 		Pricing:         price,
 		Objectives:      app.Objectives,
+		Curriculum:      toBusNewLectures(app.Curriculum),
 	}
 
 	return bus, nil
@@ -142,16 +158,17 @@ func toBusNewCourse(ctx context.Context, app NewCourse) (coursebus.NewCourse, er
 
 // UpdateProduct defines the data needed to update a product.
 type UpdateCourse struct {
-	Title           *string  `json:"title"`
-	Category        *string  `json:"category"`
-	Level           *string  `json:"level"`
-	PrimaryLanguage *string  `josn:"primary_language"`
-	Subtitle        *string  `json:"subtitle"`
-	Description     *string  `josn:"description"`
-	Image           *string  `json:"image"`
-	WelcomeMessage  *string  `json:"welcome_message"`
-	Pricing         *float64 `json:"pricing" validate:"omitempty,gte=0"`
-	Objectives      *string  `json:"objectives"`
+	Title           *string         `json:"title"`
+	Category        *string         `json:"category"`
+	Level           *string         `json:"level"`
+	PrimaryLanguage *string         `josn:"primary_language"`
+	Subtitle        *string         `json:"subtitle"`
+	Description     *string         `josn:"description"`
+	Image           *string         `json:"image"`
+	WelcomeMessage  *string         `json:"welcome_message"`
+	Pricing         *float64        `json:"pricing" validate:"omitempty,gte=0"`
+	Objectives      *string         `json:"objectives"`
+	Curriculum      []UpdateLecture `json:"curriculum" validate:"dive"`
 }
 
 // Decode implements the decoder interface.
@@ -192,6 +209,63 @@ func toBusUpdateCourse(app UpdateCourse) (coursebus.UpdateCourse, error) {
 		Objectives:      app.Objectives,
 	}
 
+	if app.Curriculum != nil {
+		lecs, err := toBusUpdateLectures(app.Curriculum)
+		if err != nil {
+			return coursebus.UpdateCourse{}, err
+		}
+		bus.Curriculum = lecs
+	}
+
+	return bus, nil
+}
+
+// ChangeStatus defines the state a course moves to. PublishAt schedules the
+// publication of the course.
+type ChangeStatus struct {
+	Status    string     `json:"status" validate:"required"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// Decode implements the decoder interface.
+func (app *ChangeStatus) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app ChangeStatus) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusChangeStatus(app ChangeStatus) (coursebus.ChangeStatus, error) {
+	var fieldErrors errs.FieldErrors
+
+	status, err := coursebus.ParseStatus(app.Status)
+	if err != nil {
+		fieldErrors.Add("status", err)
+	}
+
+	var publishAt time.Time
+	if app.PublishAt != nil {
+		if status != coursebus.StatusPublished {
+			fieldErrors.Add("publish_at", errors.New("only allowed when publishing"))
+		}
+		publishAt = *app.PublishAt
+	}
+
+	if len(fieldErrors) > 0 {
+		return coursebus.ChangeStatus{}, fieldErrors
+	}
+
+	bus := coursebus.ChangeStatus{
+		Status:    status,
+		PublishAt: publishAt,
+	}
+
 	return bus, nil
 }
 
@@ -200,10 +274,12 @@ func toBusUpdateCourse(app UpdateCourse) (coursebus.UpdateCourse, error) {
 type Lecture struct {
 	ID          string `json:"lecture_id"`
 	CourseID    string `json:"course_id"`
+	SectionID   string `json:"section_id,omitempty"`
 	Title       string `json:"title"`
 	VideoURL    string `json:"video_url"`
 	PublicID    string `json:"public_id"`
 	FreePreview bool   `json:"free_preview"`
+	Position    int    `json:"position"`
 }
 
 // Encode implements the encoder interface.
@@ -212,9 +288,42 @@ func (app Lecture) Encode() ([]byte, string, error) {
 	return data, "application/json", err
 }
 
+func toAppLecture(lec coursebus.Lecture) Lecture {
+	app := Lecture{
+		ID:          lec.ID.String(),
+		CourseID:    lec.CourseID.String(),
+		Title:       lec.Title,
+		VideoURL:    lec.VideoURL,
+		PublicID:    lec.PublicID,
+		FreePreview: lec.FreePreview,
+		Position:    lec.Position,
+	}
+
+	if lec.SectionID != nil {
+		app.SectionID = lec.SectionID.String()
+	}
+
+	return app
+}
+
+func toAppLectures(lecs []coursebus.Lecture) []Lecture {
+	app := make([]Lecture, len(lecs))
+	for i, lec := range lecs {
+		app[i] = toAppLecture(lec)
+	}
+
+	return app
+}
+
 //=====================================================================
 
-type NewLecture struct{}
+// NewLecture defines the data needed to add a lecture to a curriculum.
+type NewLecture struct {
+	Title       string `json:"title" validate:"required"`
+	VideoURL    string `json:"video_url" validate:"required"`
+	PublicID    string `json:"public_id"`
+	FreePreview bool   `json:"free_preview"`
+}
 
 // Decode implements the decoder interface.
 func (app *NewLecture) Decode(data []byte) error {
@@ -230,6 +339,532 @@ func (app NewLecture) Validate() error {
 	return nil
 }
 
+func toBusNewLectures(app []NewLecture) []coursebus.Lecture {
+	bus := make([]coursebus.Lecture, len(app))
+	for i, lec := range app {
+		bus[i] = coursebus.Lecture{
+			Title:       lec.Title,
+			VideoURL:    lec.VideoURL,
+			PublicID:    lec.PublicID,
+			FreePreview: lec.FreePreview,
+		}
+	}
+
+	return bus
+}
+
+// UpdateLecture defines a lecture in the curriculum of a course being
+// updated. Lectures without an id are added to the curriculum.
+type UpdateLecture struct {
+	ID          string `json:"lecture_id" validate:"omitempty,uuid"`
+	Title       string `json:"title" validate:"required"`
+	VideoURL    string `json:"video_url" validate:"required"`
+	PublicID    string `json:"public_id"`
+	FreePreview bool   `json:"free_preview"`
+}
+
+func toBusUpdateLectures(app []UpdateLecture) ([]coursebus.Lecture, error) {
+	bus := make([]coursebus.Lecture, len(app))
+	for i, lec := range app {
+		var id uuid.UUID
+		if lec.ID != "" {
+			var err error
+			id, err = uuid.Parse(lec.ID)
+			if err != nil {
+				return nil, fmt.Errorf("parse lecture id: %w", err)
+			}
+		}
+
+		bus[i] = coursebus.Lecture{
+			ID:          id,
+			Title:       lec.Title,
+			VideoURL:    lec.VideoURL,
+			PublicID:    lec.PublicID,
+			FreePreview: lec.FreePreview,
+		}
+	}
+
+	return bus, nil
+}
+
+//========================================================================
+
+// Section represents a section of a course with its lectures.
+type Section struct {
+	ID          string    `json:"section_id"`
+	CourseID    string    `json:"course_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Position    int       `json:"position"`
+	Lectures    []Lecture `json:"lectures"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Encode implements the encoder interface.
+func (app Section) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppSection(sec coursebus.Section) Section {
+	return Section{
+		ID:          sec.ID.String(),
+		CourseID:    sec.CourseID.String(),
+		Title:       sec.Title,
+		Description: sec.Description,
+		Position:    sec.Position,
+		Lectures:    toAppLectures(sec.Lectures),
+		CreatedAt:   sec.CreatedAt.In(time.Local),
+	}
+}
+
+func toAppSections(secs []coursebus.Section) Sections {
+	app := make([]Section, len(secs))
+	for i, sec := range secs {
+		app[i] = toAppSection(sec)
+	}
+
+	return app
+}
+
+// Sections is a collection of sections.
+type Sections []Section
+
+// Encode implements the encoder interface.
+func (app Sections) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// NewSection defines the data needed to add a section to a course.
+type NewSection struct {
+	Title       string `json:"title" validate:"required"`
+	Description string `json:"description"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewSection) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewSection) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewSection(courseID uuid.UUID, app NewSection) coursebus.NewSection {
+	return coursebus.NewSection{
+		CourseID:    courseID,
+		Title:       app.Title,
+		Description: app.Description,
+	}
+}
+
+// UpdateSection defines the data needed to update a section.
+type UpdateSection struct {
+	Title       *string `json:"title" validate:"omitempty,min=1"`
+	Description *string `json:"description"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdateSection) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateSection) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusUpdateSection(app UpdateSection) coursebus.UpdateSection {
+	return coursebus.UpdateSection{
+		Title:       app.Title,
+		Description: app.Description,
+	}
+}
+
+// SectionLayout places the lectures, in order, in a section.
+type SectionLayout struct {
+	SectionID  string   `json:"section_id" validate:"required,uuid"`
+	LectureIDs []string `json:"lecture_ids" validate:"dive,uuid"`
+}
+
+// ReorderCurriculum defines the new layout of the curriculum of a course. The
+// sections are listed in order and every lecture of the course must be placed
+// in one of them.
+type ReorderCurriculum struct {
+	Sections []SectionLayout `json:"sections" validate:"required,dive"`
+}
+
+// Decode implements the decoder interface.
+func (app *ReorderCurriculum) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app ReorderCurriculum) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusSectionLayouts(app ReorderCurriculum) ([]coursebus.SectionLayout, error) {
+	bus := make([]coursebus.SectionLayout, len(app.Sections))
+	for i, sl := range app.Sections {
+		sectionID, err := uuid.Parse(sl.SectionID)
+		if err != nil {
+			return nil, fmt.Errorf("parse section id: %w", err)
+		}
+
+		lectureIDs := make([]uuid.UUID, len(sl.LectureIDs))
+		for j, id := range sl.LectureIDs {
+			lectureIDs[j], err = uuid.Parse(id)
+			if err != nil {
+				return nil, fmt.Errorf("parse lecture id: %w", err)
+			}
+		}
+
+		bus[i] = coursebus.SectionLayout{
+			SectionID:  sectionID,
+			LectureIDs: lectureIDs,
+		}
+	}
+
+	return bus, nil
+}
+
+//========================================================================
+
+// Review represents the decision of a reviewer about a course.
+type Review struct {
+	ID         string          `json:"review_id"`
+	CourseID   string          `json:"course_id"`
+	ReviewerID string          `json:"reviewer_id,omitempty"`
+	Decision   string          `json:"decision"`
+	Summary    string          `json:"summary"`
+	Comments   []ReviewComment `json:"comments"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// ReviewComment represents a feedback comment of a review.
+type ReviewComment struct {
+	ID           string `json:"comment_id"`
+	LectureID    string `json:"lecture_id,omitempty"`
+	LectureTitle string `json:"lecture_title,omitempty"`
+	Body         string `json:"body"`
+	Position     int    `json:"position"`
+}
+
+func toAppReview(rv coursebus.Review) Review {
+	app := Review{
+		ID:        rv.ID.String(),
+		CourseID:  rv.CourseID.String(),
+		Decision:  rv.Decision.String(),
+		Summary:   rv.Summary,
+		Comments:  make([]ReviewComment, len(rv.Comments)),
+		CreatedAt: rv.CreatedAt.In(time.Local),
+	}
+
+	if rv.ReviewerID != uuid.Nil {
+		app.ReviewerID = rv.ReviewerID.String()
+	}
+
+	for i, rc := range rv.Comments {
+		app.Comments[i] = ReviewComment{
+			ID:           rc.ID.String(),
+			LectureTitle: rc.LectureTitle,
+			Body:         rc.Body,
+			Position:     rc.Position,
+		}
+
+		if rc.LectureID != nil {
+			app.Comments[i].LectureID = rc.LectureID.String()
+		}
+	}
+
+	return app
+}
+
+func toAppReviews(rvs []coursebus.Review) Reviews {
+	app := make([]Review, len(rvs))
+	for i, rv := range rvs {
+		app[i] = toAppReview(rv)
+	}
+
+	return app
+}
+
+// Reviews is the review history of a course.
+type Reviews []Review
+
+// Encode implements the encoder interface.
+func (app Reviews) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// ReviewResult is the outcome of a review: the review and the course in its
+// new state.
+type ReviewResult struct {
+	Review Review `json:"review"`
+	Course Course `json:"course"`
+}
+
+// Encode implements the encoder interface.
+func (app ReviewResult) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// NewReview defines the data needed to review a course. PublishAt schedules
+// the publication of an approved course.
+type NewReview struct {
+	Decision  string             `json:"decision" validate:"required"`
+	Summary   string             `json:"summary"`
+	Comments  []NewReviewComment `json:"comments" validate:"dive"`
+	PublishAt *time.Time         `json:"publish_at"`
+}
+
+// NewReviewComment defines a feedback comment of a review. Comments without
+// a lecture are about the whole course.
+type NewReviewComment struct {
+	LectureID string `json:"lecture_id" validate:"omitempty,uuid"`
+	Body      string `json:"body" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewReview) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewReview) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewReview(reviewerID uuid.UUID, app NewReview) (coursebus.NewReview, error) {
+	var fieldErrors errs.FieldErrors
+
+	decision, err := coursebus.ParseDecision(app.Decision)
+	if err != nil {
+		fieldErrors.Add("decision", err)
+	}
+
+	if decision == coursebus.DecisionChangesRequested && app.Summary == "" && len(app.Comments) == 0 {
+		fieldErrors.Add("summary", errors.New("feedback is required when requesting changes"))
+	}
+
+	var publishAt time.Time
+	if app.PublishAt != nil {
+		if decision != coursebus.DecisionApproved {
+			fieldErrors.Add("publish_at", errors.New("only allowed when approving"))
+		}
+		publishAt = *app.PublishAt
+	}
+
+	comments := make([]coursebus.NewReviewComment, len(app.Comments))
+	for i, nc := range app.Comments {
+		comments[i] = coursebus.NewReviewComment{
+			Body: nc.Body,
+		}
+
+		if nc.LectureID != "" {
+			lectureID, err := uuid.Parse(nc.LectureID)
+			if err != nil {
+				fieldErrors.Add(fmt.Sprintf("comments[%d].lecture_id", i), err)
+				continue
+			}
+			comments[i].LectureID = &lectureID
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return coursebus.NewReview{}, fieldErrors
+	}
+
+	bus := coursebus.NewReview{
+		ReviewerID: reviewerID,
+		Decision:   decision,
+		Summary:    app.Summary,
+		Comments:   comments,
+		PublishAt:  publishAt,
+	}
+
+	return bus, nil
+}
+
+// reviewMail is the data the course review email is rendered with.
+type reviewMail struct {
+	Title     string
+	Approved  bool
+	PublishAt string
+	Summary   string
+	Comments  []coursebus.ReviewComment
+}
+
+func toReviewMail(cor coursebus.Course, rv coursebus.Review, loc *time.Location) reviewMail {
+	return reviewMail{
+		Title:     cor.Title,
+		Approved:  rv.Decision == coursebus.DecisionApproved,
+		PublishAt: cor.PublishAt.In(loc).Format(time.RFC1123),
+		Summary:   rv.Summary,
+		Comments:  rv.Comments,
+	}
+}
+
+//========================================================================
+
+// Revision represents a snapshot of a course and its curriculum. The
+// snapshot is only provided when a single revision is requested.
+type Revision struct {
+	ID           string            `json:"revision_id"`
+	CourseID     string            `json:"course_id"`
+	Number       int               `json:"number"`
+	AuthorID     string            `json:"author_id,omitempty"`
+	RestoredFrom int               `json:"restored_from,omitempty"`
+	Snapshot     *RevisionSnapshot `json:"snapshot,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// RevisionSnapshot represents the content of a course at a revision.
+type RevisionSnapshot struct {
+	Title           string    `json:"title"`
+	Category        string    `json:"category"`
+	Level           string    `json:"level"`
+	PrimaryLanguage string    `json:"primary_language"`
+	Subtitle        string    `json:"subtitle"`
+	Description     string    `json:"description"`
+	Image           string    `json:"image"`
+	WelcomeMessage  string    `json:"welcome_message"`
+	Pricing         float64   `json:"pricing"`
+	Objectives      string    `json:"objectives"`
+	Sections        []Section `json:"sections"`
+	Curriculum      []Lecture `json:"curriculum"`
+}
+
+// Encode implements the encoder interface.
+func (app Revision) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppRevision(rv coursebus.Revision) Revision {
+	app := Revision{
+		ID:           rv.ID.String(),
+		CourseID:     rv.CourseID.String(),
+		Number:       rv.Number,
+		RestoredFrom: rv.RestoredFrom,
+		CreatedAt:    rv.CreatedAt.In(time.Local),
+	}
+
+	if rv.AuthorID != uuid.Nil {
+		app.AuthorID = rv.AuthorID.String()
+	}
+
+	return app
+}
+
+func toAppRevisionWithSnapshot(rv coursebus.Revision) Revision {
+	app := toAppRevision(rv)
+
+	app.Snapshot = &RevisionSnapshot{
+		Title:           rv.Snapshot.Title,
+		Category:        rv.Snapshot.Category,
+		Level:           rv.Snapshot.Level,
+		PrimaryLanguage: rv.Snapshot.PrimaryLanguage,
+		Subtitle:        rv.Snapshot.Subtitle,
+		Description:     rv.Snapshot.Description,
+		Image:           rv.Snapshot.Image,
+		WelcomeMessage:  rv.Snapshot.WelcomeMessage,
+		Pricing:         rv.Snapshot.Pricing.Value(),
+		Objectives:      rv.Snapshot.Objectives,
+		Sections:        toAppSections(rv.Snapshot.Sections),
+		Curriculum:      toAppLectures(rv.Snapshot.Curriculum),
+	}
+
+	return app
+}
+
+func toAppRevisions(rvs []coursebus.Revision) Revisions {
+	app := make([]Revision, len(rvs))
+	for i, rv := range rvs {
+		app[i] = toAppRevision(rv)
+	}
+
+	return app
+}
+
+// Revisions is the revision history of a course.
+type Revisions []Revision
+
+// Encode implements the encoder interface.
+func (app Revisions) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Change represents the change of a field between two revisions.
+type Change struct {
+	Field     string `json:"field"`
+	SectionID string `json:"section_id,omitempty"`
+	LectureID string `json:"lecture_id,omitempty"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+// RevisionDiff represents the field level changes between two revisions.
+type RevisionDiff struct {
+	From    int      `json:"from"`
+	To      int      `json:"to"`
+	Changes []Change `json:"changes"`
+}
+
+// Encode implements the encoder interface.
+func (app RevisionDiff) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppRevisionDiff(from coursebus.Revision, to coursebus.Revision, changes []coursebus.Change) RevisionDiff {
+	app := RevisionDiff{
+		From:    from.Number,
+		To:      to.Number,
+		Changes: make([]Change, len(changes)),
+	}
+
+	for i, c := range changes {
+		app.Changes[i] = Change{
+			Field: c.Field,
+			From:  c.From,
+			To:    c.To,
+		}
+
+		if c.SectionID != uuid.Nil {
+			app.Changes[i].SectionID = c.SectionID.String()
+		}
+
+		if c.LectureID != uuid.Nil {
+			app.Changes[i].LectureID = c.LectureID.String()
+		}
+	}
+
+	return app
+}
+
 //========================================================================
 
 // Student(course Students)/(Enrollments)
@@ -247,6 +882,92 @@ func (app Student) Encode() ([]byte, string, error) {
 	return data, "application/json", err
 }
 
+func toAppStudent(bus coursebus.Student, loc *time.Location) Student {
+	return Student{
+		ID:         bus.ID.String(),
+		StudentID:  bus.StudentID.String(),
+		CourseID:   bus.CourseID.String(),
+		PaidAmount: bus.PaidAmount.Value(),
+		EnrolledAt: bus.EnrolledAt.In(loc),
+	}
+}
+
+// NewEnrollment defines the data needed to enroll a student in a course
+// without an order.
+type NewEnrollment struct {
+	StudentID string `json:"student_id" validate:"required,uuid"`
+	CourseID  string `json:"course_id" validate:"required,uuid"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewEnrollment) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewEnrollment) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewEnrollment(app NewEnrollment) (coursebus.NewEnrollment, error) {
+	studentID, err := uuid.Parse(app.StudentID)
+	if err != nil {
+		return coursebus.NewEnrollment{}, fmt.Errorf("parse student_id: %w", err)
+	}
+
+	courseID, err := uuid.Parse(app.CourseID)
+	if err != nil {
+		return coursebus.NewEnrollment{}, fmt.Errorf("parse course_id: %w", err)
+	}
+
+	bus := coursebus.NewEnrollment{
+		StudentID: studentID,
+		CourseID:  courseID,
+	}
+
+	return bus, nil
+}
+
+// Completion reports how far a student got through a course they are
+// enrolled in.
+type Completion struct {
+	EnrollmentID   string `json:"enrollment_id"`
+	StudentID      string `json:"student_id"`
+	StudentName    string `json:"student_name"`
+	StudentEmail   string `json:"student_email"`
+	CourseID       string `json:"course_id"`
+	CourseTitle    string `json:"course_title"`
+	EnrolledAt     string `json:"enrolled_at"`
+	Completed      bool   `json:"completed"`
+	CompletionDate string `json:"completion_date,omitempty"`
+}
+
+func toAppCompletions(cmps []coursebus.Completion, loc *time.Location) []Completion {
+	items := make([]Completion, len(cmps))
+	for i, cmp := range cmps {
+		items[i] = Completion{
+			EnrollmentID: cmp.EnrollmentID.String(),
+			StudentID:    cmp.StudentID.String(),
+			StudentName:  cmp.StudentName,
+			StudentEmail: cmp.StudentEmail,
+			CourseID:     cmp.CourseID.String(),
+			CourseTitle:  cmp.CourseTitle,
+			EnrolledAt:   cmp.EnrolledAt.In(loc).Format(time.RFC3339),
+			Completed:    cmp.Completed,
+		}
+
+		if !cmp.CompletionDate.IsZero() {
+			items[i].CompletionDate = cmp.CompletionDate.In(loc).Format(time.RFC3339)
+		}
+	}
+
+	return items
+}
+
 //===========================================================================
 
 type NewStudent struct{}
@@ -281,12 +1002,17 @@ func (app CourseProgess) Encode() ([]byte, string, error) {
 	return data, "application/json", err
 }
 
-func toAppCourseProgress(corp coursebus.CourseProgress) CourseProgess {
-	return CourseProgess{
-		ID:             corp.ID.String(),
-		UserID:         corp.UserID.String(),
-		CourseID:       corp.CourseID.String(),
-		Completed:      corp.Completed,
-		CompletionDate: corp.CompletionDate,
+func toAppCourseProgress(corp coursebus.CourseProgress, loc *time.Location) CourseProgess {
+	app := CourseProgess{
+		ID:        corp.ID.String(),
+		UserID:    corp.UserID.String(),
+		CourseID:  corp.CourseID.String(),
+		Completed: corp.Completed,
+	}
+
+	if !corp.CompletionDate.IsZero() {
+		app.CompletionDate = corp.CompletionDate.In(loc)
 	}
+
+	return app
 }
@@ -4,7 +4,10 @@ import (
 	"net/http"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mailer"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
@@ -18,36 +21,71 @@ type Config struct {
 	CourseBus *coursebus.Business
 	UserBus   *userbus.Business
 	DB        *sqlx.DB
+	Auth      *auth.Auth
+	Mailer    mailer.Mailer
+
+	// RequireVerifiedEmail blocks course creation until the instructor has
+	// verified their email address.
+	RequireVerifiedEmail bool
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
-	cor := mid.GetCourseByID(cfg.CourseBus)
-	usr := mid.GetUserByID(cfg.UserBus)
+	authen := mid.Authenticate(cfg.Auth)
+	authenOptional := mid.AuthenticateOptional(cfg.Auth)
+	authenCoursesRead := mid.AuthenticateWithScope(cfg.Auth, apikeybus.ScopeCoursesRead)
+	authenEnrollmentsRead := mid.AuthenticateWithScope(cfg.Auth, apikeybus.ScopeEnrollmentsRead)
+	authenEnrollmentsWrite := mid.AuthenticateWithScope(cfg.Auth, apikeybus.ScopeEnrollmentsWrite)
+	authenReportsRead := mid.AuthenticateWithScope(cfg.Auth, apikeybus.ScopeReportsRead)
+	ruleAdmin := mid.Authorize(cfg.Auth, auth.RuleAdminOnly)
+	ruleAny := mid.Authorize(cfg.Auth, auth.RuleAny)
+	ruleAdminOrInstructor := mid.Authorize(cfg.Auth, auth.RuleAdminOrInstructor)
+	ruleCourse := mid.AuthorizeCourse(cfg.Auth, cfg.CourseBus, auth.RuleAny)
+	ruleCourseOwner := mid.AuthorizeCourse(cfg.Auth, cfg.CourseBus, auth.RuleAdminOrOwner)
+	ruleCourseAdmin := mid.AuthorizeCourse(cfg.Auth, cfg.CourseBus, auth.RuleAdminOnly)
+	ruleUserSubject := mid.AuthorizeUser(cfg.Auth, cfg.UserBus, auth.RuleAdminOrSubject)
+	verified := mid.RequireVerifiedEmail(cfg.UserBus, cfg.RequireVerifiedEmail)
 	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
 
-	api := newApp(cfg.CourseBus, cfg.UserBus)
+	api := newApp(cfg.Log, cfg.Mailer, cfg.CourseBus, cfg.UserBus)
 
 	//instructor
-	app.HandlerFunc(http.MethodPost, version, "/add", api.create, transaction)
-	app.HandlerFunc(http.MethodGet, version, "/get/details/{course_id}", api.queryByID, cor, transaction)
-	app.HandlerFunc(http.MethodGet, version, "/get", api.queryAll, transaction)
-	app.HandlerFunc(http.MethodPut, version, "/update/{course_id}", api.update, cor, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/instructor/add", api.create, authen, ruleAdminOrInstructor, verified, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/instructor/get/details/{course_id}", api.queryByID, authenCoursesRead, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/instructor/get", api.queryAll, authenCoursesRead, ruleAdminOrInstructor, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/instructor/update/{course_id}", api.update, authen, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/instructor/status/{course_id}", api.changeStatus, authen, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/instructor/sections/{course_id}", api.createSection, authen, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/instructor/sections/{course_id}/{section_id}", api.updateSection, authen, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodDelete, version, "/instructor/sections/{course_id}/{section_id}", api.deleteSection, authen, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/instructor/reorder/{course_id}", api.reorderCurriculum, authen, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/instructor/reviews/{course_id}", api.queryReviews, authen, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/instructor/revisions/{course_id}", api.queryRevisions, authen, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/instructor/revisions/{course_id}/diff", api.diffRevisions, authen, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/instructor/revisions/{course_id}/{revision_number}", api.queryRevision, authen, ruleCourseOwner, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/instructor/revisions/{course_id}/{revision_number}/restore", api.restoreRevision, authen, ruleCourseOwner, transaction)
+
+	//review queue
+	app.HandlerFunc(http.MethodGet, version, "/reviews", api.queryReviewQueue, authen, ruleAdmin, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/reviews/{course_id}", api.review, authen, ruleCourseAdmin, transaction)
 
 	//student routes
 	//-course
 	app.HandlerFunc(http.MethodGet, version, "/get", api.getAllStudentViewCourses, transaction)
-	app.HandlerFunc(http.MethodGet, version, "get/details/{course_id}", api.getStudentViewCourseDetails, cor, transaction)                  //"/get/details/:id"
-	app.HandlerFunc(http.MethodGet, version, "/purchase-info/{course_id}/{student_id}", api.checkCoursePurchaseInfo, usr, cor, transaction) //"/purchase-info/:id/:studentId""
+	app.HandlerFunc(http.MethodGet, version, "/get/details/{course_id}", api.getStudentViewCourseDetails, authenOptional, ruleCourse, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/purchase-info/{course_id}/{user_id}", api.checkCoursePurchaseInfo, authenEnrollmentsRead, ruleUserSubject, ruleCourse, transaction)
 
 	//-student-courses
-	app.HandlerFunc(http.MethodGet, version, "/get/{user_id}", api.getCoursesByStudentId, usr, transaction) //----"/get/{student_id}"
+	app.HandlerFunc(http.MethodGet, version, "/get/{user_id}", api.getCoursesByStudentId, authenEnrollmentsRead, ruleUserSubject, transaction)
 
 	//-course progress
-	app.HandlerFunc(http.MethodGet, version, "/get/{user_id}/{course_id}", api.getCurrentCourseProgress, usr, cor, transaction) //"get/:userId/:courseId"
-	app.HandlerFunc(http.MethodPost, version, "/mark-lecture-viewed", api.markLectureAsViewed, transaction)
-	app.HandlerFunc(http.MethodPost, version, "/reset-progress", api.resetCurrentCourseProgress, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/get/{user_id}/{course_id}", api.getCurrentCourseProgress, authenReportsRead, ruleUserSubject, ruleCourse, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/mark-lecture-viewed", api.markLectureAsViewed, authen, ruleAny, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/reset-progress", api.resetCurrentCourseProgress, authen, ruleAny, transaction)
 
+	//-organization integrations
+	app.HandlerFunc(http.MethodPost, version, "/enrollments", api.enroll, authenEnrollmentsWrite, ruleAdmin, transaction)
+	app.HandlerFunc(http.MethodGet, version, "/reports/completions", api.queryCompletions, authenReportsRead, ruleAdmin)
 }
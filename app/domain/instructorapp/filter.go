@@ -0,0 +1,71 @@
+package instructorapp
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+)
+
+type queryParams struct {
+	Page    string
+	Rows    string
+	OrderBy string
+	ID      string
+	UserID  string
+	Status  string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+	return queryParams{
+		Page:    values.Get("page"),
+		Rows:    values.Get("rows"),
+		OrderBy: values.Get("orderBy"),
+		ID:      values.Get("application_id"),
+		UserID:  values.Get("user_id"),
+		Status:  values.Get("status"),
+	}
+}
+
+func parseFilter(qp queryParams) (instructorbus.QueryFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter instructorbus.QueryFilter
+
+	if qp.ID != "" {
+		id, err := uuid.Parse(qp.ID)
+		switch err {
+		case nil:
+			filter.ID = &id
+		default:
+			fieldErrors.Add("application_id", err)
+		}
+	}
+
+	if qp.UserID != "" {
+		id, err := uuid.Parse(qp.UserID)
+		switch err {
+		case nil:
+			filter.UserID = &id
+		default:
+			fieldErrors.Add("user_id", err)
+		}
+	}
+
+	if qp.Status != "" {
+		status, err := instructorbus.ParseStatus(qp.Status)
+		switch err {
+		case nil:
+			filter.Status = &status
+		default:
+			fieldErrors.Add("status", err)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return instructorbus.QueryFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
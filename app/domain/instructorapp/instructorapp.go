@@ -0,0 +1,226 @@
+// Package instructorapp maintains the app layer api for the instructor
+// onboarding domain.
+package instructorapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/query"
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+type app struct {
+	instructorBus *instructorbus.Business
+}
+
+func newApp(instructorBus *instructorbus.Business) *app {
+	return &app{
+		instructorBus: instructorBus,
+	}
+}
+
+// newWithTx constructs a new Handlers value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	instructorBus, err := a.instructorBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := app{
+		instructorBus: instructorBus,
+	}
+
+	return &app, nil
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewApplication
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	na, err := toBusNewApplication(ctx, app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	ia, err := a.instructorBus.Create(ctx, na)
+	if err != nil {
+		switch {
+		case errors.Is(err, instructorbus.ErrPendingApplication):
+			return errs.New(errs.AlreadyExists, instructorbus.ErrPendingApplication)
+		case errors.Is(err, instructorbus.ErrAlreadyInstructor):
+			return errs.New(errs.FailedPrecondition, instructorbus.ErrAlreadyInstructor)
+		}
+		return errs.Newf(errs.Internal, "create: userID[%s]: %s", na.UserID, err)
+	}
+
+	return toAppApplication(ia, mid.GetLocation(ctx))
+}
+
+func (a *app) queryMine(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter := instructorbus.QueryFilter{
+		UserID: &userID,
+	}
+
+	orderBy := order.NewBy(instructorbus.OrderByCreatedAt, order.DESC)
+
+	return a.queryPage(ctx, filter, orderBy, page)
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, instructorbus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	return a.queryPage(ctx, filter, orderBy, page)
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	ia, err := a.applicationFromParam(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return toAppApplication(ia, mid.GetLocation(ctx))
+}
+
+func (a *app) approve(ctx context.Context, r *http.Request) web.Encoder {
+	var app Review
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	rv, err := toBusReview(ctx, app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	ia, appErr := a.applicationFromParam(ctx, r)
+	if appErr != nil {
+		return appErr
+	}
+
+	updIA, err := a.instructorBus.Approve(ctx, ia, rv)
+	if err != nil {
+		if errors.Is(err, instructorbus.ErrAlreadyReviewed) {
+			return errs.New(errs.FailedPrecondition, instructorbus.ErrAlreadyReviewed)
+		}
+		return errs.Newf(errs.Internal, "approve: applicationID[%s]: %s", ia.ID, err)
+	}
+
+	return toAppApplication(updIA, mid.GetLocation(ctx))
+}
+
+func (a *app) reject(ctx context.Context, r *http.Request) web.Encoder {
+	var app Review
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if app.Note == "" {
+		return errs.NewFieldErrors("note", errors.New("a note is required when rejecting an application"))
+	}
+
+	rv, err := toBusReview(ctx, app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	ia, appErr := a.applicationFromParam(ctx, r)
+	if appErr != nil {
+		return appErr
+	}
+
+	updIA, err := a.instructorBus.Reject(ctx, ia, rv)
+	if err != nil {
+		if errors.Is(err, instructorbus.ErrAlreadyReviewed) {
+			return errs.New(errs.FailedPrecondition, instructorbus.ErrAlreadyReviewed)
+		}
+		return errs.Newf(errs.Internal, "reject: applicationID[%s]: %s", ia.ID, err)
+	}
+
+	return toAppApplication(updIA, mid.GetLocation(ctx))
+}
+
+// =============================================================================
+
+func (a *app) queryPage(ctx context.Context, filter instructorbus.QueryFilter, orderBy order.By, page page.Page) web.Encoder {
+	apps, err := a.instructorBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.instructorBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppApplications(apps, mid.GetLocation(ctx)), total, page)
+}
+
+func (a *app) applicationFromParam(ctx context.Context, r *http.Request) (instructorbus.Application, *errs.Error) {
+	id, err := uuid.Parse(web.Param(r, "application_id"))
+	if err != nil {
+		return instructorbus.Application{}, errs.NewFieldErrors("application_id", err)
+	}
+
+	ia, err := a.instructorBus.QueryByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, instructorbus.ErrNotFound) {
+			return instructorbus.Application{}, errs.New(errs.NotFound, instructorbus.ErrNotFound)
+		}
+		return instructorbus.Application{}, errs.Newf(errs.Internal, "querybyid: applicationID[%s]: %s", id, err)
+	}
+
+	return ia, nil
+}
@@ -0,0 +1,135 @@
+package instructorapp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+)
+
+// Application represents an application to become an instructor.
+type Application struct {
+	ID         string   `json:"application_id"`
+	UserID     string   `json:"user_id"`
+	Bio        string   `json:"bio"`
+	Expertise  []string `json:"expertise"`
+	Links      []string `json:"links"`
+	Status     string   `json:"status"`
+	ReviewerID string   `json:"reviewer_id,omitempty"`
+	ReviewNote string   `json:"review_note,omitempty"`
+	ReviewedAt string   `json:"reviewed_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// Encode implements the encoder interface.
+func (app Application) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppApplication(bus instructorbus.Application, loc *time.Location) Application {
+	app := Application{
+		ID:         bus.ID.String(),
+		UserID:     bus.UserID.String(),
+		Bio:        bus.Bio,
+		Expertise:  bus.Expertise,
+		Links:      bus.Links,
+		Status:     bus.Status.String(),
+		ReviewNote: bus.ReviewNote,
+		CreatedAt:  bus.CreatedAt.In(loc).Format(time.RFC3339),
+	}
+
+	if !bus.ReviewedAt.IsZero() {
+		app.ReviewerID = bus.ReviewerID.String()
+		app.ReviewedAt = bus.ReviewedAt.In(loc).Format(time.RFC3339)
+	}
+
+	return app
+}
+
+func toAppApplications(apps []instructorbus.Application, loc *time.Location) []Application {
+	items := make([]Application, len(apps))
+	for i, app := range apps {
+		items[i] = toAppApplication(app, loc)
+	}
+
+	return items
+}
+
+// =============================================================================
+
+// NewApplication defines the data needed to apply to become an instructor.
+type NewApplication struct {
+	Bio       string   `json:"bio" validate:"required,min=20"`
+	Expertise []string `json:"expertise" validate:"required,min=1,dive,required"`
+	Links     []string `json:"links" validate:"dive,url"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewApplication) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewApplication) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewApplication(ctx context.Context, app NewApplication) (instructorbus.NewApplication, error) {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return instructorbus.NewApplication{}, fmt.Errorf("getuserid: %w", err)
+	}
+
+	bus := instructorbus.NewApplication{
+		UserID:    userID,
+		Bio:       app.Bio,
+		Expertise: app.Expertise,
+		Links:     app.Links,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// Review defines the data an admin provides when reviewing an application.
+type Review struct {
+	Note string `json:"note"`
+}
+
+// Decode implements the decoder interface.
+func (app *Review) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app Review) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusReview(ctx context.Context, app Review) (instructorbus.Review, error) {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return instructorbus.Review{}, fmt.Errorf("getuserid: %w", err)
+	}
+
+	bus := instructorbus.Review{
+		ReviewerID: userID,
+		Note:       app.Note,
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,10 @@
+package instructorapp
+
+import "github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+
+var orderByFields = map[string]string{
+	"application_id": instructorbus.OrderByID,
+	"user_id":        instructorbus.OrderByUserID,
+	"status":         instructorbus.OrderByStatus,
+	"created_at":     instructorbus.OrderByCreatedAt,
+}
@@ -0,0 +1,41 @@
+package instructorapp
+
+import (
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log           *logger.Logger
+	InstructorBus *instructorbus.Business
+	DB            *sqlx.DB
+	Auth          *auth.Auth
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.Auth)
+	ruleAny := mid.Authorize(cfg.Auth, auth.RuleAny)
+	ruleAdmin := mid.Authorize(cfg.Auth, auth.RuleAdminOnly)
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
+
+	api := newApp(cfg.InstructorBus)
+
+	app.HandlerFunc(http.MethodPost, version, "/instructor-applications", api.create, authen, ruleAny)
+	app.HandlerFunc(http.MethodGet, version, "/instructor-applications/mine", api.queryMine, authen, ruleAny)
+
+	app.HandlerFunc(http.MethodGet, version, "/instructor-applications", api.query, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/instructor-applications/{application_id}", api.queryByID, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodPost, version, "/instructor-applications/{application_id}/approve", api.approve, authen, ruleAdmin, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/instructor-applications/{application_id}/reject", api.reject, authen, ruleAdmin, transaction)
+}
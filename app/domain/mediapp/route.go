@@ -3,7 +3,9 @@ package mediapp
 import (
 	"net/http"
 
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/cloudinary"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
 	"github.com/kamogelosekhukhune777/lms/foundation/web"
 )
@@ -12,15 +14,19 @@ import (
 type Config struct {
 	Log              *logger.Logger
 	CloudinaryClient *cloudinary.CloudinaryService
+	Auth             *auth.Auth
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
+	authen := mid.Authenticate(cfg.Auth)
+	ruleAdminOrInstructor := mid.Authorize(cfg.Auth, auth.RuleAdminOrInstructor)
+
 	api := newApp(cfg.CloudinaryClient)
 
-	app.HandlerFunc(http.MethodPost, version, "/uplaod", api.uploadFile)
-	app.HandlerFunc(http.MethodDelete, version, "/delete/{id}", api.deleteFile)
-	app.HandlerFunc(http.MethodPost, version, "/bulk-upload", api.bulkUpload)
+	app.HandlerFunc(http.MethodPost, version, "/uplaod", api.uploadFile, authen, ruleAdminOrInstructor)
+	app.HandlerFunc(http.MethodDelete, version, "/delete/{id}", api.deleteFile, authen, ruleAdminOrInstructor)
+	app.HandlerFunc(http.MethodPost, version, "/bulk-upload", api.bulkUpload, authen, ruleAdminOrInstructor)
 }
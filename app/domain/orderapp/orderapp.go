@@ -73,6 +73,9 @@ func (a *app) capturePayment(ctx context.Context, r *http.Request) web.Encoder {
 
 	ord, err := a.orderBus.GetOrderByID(ctx, orderID)
 	if err != nil {
+		if errors.Is(err, orderbus.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
 		return errs.New(errs.Internal, err)
 	}
 
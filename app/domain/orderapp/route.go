@@ -3,6 +3,8 @@ package orderapp
 import (
 	"net/http"
 
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/paypal"
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
@@ -16,14 +18,24 @@ type Config struct {
 	CourseBus *coursebus.Business
 	UserBus   *userbus.Business
 	Paypal    *paypal.PayPalClient
+	Auth      *auth.Auth
+
+	// RequireVerifiedEmail blocks purchases until the user has verified
+	// their email address.
+	RequireVerifiedEmail bool
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
+	authen := mid.Authenticate(cfg.Auth)
+	ruleAny := mid.Authorize(cfg.Auth, auth.RuleAny)
+	verified := mid.RequireVerifiedEmail(cfg.UserBus, cfg.RequireVerifiedEmail)
+	denyImpersonation := mid.DenyImpersonation()
+
 	api := newApp(cfg.CourseBus, cfg.UserBus, cfg.Paypal)
 
-	app.HandlerFunc(http.MethodPost, version, "/create", api.createOrder)
-	app.HandlerFunc(http.MethodPost, version, "/capture", api.capturePayment) //capturePaymentAndFinalizeOrder
+	app.HandlerFunc(http.MethodPost, version, "/create", api.createOrder, authen, denyImpersonation, ruleAny, verified)
+	app.HandlerFunc(http.MethodPost, version, "/capture", api.capturePayment, authen, denyImpersonation, ruleAny, verified) //capturePaymentAndFinalizeOrder
 }
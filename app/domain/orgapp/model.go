@@ -0,0 +1,133 @@
+package orgapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/domain/orgbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/types/name"
+	"github.com/kamogelosekhukhune777/lms/business/types/role"
+	"github.com/kamogelosekhukhune777/lms/business/types/slug"
+)
+
+// Organization represents information about an organization.
+type Organization struct {
+	ID        string `json:"organization_id"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Encode implements the encoder interface.
+func (app Organization) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppOrganization(bus orgbus.Organization, loc *time.Location) Organization {
+	return Organization{
+		ID:        bus.ID.String(),
+		Name:      bus.Name,
+		Slug:      bus.Slug.String(),
+		CreatedAt: bus.CreatedAt.In(loc).Format(time.RFC3339),
+		UpdatedAt: bus.UpdatedAt.In(loc).Format(time.RFC3339),
+	}
+}
+
+func toAppOrganizations(orgs []orgbus.Organization, loc *time.Location) []Organization {
+	items := make([]Organization, len(orgs))
+	for i, org := range orgs {
+		items[i] = toAppOrganization(org, loc)
+	}
+
+	return items
+}
+
+// =============================================================================
+
+// NewOrganization defines the data needed to create an organization along
+// with its first admin.
+type NewOrganization struct {
+	Name          string `json:"name" validate:"required"`
+	Slug          string `json:"slug" validate:"required"`
+	AdminName     string `json:"admin_name" validate:"required"`
+	AdminEmail    string `json:"admin_email" validate:"required,email"`
+	AdminPassword string `json:"admin_password" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewOrganization) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewOrganization) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewOrganization(app NewOrganization) (orgbus.NewOrganization, userbus.NewUser, error) {
+	slg, err := slug.Parse(app.Slug)
+	if err != nil {
+		return orgbus.NewOrganization{}, userbus.NewUser{}, fmt.Errorf("parse slug: %w", err)
+	}
+
+	addr, err := mail.ParseAddress(app.AdminEmail)
+	if err != nil {
+		return orgbus.NewOrganization{}, userbus.NewUser{}, fmt.Errorf("parse admin email: %w", err)
+	}
+
+	nme, err := name.Parse(app.AdminName)
+	if err != nil {
+		return orgbus.NewOrganization{}, userbus.NewUser{}, fmt.Errorf("parse admin name: %w", err)
+	}
+
+	org := orgbus.NewOrganization{
+		Name: app.Name,
+		Slug: slg,
+	}
+
+	admin := userbus.NewUser{
+		UserName:     nme,
+		UserEmail:    *addr,
+		PasswordHash: app.AdminPassword,
+		Roles:        []role.Role{role.Admin},
+	}
+
+	return org, admin, nil
+}
+
+// =============================================================================
+
+// UpdateOrganization defines the data needed to update an organization.
+type UpdateOrganization struct {
+	Name *string `json:"name" validate:"omitempty,min=1"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdateOrganization) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateOrganization) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusUpdateOrganization(app UpdateOrganization) orgbus.UpdateOrganization {
+	return orgbus.UpdateOrganization{
+		Name: app.Name,
+	}
+}
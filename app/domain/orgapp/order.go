@@ -0,0 +1,10 @@
+package orgapp
+
+import "github.com/kamogelosekhukhune777/lms/business/domain/orgbus"
+
+var orderByFields = map[string]string{
+	"organization_id": orgbus.OrderByID,
+	"name":            orgbus.OrderByName,
+	"slug":            orgbus.OrderBySlug,
+	"created_at":      orgbus.OrderByCreatedAt,
+}
@@ -0,0 +1,187 @@
+// Package orgapp maintains the app layer api for the organization domain.
+package orgapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/query"
+	"github.com/kamogelosekhukhune777/lms/business/domain/orgbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/foundation/password"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+type app struct {
+	orgBus  *orgbus.Business
+	userBus *userbus.Business
+}
+
+func newApp(orgBus *orgbus.Business, userBus *userbus.Business) *app {
+	return &app{
+		orgBus:  orgBus,
+		userBus: userBus,
+	}
+}
+
+// newWithTx constructs a new Handlers value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgBus, err := a.orgBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	userBus, err := a.userBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := app{
+		orgBus:  orgBus,
+		userBus: userBus,
+	}
+
+	return &app, nil
+}
+
+// create adds an organization together with its first admin, who can then
+// manage the users and courses of the organization.
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewOrganization
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	no, nu, err := toBusNewOrganization(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err = a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	org, err := a.orgBus.Create(ctx, no)
+	if err != nil {
+		if errors.Is(err, orgbus.ErrUniqueSlug) {
+			return errs.New(errs.AlreadyExists, orgbus.ErrUniqueSlug)
+		}
+		return errs.Newf(errs.Internal, "create: org[%+v]: %s", no, err)
+	}
+
+	if _, err := a.userBus.Create(tenant.Set(ctx, org.ID), nu); err != nil {
+		var pe *password.PolicyError
+		if errors.As(err, &pe) {
+			var fe errs.FieldErrors
+			for _, v := range pe.Violations {
+				fe.Add("admin_password", errors.New(v))
+			}
+			return fe.ToError()
+		}
+		return errs.Newf(errs.Internal, "create admin: orgID[%s]: %s", org.ID, err)
+	}
+
+	return toAppOrganization(org, mid.GetLocation(ctx))
+}
+
+func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
+	orgID, err := uuid.Parse(web.Param(r, "organization_id"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	return a.updateOrganization(ctx, r, orgID)
+}
+
+// updateMine lets an org admin update the organization they belong to.
+func (a *app) updateMine(ctx context.Context, r *http.Request) web.Encoder {
+	return a.updateOrganization(ctx, r, tenant.Get(ctx))
+}
+
+func (a *app) updateOrganization(ctx context.Context, r *http.Request, orgID uuid.UUID) web.Encoder {
+	var app UpdateOrganization
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	org, err := a.orgBus.QueryByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, orgbus.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.Newf(errs.Internal, "querybyid: orgID[%s]: %s", orgID, err)
+	}
+
+	org, err = a.orgBus.Update(ctx, org, toBusUpdateOrganization(app))
+	if err != nil {
+		return errs.Newf(errs.Internal, "update: orgID[%s]: %s", orgID, err)
+	}
+
+	return toAppOrganization(org, mid.GetLocation(ctx))
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, orgbus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	orgs, err := a.orgBus.Query(ctx, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.orgBus.Count(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppOrganizations(orgs, mid.GetLocation(ctx)), total, page)
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	orgID, err := uuid.Parse(web.Param(r, "organization_id"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	return a.queryOrganization(ctx, orgID)
+}
+
+// queryMine returns the organization the org admin belongs to.
+func (a *app) queryMine(ctx context.Context, r *http.Request) web.Encoder {
+	return a.queryOrganization(ctx, tenant.Get(ctx))
+}
+
+func (a *app) queryOrganization(ctx context.Context, orgID uuid.UUID) web.Encoder {
+	org, err := a.orgBus.QueryByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, orgbus.ErrNotFound) {
+			return errs.New(errs.NotFound, err)
+		}
+		return errs.Newf(errs.Internal, "querybyid: orgID[%s]: %s", orgID, err)
+	}
+
+	return toAppOrganization(org, mid.GetLocation(ctx))
+}
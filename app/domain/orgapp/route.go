@@ -0,0 +1,43 @@
+package orgapp
+
+import (
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/orgbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log     *logger.Logger
+	OrgBus  *orgbus.Business
+	UserBus *userbus.Business
+	DB      *sqlx.DB
+	Auth    *auth.Auth
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.Auth)
+	ruleAdmin := mid.Authorize(cfg.Auth, auth.RuleAdminOnly)
+	rulePlatformAdmin := mid.Authorize(cfg.Auth, auth.RulePlatformAdmin)
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
+
+	api := newApp(cfg.OrgBus, cfg.UserBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/organization", api.queryMine, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodPut, version, "/organization", api.updateMine, authen, ruleAdmin)
+
+	app.HandlerFunc(http.MethodGet, version, "/organizations", api.query, authen, rulePlatformAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/organizations/{organization_id}", api.queryByID, authen, rulePlatformAdmin)
+	app.HandlerFunc(http.MethodPost, version, "/organizations", api.create, authen, rulePlatformAdmin, transaction)
+	app.HandlerFunc(http.MethodPut, version, "/organizations/{organization_id}", api.update, authen, rulePlatformAdmin)
+}
@@ -0,0 +1,349 @@
+package privacyapp
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus"
+)
+
+// Set of formats an export can be downloaded in.
+const (
+	formatJSON = "json"
+	formatZIP  = "zip"
+)
+
+// Profile represents the account details of the user.
+type Profile struct {
+	ID         string   `json:"user_id"`
+	TenantID   string   `json:"tenant_id"`
+	Name       string   `json:"user_name"`
+	Email      string   `json:"user_email"`
+	Roles      []string `json:"roles"`
+	VerifiedAt string   `json:"verified_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// Enrollment represents a course the user is enrolled in.
+type Enrollment struct {
+	ID          string  `json:"enrollment_id"`
+	CourseID    string  `json:"course_id"`
+	CourseTitle string  `json:"course_title"`
+	PaidAmount  float64 `json:"paid_amount"`
+	EnrolledAt  string  `json:"enrolled_at"`
+}
+
+// Order represents an order placed by the user.
+type Order struct {
+	ID            string  `json:"order_id"`
+	CourseID      string  `json:"course_id"`
+	OrderStatus   string  `json:"order_status"`
+	PaymentMethod string  `json:"payment_method"`
+	PaymentStatus string  `json:"payment_status"`
+	PaymentID     string  `json:"payment_id,omitempty"`
+	CoursePricing float64 `json:"course_pricing"`
+	OrderDate     string  `json:"order_date"`
+}
+
+// CourseProgress represents the progress of the user through a course.
+type CourseProgress struct {
+	CourseID       string `json:"course_id"`
+	Completed      bool   `json:"completed"`
+	CompletionDate string `json:"completion_date,omitempty"`
+}
+
+// LectureProgress represents a lecture the user has viewed.
+type LectureProgress struct {
+	LectureID  string `json:"lecture_id"`
+	Viewed     bool   `json:"viewed"`
+	DateViewed string `json:"date_viewed,omitempty"`
+}
+
+// Application represents an application of the user to become an
+// instructor, including the note left by the reviewer.
+type Application struct {
+	ID         string   `json:"application_id"`
+	Bio        string   `json:"bio"`
+	Expertise  []string `json:"expertise"`
+	Links      []string `json:"links"`
+	Status     string   `json:"status"`
+	ReviewNote string   `json:"review_note,omitempty"`
+	ReviewedAt string   `json:"reviewed_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// Session represents a device the user signed in from.
+type Session struct {
+	ID         string `json:"session_id"`
+	Device     string `json:"device"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	RevokedAt  string `json:"revoked_at,omitempty"`
+}
+
+// Identity represents an external account linked to the user.
+type Identity struct {
+	Provider  string `json:"provider"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Consent represents a version of a legal document the user accepted.
+type Consent struct {
+	Kind       string `json:"kind"`
+	Version    string `json:"version"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+	AcceptedAt string `json:"accepted_at"`
+}
+
+// Export represents everything held about the user.
+type Export struct {
+	GeneratedAt     string            `json:"generated_at"`
+	Profile         Profile           `json:"profile"`
+	Enrollments     []Enrollment      `json:"enrollments"`
+	Orders          []Order           `json:"orders"`
+	CourseProgress  []CourseProgress  `json:"course_progress"`
+	LectureProgress []LectureProgress `json:"lecture_progress"`
+	Applications    []Application     `json:"instructor_applications"`
+	Sessions        []Session         `json:"sessions"`
+	Identities      []Identity        `json:"identities"`
+	Consents        []Consent         `json:"consents"`
+}
+
+func toAppExport(bus privacybus.Export, loc *time.Location) Export {
+	exp := Export{
+		GeneratedAt: bus.GeneratedAt.In(loc).Format(time.RFC3339),
+		Profile: Profile{
+			ID:         bus.Profile.ID.String(),
+			TenantID:   bus.Profile.TenantID.String(),
+			Name:       bus.Profile.Name,
+			Email:      bus.Profile.Email,
+			Roles:      bus.Profile.Roles,
+			VerifiedAt: formatTime(bus.Profile.VerifiedAt, loc),
+			CreatedAt:  formatTime(bus.Profile.CreatedAt, loc),
+		},
+		Enrollments:     make([]Enrollment, len(bus.Enrollments)),
+		Orders:          make([]Order, len(bus.Orders)),
+		CourseProgress:  make([]CourseProgress, len(bus.CourseProgress)),
+		LectureProgress: make([]LectureProgress, len(bus.LectureProgress)),
+		Applications:    make([]Application, len(bus.Applications)),
+		Sessions:        make([]Session, len(bus.Sessions)),
+		Identities:      make([]Identity, len(bus.Identities)),
+		Consents:        make([]Consent, len(bus.Consents)),
+	}
+
+	for i, e := range bus.Enrollments {
+		exp.Enrollments[i] = Enrollment{
+			ID:          e.ID.String(),
+			CourseID:    e.CourseID.String(),
+			CourseTitle: e.CourseTitle,
+			PaidAmount:  e.PaidAmount.Value(),
+			EnrolledAt:  formatTime(e.EnrolledAt, loc),
+		}
+	}
+
+	for i, o := range bus.Orders {
+		exp.Orders[i] = Order{
+			ID:            o.ID.String(),
+			CourseID:      o.CourseID.String(),
+			OrderStatus:   o.OrderStatus,
+			PaymentMethod: o.PaymentMethod,
+			PaymentStatus: o.PaymentStatus,
+			PaymentID:     o.PaymentID,
+			CoursePricing: o.CoursePricing.Value(),
+			OrderDate:     formatTime(o.OrderDate, loc),
+		}
+	}
+
+	for i, cp := range bus.CourseProgress {
+		exp.CourseProgress[i] = CourseProgress{
+			CourseID:       cp.CourseID.String(),
+			Completed:      cp.Completed,
+			CompletionDate: formatTime(cp.CompletionDate, loc),
+		}
+	}
+
+	for i, lp := range bus.LectureProgress {
+		exp.LectureProgress[i] = LectureProgress{
+			LectureID:  lp.LectureID.String(),
+			Viewed:     lp.Viewed,
+			DateViewed: formatTime(lp.DateViewed, loc),
+		}
+	}
+
+	for i, ia := range bus.Applications {
+		exp.Applications[i] = Application{
+			ID:         ia.ID.String(),
+			Bio:        ia.Bio,
+			Expertise:  ia.Expertise,
+			Links:      ia.Links,
+			Status:     ia.Status,
+			ReviewNote: ia.ReviewNote,
+			ReviewedAt: formatTime(ia.ReviewedAt, loc),
+			CreatedAt:  formatTime(ia.CreatedAt, loc),
+		}
+	}
+
+	for i, s := range bus.Sessions {
+		exp.Sessions[i] = Session{
+			ID:         s.ID.String(),
+			Device:     s.Device,
+			IPAddress:  s.IPAddress,
+			UserAgent:  s.UserAgent,
+			CreatedAt:  formatTime(s.CreatedAt, loc),
+			LastSeenAt: formatTime(s.LastSeenAt, loc),
+			RevokedAt:  formatTime(s.RevokedAt, loc),
+		}
+	}
+
+	for i, id := range bus.Identities {
+		exp.Identities[i] = Identity{
+			Provider:  id.Provider,
+			Email:     id.Email,
+			CreatedAt: formatTime(id.CreatedAt, loc),
+		}
+	}
+
+	for i, c := range bus.Consents {
+		exp.Consents[i] = Consent{
+			Kind:       c.Kind,
+			Version:    c.Version,
+			IPAddress:  c.IPAddress,
+			UserAgent:  c.UserAgent,
+			AcceptedAt: formatTime(c.AcceptedAt, loc),
+		}
+	}
+
+	return exp
+}
+
+// filename returns the name the archive is downloaded as.
+func (exp Export) filename(ext string) string {
+	return fmt.Sprintf("lms-export-%s.%s", exp.Profile.ID, ext)
+}
+
+// jsonArchive encodes the export as a single JSON document.
+type jsonArchive struct {
+	export Export
+}
+
+// Encode implements the encoder interface.
+func (ja jsonArchive) Encode() ([]byte, string, error) {
+	data, err := json.MarshalIndent(ja.export, "", "  ")
+	return data, "application/json", err
+}
+
+// Headers asks the client to download the export as a file.
+func (ja jsonArchive) Headers() map[string]string {
+	return map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", ja.export.filename(formatJSON)),
+	}
+}
+
+// zipArchive encodes the export as a zip archive with a JSON file per
+// section.
+type zipArchive struct {
+	export Export
+}
+
+// Encode implements the encoder interface.
+func (za zipArchive) Encode() ([]byte, string, error) {
+	exp := za.export
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", exp.Profile},
+		{"enrollments.json", exp.Enrollments},
+		{"orders.json", exp.Orders},
+		{"course_progress.json", exp.CourseProgress},
+		{"lecture_progress.json", exp.LectureProgress},
+		{"instructor_applications.json", exp.Applications},
+		{"sessions.json", exp.Sessions},
+		{"identities.json", exp.Identities},
+		{"consents.json", exp.Consents},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("create %s: %w", f.name, err)
+		}
+
+		data, err := json.MarshalIndent(f.data, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("marshal %s: %w", f.name, err)
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return nil, "", fmt.Errorf("write %s: %w", f.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, "", fmt.Errorf("close: %w", err)
+	}
+
+	return buf.Bytes(), "application/zip", nil
+}
+
+// Headers asks the client to download the export as a file.
+func (za zipArchive) Headers() map[string]string {
+	return map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", za.export.filename(formatZIP)),
+	}
+}
+
+// =============================================================================
+
+// Erasure represents a request of the user to have their personal data
+// erased.
+type Erasure struct {
+	ID           string `json:"request_id"`
+	Status       string `json:"status"`
+	RequestedAt  string `json:"requested_at"`
+	ScheduledFor string `json:"scheduled_for"`
+	CompletedAt  string `json:"completed_at,omitempty"`
+	CanceledAt   string `json:"canceled_at,omitempty"`
+}
+
+// Encode implements the encoder interface.
+func (app Erasure) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppErasure(bus privacybus.Erasure, loc *time.Location) Erasure {
+	return Erasure{
+		ID:           bus.ID.String(),
+		Status:       bus.Status.String(),
+		RequestedAt:  formatTime(bus.RequestedAt, loc),
+		ScheduledFor: formatTime(bus.ScheduledFor, loc),
+		CompletedAt:  formatTime(bus.CompletedAt, loc),
+		CanceledAt:   formatTime(bus.CanceledAt, loc),
+	}
+}
+
+// formatTime formats the time as RFC3339 in the time zone of the user,
+// leaving times that were never set empty.
+func formatTime(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.In(loc).Format(time.RFC3339)
+}
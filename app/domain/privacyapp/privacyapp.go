@@ -0,0 +1,139 @@
+// Package privacyapp maintains the app layer api for the data subject
+// requests of users.
+package privacyapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+type app struct {
+	privacyBus  *privacybus.Business
+	gracePeriod time.Duration
+}
+
+func newApp(privacyBus *privacybus.Business, gracePeriod time.Duration) *app {
+	return &app{
+		privacyBus:  privacyBus,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// newWithTx constructs a new Handlers value with the domain apis
+// using a store transaction that was created via middleware.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	privacyBus, err := a.privacyBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := app{
+		privacyBus:  privacyBus,
+		gracePeriod: a.gracePeriod,
+	}
+
+	return &app, nil
+}
+
+// export returns everything held about the caller, as a zip archive with a
+// JSON file per section by default or as a single JSON document when the
+// format=json query parameter is given.
+func (a *app) export(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "", formatZIP, formatJSON:
+	default:
+		return errs.NewFieldErrors("format", errors.New("must be one of json or zip"))
+	}
+
+	exp, err := a.privacyBus.Export(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "export: userID[%s]: %s", userID, err)
+	}
+
+	app := toAppExport(exp, mid.GetLocation(ctx))
+
+	if format == formatJSON {
+		return jsonArchive{export: app}
+	}
+
+	return zipArchive{export: app}
+}
+
+func (a *app) queryErasure(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	ers, err := a.privacyBus.QueryPendingErasure(ctx, userID)
+	if err != nil {
+		if errors.Is(err, privacybus.ErrNotFound) {
+			return errs.New(errs.NotFound, privacybus.ErrNotFound)
+		}
+		return errs.Newf(errs.Internal, "querypending: userID[%s]: %s", userID, err)
+	}
+
+	return toAppErasure(ers, mid.GetLocation(ctx))
+}
+
+func (a *app) requestErasure(ctx context.Context, r *http.Request) web.Encoder {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	ers, err := a.privacyBus.RequestErasure(ctx, userID, a.gracePeriod, web.ClientIP(r))
+	if err != nil {
+		if errors.Is(err, privacybus.ErrErasurePending) {
+			return errs.New(errs.AlreadyExists, privacybus.ErrErasurePending)
+		}
+		return errs.Newf(errs.Internal, "requesterasure: userID[%s]: %s", userID, err)
+	}
+
+	return toAppErasure(ers, mid.GetLocation(ctx))
+}
+
+func (a *app) cancelErasure(ctx context.Context, r *http.Request) web.Encoder {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	ers, err := a.privacyBus.CancelErasure(ctx, userID, web.ClientIP(r))
+	if err != nil {
+		if errors.Is(err, privacybus.ErrNotFound) {
+			return errs.New(errs.NotFound, privacybus.ErrNotFound)
+		}
+		return errs.Newf(errs.Internal, "cancelerasure: userID[%s]: %s", userID, err)
+	}
+
+	return toAppErasure(ers, mid.GetLocation(ctx))
+}
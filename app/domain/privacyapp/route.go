@@ -0,0 +1,39 @@
+package privacyapp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log                *logger.Logger
+	PrivacyBus         *privacybus.Business
+	DB                 *sqlx.DB
+	Auth               *auth.Auth
+	ErasureGracePeriod time.Duration
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.Auth)
+	denyImpersonation := mid.DenyImpersonation()
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
+
+	api := newApp(cfg.PrivacyBus, cfg.ErasureGracePeriod)
+
+	app.HandlerFunc(http.MethodGet, version, "/me/export", api.export, authen, denyImpersonation)
+	app.HandlerFunc(http.MethodGet, version, "/me/erasure", api.queryErasure, authen, denyImpersonation)
+	app.HandlerFunc(http.MethodPost, version, "/me/erasure", api.requestErasure, authen, denyImpersonation, transaction)
+	app.HandlerFunc(http.MethodDelete, version, "/me/erasure", api.cancelErasure, authen, denyImpersonation, transaction)
+}
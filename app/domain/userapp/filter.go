@@ -0,0 +1,111 @@
+package userapp
+
+import (
+	"net/http"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/types/name"
+	"github.com/kamogelosekhukhune777/lms/business/types/role"
+)
+
+type queryParams struct {
+	Page             string
+	Rows             string
+	OrderBy          string
+	ID               string
+	Name             string
+	Email            string
+	Role             string
+	StartCreatedDate string
+	EndCreatedDate   string
+}
+
+func parseQueryParams(r *http.Request) queryParams {
+	values := r.URL.Query()
+	return queryParams{
+		Page:             values.Get("page"),
+		Rows:             values.Get("rows"),
+		OrderBy:          values.Get("orderBy"),
+		ID:               values.Get("user_id"),
+		Name:             values.Get("user_name"),
+		Email:            values.Get("user_email"),
+		Role:             values.Get("role"),
+		StartCreatedDate: values.Get("start_created_date"),
+		EndCreatedDate:   values.Get("end_created_date"),
+	}
+}
+
+func parseFilter(qp queryParams) (userbus.QueryFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter userbus.QueryFilter
+
+	if qp.ID != "" {
+		id, err := uuid.Parse(qp.ID)
+		switch err {
+		case nil:
+			filter.ID = &id
+		default:
+			fieldErrors.Add("user_id", err)
+		}
+	}
+
+	if qp.Name != "" {
+		nme, err := name.Parse(qp.Name)
+		switch err {
+		case nil:
+			filter.Name = &nme
+		default:
+			fieldErrors.Add("user_name", err)
+		}
+	}
+
+	if qp.Email != "" {
+		addr, err := mail.ParseAddress(qp.Email)
+		switch err {
+		case nil:
+			filter.Email = addr
+		default:
+			fieldErrors.Add("user_email", err)
+		}
+	}
+
+	if qp.Role != "" {
+		rl, err := role.Parse(qp.Role)
+		switch err {
+		case nil:
+			filter.Role = &rl
+		default:
+			fieldErrors.Add("role", err)
+		}
+	}
+
+	if qp.StartCreatedDate != "" {
+		t, err := time.Parse(time.RFC3339, qp.StartCreatedDate)
+		switch err {
+		case nil:
+			filter.StartCreatedDate = &t
+		default:
+			fieldErrors.Add("start_created_date", err)
+		}
+	}
+
+	if qp.EndCreatedDate != "" {
+		t, err := time.Parse(time.RFC3339, qp.EndCreatedDate)
+		switch err {
+		case nil:
+			filter.EndCreatedDate = &t
+		default:
+			fieldErrors.Add("end_created_date", err)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return userbus.QueryFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
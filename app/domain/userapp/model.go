@@ -1,25 +1,39 @@
 package userapp
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/mail"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/business/types/locale"
 	"github.com/kamogelosekhukhune777/lms/business/types/name"
 	"github.com/kamogelosekhukhune777/lms/business/types/role"
+	"github.com/kamogelosekhukhune777/lms/foundation/oidc"
 	"github.com/kamogelosekhukhune777/lms/foundation/web"
+	"github.com/kamogelosekhukhune777/lms/foundation/webauthn"
 )
 
 // NewUser defines the data needed to add a new user.
 type NewUser struct {
 	Name            string `json:"user_name" validate:"required"`
 	Email           string `json:"user_email" validate:"required,email"`
-	Role            string `json:"role" validate:"required"`
+	Role            string `json:"role" validate:"required,oneof=USER STUDENT"`
 	Password        string `json:"password" validate:"required"`
 	PasswordConfirm string `json:"passwordConfirm" validate:"eqfield=Password"`
+
+	// AcceptedDocuments holds the ids of the legal documents the user
+	// accepted while registering, which must cover the current versions.
+	AcceptedDocuments []string `json:"accepted_documents"`
 }
 
 // Decode implements the decoder interface.
@@ -92,9 +106,11 @@ type userResponse struct {
 	Email        string   `json:"user_email"`
 	Roles        []string `json:"roles"`
 	PasswordHash []byte   `json:"-"`
+	Verified     bool     `json:"verified"`
 	DateCreated  string   `json:"CreatedAt"`
 
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 // Encode implements the web.Encoder interface.
@@ -110,16 +126,1133 @@ func (ur userResponse) Headers() map[string]string {
 	}
 }
 
-// toAppUserWithToken converts the business user and token into a web response.
-func toAppUserWithToken(bus *userbus.User, token string) web.Encoder {
+// toAppUserWithToken converts the business user and tokens into a web response.
+func toAppUserWithToken(bus *userbus.User, tkn tokens, loc *time.Location) web.Encoder {
+	return toUserResponse(bus, tkn, loc)
+}
 
+func toUserResponse(bus *userbus.User, tkn tokens, loc *time.Location) userResponse {
 	return userResponse{
 		ID:           bus.ID.String(),
 		Name:         bus.UserName.String(),
 		Email:        bus.UserEmail.Address,
 		Roles:        role.ParseToString(bus.Roles),
 		PasswordHash: bus.PasswordHash,
-		DateCreated:  bus.CreatedAt.Format(time.RFC3339),
-		Token:        token,
+		Verified:     bus.IsVerified(),
+		DateCreated:  bus.CreatedAt.In(loc).Format(time.RFC3339),
+		Token:        tkn.access,
+		RefreshToken: tkn.refresh,
+	}
+}
+
+// =============================================================================
+
+// User represents information about an individual user.
+type User struct {
+	ID          string   `json:"user_id"`
+	Name        string   `json:"user_name"`
+	Email       string   `json:"user_email"`
+	Roles       []string `json:"roles"`
+	Verified    bool     `json:"verified"`
+	DateCreated string   `json:"CreatedAt"`
+}
+
+// Encode implements the encoder interface.
+func (app User) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppUser(bus userbus.User, loc *time.Location) User {
+	return User{
+		ID:          bus.ID.String(),
+		Name:        bus.UserName.String(),
+		Email:       bus.UserEmail.Address,
+		Roles:       role.ParseToString(bus.Roles),
+		Verified:    bus.IsVerified(),
+		DateCreated: bus.CreatedAt.In(loc).Format(time.RFC3339),
+	}
+}
+
+func toAppUsers(users []userbus.User, loc *time.Location) []User {
+	app := make([]User, len(users))
+	for i, usr := range users {
+		app[i] = toAppUser(usr, loc)
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// UpdateUser defines the data needed for an admin to update a user.
+type UpdateUser struct {
+	Name            *string `json:"user_name"`
+	Email           *string `json:"user_email" validate:"omitempty,email"`
+	Password        *string `json:"password"`
+	PasswordConfirm *string `json:"passwordConfirm" validate:"omitempty,eqfield=Password"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdateUser) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateUser) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusUpdateUser(app UpdateUser) (userbus.UpdateUser, error) {
+	var nme *name.Name
+	if app.Name != nil {
+		nm, err := name.Parse(*app.Name)
+		if err != nil {
+			return userbus.UpdateUser{}, fmt.Errorf("parse: %w", err)
+		}
+		nme = &nm
+	}
+
+	var addr *mail.Address
+	if app.Email != nil {
+		var err error
+		addr, err = mail.ParseAddress(*app.Email)
+		if err != nil {
+			return userbus.UpdateUser{}, fmt.Errorf("parse: %w", err)
+		}
+	}
+
+	bus := userbus.UpdateUser{
+		UserName:  nme,
+		UserEmail: addr,
+		Password:  app.Password,
+	}
+
+	return bus, nil
+}
+
+// UpdateUserRole defines the data needed for an admin to change the roles
+// of a user.
+type UpdateUserRole struct {
+	Roles []string `json:"roles" validate:"required,min=1"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdateUserRole) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateUserRole) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusUpdateUserRole(app UpdateUserRole) (userbus.UpdateUser, error) {
+	roles, err := role.ParseMany(app.Roles)
+	if err != nil {
+		return userbus.UpdateUser{}, fmt.Errorf("parse: %w", err)
+	}
+
+	bus := userbus.UpdateUser{
+		Roles: roles,
+	}
+
+	return bus, nil
+}
+
+// UpdateProfile defines the data a user needs to update their own account.
+// The current password is required to change the email or password.
+type UpdateProfile struct {
+	Name            *string `json:"user_name"`
+	Email           *string `json:"user_email" validate:"omitempty,email"`
+	Password        *string `json:"password"`
+	PasswordConfirm *string `json:"passwordConfirm" validate:"omitempty,eqfield=Password"`
+	CurrentPassword string  `json:"current_password"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdateProfile) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateProfile) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	if app.sensitive() && app.CurrentPassword == "" {
+		var fe errs.FieldErrors
+		fe.Add("current_password", errors.New("current_password is required to change the email or password"))
+		return fe
+	}
+
+	return nil
+}
+
+// sensitive reports whether the update changes the email or password.
+func (app UpdateProfile) sensitive() bool {
+	return app.Email != nil || app.Password != nil
+}
+
+func toBusUpdateProfile(app UpdateProfile) (userbus.UpdateUser, error) {
+	return toBusUpdateUser(UpdateUser{
+		Name:     app.Name,
+		Email:    app.Email,
+		Password: app.Password,
+	})
+}
+
+// =============================================================================
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *refreshRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app refreshRequest) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Encode implements the web.Encoder interface.
+func (tr tokenResponse) Encode() ([]byte, string, error) {
+	b, err := json.Marshal(tr)
+	return b, "application/json", err
+}
+
+// Headers allows the response to add extra HTTP headers (here, the Authorization header).
+func (tr tokenResponse) Headers() map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + tr.Token,
+	}
+}
+
+func toAppTokens(tkn tokens) tokenResponse {
+	return tokenResponse{
+		Token:        tkn.access,
+		RefreshToken: tkn.refresh,
+	}
+}
+
+// =============================================================================
+
+type forgotPassword struct {
+	Email string `json:"user_email" validate:"required,email"`
+}
+
+// Decode implements the decoder interface.
+func (app *forgotPassword) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app forgotPassword) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+type resetPassword struct {
+	Token           string `json:"token" validate:"required"`
+	Password        string `json:"password" validate:"required"`
+	PasswordConfirm string `json:"passwordConfirm" validate:"eqfield=Password"`
+}
+
+// Decode implements the decoder interface.
+func (app *resetPassword) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app resetPassword) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+type magicLinkRequest struct {
+	Email string `json:"user_email" validate:"required,email"`
+}
+
+// Decode implements the decoder interface.
+func (app *magicLinkRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app magicLinkRequest) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+type magicLinkLogin struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *magicLinkLogin) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app magicLinkLogin) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+type verifyEmail struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *verifyEmail) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app verifyEmail) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// Set of challenges a login can stop at before tokens are issued.
+const (
+	challengeSecondFactor = "second_factor"
+	challengeEnroll       = "enroll"
+)
+
+type challengeResponse struct {
+	ChallengeToken string   `json:"challenge_token"`
+	Type           string   `json:"challenge_type"`
+	Methods        []string `json:"methods,omitempty"`
+}
+
+// Encode implements the web.Encoder interface.
+func (cr challengeResponse) Encode() ([]byte, string, error) {
+	b, err := json.Marshal(cr)
+	return b, "application/json", err
+}
+
+type twoFactorLogin struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *twoFactorLogin) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app twoFactorLogin) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+type twoFactorChallenge struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *twoFactorChallenge) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app twoFactorChallenge) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+type twoFactorCode struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *twoFactorCode) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app twoFactorCode) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// TOTPEnrollment represents what a client needs to set up an authenticator
+// app.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// Encode implements the encoder interface.
+func (app TOTPEnrollment) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppTOTPEnrollment(bus userbus.TOTPEnrollment) TOTPEnrollment {
+	return TOTPEnrollment{
+		Secret: bus.Secret,
+		URI:    bus.URI,
 	}
 }
+
+// RecoveryCodes represents a new set of recovery codes. They are only ever
+// shown once.
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// Encode implements the encoder interface.
+func (app RecoveryCodes) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// userWithRecoveryCodes is returned when two-factor enrollment completes a
+// login.
+type userWithRecoveryCodes struct {
+	userResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Encode implements the web.Encoder interface.
+func (ur userWithRecoveryCodes) Encode() ([]byte, string, error) {
+	b, err := json.Marshal(ur)
+	return b, "application/json", err
+}
+
+// TwoFactorPolicy represents the roles that must use two-factor
+// authentication.
+type TwoFactorPolicy struct {
+	Roles []string `json:"roles" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *TwoFactorPolicy) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Encode implements the encoder interface.
+func (app TwoFactorPolicy) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// Validate checks the data in the model is considered clean.
+func (app TwoFactorPolicy) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// OIDCProviders lists the external identity providers users can sign in
+// with.
+type OIDCProviders struct {
+	Providers []string `json:"providers"`
+}
+
+// Encode implements the encoder interface.
+func (app OIDCProviders) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// OIDCStart contains the URL the client sends the user to in order to sign
+// in with an external provider.
+type OIDCStart struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// Encode implements the encoder interface.
+func (app OIDCStart) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+type oidcCallback struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *oidcCallback) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app oidcCallback) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusExternalIdentity(provider string, claims oidc.Claims) (userbus.ExternalIdentity, error) {
+	addr, err := mail.ParseAddress(claims.Email)
+	if err != nil {
+		return userbus.ExternalIdentity{}, fmt.Errorf("parse email: %w", err)
+	}
+
+	bus := userbus.ExternalIdentity{
+		Provider:      provider,
+		Subject:       claims.Subject,
+		Email:         *addr,
+		EmailVerified: claims.EmailVerified,
+		UserName:      identityName(claims.Name, addr.Address),
+	}
+
+	return bus, nil
+}
+
+// identityName turns the display name from a provider into a valid user
+// name, falling back to the local part of the email address.
+func identityName(display string, email string) name.Name {
+	if nme, err := name.Parse(display); err == nil {
+		return nme
+	}
+
+	candidate := display
+	if candidate == "" {
+		candidate, _, _ = strings.Cut(email, "@")
+	}
+
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == ' ', r == '-', r == '\'':
+			return r
+		case r == '.' || r == '_':
+			return ' '
+		}
+		return -1
+	}, candidate)
+
+	clean = strings.TrimSpace(clean)
+	if len(clean) > 20 {
+		clean = strings.TrimSpace(clean[:20])
+	}
+
+	if nme, err := name.Parse(clean); err == nil {
+		return nme
+	}
+
+	return name.MustParse("New Learner")
+}
+
+// Identity represents an external identity linked to the user.
+type Identity struct {
+	ID          string `json:"identity_id"`
+	Provider    string `json:"provider"`
+	Email       string `json:"email"`
+	DateCreated string `json:"CreatedAt"`
+}
+
+// Identities represents the external identities linked to the user.
+type Identities []Identity
+
+// Encode implements the encoder interface.
+func (app Identities) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppIdentities(idns []userbus.Identity, loc *time.Location) Identities {
+	app := make(Identities, len(idns))
+	for i, idn := range idns {
+		app[i] = Identity{
+			ID:          idn.ID.String(),
+			Provider:    idn.Provider,
+			Email:       idn.Email,
+			DateCreated: idn.CreatedAt.In(loc).Format(time.RFC3339),
+		}
+	}
+
+	return app
+}
+
+// =============================================================================
+
+// PasskeyCreationOptions contains what the client passes to
+// navigator.credentials.create to register a passkey.
+type PasskeyCreationOptions struct {
+	ChallengeID string                `json:"challenge_id"`
+	PublicKey   publicKeyCreationJSON `json:"publicKey"`
+}
+
+// Encode implements the encoder interface.
+func (app PasskeyCreationOptions) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+type publicKeyCreationJSON struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     rpEntity               `json:"rp"`
+	User                   userEntity             `json:"user"`
+	PubKeyCredParams       []credentialParam      `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	Attestation            string                 `json:"attestation"`
+	ExcludeCredentials     []credentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection authenticatorSelection `json:"authenticatorSelection"`
+}
+
+type rpEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type userEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type credentialParam struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type credentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type authenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+func toAppCreationOptions(rp webauthn.RelyingParty, wc userbus.WebAuthnChallenge, usr userbus.User, existing []userbus.Passkey, timeout time.Duration) PasskeyCreationOptions {
+	params := make([]credentialParam, len(webauthn.Algorithms))
+	for i, alg := range webauthn.Algorithms {
+		params[i] = credentialParam{Type: "public-key", Alg: alg}
+	}
+
+	return PasskeyCreationOptions{
+		ChallengeID: wc.ID.String(),
+		PublicKey: publicKeyCreationJSON{
+			Challenge: base64.RawURLEncoding.EncodeToString(wc.Challenge),
+			RP: rpEntity{
+				ID:   rp.ID,
+				Name: rp.Name,
+			},
+			User: userEntity{
+				ID:          base64.RawURLEncoding.EncodeToString(usr.ID[:]),
+				Name:        usr.UserEmail.Address,
+				DisplayName: usr.UserName.String(),
+			},
+			PubKeyCredParams:   params,
+			Timeout:            timeout.Milliseconds(),
+			Attestation:        "none",
+			ExcludeCredentials: toCredentialDescriptors(existing),
+			AuthenticatorSelection: authenticatorSelection{
+				ResidentKey:      "preferred",
+				UserVerification: "preferred",
+			},
+		},
+	}
+}
+
+// PasskeyRequestOptions contains what the client passes to
+// navigator.credentials.get to sign in with a passkey.
+type PasskeyRequestOptions struct {
+	ChallengeID string               `json:"challenge_id"`
+	PublicKey   publicKeyRequestJSON `json:"publicKey"`
+}
+
+// Encode implements the encoder interface.
+func (app PasskeyRequestOptions) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+type publicKeyRequestJSON struct {
+	Challenge        string                 `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int64                  `json:"timeout"`
+	AllowCredentials []credentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+func toAppRequestOptions(rp webauthn.RelyingParty, wc userbus.WebAuthnChallenge, allowed []userbus.Passkey, userVerification string, timeout time.Duration) PasskeyRequestOptions {
+	return PasskeyRequestOptions{
+		ChallengeID: wc.ID.String(),
+		PublicKey: publicKeyRequestJSON{
+			Challenge:        base64.RawURLEncoding.EncodeToString(wc.Challenge),
+			RPID:             rp.ID,
+			Timeout:          timeout.Milliseconds(),
+			AllowCredentials: toCredentialDescriptors(allowed),
+			UserVerification: userVerification,
+		},
+	}
+}
+
+func toCredentialDescriptors(pks []userbus.Passkey) []credentialDescriptor {
+	descs := make([]credentialDescriptor, len(pks))
+	for i, pk := range pks {
+		descs[i] = credentialDescriptor{
+			Type:       "public-key",
+			ID:         base64.RawURLEncoding.EncodeToString(pk.CredentialID),
+			Transports: pk.Transports,
+		}
+	}
+
+	return descs
+}
+
+// NewPasskey defines the registration response needed to add a passkey.
+type NewPasskey struct {
+	ChallengeID string `json:"challenge_id" validate:"required,uuid"`
+	Name        string `json:"name" validate:"max=64"`
+	Credential  struct {
+		ID       string `json:"id" validate:"required"`
+		Type     string `json:"type" validate:"required,eq=public-key"`
+		Response struct {
+			ClientDataJSON    string   `json:"clientDataJSON" validate:"required"`
+			AttestationObject string   `json:"attestationObject" validate:"required"`
+			Transports        []string `json:"transports"`
+		} `json:"response"`
+	} `json:"credential"`
+}
+
+// Decode implements the decoder interface.
+func (app *NewPasskey) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app NewPasskey) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusNewPasskey(app NewPasskey) (uuid.UUID, userbus.NewPasskey, error) {
+	challengeID, err := uuid.Parse(app.ChallengeID)
+	if err != nil {
+		return uuid.UUID{}, userbus.NewPasskey{}, fmt.Errorf("parse challenge_id: %w", err)
+	}
+
+	clientData, err := decodeBase64URL(app.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return uuid.UUID{}, userbus.NewPasskey{}, fmt.Errorf("decode clientDataJSON: %w", err)
+	}
+
+	attObj, err := decodeBase64URL(app.Credential.Response.AttestationObject)
+	if err != nil {
+		return uuid.UUID{}, userbus.NewPasskey{}, fmt.Errorf("decode attestationObject: %w", err)
+	}
+
+	bus := userbus.NewPasskey{
+		Name:              app.Name,
+		Transports:        app.Credential.Response.Transports,
+		ClientDataJSON:    clientData,
+		AttestationObject: attObj,
+	}
+
+	return challengeID, bus, nil
+}
+
+type passkeyBegin struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *passkeyBegin) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app passkeyBegin) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+// passkeyAssertion is the response of an authenticator asked to sign in. The
+// challenge token is only needed when the passkey is used as a second factor.
+type passkeyAssertion struct {
+	ChallengeID    string `json:"challenge_id" validate:"required,uuid"`
+	ChallengeToken string `json:"challenge_token"`
+	Credential     struct {
+		ID       string `json:"id" validate:"required"`
+		Type     string `json:"type" validate:"required,eq=public-key"`
+		Response struct {
+			ClientDataJSON    string `json:"clientDataJSON" validate:"required"`
+			AuthenticatorData string `json:"authenticatorData" validate:"required"`
+			Signature         string `json:"signature" validate:"required"`
+			UserHandle        string `json:"userHandle"`
+		} `json:"response"`
+	} `json:"credential"`
+}
+
+// Decode implements the decoder interface.
+func (app *passkeyAssertion) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app passkeyAssertion) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	return nil
+}
+
+func toBusPasskeyAssertion(app passkeyAssertion) (uuid.UUID, userbus.PasskeyAssertion, error) {
+	challengeID, err := uuid.Parse(app.ChallengeID)
+	if err != nil {
+		return uuid.UUID{}, userbus.PasskeyAssertion{}, fmt.Errorf("parse challenge_id: %w", err)
+	}
+
+	credID, err := decodeBase64URL(app.Credential.ID)
+	if err != nil {
+		return uuid.UUID{}, userbus.PasskeyAssertion{}, fmt.Errorf("decode id: %w", err)
+	}
+
+	clientData, err := decodeBase64URL(app.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return uuid.UUID{}, userbus.PasskeyAssertion{}, fmt.Errorf("decode clientDataJSON: %w", err)
+	}
+
+	authData, err := decodeBase64URL(app.Credential.Response.AuthenticatorData)
+	if err != nil {
+		return uuid.UUID{}, userbus.PasskeyAssertion{}, fmt.Errorf("decode authenticatorData: %w", err)
+	}
+
+	sig, err := decodeBase64URL(app.Credential.Response.Signature)
+	if err != nil {
+		return uuid.UUID{}, userbus.PasskeyAssertion{}, fmt.Errorf("decode signature: %w", err)
+	}
+
+	userHandle, err := decodeBase64URL(app.Credential.Response.UserHandle)
+	if err != nil {
+		return uuid.UUID{}, userbus.PasskeyAssertion{}, fmt.Errorf("decode userHandle: %w", err)
+	}
+
+	bus := userbus.PasskeyAssertion{
+		CredentialID:      credID,
+		ClientDataJSON:    clientData,
+		AuthenticatorData: authData,
+		Signature:         sig,
+		UserHandle:        userHandle,
+	}
+
+	return challengeID, bus, nil
+}
+
+// Passkey represents a passkey registered by the user.
+type Passkey struct {
+	ID          string   `json:"passkey_id"`
+	Name        string   `json:"name"`
+	Transports  []string `json:"transports"`
+	LastUsedAt  string   `json:"last_used_at,omitempty"`
+	DateCreated string   `json:"CreatedAt"`
+}
+
+// Encode implements the encoder interface.
+func (app Passkey) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppPasskey(bus userbus.Passkey, loc *time.Location) Passkey {
+	app := Passkey{
+		ID:          bus.ID.String(),
+		Name:        bus.Name,
+		Transports:  bus.Transports,
+		DateCreated: bus.CreatedAt.In(loc).Format(time.RFC3339),
+	}
+
+	if !bus.LastUsedAt.IsZero() {
+		app.LastUsedAt = bus.LastUsedAt.In(loc).Format(time.RFC3339)
+	}
+
+	return app
+}
+
+// Passkeys represents the passkeys registered by the user.
+type Passkeys []Passkey
+
+// Encode implements the encoder interface.
+func (app Passkeys) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppPasskeys(pks []userbus.Passkey, loc *time.Location) Passkeys {
+	app := make(Passkeys, len(pks))
+	for i, pk := range pks {
+		app[i] = toAppPasskey(pk, loc)
+	}
+
+	return app
+}
+
+// decodeBase64URL decodes the base64url values used by WebAuthn, with or
+// without padding.
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// =============================================================================
+
+// tokenLink builds the link to the page of the app that redeems the token.
+// Links for users outside the default organization name the organization,
+// so the app can send it back in the X-Tenant header.
+func tokenLink(ctx context.Context, appURL string, path string, token string) string {
+	values := url.Values{"token": {token}}
+	if tenantID := tenant.Get(ctx); tenantID != tenant.DefaultID {
+		values.Set("tenant", tenantID.String())
+	}
+
+	return fmt.Sprintf("%s/%s?%s", appURL, path, values.Encode())
+}
+
+// tokenMail is the data the token link emails are rendered with.
+type tokenMail struct {
+	Link string
+	TTL  time.Duration
+}
+
+// =============================================================================
+
+// Session represents a signed in client of the user.
+type Session struct {
+	ID          string `json:"session_id"`
+	Device      string `json:"device"`
+	IPAddress   string `json:"ip_address"`
+	UserAgent   string `json:"user_agent"`
+	Current     bool   `json:"current"`
+	LastSeenAt  string `json:"last_seen_at"`
+	ExpiresAt   string `json:"expires_at"`
+	DateCreated string `json:"CreatedAt"`
+}
+
+// Encode implements the encoder interface.
+func (app Session) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppSession(bus userbus.Session, currentID string, loc *time.Location) Session {
+	return Session{
+		ID:          bus.ID.String(),
+		Device:      bus.Device,
+		IPAddress:   bus.IPAddress,
+		UserAgent:   bus.UserAgent,
+		Current:     bus.ID.String() == currentID,
+		LastSeenAt:  bus.LastSeenAt.In(loc).Format(time.RFC3339),
+		ExpiresAt:   bus.ExpiresAt.In(loc).Format(time.RFC3339),
+		DateCreated: bus.CreatedAt.In(loc).Format(time.RFC3339),
+	}
+}
+
+// Sessions represents the active sessions of the user.
+type Sessions []Session
+
+// Encode implements the encoder interface.
+func (app Sessions) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppSessions(sessions []userbus.Session, currentID string, loc *time.Location) Sessions {
+	app := make(Sessions, len(sessions))
+	for i, sess := range sessions {
+		app[i] = toAppSession(sess, currentID, loc)
+	}
+
+	return app
+}
+
+// RevokedSessions reports how many sessions were signed out.
+type RevokedSessions struct {
+	Revoked int `json:"revoked"`
+}
+
+// Encode implements the encoder interface.
+func (app RevokedSessions) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// =============================================================================
+
+// Impersonation represents a token that lets an admin act as a user.
+type Impersonation struct {
+	Token     string `json:"token"`
+	UserID    string `json:"user_id"`
+	UserName  string `json:"user_name"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// Encode implements the encoder interface.
+func (app Impersonation) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppImpersonation(usr userbus.User, token string, expiresAt time.Time, loc *time.Location) Impersonation {
+	return Impersonation{
+		Token:     token,
+		UserID:    usr.ID.String(),
+		UserName:  usr.UserName.String(),
+		ExpiresAt: expiresAt.In(loc).Format(time.RFC3339),
+	}
+}
+
+// =============================================================================
+
+// Preferences represents the locale, time zone and notification settings of
+// the user.
+type Preferences struct {
+	Locale              string `json:"locale"`
+	TimeZone            string `json:"time_zone"`
+	NotifyCourseUpdates bool   `json:"notify_course_updates"`
+	NotifyMarketing     bool   `json:"notify_marketing"`
+	UpdatedAt           string `json:"updated_at,omitempty"`
+}
+
+// Encode implements the encoder interface.
+func (app Preferences) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppPreferences(bus userbus.Preferences) Preferences {
+	app := Preferences{
+		Locale:              bus.Locale.String(),
+		TimeZone:            bus.TimeZone.String(),
+		NotifyCourseUpdates: bus.NotifyCourseUpdates,
+		NotifyMarketing:     bus.NotifyMarketing,
+	}
+
+	if !bus.UpdatedAt.IsZero() {
+		app.UpdatedAt = bus.UpdatedAt.In(bus.TimeZone).Format(time.RFC3339)
+	}
+
+	return app
+}
+
+// UpdatePreferences defines the data a user needs to change their
+// preferences. The time zone is an IANA name such as Africa/Johannesburg.
+type UpdatePreferences struct {
+	Locale              *string `json:"locale" validate:"omitempty,oneof=en es fr pt"`
+	TimeZone            *string `json:"time_zone"`
+	NotifyCourseUpdates *bool   `json:"notify_course_updates"`
+	NotifyMarketing     *bool   `json:"notify_marketing"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdatePreferences) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdatePreferences) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	if app.TimeZone != nil {
+		// An empty name and Local load the UTC and server time zones, which
+		// is not what the user asked for.
+		_, err := time.LoadLocation(*app.TimeZone)
+		if err != nil || *app.TimeZone == "" || *app.TimeZone == "Local" {
+			var fe errs.FieldErrors
+			fe.Add("time_zone", fmt.Errorf("time_zone %q is not a known time zone", *app.TimeZone))
+			return fe
+		}
+	}
+
+	return nil
+}
+
+func toBusUpdatePreferences(app UpdatePreferences) (userbus.UpdatePreferences, error) {
+	var loc *locale.Locale
+	if app.Locale != nil {
+		l, err := locale.Parse(*app.Locale)
+		if err != nil {
+			return userbus.UpdatePreferences{}, fmt.Errorf("parse: %w", err)
+		}
+		loc = &l
+	}
+
+	var tz *time.Location
+	if app.TimeZone != nil {
+		var err error
+		tz, err = time.LoadLocation(*app.TimeZone)
+		if err != nil {
+			return userbus.UpdatePreferences{}, fmt.Errorf("parse: %w", err)
+		}
+	}
+
+	bus := userbus.UpdatePreferences{
+		Locale:              loc,
+		TimeZone:            tz,
+		NotifyCourseUpdates: app.NotifyCourseUpdates,
+		NotifyMarketing:     app.NotifyMarketing,
+	}
+
+	return bus, nil
+}
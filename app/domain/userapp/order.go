@@ -0,0 +1,11 @@
+package userapp
+
+import "github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+
+var orderByFields = map[string]string{
+	"user_id":    userbus.OrderByID,
+	"user_name":  userbus.OrderByName,
+	"user_email": userbus.OrderByEmail,
+	"roles":      userbus.OrderByRoles,
+	"created_at": userbus.OrderByCreatedAt,
+}
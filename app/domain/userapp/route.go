@@ -2,27 +2,112 @@ package userapp
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mailer"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/loginbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/oidc"
 	"github.com/kamogelosekhukhune777/lms/foundation/web"
+	"github.com/kamogelosekhukhune777/lms/foundation/webauthn"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Log     *logger.Logger
-	UserBus *userbus.Business
-	Auth    *auth.Auth
+	Log                  *logger.Logger
+	UserBus              *userbus.Business
+	LoginBus             *loginbus.Business
+	ConsentBus           *consentbus.Business
+	PrivacyBus           *privacybus.Business
+	DB                   *sqlx.DB
+	Auth                 *auth.Auth
+	Mailer               mailer.Mailer
+	AppURL               string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	ChallengeTTL         time.Duration
+	TOTPIssuer           string
+	OIDCProviders        map[string]*oidc.Provider
+	OIDCStateTTL         time.Duration
+	MagicLinkTTL         time.Duration
+	MagicLinkLimit       int
+	MagicLinkWindow      time.Duration
+	RelyingParty         webauthn.RelyingParty
+	WebAuthnTimeout      time.Duration
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
-	api := newApp(cfg.UserBus, cfg.Auth)
+	api := newApp(cfg)
 
-	app.HandlerFunc(http.MethodGet, version, "/check-auth", api.checkAuth)
+	authen := mid.Authenticate(cfg.Auth)
+	ruleAdmin := mid.Authorize(cfg.Auth, auth.RuleAdminOnly)
+	rulePlatformAdmin := mid.Authorize(cfg.Auth, auth.RulePlatformAdmin)
+	ruleAuthorizeUser := mid.AuthorizeUser(cfg.Auth, cfg.UserBus, auth.RuleAdminOnly)
+	denyImpersonation := mid.DenyImpersonation()
+	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
+
+	app.HandlerFunc(http.MethodGet, version, "/check-auth", api.checkAuth, authen)
 	app.HandlerFunc(http.MethodPost, version, "/register", api.create)
 	app.HandlerFunc(http.MethodPut, version, "/login", api.logIn)
+	app.HandlerFunc(http.MethodPost, version, "/login/magic-link", api.requestMagicLink)
+	app.HandlerFunc(http.MethodPost, version, "/login/magic-link/verify", api.logInMagicLink)
+	app.HandlerFunc(http.MethodPost, version, "/login/2fa", api.logInTwoFactor)
+	app.HandlerFunc(http.MethodPost, version, "/login/2fa/webauthn/begin", api.beginPasskeySecondFactor)
+	app.HandlerFunc(http.MethodPost, version, "/login/2fa/webauthn/finish", api.finishPasskeySecondFactor)
+	app.HandlerFunc(http.MethodPost, version, "/login/webauthn/begin", api.beginPasskeyLogin)
+	app.HandlerFunc(http.MethodPost, version, "/login/webauthn/finish", api.finishPasskeyLogin)
+	app.HandlerFunc(http.MethodPost, version, "/login/2fa/enroll", api.logInEnroll)
+	app.HandlerFunc(http.MethodPost, version, "/login/2fa/confirm", api.logInConfirm)
+	app.HandlerFunc(http.MethodPost, version, "/refresh", api.refresh)
+	app.HandlerFunc(http.MethodPost, version, "/logout", api.logOut)
+	app.HandlerFunc(http.MethodPost, version, "/password/forgot", api.forgotPassword)
+	app.HandlerFunc(http.MethodPost, version, "/password/reset", api.resetPassword, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/verify-email", api.verifyEmail)
+	app.HandlerFunc(http.MethodPost, version, "/verify-email/resend", api.resendVerification, authen)
+
+	app.HandlerFunc(http.MethodGet, version, "/auth/oidc/providers", api.queryOIDCProviders)
+	app.HandlerFunc(http.MethodPost, version, "/auth/oidc/{provider}/start", api.oidcStart)
+	app.HandlerFunc(http.MethodPost, version, "/auth/oidc/{provider}/callback", api.oidcCallback)
+	app.HandlerFunc(http.MethodGet, version, "/me/identities", api.queryIdentities, authen)
+	app.HandlerFunc(http.MethodDelete, version, "/me/identities/{identity_id}", api.deleteIdentity, authen, denyImpersonation)
+
+	app.HandlerFunc(http.MethodPost, version, "/webauthn/register/begin", api.beginPasskeyRegistration, authen, denyImpersonation)
+	app.HandlerFunc(http.MethodPost, version, "/webauthn/register/finish", api.finishPasskeyRegistration, authen, denyImpersonation)
+	app.HandlerFunc(http.MethodGet, version, "/webauthn/credentials", api.queryPasskeys, authen)
+	app.HandlerFunc(http.MethodDelete, version, "/webauthn/credentials/{passkey_id}", api.deletePasskey, authen, denyImpersonation)
+
+	app.HandlerFunc(http.MethodPost, version, "/2fa/enroll", api.enrollTOTP, authen, denyImpersonation)
+	app.HandlerFunc(http.MethodPost, version, "/2fa/confirm", api.confirmTOTP, authen, denyImpersonation)
+	app.HandlerFunc(http.MethodPost, version, "/2fa/disable", api.disableTOTP, authen, denyImpersonation)
+	app.HandlerFunc(http.MethodPost, version, "/2fa/recovery-codes", api.regenerateRecoveryCodes, authen, denyImpersonation)
+	app.HandlerFunc(http.MethodGet, version, "/admin/2fa-policy", api.queryTwoFactorPolicy, authen, rulePlatformAdmin)
+	app.HandlerFunc(http.MethodPut, version, "/admin/2fa-policy", api.updateTwoFactorPolicy, authen, rulePlatformAdmin)
+
+	app.HandlerFunc(http.MethodGet, version, "/me/sessions", api.querySessions, authen)
+	app.HandlerFunc(http.MethodDelete, version, "/me/sessions/{session_id}", api.revokeSession, authen, denyImpersonation)
+	app.HandlerFunc(http.MethodPost, version, "/me/sessions/revoke-others", api.revokeOtherSessions, authen, denyImpersonation)
+
+	app.HandlerFunc(http.MethodGet, version, "/me/preferences", api.queryPreferences, authen)
+	app.HandlerFunc(http.MethodPut, version, "/me/preferences", api.updatePreferences, authen)
+
+	app.HandlerFunc(http.MethodGet, version, "/me", api.me, authen)
+	app.HandlerFunc(http.MethodPut, version, "/me", api.updateMe, authen, denyImpersonation)
+
+	app.HandlerFunc(http.MethodGet, version, "/users", api.query, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}", api.queryByID, authen, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodPut, version, "/users/{user_id}", api.update, authen, denyImpersonation, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodPut, version, "/users/role/{user_id}", api.updateRole, authen, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}", api.delete, authen, denyImpersonation, ruleAuthorizeUser, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/users/unlock/{user_id}", api.unlock, authen, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodPost, version, "/users/impersonate/{user_id}", api.impersonate, authen, denyImpersonation, ruleAuthorizeUser)
 }
@@ -4,29 +4,106 @@ package userapp
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/mail"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mailer"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/query"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/loginbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/types/role"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/oidc"
+	"github.com/kamogelosekhukhune777/lms/foundation/password"
 	"github.com/kamogelosekhukhune777/lms/foundation/web"
+	"github.com/kamogelosekhukhune777/lms/foundation/webauthn"
 )
 
 type app struct {
-	userBus *userbus.Business
-	auth    *auth.Auth
+	log                  *logger.Logger
+	userBus              *userbus.Business
+	loginBus             *loginbus.Business
+	consentBus           *consentbus.Business
+	privacyBus           *privacybus.Business
+	auth                 *auth.Auth
+	mailer               mailer.Mailer
+	appURL               string
+	passwordResetTTL     time.Duration
+	emailVerificationTTL time.Duration
+	challengeTTL         time.Duration
+	totpIssuer           string
+	oidcProviders        map[string]*oidc.Provider
+	oidcStateTTL         time.Duration
+	magicLinkTTL         time.Duration
+	magicLinkLimit       int
+	magicLinkWindow      time.Duration
+	relyingParty         webauthn.RelyingParty
+	webAuthnTimeout      time.Duration
 }
 
-func newApp(userBus *userbus.Business, auth *auth.Auth) *app {
+func newApp(cfg Config) *app {
 	return &app{
-		userBus: userBus,
-		auth:    auth,
+		log:                  cfg.Log,
+		userBus:              cfg.UserBus,
+		loginBus:             cfg.LoginBus,
+		consentBus:           cfg.ConsentBus,
+		privacyBus:           cfg.PrivacyBus,
+		auth:                 cfg.Auth,
+		mailer:               cfg.Mailer,
+		appURL:               cfg.AppURL,
+		passwordResetTTL:     cfg.PasswordResetTTL,
+		emailVerificationTTL: cfg.EmailVerificationTTL,
+		challengeTTL:         cfg.ChallengeTTL,
+		totpIssuer:           cfg.TOTPIssuer,
+		oidcProviders:        cfg.OIDCProviders,
+		oidcStateTTL:         cfg.OIDCStateTTL,
+		magicLinkTTL:         cfg.MagicLinkTTL,
+		magicLinkLimit:       cfg.MagicLinkLimit,
+		magicLinkWindow:      cfg.MagicLinkWindow,
+		relyingParty:         cfg.RelyingParty,
+		webAuthnTimeout:      cfg.WebAuthnTimeout,
 	}
 }
 
+// newWithTx constructs a new app value with the business packages that take
+// part in the transaction of the request using it.
+func (a *app) newWithTx(ctx context.Context) (*app, error) {
+	tx, err := mid.GetTran(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userBus, err := a.userBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	privacyBus, err := a.privacyBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := *a
+	app.userBus = userBus
+	app.privacyBus = privacyBus
+
+	return &app, nil
+}
+
 func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 	var app NewUser
 	if err := web.Decode(r, &app); err != nil {
@@ -38,31 +115,69 @@ func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
+	documentIDs, errEnc := a.checkAcceptedDocuments(ctx, app.AcceptedDocuments)
+	if errEnc != nil {
+		return errEnc
+	}
+
 	usr, err := a.userBus.Create(ctx, nc)
 	if err != nil {
+		if errEnc := policyError(err); errEnc != nil {
+			return errEnc
+		}
 		if errors.Is(err, userbus.ErrUniqueEmail) {
 			return errs.New(errs.Aborted, userbus.ErrUniqueEmail)
 		}
 		return errs.Newf(errs.Internal, "create: usr[%+v]: %s", usr, err)
 	}
 
-	claims := auth.Claims{
-		Roles: []string{"USER"},
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   usr.ID.String(),
-			Issuer:    a.auth.Issuer(),
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(100 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		},
+	// A user whose acceptance couldn't be recorded is asked to accept again
+	// on their first request, so the account is kept.
+	if _, err := a.consentBus.Accept(ctx, usr.ID, documentIDs, web.ClientIP(r), r.UserAgent()); err != nil {
+		a.log.Error(ctx, "create: record consent", "userID", usr.ID, "err", err)
 	}
 
-	token, err := a.auth.GenerateToken(claims)
+	if err := a.sendVerification(ctx, usr.ID); err != nil {
+		a.log.Error(ctx, "create: send verification", "userID", usr.ID, "err", err)
+	}
+
+	tkn, err := a.issueTokens(ctx, r, usr)
+	if err != nil {
+		return errs.Newf(errs.Internal, "create: %s", err)
+	}
+
+	return toAppUserWithToken(&usr, tkn, mid.GetLocation(ctx))
+}
+
+// checkAcceptedDocuments makes sure a registration accepts every current
+// legal document, and returns the ids of the current documents to record
+// the acceptance against.
+func (a *app) checkAcceptedDocuments(ctx context.Context, ids []string) ([]uuid.UUID, *errs.Error) {
+	accepted := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		documentID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, errs.NewFieldErrors("accepted_documents", err)
+		}
+
+		accepted[documentID] = true
+	}
+
+	current, err := a.consentBus.QueryCurrent(ctx)
 	if err != nil {
-		return errs.Newf(errs.Internal, "create: failed to generate token: %s", err)
+		return nil, errs.Newf(errs.Internal, "querycurrent: %s", err)
 	}
 
-	return toAppUserWithToken(&usr, token)
+	documentIDs := make([]uuid.UUID, len(current))
+	for i, doc := range current {
+		if !accepted[doc.ID] {
+			return nil, errs.NewFieldErrors("accepted_documents", fmt.Errorf("version %s of the %s document must be accepted", doc.Version, strings.ToLower(doc.Kind.String())))
+		}
+
+		documentIDs[i] = doc.ID
+	}
 
+	return documentIDs, nil
 }
 
 func (a *app) logIn(ctx context.Context, r *http.Request) web.Encoder {
@@ -76,33 +191,1343 @@ func (a *app) logIn(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
-	// Find user by email
-	usr, err := a.userBus.Authenticate(ctx, *email, app.Password)
+	usr, err := a.loginBus.Authenticate(ctx, *email, app.Password, web.ClientIP(r))
 	if err != nil {
-		if errors.Is(err, userbus.ErrAuthenticationFailure) {
+		switch {
+		case errors.Is(err, userbus.ErrAuthenticationFailure):
 			return errs.New(errs.Unauthenticated, errors.New("invalid email or password"))
+		case errors.Is(err, loginbus.ErrTooManyAttempts):
+			return errs.New(errs.TooManyRequests, errors.New("too many login attempts, try again later"))
 		}
 		return errs.Newf(errs.Internal, "logIn: failed to authenticate user: %s", err)
 	}
 
-	claims := auth.Claims{
-		Roles: []string{"USER"},
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   usr.ID.String(),
-			Issuer:    a.auth.Issuer(),
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(100 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		},
+	return a.completeLogIn(ctx, r, usr)
+}
+
+// logInTwoFactor completes a login that was challenged for a TOTP or
+// recovery code.
+func (a *app) logInTwoFactor(ctx context.Context, r *http.Request) web.Encoder {
+	var app twoFactorLogin
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
 	}
 
-	token, err := a.auth.GenerateToken(claims)
+	usr, errEnc := a.challengeUser(ctx, app.ChallengeToken, auth.PurposeTwoFactor)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.loginBus.VerifySecondFactor(ctx, usr, app.Code, web.ClientIP(r)); err != nil {
+		return twoFactorError(err, errs.Unauthenticated)
+	}
+
+	tkn, err := a.issueTokens(ctx, r, usr)
 	if err != nil {
-		return errs.Newf(errs.Internal, "logIn: failed to generate token: %s", err)
+		return errs.Newf(errs.Internal, "logintwofactor: %s", err)
 	}
 
-	return toAppUserWithToken(&usr, token)
+	return toAppUserWithToken(&usr, tkn, mid.GetLocation(ctx))
 }
 
-func (a *app) checkAuth(ctx context.Context, r *http.Request) web.Encoder {
+// logInEnroll starts the TOTP enrollment of a user whose role requires
+// two-factor authentication before they can finish logging in.
+func (a *app) logInEnroll(ctx context.Context, r *http.Request) web.Encoder {
+	var app twoFactorChallenge
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, errEnc := a.challengeUser(ctx, app.ChallengeToken, auth.PurposeTwoFactorEnroll)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	enr, err := a.userBus.EnrollTOTP(ctx, usr, a.totpIssuer)
+	if err != nil {
+		return twoFactorError(err, errs.InvalidArgument)
+	}
+
+	return toAppTOTPEnrollment(enr)
+}
+
+// logInConfirm confirms the enrollment started by logInEnroll and completes
+// the login. The recovery codes are returned along with the tokens.
+func (a *app) logInConfirm(ctx context.Context, r *http.Request) web.Encoder {
+	var app twoFactorLogin
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, errEnc := a.challengeUser(ctx, app.ChallengeToken, auth.PurposeTwoFactorEnroll)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	codes, err := a.userBus.ConfirmTOTP(ctx, usr, app.Code)
+	if err != nil {
+		return twoFactorError(err, errs.Unauthenticated)
+	}
+
+	tkn, err := a.issueTokens(ctx, r, usr)
+	if err != nil {
+		return errs.Newf(errs.Internal, "loginconfirm: %s", err)
+	}
+
+	return userWithRecoveryCodes{
+		userResponse:  toUserResponse(&usr, tkn, mid.GetLocation(ctx)),
+		RecoveryCodes: codes,
+	}
+}
+
+func (a *app) queryOIDCProviders(ctx context.Context, r *http.Request) web.Encoder {
+	names := make([]string, 0, len(a.oidcProviders))
+	for name := range a.oidcProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return OIDCProviders{Providers: names}
+}
+
+// oidcStart begins a sign in with an external provider. The client sends the
+// user to the returned URL and the provider redirects back to the client with
+// the code and state for oidcCallback.
+func (a *app) oidcStart(ctx context.Context, r *http.Request) web.Encoder {
+	prv, errEnc := a.oidcProvider(r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return errs.Newf(errs.Internal, "oidcstart: verifier: %s", err)
+	}
+
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return errs.Newf(errs.Internal, "oidcstart: nonce: %s", err)
+	}
+
+	state, err := a.userBus.CreateOIDCState(ctx, prv.Name(), verifier, nonce, a.oidcStateTTL)
+	if err != nil {
+		return errs.Newf(errs.Internal, "oidcstart: %s", err)
+	}
+
+	authURL, err := prv.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return errs.Newf(errs.Internal, "oidcstart: provider[%s]: %s", prv.Name(), err)
+	}
+
+	return OIDCStart{AuthorizationURL: authURL}
+}
+
+// oidcCallback completes a sign in with an external provider. The identity
+// is linked to an account the first time it is seen and the login then
+// continues like a password login.
+func (a *app) oidcCallback(ctx context.Context, r *http.Request) web.Encoder {
+	var app oidcCallback
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	prv, errEnc := a.oidcProvider(r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	st, err := a.userBus.ConsumeOIDCState(ctx, prv.Name(), app.State)
+	if err != nil {
+		if errors.Is(err, userbus.ErrInvalidToken) || errors.Is(err, userbus.ErrTokenExpired) {
+			return errs.New(errs.InvalidArgument, errors.New("invalid or expired sign in state"))
+		}
+		return errs.Newf(errs.Internal, "oidccallback: %s", err)
+	}
+
+	claims, err := prv.Exchange(ctx, app.Code, st.CodeVerifier, st.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			return errs.New(errs.Unauthenticated, oidc.ErrInvalidIDToken)
+		}
+		return errs.Newf(errs.Internal, "oidccallback: provider[%s]: %s", prv.Name(), err)
+	}
+
+	ext, err := toBusExternalIdentity(prv.Name(), claims)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := a.userBus.AuthenticateIdentity(ctx, ext)
+	if err != nil {
+		switch {
+		case errors.Is(err, userbus.ErrIdentityNotVerified):
+			return errs.New(errs.FailedPrecondition, userbus.ErrIdentityNotVerified)
+		case errors.Is(err, userbus.ErrEmailNotVerified):
+			return errs.New(errs.FailedPrecondition, errors.New("an account with this email address exists, verify it before signing in with this provider"))
+		case errors.Is(err, userbus.ErrUniqueEmail):
+			return errs.New(errs.Aborted, userbus.ErrUniqueEmail)
+		}
+		return errs.Newf(errs.Internal, "oidccallback: authenticateidentity: %s", err)
+	}
+
+	return a.completeLogIn(ctx, r, usr)
+}
+
+func (a *app) queryIdentities(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	idns, err := a.userBus.QueryIdentities(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "queryidentities: userID[%s]: %s", userID, err)
+	}
+
+	return toAppIdentities(idns, mid.GetLocation(ctx))
+}
+
+func (a *app) deleteIdentity(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	identityID, err := uuid.Parse(web.Param(r, "identity_id"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := a.userBus.DeleteIdentity(ctx, userID, identityID); err != nil {
+		if errors.Is(err, userbus.ErrIdentityNotFound) {
+			return errs.New(errs.NotFound, userbus.ErrIdentityNotFound)
+		}
+		return errs.Newf(errs.Internal, "deleteidentity: identityID[%s]: %s", identityID, err)
+	}
+
+	return nil
+}
+
+func (a *app) beginPasskeyRegistration(ctx context.Context, r *http.Request) web.Encoder {
+	usr, errEnc := a.currentUser(ctx)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	existing, err := a.userBus.QueryPasskeys(ctx, usr.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querypasskeys: userID[%s]: %s", usr.ID, err)
+	}
+
+	wc, err := a.userBus.BeginPasskeyCeremony(ctx, usr.ID, userbus.PasskeyRegister, a.webAuthnTimeout)
+	if err != nil {
+		return errs.Newf(errs.Internal, "beginpasskeyceremony: userID[%s]: %s", usr.ID, err)
+	}
+
+	return toAppCreationOptions(a.relyingParty, wc, usr, existing, a.webAuthnTimeout)
+}
+
+func (a *app) finishPasskeyRegistration(ctx context.Context, r *http.Request) web.Encoder {
+	var app NewPasskey
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	challengeID, np, err := toBusNewPasskey(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, errEnc := a.currentUser(ctx)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	pk, err := a.userBus.RegisterPasskey(ctx, a.relyingParty, usr, challengeID, np)
+	if err != nil {
+		switch {
+		case errors.Is(err, userbus.ErrInvalidToken) || errors.Is(err, userbus.ErrTokenExpired):
+			return errs.New(errs.InvalidArgument, errors.New("invalid or expired passkey challenge"))
+		case errors.Is(err, webauthn.ErrVerification):
+			return errs.New(errs.InvalidArgument, webauthn.ErrVerification)
+		case errors.Is(err, userbus.ErrPasskeyExists):
+			return errs.New(errs.AlreadyExists, userbus.ErrPasskeyExists)
+		}
+		return errs.Newf(errs.Internal, "registerpasskey: userID[%s]: %s", usr.ID, err)
+	}
+
+	return toAppPasskey(pk, mid.GetLocation(ctx))
+}
+
+func (a *app) queryPasskeys(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	pks, err := a.userBus.QueryPasskeys(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querypasskeys: userID[%s]: %s", userID, err)
+	}
+
+	return toAppPasskeys(pks, mid.GetLocation(ctx))
+}
+
+func (a *app) deletePasskey(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	passkeyID, err := uuid.Parse(web.Param(r, "passkey_id"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := a.userBus.DeletePasskey(ctx, userID, passkeyID); err != nil {
+		if errors.Is(err, userbus.ErrPasskeyNotFound) {
+			return errs.New(errs.NotFound, userbus.ErrPasskeyNotFound)
+		}
+		return errs.Newf(errs.Internal, "deletepasskey: passkeyID[%s]: %s", passkeyID, err)
+	}
+
 	return nil
 }
+
+// beginPasskeyLogin starts a passwordless login. No credentials are listed
+// so the authenticator offers the discoverable passkeys it holds for the
+// site.
+func (a *app) beginPasskeyLogin(ctx context.Context, r *http.Request) web.Encoder {
+	wc, err := a.userBus.BeginPasskeyCeremony(ctx, uuid.UUID{}, userbus.PasskeyLogin, a.webAuthnTimeout)
+	if err != nil {
+		return errs.Newf(errs.Internal, "beginpasskeyceremony: %s", err)
+	}
+
+	return toAppRequestOptions(a.relyingParty, wc, nil, "required", a.webAuthnTimeout)
+}
+
+// finishPasskeyLogin completes a passwordless login. A verified passkey
+// already combines possession and user verification, so no second factor
+// is asked for.
+func (a *app) finishPasskeyLogin(ctx context.Context, r *http.Request) web.Encoder {
+	var app passkeyAssertion
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	challengeID, pa, err := toBusPasskeyAssertion(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := a.loginBus.AuthenticatePasskey(ctx, a.relyingParty, challengeID, pa, web.ClientIP(r))
+	if err != nil {
+		return passkeyError(err)
+	}
+
+	tkn, err := a.issueTokens(ctx, r, usr)
+	if err != nil {
+		return errs.Newf(errs.Internal, "finishpasskeylogin: %s", err)
+	}
+
+	return toAppUserWithToken(&usr, tkn, mid.GetLocation(ctx))
+}
+
+// beginPasskeySecondFactor starts the passkey ceremony for a login that was
+// challenged for a second factor.
+func (a *app) beginPasskeySecondFactor(ctx context.Context, r *http.Request) web.Encoder {
+	var app passkeyBegin
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, errEnc := a.challengeUser(ctx, app.ChallengeToken, auth.PurposeTwoFactor)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	pks, err := a.userBus.QueryPasskeys(ctx, usr.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querypasskeys: userID[%s]: %s", usr.ID, err)
+	}
+
+	if len(pks) == 0 {
+		return errs.New(errs.FailedPrecondition, userbus.ErrPasskeyNotFound)
+	}
+
+	wc, err := a.userBus.BeginPasskeyCeremony(ctx, usr.ID, userbus.PasskeySecondFactor, a.webAuthnTimeout)
+	if err != nil {
+		return errs.Newf(errs.Internal, "beginpasskeyceremony: userID[%s]: %s", usr.ID, err)
+	}
+
+	return toAppRequestOptions(a.relyingParty, wc, pks, "preferred", a.webAuthnTimeout)
+}
+
+// finishPasskeySecondFactor completes a login that was challenged for a
+// second factor with a passkey.
+func (a *app) finishPasskeySecondFactor(ctx context.Context, r *http.Request) web.Encoder {
+	var app passkeyAssertion
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	challengeID, pa, err := toBusPasskeyAssertion(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, errEnc := a.challengeUser(ctx, app.ChallengeToken, auth.PurposeTwoFactor)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.loginBus.VerifyPasskey(ctx, a.relyingParty, usr, challengeID, pa, web.ClientIP(r)); err != nil {
+		return passkeyError(err)
+	}
+
+	tkn, err := a.issueTokens(ctx, r, usr)
+	if err != nil {
+		return errs.Newf(errs.Internal, "finishpasskeysecondfactor: %s", err)
+	}
+
+	return toAppUserWithToken(&usr, tkn, mid.GetLocation(ctx))
+}
+
+func (a *app) enrollTOTP(ctx context.Context, r *http.Request) web.Encoder {
+	usr, errEnc := a.currentUser(ctx)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	enr, err := a.userBus.EnrollTOTP(ctx, usr, a.totpIssuer)
+	if err != nil {
+		return twoFactorError(err, errs.InvalidArgument)
+	}
+
+	return toAppTOTPEnrollment(enr)
+}
+
+func (a *app) confirmTOTP(ctx context.Context, r *http.Request) web.Encoder {
+	var app twoFactorCode
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, errEnc := a.currentUser(ctx)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	codes, err := a.userBus.ConfirmTOTP(ctx, usr, app.Code)
+	if err != nil {
+		return twoFactorError(err, errs.InvalidArgument)
+	}
+
+	return RecoveryCodes{Codes: codes}
+}
+
+func (a *app) disableTOTP(ctx context.Context, r *http.Request) web.Encoder {
+	var app twoFactorCode
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, errEnc := a.currentUser(ctx)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	if err := a.userBus.DisableTOTP(ctx, usr, app.Code); err != nil {
+		return twoFactorError(err, errs.InvalidArgument)
+	}
+
+	return nil
+}
+
+func (a *app) regenerateRecoveryCodes(ctx context.Context, r *http.Request) web.Encoder {
+	var app twoFactorCode
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, errEnc := a.currentUser(ctx)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	codes, err := a.userBus.RegenerateRecoveryCodes(ctx, usr, app.Code)
+	if err != nil {
+		return twoFactorError(err, errs.InvalidArgument)
+	}
+
+	return RecoveryCodes{Codes: codes}
+}
+
+func (a *app) queryTwoFactorPolicy(ctx context.Context, r *http.Request) web.Encoder {
+	roles, err := a.userBus.TwoFactorRoles(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "twofactorroles: %s", err)
+	}
+
+	return TwoFactorPolicy{Roles: role.ParseToString(roles)}
+}
+
+func (a *app) updateTwoFactorPolicy(ctx context.Context, r *http.Request) web.Encoder {
+	var app TwoFactorPolicy
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	roles, err := role.ParseMany(app.Roles)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := a.userBus.SetTwoFactorRoles(ctx, roles); err != nil {
+		return errs.Newf(errs.Internal, "settwofactorroles: %s", err)
+	}
+
+	return TwoFactorPolicy{Roles: role.ParseToString(roles)}
+}
+
+func (a *app) update(ctx context.Context, r *http.Request) web.Encoder {
+	var app UpdateUser
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	uu, err := toBusUpdateUser(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	updUsr, err := a.userBus.Update(ctx, usr, uu)
+	if err != nil {
+		if errEnc := policyError(err); errEnc != nil {
+			return errEnc
+		}
+		if errors.Is(err, userbus.ErrUniqueEmail) {
+			return errs.New(errs.Aborted, userbus.ErrUniqueEmail)
+		}
+		return errs.Newf(errs.Internal, "update: userID[%s] uu[%+v]: %s", usr.ID, uu, err)
+	}
+
+	return toAppUser(updUsr, mid.GetLocation(ctx))
+}
+
+func (a *app) updateRole(ctx context.Context, r *http.Request) web.Encoder {
+	var app UpdateUserRole
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	uu, err := toBusUpdateUserRole(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	updUsr, err := a.userBus.Update(ctx, usr, uu)
+	if err != nil {
+		return errs.Newf(errs.Internal, "updaterole: userID[%s] uu[%+v]: %s", usr.ID, uu, err)
+	}
+
+	return toAppUser(updUsr, mid.GetLocation(ctx))
+}
+
+// delete erases the personal data of a user and signs them out everywhere.
+// The account is anonymized rather than removed so the orders of the user,
+// and of the courses they teach, are kept as financial records.
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	actorID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	sessions, err := a.userBus.QuerySessions(ctx, usr.ID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querysessions: userID[%s]: %s", usr.ID, err)
+	}
+
+	if err := a.privacyBus.Erase(ctx, usr.ID, actorID, web.ClientIP(r)); err != nil {
+		return errs.Newf(errs.Internal, "erase: userID[%s]: %s", usr.ID, err)
+	}
+
+	forget := a.auth.ForgetSession
+	mid.AfterCommit(ctx, func(ctx context.Context) {
+		for _, ses := range sessions {
+			forget(ses.ID)
+		}
+	})
+
+	return nil
+}
+
+// unlock clears the failed login attempts of a user that has been locked out.
+func (a *app) unlock(ctx context.Context, r *http.Request) web.Encoder {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	actorID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if err := a.loginBus.Unlock(ctx, usr, actorID, web.ClientIP(r)); err != nil {
+		return errs.Newf(errs.Internal, "unlock: userID[%s]: %s", usr.ID, err)
+	}
+
+	return nil
+}
+
+// impersonate issues a short lived token that lets an admin use the platform
+// as the specified user. Admin accounts can't be impersonated.
+func (a *app) impersonate(ctx context.Context, r *http.Request) web.Encoder {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	claims := mid.GetClaims(ctx)
+
+	if usr.ID.String() == claims.Subject {
+		return errs.Newf(errs.InvalidArgument, "you can't impersonate yourself")
+	}
+
+	if slices.Contains(usr.Roles, role.Admin) {
+		return errs.Newf(errs.PermissionDenied, "admin accounts can't be impersonated")
+	}
+
+	token, expiresAt, err := a.auth.GenerateImpersonationToken(ctx, claims, usr.ID, role.ParseToString(usr.Roles), web.ClientIP(r))
+	if err != nil {
+		return errs.Newf(errs.Internal, "impersonate: userID[%s]: %s", usr.ID, err)
+	}
+
+	return toAppImpersonation(usr, token, expiresAt, mid.GetLocation(ctx))
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp := parseQueryParams(r)
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, userbus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	usrs, err := a.userBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.userBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Newf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppUsers(usrs, mid.GetLocation(ctx)), total, page)
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyid: %s", err)
+	}
+
+	return toAppUser(usr, mid.GetLocation(ctx))
+}
+
+func (a *app) me(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", userID, err)
+	}
+
+	return toAppUser(usr, mid.GetLocation(ctx))
+}
+
+// updateMe lets users edit their own profile. Changing the email address
+// or password requires the current password, checked with the same throttle
+// as a login, and a new email address has to be verified again. A new
+// password ends the other sessions of the user.
+func (a *app) updateMe(ctx context.Context, r *http.Request) web.Encoder {
+	var app UpdateProfile
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	uu, err := toBusUpdateProfile(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", userID, err)
+	}
+
+	if app.sensitive() {
+		usr, err = a.loginBus.Authenticate(ctx, usr.UserEmail, app.CurrentPassword, web.ClientIP(r))
+		if err != nil {
+			switch {
+			case errors.Is(err, userbus.ErrAuthenticationFailure):
+				return errs.NewFieldErrors("current_password", errors.New("current password is incorrect"))
+			case errors.Is(err, loginbus.ErrTooManyAttempts):
+				return errs.New(errs.TooManyRequests, errors.New("too many attempts, try again later"))
+			}
+			return errs.Newf(errs.Internal, "authenticate: userID[%s]: %s", userID, err)
+		}
+	}
+
+	updUsr, err := a.userBus.Update(ctx, usr, uu)
+	if err != nil {
+		if errEnc := policyError(err); errEnc != nil {
+			return errEnc
+		}
+		if errors.Is(err, userbus.ErrUniqueEmail) {
+			return errs.New(errs.Aborted, userbus.ErrUniqueEmail)
+		}
+		return errs.Newf(errs.Internal, "update: userID[%s]: %s", userID, err)
+	}
+
+	if uu.Password != nil {
+		currentID, err := uuid.Parse(mid.GetClaims(ctx).SessionID)
+		if err != nil {
+			return errs.Newf(errs.Unauthenticated, "invalid token session: %s", err)
+		}
+
+		revoked, err := a.userBus.RevokeOtherSessions(ctx, userID, currentID)
+		if err != nil {
+			return errs.Newf(errs.Internal, "revokeothersessions: userID[%s]: %s", userID, err)
+		}
+
+		for _, sessionID := range revoked {
+			a.auth.ForgetSession(sessionID)
+		}
+	}
+
+	if usr.IsVerified() && !updUsr.IsVerified() {
+		if err := a.sendVerification(ctx, updUsr.ID); err != nil {
+			a.log.Error(ctx, "updateme: send verification", "userID", updUsr.ID, "err", err)
+		}
+	}
+
+	return toAppUser(updUsr, mid.GetLocation(ctx))
+}
+
+func (a *app) refresh(ctx context.Context, r *http.Request) web.Encoder {
+	var app refreshRequest
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, refreshToken, rt, err := a.userBus.RotateRefreshToken(ctx, app.RefreshToken, newSession(r), a.auth.RefreshTokenTTL())
+	if err != nil {
+		switch {
+		case errors.Is(err, userbus.ErrInvalidToken),
+			errors.Is(err, userbus.ErrTokenExpired),
+			errors.Is(err, userbus.ErrTokenReused):
+			return errs.New(errs.Unauthenticated, errors.New("invalid refresh token"))
+		}
+		return errs.Newf(errs.Internal, "refresh: %s", err)
+	}
+
+	token, err := a.generateAccessToken(usr, rt.FamilyID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "refresh: %s", err)
+	}
+
+	return toAppTokens(tokens{access: token, refresh: refreshToken})
+}
+
+func (a *app) logOut(ctx context.Context, r *http.Request) web.Encoder {
+	var app refreshRequest
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	sessionID, err := a.userBus.RevokeRefreshToken(ctx, app.RefreshToken)
+	if err != nil {
+		if errors.Is(err, userbus.ErrInvalidToken) {
+			return errs.New(errs.Unauthenticated, errors.New("invalid refresh token"))
+		}
+		return errs.Newf(errs.Internal, "logout: %s", err)
+	}
+
+	a.auth.ForgetSession(sessionID)
+
+	return nil
+}
+
+// querySessions lists the active sessions of the user. The session the
+// request was made from is flagged as the current one.
+func (a *app) querySessions(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	sessions, err := a.userBus.QuerySessions(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querysessions: userID[%s]: %s", userID, err)
+	}
+
+	return toAppSessions(sessions, mid.GetClaims(ctx).SessionID, mid.GetLocation(ctx))
+}
+
+// revokeSession signs the user out of one of their sessions.
+func (a *app) revokeSession(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	sessionID, err := uuid.Parse(web.Param(r, "session_id"))
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := a.userBus.RevokeSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, userbus.ErrSessionNotFound) {
+			return errs.New(errs.NotFound, userbus.ErrSessionNotFound)
+		}
+		return errs.Newf(errs.Internal, "revokesession: sessionID[%s]: %s", sessionID, err)
+	}
+
+	a.auth.ForgetSession(sessionID)
+
+	return nil
+}
+
+// revokeOtherSessions signs the user out everywhere except the session the
+// request was made from.
+func (a *app) revokeOtherSessions(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	currentID, err := uuid.Parse(mid.GetClaims(ctx).SessionID)
+	if err != nil {
+		return errs.Newf(errs.Unauthenticated, "invalid token session: %s", err)
+	}
+
+	revoked, err := a.userBus.RevokeOtherSessions(ctx, userID, currentID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "revokeothersessions: userID[%s]: %s", userID, err)
+	}
+
+	for _, sessionID := range revoked {
+		a.auth.ForgetSession(sessionID)
+	}
+
+	return RevokedSessions{Revoked: len(revoked)}
+}
+
+// queryPreferences returns the preferences of the user.
+func (a *app) queryPreferences(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	prefs, err := a.userBus.QueryPreferences(ctx, userID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "querypreferences: userID[%s]: %s", userID, err)
+	}
+
+	return toAppPreferences(prefs)
+}
+
+// updatePreferences changes the preferences of the user. The new locale and
+// time zone apply from the next request.
+func (a *app) updatePreferences(ctx context.Context, r *http.Request) web.Encoder {
+	var app UpdatePreferences
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	up, err := toBusUpdatePreferences(app)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	prefs, err := a.userBus.UpdatePreferences(ctx, userID, up)
+	if err != nil {
+		return errs.Newf(errs.Internal, "updatepreferences: userID[%s]: %s", userID, err)
+	}
+
+	a.auth.ForgetPreferences(userID)
+
+	return toAppPreferences(prefs)
+}
+
+// forgotPassword emails a password reset link to the user. The response is
+// the same whether or not the email belongs to an account, and the email is
+// sent in the background so the response time doesn't tell them apart.
+func (a *app) forgotPassword(ctx context.Context, r *http.Request) web.Encoder {
+	var app forgotPassword
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	email, err := mail.ParseAddress(app.Email)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, token, err := a.userBus.CreatePasswordResetToken(ctx, *email, a.passwordResetTTL)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			a.log.Info(ctx, "password reset requested for unknown email")
+			return nil
+		}
+		return errs.Newf(errs.Internal, "forgotpassword: %s", err)
+	}
+
+	data := tokenMail{
+		Link: tokenLink(ctx, a.appURL, "reset-password", token),
+		TTL:  a.passwordResetTTL,
+	}
+
+	a.sendInBackground(ctx, usr, mailer.TemplatePasswordReset, data)
+
+	return nil
+}
+
+func (a *app) resetPassword(ctx context.Context, r *http.Request) web.Encoder {
+	var app resetPassword
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	_, revoked, err := a.userBus.ResetPassword(ctx, app.Token, app.Password)
+	if err != nil {
+		if errEnc := policyError(err); errEnc != nil {
+			return errEnc
+		}
+		if errors.Is(err, userbus.ErrInvalidToken) || errors.Is(err, userbus.ErrTokenExpired) {
+			return errs.New(errs.InvalidArgument, errors.New("invalid or expired reset token"))
+		}
+		return errs.Newf(errs.Internal, "resetpassword: %s", err)
+	}
+
+	// Cached sessions are forgotten once the new password is stored.
+	forget := a.auth.ForgetSession
+	mid.AfterCommit(ctx, func(ctx context.Context) {
+		for _, sessionID := range revoked {
+			forget(sessionID)
+		}
+	})
+
+	return nil
+}
+
+// requestMagicLink emails the user a link that signs them in without a
+// password. Like forgotPassword it does not reveal whether the email belongs
+// to an account, or whether the limit on links has been reached, and sends
+// the email in the background.
+func (a *app) requestMagicLink(ctx context.Context, r *http.Request) web.Encoder {
+	var app magicLinkRequest
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	email, err := mail.ParseAddress(app.Email)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, token, err := a.userBus.CreateMagicLinkToken(ctx, *email, a.magicLinkTTL, a.magicLinkLimit, a.magicLinkWindow)
+	if err != nil {
+		switch {
+		case errors.Is(err, userbus.ErrNotFound):
+			a.log.Info(ctx, "magic link requested for unknown email")
+			return nil
+		case errors.Is(err, userbus.ErrTooManyLinks):
+			a.log.Info(ctx, "magic link limit reached", "err", err)
+			return nil
+		}
+		return errs.Newf(errs.Internal, "requestmagiclink: %s", err)
+	}
+
+	data := tokenMail{
+		Link: tokenLink(ctx, a.appURL, "magic-link", token),
+		TTL:  a.magicLinkTTL,
+	}
+
+	a.sendInBackground(ctx, usr, mailer.TemplateMagicLink, data)
+
+	return nil
+}
+
+// logInMagicLink exchanges a magic link token for the same tokens a password
+// login issues.
+func (a *app) logInMagicLink(ctx context.Context, r *http.Request) web.Encoder {
+	var app magicLinkLogin
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := a.userBus.ConsumeMagicLink(ctx, app.Token)
+	if err != nil {
+		if errors.Is(err, userbus.ErrInvalidToken) || errors.Is(err, userbus.ErrTokenExpired) {
+			return errs.New(errs.Unauthenticated, errors.New("invalid or expired sign in link"))
+		}
+		return errs.Newf(errs.Internal, "loginmagiclink: %s", err)
+	}
+
+	return a.completeLogIn(ctx, r, usr)
+}
+
+func (a *app) verifyEmail(ctx context.Context, r *http.Request) web.Encoder {
+	var app verifyEmail
+	if err := web.Decode(r, &app); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if _, err := a.userBus.VerifyEmail(ctx, app.Token); err != nil {
+		if errors.Is(err, userbus.ErrInvalidToken) || errors.Is(err, userbus.ErrTokenExpired) {
+			return errs.New(errs.InvalidArgument, errors.New("invalid or expired verification token"))
+		}
+		return errs.Newf(errs.Internal, "verifyemail: %s", err)
+	}
+
+	return nil
+}
+
+func (a *app) resendVerification(ctx context.Context, r *http.Request) web.Encoder {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, err)
+	}
+
+	if err := a.sendVerification(ctx, userID); err != nil {
+		if errors.Is(err, userbus.ErrAlreadyVerified) {
+			return errs.New(errs.FailedPrecondition, userbus.ErrAlreadyVerified)
+		}
+		return errs.Newf(errs.Internal, "resendverification: %s", err)
+	}
+
+	return nil
+}
+
+func (a *app) checkAuth(ctx context.Context, r *http.Request) web.Encoder {
+	return nil
+}
+
+// =============================================================================
+
+// sendVerification issues an email verification token for the user and
+// emails them a link to confirm their address.
+func (a *app) sendVerification(ctx context.Context, userID uuid.UUID) error {
+	usr, token, err := a.userBus.CreateEmailVerificationToken(ctx, userID, a.emailVerificationTTL)
+	if err != nil {
+		return fmt.Errorf("create verification token: %w", err)
+	}
+
+	data := tokenMail{
+		Link: tokenLink(ctx, a.appURL, "verify-email", token),
+		TTL:  a.emailVerificationTTL,
+	}
+
+	msg, err := a.message(ctx, usr, mailer.TemplateEmailVerification, data)
+	if err != nil {
+		return err
+	}
+
+	if err := a.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	return nil
+}
+
+// sendInBackground renders and delivers the email without holding up the
+// request. Failures are logged since the caller has already responded.
+func (a *app) sendInBackground(ctx context.Context, usr userbus.User, name string, data any) {
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		msg, err := a.message(ctx, usr, name, data)
+		if err != nil {
+			a.log.Error(ctx, "mail: render", "userID", usr.ID, "template", name, "err", err)
+			return
+		}
+
+		if err := a.mailer.Send(ctx, msg); err != nil {
+			a.log.Error(ctx, "mail: send", "userID", usr.ID, "template", name, "err", err)
+		}
+	}()
+}
+
+// message renders the email template in the locale the user prefers.
+func (a *app) message(ctx context.Context, usr userbus.User, name string, data any) (mailer.Message, error) {
+	prefs, err := a.userBus.QueryPreferences(ctx, usr.ID)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("querypreferences: userID[%s]: %w", usr.ID, err)
+	}
+
+	msg, err := mailer.Render(usr.UserEmail, name, prefs.Locale, data)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("render: %w", err)
+	}
+
+	return msg, nil
+}
+
+// =============================================================================
+
+// completeLogIn finishes the first step of a login. The user is challenged
+// for a second factor when one is enabled or required, otherwise tokens are
+// issued.
+func (a *app) completeLogIn(ctx context.Context, r *http.Request, usr userbus.User) web.Encoder {
+	challenge, purpose, methods, err := a.loginChallenge(ctx, usr)
+	if err != nil {
+		return errs.Newf(errs.Internal, "login: %s", err)
+	}
+
+	if challenge != "" {
+		token, err := a.auth.GenerateChallengeToken(usr.ID, purpose, a.challengeTTL)
+		if err != nil {
+			return errs.Newf(errs.Internal, "login: challenge: %s", err)
+		}
+
+		return challengeResponse{ChallengeToken: token, Type: challenge, Methods: methods}
+	}
+
+	tkn, err := a.issueTokens(ctx, r, usr)
+	if err != nil {
+		return errs.Newf(errs.Internal, "login: %s", err)
+	}
+
+	return toAppUserWithToken(&usr, tkn, mid.GetLocation(ctx))
+}
+
+// loginChallenge decides whether a user who passed the first step of a login
+// has to present a second factor, or enroll in TOTP first, before tokens are
+// issued. The second factors the user can choose from are returned as well.
+func (a *app) loginChallenge(ctx context.Context, usr userbus.User) (string, string, []string, error) {
+	factors, err := a.userBus.SecondFactors(ctx, usr.ID)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("secondfactors: %w", err)
+	}
+
+	if len(factors) > 0 {
+		return challengeSecondFactor, auth.PurposeTwoFactor, factors, nil
+	}
+
+	required, err := a.userBus.RequiresTwoFactor(ctx, usr)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("requirestwofactor: %w", err)
+	}
+
+	if required {
+		return challengeEnroll, auth.PurposeTwoFactorEnroll, []string{userbus.FactorTOTP}, nil
+	}
+
+	return "", "", nil, nil
+}
+
+// challengeUser returns the user a challenge token was issued to.
+func (a *app) challengeUser(ctx context.Context, token string, purpose string) (userbus.User, *errs.Error) {
+	userID, err := a.auth.ParseChallengeToken(token, purpose)
+	if err != nil {
+		return userbus.User{}, errs.New(errs.Unauthenticated, errors.New("invalid or expired challenge token"))
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return userbus.User{}, errs.New(errs.Unauthenticated, errors.New("invalid or expired challenge token"))
+		}
+		return userbus.User{}, errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", userID, err)
+	}
+
+	return usr, nil
+}
+
+// oidcProvider returns the provider named in the request path.
+func (a *app) oidcProvider(r *http.Request) (*oidc.Provider, *errs.Error) {
+	name := web.Param(r, "provider")
+
+	prv, exists := a.oidcProviders[name]
+	if !exists {
+		return nil, errs.Newf(errs.NotFound, "unknown identity provider %q", name)
+	}
+
+	return prv, nil
+}
+
+// currentUser returns the authenticated user.
+func (a *app) currentUser(ctx context.Context) (userbus.User, *errs.Error) {
+	userID, err := mid.GetUserID(ctx)
+	if err != nil {
+		return userbus.User{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		return userbus.User{}, errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", userID, err)
+	}
+
+	return usr, nil
+}
+
+// twoFactorError maps the two-factor errors of the business layer to app
+// errors. Invalid codes are reported with the specified code.
+func twoFactorError(err error, invalidCode errs.ErrCode) *errs.Error {
+	switch {
+	case errors.Is(err, userbus.ErrInvalidCode):
+		return errs.New(invalidCode, userbus.ErrInvalidCode)
+	case errors.Is(err, userbus.ErrTOTPNotEnabled):
+		return errs.New(errs.FailedPrecondition, userbus.ErrTOTPNotEnabled)
+	case errors.Is(err, userbus.ErrTOTPAlreadyEnabled):
+		return errs.New(errs.AlreadyExists, userbus.ErrTOTPAlreadyEnabled)
+	case errors.Is(err, userbus.ErrTwoFactorRequired):
+		return errs.New(errs.FailedPrecondition, userbus.ErrTwoFactorRequired)
+	case errors.Is(err, loginbus.ErrTooManyAttempts):
+		return errs.New(errs.TooManyRequests, errors.New("too many attempts, try again later"))
+	}
+
+	return errs.Newf(errs.Internal, "twofactor: %s", err)
+}
+
+// policyError converts a password policy violation into field errors, one
+// per broken rule. It returns nil for any other error.
+func policyError(err error) *errs.Error {
+	var pe *password.PolicyError
+	if !errors.As(err, &pe) {
+		return nil
+	}
+
+	var fe errs.FieldErrors
+	for _, v := range pe.Violations {
+		fe.Add("password", errors.New(v))
+	}
+
+	return fe.ToError()
+}
+
+// passkeyError maps the errors of a passkey login to app errors.
+func passkeyError(err error) *errs.Error {
+	switch {
+	case errors.Is(err, userbus.ErrInvalidToken) || errors.Is(err, userbus.ErrTokenExpired):
+		return errs.New(errs.Unauthenticated, errors.New("invalid or expired passkey challenge"))
+	case errors.Is(err, userbus.ErrAuthenticationFailure):
+		return errs.New(errs.Unauthenticated, errors.New("passkey sign in failed"))
+	case errors.Is(err, loginbus.ErrTooManyAttempts):
+		return errs.New(errs.TooManyRequests, errors.New("too many login attempts, try again later"))
+	}
+
+	return errs.Newf(errs.Internal, "passkey: %s", err)
+}
+
+// =============================================================================
+
+// tokens represents the pair of tokens handed to a client when a session
+// is started or refreshed.
+type tokens struct {
+	access  string
+	refresh string
+}
+
+// issueTokens starts a new session for the user by issuing a refresh token
+// and an access token bound to the refresh token family. The session records
+// the client the request came from.
+func (a *app) issueTokens(ctx context.Context, r *http.Request, usr userbus.User) (tokens, error) {
+	refreshToken, rt, err := a.userBus.CreateRefreshToken(ctx, usr.ID, newSession(r), a.auth.RefreshTokenTTL())
+	if err != nil {
+		return tokens{}, fmt.Errorf("create refresh token: %w", err)
+	}
+
+	token, err := a.generateAccessToken(usr, rt.FamilyID)
+	if err != nil {
+		return tokens{}, err
+	}
+
+	return tokens{access: token, refresh: refreshToken}, nil
+}
+
+// newSession returns the client information recorded for a session.
+func newSession(r *http.Request) userbus.NewSession {
+	return userbus.NewSession{
+		IPAddress: web.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// generateAccessToken generates a short lived access token for the user
+// bound to the specified session.
+func (a *app) generateAccessToken(usr userbus.User, sessionID uuid.UUID) (string, error) {
+	now := time.Now().UTC()
+
+	claims := auth.Claims{
+		Roles:     role.ParseToString(usr.Roles),
+		SessionID: sessionID.String(),
+		TenantID:  usr.TenantID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   usr.ID.String(),
+			Issuer:    a.auth.Issuer(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.auth.AccessTokenTTL())),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	token, err := a.auth.GenerateToken(claims)
+	if err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+
+	return token, nil
+}
@@ -5,66 +5,218 @@ package auth
 
 import (
 	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus/stores/apikeydb"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus/stores/auditdb"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus/stores/consentdb"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus/stores/userdb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/business/types/role"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/password"
 )
 
+// apiKeyScheme is the authorization scheme API keys are sent with.
+const apiKeyScheme = "ApiKey "
+
 // ErrForbidden is returned when an auth issue is identified.
 var ErrForbidden = errors.New("attempted action is not allowed")
 
-// Claims represents the authorization claims transmitted via a JWT.
+// These are the current set of rules we have for auth.
+const (
+	RuleAny               = "rule_any"
+	RuleAdminOnly         = "rule_admin_only"
+	RuleAdminOrInstructor = "rule_admin_or_instructor"
+	RuleAdminOrSubject    = "rule_admin_or_subject"
+	RuleAdminOrOwner      = "rule_admin_or_owner"
+	RulePlatformAdmin     = "rule_platform_admin"
+)
+
+// These are the purposes a challenge token can be issued for.
+const (
+	PurposeTwoFactor       = "mfa"
+	PurposeTwoFactorEnroll = "mfa_enroll"
+)
+
+// These are the actions recorded in the audit log for impersonation.
+const (
+	ActionImpersonationStart   = "impersonation.start"
+	ActionImpersonationRequest = "impersonation.request"
+)
+
+// Claims represents the authorization claims transmitted via a JWT. Tokens
+// with a purpose are challenge tokens and are never accepted as access tokens.
+// Tokens with an actor are impersonation tokens, where the subject is the
+// impersonated user and the actor is the admin using the token. The tenant
+// is the organization the subject belongs to. Claims built from an API key
+// carry the id and scopes of the key, which are never part of a token.
 type Claims struct {
 	jwt.RegisteredClaims
-	Roles []string `json:"roles"`
+	Roles     []string `json:"roles"`
+	SessionID string   `json:"sid"`
+	TenantID  string   `json:"tid,omitempty"`
+	Purpose   string   `json:"pur,omitempty"`
+	Actor     *Actor   `json:"act,omitempty"`
+	APIKeyID  string   `json:"-"`
+	Scopes    []string `json:"-"`
+}
+
+// Actor identifies the party acting on behalf of the subject of a token.
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+// IsImpersonation reports whether the claims belong to an impersonation
+// token.
+func (c Claims) IsImpersonation() bool {
+	return c.Actor != nil
+}
+
+// IsAPIKey reports whether the claims were built from an API key.
+func (c Claims) IsAPIKey() bool {
+	return c.APIKeyID != ""
+}
+
+// HasScope checks if the API key the claims were built from was granted the
+// scope.
+func (c Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Tenant returns the organization the token was issued for. Tokens issued
+// before organizations existed belong to the default organization.
+func (c Claims) Tenant() (uuid.UUID, error) {
+	if c.TenantID == "" {
+		return tenant.DefaultID, nil
+	}
+
+	return uuid.Parse(c.TenantID)
+}
+
+// HasRole checks if the claims contain the specified role.
+func (c Claims) HasRole(r role.Role) bool {
+	for _, cr := range c.Roles {
+		if cr == r.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// KeyLookup declares a method set of behavior for looking up the keys used
+// to sign and verify tokens.
+type KeyLookup interface {
+	SigningKey() (kid string, key crypto.Signer, err error)
+	PublicKey(kid string) (crypto.PublicKey, error)
+	PublicKeys() map[string]crypto.PublicKey
 }
 
 // Config represents information required to initialize auth.
 type Config struct {
-	Log    *logger.Logger
-	DB     *sqlx.DB
-	Secret string
-	Issuer string
+	Log              *logger.Logger
+	DB               *sqlx.DB
+	KeyLookup        KeyLookup
+	Issuer           string
+	AccessTokenTTL   time.Duration
+	RefreshTokenTTL  time.Duration
+	SessionCacheTTL  time.Duration
+	ImpersonationTTL time.Duration
 }
 
 // Auth is used to authenticate clients. It can generate a token for a
 // set of user claims and recreate the claims by parsing the token.
 type Auth struct {
-	log     *logger.Logger
-	secret  string
-	userBus *userbus.Business
-	issuer  string
+	log              *logger.Logger
+	keyLookup        KeyLookup
+	parser           *jwt.Parser
+	userBus          *userbus.Business
+	auditBus         *auditbus.Business
+	consentBus       *consentbus.Business
+	apiKeyBus        *apikeybus.Business
+	issuer           string
+	accessTokenTTL   time.Duration
+	refreshTokenTTL  time.Duration
+	impersonationTTL time.Duration
+	sessions         *checkCache[bool]
+	consents         *checkCache[bool]
+	preferences      *checkCache[userbus.Preferences]
 }
 
 // New creates an Auth instance to support authentication/authorization.
 func New(cfg Config) (*Auth, error) {
 	var userBus *userbus.Business
+	var auditBus *auditbus.Business
+	var consentBus *consentbus.Business
+	var apiKeyBus *apikeybus.Business
 	if cfg.DB != nil {
-		userBus = userbus.NewBusiness(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB))
+		// Auth never hashes passwords, it only needs the user business to
+		// check sessions.
+		hasher := password.NewHasher(password.NewArgon2id(password.DefaultArgon2Params))
+		userBus = userbus.NewBusiness(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), hasher, password.Policy{})
+		auditBus = auditbus.NewBusiness(cfg.Log, auditdb.NewStore(cfg.Log, cfg.DB))
+		consentBus = consentbus.NewBusiness(cfg.Log, consentdb.NewStore(cfg.Log, cfg.DB))
+		apiKeyBus = apikeybus.NewBusiness(cfg.Log, apikeydb.NewStore(cfg.Log, cfg.DB))
 	}
 
 	a := Auth{
-		log:     cfg.Log,
-		secret:  cfg.Secret,
-		userBus: userBus,
-		issuer:  cfg.Issuer,
+		log:              cfg.Log,
+		keyLookup:        cfg.KeyLookup,
+		parser:           jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()})),
+		userBus:          userBus,
+		auditBus:         auditBus,
+		consentBus:       consentBus,
+		apiKeyBus:        apiKeyBus,
+		issuer:           cfg.Issuer,
+		accessTokenTTL:   cfg.AccessTokenTTL,
+		refreshTokenTTL:  cfg.RefreshTokenTTL,
+		impersonationTTL: cfg.ImpersonationTTL,
+		sessions:         newCheckCache[bool](cfg.SessionCacheTTL),
+		consents:         newCheckCache[bool](cfg.SessionCacheTTL),
+		preferences:      newCheckCache[userbus.Preferences](cfg.SessionCacheTTL),
 	}
 
 	return &a, nil
 }
 
-// GenerateToken generates a signed JWT token string representing the user Claims.
+// GenerateToken generates a signed JWT token string representing the user
+// Claims. The token is signed with the active key and the key id is stored
+// in the kid header so the token can be verified after the key is rotated.
 func (a *Auth) GenerateToken(claims Claims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	kid, key, err := a.keyLookup.SigningKey()
+	if err != nil {
+		return "", fmt.Errorf("signing key: %w", err)
+	}
+
+	method, err := signingMethod(key.Public())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
 
-	str, err := token.SignedString([]byte(a.secret))
+	str, err := token.SignedString(key)
 	if err != nil {
 		return "", fmt.Errorf("signing token: %w", err)
 	}
@@ -81,9 +233,7 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 	tokenStr := bearerToken[7:]
 
 	var claims Claims
-	token, err := jwt.ParseWithClaims(tokenStr, &claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(a.secret), nil
-	})
+	token, err := a.parser.ParseWithClaims(tokenStr, &claims, a.verificationKey)
 	if err != nil || !token.Valid {
 		return Claims{}, fmt.Errorf("authentication failed: %w", err)
 	}
@@ -92,33 +242,424 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 		return Claims{}, errors.New("invalid token issuer")
 	}
 
+	if claims.Purpose != "" {
+		return Claims{}, errors.New("challenge token used as an access token")
+	}
+
+	if err := a.isSessionActive(ctx, claims); err != nil {
+		return Claims{}, err
+	}
+
 	return claims, nil
 }
 
-// Authorize checks if the user has the required role.
-func (a *Auth) Authorize(ctx context.Context, claims Claims, userID uuid.UUID, requiredRole string) error {
-	hasRole := false
-	for _, role := range claims.Roles {
-		if role == requiredRole {
-			hasRole = true
-			break
+// IsAPIKey reports whether the authorization header carries an API key
+// rather than a bearer token.
+func IsAPIKey(authorization string) bool {
+	return strings.HasPrefix(authorization, apiKeyScheme)
+}
+
+// AuthenticateAPIKey validates the API key in the authorization header and
+// builds the claims the request is made with. A user key acts as its owner
+// with the roles the owner has now, and an organization key acts as an admin
+// of the organization on behalf of the user who created it, as long as that
+// user still exists and is still an admin.
+func (a *Auth) AuthenticateAPIKey(ctx context.Context, authorization string) (Claims, error) {
+	if !IsAPIKey(authorization) {
+		return Claims{}, errors.New("expected authorization header format: ApiKey <key>")
+	}
+
+	if a.apiKeyBus == nil {
+		return Claims{}, errors.New("api keys are not supported")
+	}
+
+	key, err := a.apiKeyBus.Authenticate(ctx, strings.TrimPrefix(authorization, apiKeyScheme))
+	if err != nil {
+		return Claims{}, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	ctx = tenant.Set(ctx, key.TenantID)
+
+	if key.IsOrganizationKey() {
+		usr, err := a.userBus.QueryByID(ctx, key.CreatedBy)
+		if err != nil {
+			return Claims{}, fmt.Errorf("query creator: %w", err)
+		}
+
+		if !usr.HasRole(role.Admin) {
+			return Claims{}, fmt.Errorf("creator[%s] is no longer an admin: %w", usr.ID, ErrForbidden)
+		}
+
+		return a.apiKeyClaims(key, usr.ID, []string{role.Admin.String()}), nil
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, *key.UserID)
+	if err != nil {
+		return Claims{}, fmt.Errorf("query owner: %w", err)
+	}
+
+	return a.apiKeyClaims(key, usr.ID, role.ParseToString(usr.Roles)), nil
+}
+
+func (a *Auth) apiKeyClaims(key apikeybus.APIKey, subject uuid.UUID, roles []string) Claims {
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: subject.String(),
+			Issuer:  a.issuer,
+		},
+		Roles:    roles,
+		TenantID: key.TenantID.String(),
+		APIKeyID: key.ID.String(),
+		Scopes:   apikeybus.ScopesToString(key.Scopes),
+	}
+
+	return claims
+}
+
+// GenerateChallengeToken generates a short lived token that proves the user
+// passed the first step of a login. The token can only be redeemed through
+// ParseChallengeToken with the same purpose.
+func (a *Auth) GenerateChallengeToken(userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Issuer:    a.issuer,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Purpose: purpose,
+	}
+
+	return a.GenerateToken(claims)
+}
+
+// ParseChallengeToken validates a challenge token issued for the specified
+// purpose and returns the user it was issued to.
+func (a *Auth) ParseChallengeToken(tokenStr string, purpose string) (uuid.UUID, error) {
+	var claims Claims
+	token, err := a.parser.ParseWithClaims(tokenStr, &claims, a.verificationKey)
+	if err != nil || !token.Valid {
+		return uuid.UUID{}, fmt.Errorf("challenge failed: %w", err)
+	}
+
+	if claims.Issuer != a.issuer {
+		return uuid.UUID{}, errors.New("invalid token issuer")
+	}
+
+	if claims.Purpose != purpose {
+		return uuid.UUID{}, errors.New("invalid challenge purpose")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("invalid challenge subject: %w", err)
+	}
+
+	return userID, nil
+}
+
+// GenerateImpersonationToken generates a short lived token that lets the
+// admin identified by the claims act as the specified user. The token is
+// bound to the admin's session, so it stops working when the admin signs
+// out, and the start of the impersonation is written to the audit log.
+func (a *Auth) GenerateImpersonationToken(ctx context.Context, admin Claims, userID uuid.UUID, roles []string, ip string) (string, time.Time, error) {
+	if admin.IsImpersonation() {
+		return "", time.Time{}, errors.New("impersonation tokens can't start an impersonation")
+	}
+
+	adminID, err := uuid.Parse(admin.Subject)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("parsing admin subject: %w", err)
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(a.impersonationTTL)
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Issuer:    a.issuer,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Roles:     roles,
+		SessionID: admin.SessionID,
+		TenantID:  admin.TenantID,
+		Actor:     &Actor{Subject: adminID.String()},
+	}
+
+	token, err := a.GenerateToken(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	details := map[string]string{
+		"session_id": admin.SessionID,
+		"expires_at": expiresAt.Format(time.RFC3339),
+	}
+
+	if err := a.audit(ctx, adminID, ActionImpersonationStart, userID.String(), ip, details); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// AuditImpersonation writes a request made with an impersonation token to
+// the audit log.
+func (a *Auth) AuditImpersonation(ctx context.Context, claims Claims, method string, path string, ip string) error {
+	adminID, err := uuid.Parse(claims.Actor.Subject)
+	if err != nil {
+		return fmt.Errorf("parsing actor subject: %w", err)
+	}
+
+	details := map[string]string{
+		"method": method,
+		"path":   path,
+	}
+
+	return a.audit(ctx, adminID, ActionImpersonationRequest, claims.Subject, ip, details)
+}
+
+// audit records an event in the audit log. Nothing is recorded when auth was
+// constructed without a database.
+func (a *Auth) audit(ctx context.Context, actorID uuid.UUID, action string, subject string, ip string, details map[string]string) error {
+	if a.auditBus == nil {
+		return nil
+	}
+
+	na := auditbus.NewAudit{
+		ActorID: actorID,
+		Action:  action,
+		Subject: subject,
+		IP:      ip,
+		Details: details,
+	}
+
+	if _, err := a.auditBus.Create(ctx, na); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return nil
+}
+
+// verificationKey looks up the public key named by the kid header and checks
+// the token was signed with the algorithm that matches the key.
+func (a *Auth) verificationKey(token *jwt.Token) (any, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("kid missing from header")
+	}
+
+	key, err := a.keyLookup.PublicKey(kid)
+	if err != nil {
+		return nil, fmt.Errorf("public key: %w", err)
+	}
+
+	method, err := signingMethod(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Method.Alg() != method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %q for kid %q", token.Method.Alg(), kid)
+	}
+
+	return key, nil
+}
+
+// ForgetSession drops the cached state of the session so a revocation takes
+// effect immediately on this instance.
+func (a *Auth) ForgetSession(sessionID uuid.UUID) {
+	a.sessions.delete(sessionID)
+}
+
+// isSessionActive checks the session the token was issued for has not been
+// revoked. Results are cached briefly, and the last seen time of the session
+// is recorded whenever the database is checked. The check is skipped when
+// auth was constructed without a database.
+func (a *Auth) isSessionActive(ctx context.Context, claims Claims) error {
+	if a.userBus == nil {
+		return nil
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return errors.New("invalid token session")
+	}
+
+	now := time.Now()
+
+	active, cached := a.sessions.get(sessionID, now)
+	if !cached {
+		active, err = a.userBus.TouchSession(ctx, sessionID)
+		if err != nil {
+			return fmt.Errorf("session check: %w", err)
 		}
+
+		a.sessions.set(sessionID, active, now)
+	}
+
+	if !active {
+		return errors.New("token session has been revoked")
+	}
+
+	return nil
+}
+
+// ForgetConsent drops the cached consent state of the user so an acceptance
+// takes effect immediately on this instance.
+func (a *Auth) ForgetConsent(userID uuid.UUID) {
+	a.consents.delete(userID)
+}
+
+// CheckConsent returns consentbus.ErrConsentRequired when the user has not
+// accepted the current legal documents. Impersonation tokens are let through
+// as the admin can't accept on behalf of the user, and so are API keys as
+// the user accepted the terms when creating one. Results are cached like
+// session checks, and the check is skipped when auth was constructed without
+// a database.
+func (a *Auth) CheckConsent(ctx context.Context, claims Claims) error {
+	if a.consentBus == nil || claims.IsImpersonation() || claims.IsAPIKey() {
+		return nil
 	}
 
-	if !hasRole {
-		return ErrForbidden
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("parsing subject: %w", err)
 	}
 
-	// Special case for admin-or-subject rule
-	if requiredRole == "admin_or_subject" {
-		if userID.String() != claims.Subject {
-			return ErrForbidden
+	now := time.Now()
+
+	accepted, cached := a.consents.get(userID, now)
+	if !cached {
+		switch err := a.consentBus.CheckConsent(ctx, userID); {
+		case err == nil:
+			accepted = true
+		case errors.Is(err, consentbus.ErrConsentRequired):
+			accepted = false
+		default:
+			return fmt.Errorf("consent check: %w", err)
 		}
+
+		a.consents.set(userID, accepted, now)
+	}
+
+	if !accepted {
+		return consentbus.ErrConsentRequired
 	}
 
 	return nil
 }
 
+// ForgetPreferences drops the cached preferences of the user so an update
+// takes effect immediately on this instance.
+func (a *Auth) ForgetPreferences(userID uuid.UUID) {
+	a.preferences.delete(userID)
+}
+
+// QueryPreferences returns the preferences of the user, used to localize the
+// responses to a request. Results are cached like session checks, and the
+// defaults are returned when auth was constructed without a database.
+func (a *Auth) QueryPreferences(ctx context.Context, userID uuid.UUID) (userbus.Preferences, error) {
+	if a.userBus == nil {
+		return userbus.DefaultPreferences(userID), nil
+	}
+
+	now := time.Now()
+
+	prefs, cached := a.preferences.get(userID, now)
+	if cached {
+		return prefs, nil
+	}
+
+	prefs, err := a.userBus.QueryPreferences(ctx, userID)
+	if err != nil {
+		return userbus.Preferences{}, fmt.Errorf("query preferences: %w", err)
+	}
+
+	a.preferences.set(userID, prefs, now)
+
+	return prefs, nil
+}
+
+// Authorize attempts to authorize the user with the provided rule. The userID
+// is the identity the rule is evaluated against, such as the user being
+// accessed or the instructor who owns the course being accessed.
+func (a *Auth) Authorize(ctx context.Context, claims Claims, userID uuid.UUID, rule string) error {
+	switch rule {
+	case RuleAny:
+		return nil
+
+	case RuleAdminOnly:
+		if claims.HasRole(role.Admin) {
+			return nil
+		}
+
+	case RuleAdminOrInstructor:
+		if claims.HasRole(role.Admin) || claims.HasRole(role.Instructor) {
+			return nil
+		}
+
+	case RuleAdminOrSubject:
+		if claims.HasRole(role.Admin) || claims.Subject == userID.String() {
+			return nil
+		}
+
+	case RuleAdminOrOwner:
+		if claims.HasRole(role.Admin) {
+			return nil
+		}
+
+		if claims.HasRole(role.Instructor) && claims.Subject == userID.String() {
+			return nil
+		}
+
+	case RulePlatformAdmin:
+		// API keys never act as platform admins, whatever the organization.
+		tenantID, err := claims.Tenant()
+		if err != nil {
+			return fmt.Errorf("invalid token tenant: %w", err)
+		}
+
+		if claims.HasRole(role.Admin) && tenantID == tenant.DefaultID && !claims.IsAPIKey() {
+			return nil
+		}
+
+	default:
+		return fmt.Errorf("unknown rule %q", rule)
+	}
+
+	return ErrForbidden
+}
+
+// Issuer returns the issuer used when generating and validating tokens.
 func (a *Auth) Issuer() string {
 	return a.issuer
 }
+
+// AccessTokenTTL returns how long an access token is valid for.
+func (a *Auth) AccessTokenTTL() time.Duration {
+	return a.accessTokenTTL
+}
+
+// RefreshTokenTTL returns how long a refresh token is valid for.
+func (a *Auth) RefreshTokenTTL() time.Duration {
+	return a.refreshTokenTTL
+}
+
+// =============================================================================
+
+// signingMethod returns the JWT signing method to use with the key.
+func signingMethod(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
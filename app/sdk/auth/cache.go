@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxCachedChecks bounds the size of a check cache. Expired entries are
+// swept once the cache grows past it.
+const maxCachedChecks = 10_000
+
+// checkCache remembers the result of recent session, consent and preference
+// lookups so every request doesn't have to hit the database. A revoked
+// session may be accepted by another instance until its entry expires.
+type checkCache[T any] struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[uuid.UUID]checkEntry[T]
+}
+
+type checkEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func newCheckCache[T any](ttl time.Duration) *checkCache[T] {
+	return &checkCache[T]{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]checkEntry[T]),
+	}
+}
+
+// get returns the cached result for the id, if there is one.
+func (c *checkCache[T]) get(id uuid.UUID, now time.Time) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[id]
+	if !exists || now.After(entry.expiresAt) {
+		var zero T
+		return zero, false
+	}
+
+	return entry.value, true
+}
+
+// set stores the result of a check.
+func (c *checkCache[T]) set(id uuid.UUID, value T, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCachedChecks {
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+	}
+
+	c.entries[id] = checkEntry[T]{
+		value:     value,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// delete drops the cached result for the id.
+func (c *checkCache[T]) delete(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, id)
+}
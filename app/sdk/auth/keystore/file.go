@@ -0,0 +1,103 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// NewFile constructs a KeyStore from the PEM files found in the root of the
+// file system. Each file is named after its key id, such as <kid>.pem. The
+// file matching the active key id must hold a private key and is used for
+// signing. Every other file is loaded as a retired key and may hold either a
+// private or a public key. Retired keys loaded from disk are kept until the
+// file is removed.
+func NewFile(fsys fs.FS, activeKID string) (*KeyStore, error) {
+	ks := NewMemory(0)
+
+	fn := func(fileName string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("walkdir failure: %w", err)
+		}
+
+		if dirEntry.IsDir() || path.Ext(fileName) != ".pem" {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, fileName)
+		if err != nil {
+			return fmt.Errorf("reading key file: %w", err)
+		}
+
+		kid := strings.TrimSuffix(dirEntry.Name(), ".pem")
+
+		signer, public, err := parsePEM(data)
+		if err != nil {
+			return fmt.Errorf("parsing key file %s: %w", fileName, err)
+		}
+
+		if kid == activeKID {
+			if signer == nil {
+				return fmt.Errorf("active key %s: %w", kid, ErrPrivateKeyNeeded)
+			}
+			return ks.Rotate(kid, signer)
+		}
+
+		return ks.AddRetired(kid, public, time.Time{})
+	}
+
+	if err := fs.WalkDir(fsys, ".", fn); err != nil {
+		return nil, fmt.Errorf("walking directory: %w", err)
+	}
+
+	if _, _, err := ks.SigningKey(); err != nil {
+		return nil, fmt.Errorf("active key %s: %w", activeKID, err)
+	}
+
+	return ks, nil
+}
+
+// parsePEM decodes a private or public key from PEM encoded data. The signer
+// is nil when the data only holds a public key.
+func parsePEM(data []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("invalid key: key must be PEM encoded")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing pkcs8 private key: %w", err)
+		}
+
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, nil, fmt.Errorf("%T: %w", key, ErrUnsupportedKey)
+		}
+		return signer, signer.Public(), nil
+
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing pkcs1 private key: %w", err)
+		}
+		return key, key.Public(), nil
+
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing public key: %w", err)
+		}
+		return nil, key, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown PEM block type %q", block.Type)
+	}
+}
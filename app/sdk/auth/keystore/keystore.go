@@ -0,0 +1,185 @@
+// Package keystore implements the auth.KeyLookup interface. Keys can be held
+// in memory or loaded from PEM files on disk. One key is active and used for
+// signing, retired keys are kept so tokens they signed can still be verified.
+package keystore
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Set of error variables for key lookups.
+var (
+	ErrKeyNotFound      = errors.New("key not found")
+	ErrNoActiveKey      = errors.New("no active signing key")
+	ErrUnsupportedKey   = errors.New("unsupported key type")
+	ErrPrivateKeyNeeded = errors.New("private key required for signing")
+)
+
+// key represents a single key held by the store. A retired key may only have
+// the public half available.
+type key struct {
+	signer    crypto.Signer
+	public    crypto.PublicKey
+	retiredAt time.Time
+}
+
+// KeyStore represents an in memory store of keys that implements the
+// auth.KeyLookup interface.
+type KeyStore struct {
+	mu        sync.RWMutex
+	activeKID string
+	keys      map[string]key
+	retention time.Duration
+}
+
+// NewMemory constructs an empty KeyStore. Keys retired by a rotation are
+// kept for the retention period, which should be at least as long as the
+// lifetime of the tokens they signed. A retention of zero keeps retired keys
+// until they are removed.
+func NewMemory(retention time.Duration) *KeyStore {
+	return &KeyStore{
+		keys:      make(map[string]key),
+		retention: retention,
+	}
+}
+
+// Rotate makes the specified key the active signing key. The previously
+// active key is retired and only used to verify tokens from then on.
+func (ks *KeyStore) Rotate(kid string, signer crypto.Signer) error {
+	if err := checkKeyType(signer.Public()); err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if prev, exists := ks.keys[ks.activeKID]; exists && ks.activeKID != kid {
+		prev.retiredAt = time.Now()
+		ks.keys[ks.activeKID] = prev
+	}
+
+	ks.keys[kid] = key{
+		signer: signer,
+		public: signer.Public(),
+	}
+	ks.activeKID = kid
+
+	return nil
+}
+
+// Generate creates a new Ed25519 key and makes it the active signing key.
+func (ks *KeyStore) Generate(kid string) error {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generating key: %w", err)
+	}
+
+	return ks.Rotate(kid, private)
+}
+
+// AddRetired adds a public key that can only be used to verify tokens.
+func (ks *KeyStore) AddRetired(kid string, public crypto.PublicKey, retiredAt time.Time) error {
+	if err := checkKeyType(public); err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.keys[kid] = key{
+		public:    public,
+		retiredAt: retiredAt,
+	}
+
+	return nil
+}
+
+// SigningKey returns the key id and private key of the active key.
+func (ks *KeyStore) SigningKey() (string, crypto.Signer, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	k, exists := ks.keys[ks.activeKID]
+	if !exists {
+		return "", nil, ErrNoActiveKey
+	}
+
+	if k.signer == nil {
+		return "", nil, ErrPrivateKeyNeeded
+	}
+
+	return ks.activeKID, k.signer, nil
+}
+
+// PublicKey returns the public key for the specified key id. Retired keys
+// past their retention period are no longer returned.
+func (ks *KeyStore) PublicKey(kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	k, exists := ks.keys[kid]
+	if !exists || ks.expired(k) {
+		return nil, fmt.Errorf("kid[%s]: %w", kid, ErrKeyNotFound)
+	}
+
+	return k.public, nil
+}
+
+// PublicKeys returns the public keys that can currently be used to verify
+// tokens, keyed by key id.
+func (ks *KeyStore) PublicKeys() map[string]crypto.PublicKey {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	keys := make(map[string]crypto.PublicKey, len(ks.keys))
+	for kid, k := range ks.keys {
+		if ks.expired(k) {
+			delete(ks.keys, kid)
+			continue
+		}
+		keys[kid] = k.public
+	}
+
+	return keys
+}
+
+// KIDs returns the sorted set of key ids held by the store.
+func (ks *KeyStore) KIDs() []string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	return kids
+}
+
+func (ks *KeyStore) expired(k key) bool {
+	if k.retiredAt.IsZero() || ks.retention == 0 {
+		return false
+	}
+
+	return time.Since(k.retiredAt) > ks.retention
+}
+
+// =============================================================================
+
+func checkKeyType(public crypto.PublicKey) error {
+	switch public.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return nil
+	default:
+		return fmt.Errorf("%T: %w", public, ErrUnsupportedKey)
+	}
+}
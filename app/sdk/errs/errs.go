@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
 )
 
 // ErrCode represents an error code in the system.
@@ -49,12 +52,16 @@ func (ec ErrCode) Equal(ec2 ErrCode) bool {
 
 // =============================================================================
 
-// Error represents an error in the system.
+// Error represents an error in the system. The error it was constructed from
+// is kept so field errors can be localized before they are sent.
 type Error struct {
 	Code     ErrCode `json:"code"`
 	Message  string  `json:"message"`
 	FuncName string  `json:"-"`
 	FileName string  `json:"-"`
+
+	err       error
+	localized bool
 }
 
 // New constructs an error based on an app error.
@@ -66,6 +73,7 @@ func New(code ErrCode, err error) *Error {
 		Message:  err.Error(),
 		FuncName: runtime.FuncForPC(pc).Name(),
 		FileName: fmt.Sprintf("%s:%d", filename, line),
+		err:      err,
 	}
 }
 
@@ -109,6 +117,25 @@ func (e *Error) HTTPStatus() int {
 	return httpStatus[e.Code]
 }
 
+// Localize returns the error with any field error messages translated to the
+// language. An error is only localized once, so the innermost middleware
+// that knows the language of the user decides.
+func (e *Error) Localize(lang string) *Error {
+	if e.localized {
+		return e
+	}
+
+	le := *e
+	le.localized = true
+
+	var fe FieldErrors
+	if errors.As(e.err, &fe) {
+		le.Message = strings.Replace(e.Message, fe.Error(), fe.Localize(lang).Error(), 1)
+	}
+
+	return &le
+}
+
 // Equal provides support for the go-cmp package and testing.
 func (e *Error) Equal(e2 *Error) bool {
 	return e.Code == e2.Code && e.Message == e2.Message
@@ -120,6 +147,8 @@ func (e *Error) Equal(e2 *Error) bool {
 type FieldError struct {
 	Field string `json:"field"`
 	Err   string `json:"error"`
+
+	verr validator.FieldError
 }
 
 // FieldErrors represents a collection of field errors.
@@ -1,14 +1,19 @@
 package errs
 
 import (
-	"errors"
 	"reflect"
 	"strings"
 
 	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/pt"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	pt_translations "github.com/go-playground/validator/v10/translations/pt"
 )
 
 // validate holds the settings and caches for validating request struct values.
@@ -17,17 +22,34 @@ var validate *validator.Validate
 // translator is a cache of locale and translation information.
 var translator ut.Translator
 
+// translators holds a translator for every language validation messages
+// can be localized to, keyed by language.
+var translators = make(map[string]ut.Translator)
+
 func init() {
 
 	// Instantiate a validator.
 	validate = validator.New(validator.WithRequiredStructEnabled())
 
-	// Create a translator for english so the error messages are
-	// more human-readable than technical.
-	translator, _ = ut.New(en.New(), en.New()).GetTranslator("en")
+	// Create a translator for every supported language so the error
+	// messages are more human-readable than technical.
+	uni := ut.New(en.New(), en.New(), es.New(), fr.New(), pt.New())
+
+	register := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"es": es_translations.RegisterDefaultTranslations,
+		"fr": fr_translations.RegisterDefaultTranslations,
+		"pt": pt_translations.RegisterDefaultTranslations,
+	}
+
+	for lang, fn := range register {
+		trans, _ := uni.GetTranslator(lang)
+		fn(validate, trans)
+		translators[lang] = trans
+	}
 
-	// Register the english error messages for use.
-	en_translations.RegisterDefaultTranslations(validate, translator)
+	// English is used until a message is localized.
+	translator = translators["en"]
 
 	// Use JSON tag names for errors instead of Go struct names.
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
@@ -49,10 +71,11 @@ func Check(val any) error {
 
 		var fields FieldErrors
 		for _, verror := range verrors {
-			fields.Add(
-				verror.Field(),
-				errors.New(verror.Translate(translator)),
-			)
+			fields = append(fields, FieldError{
+				Field: verror.Field(),
+				Err:   verror.Translate(translator),
+				verr:  verror,
+			})
 		}
 
 		return fields
@@ -60,3 +83,23 @@ func Check(val any) error {
 
 	return nil
 }
+
+// Localize returns the field errors with the validation messages translated
+// to the language. Messages that did not come from validation, and languages
+// there are no translations for, are left as they are.
+func (fe FieldErrors) Localize(lang string) FieldErrors {
+	trans, exists := translators[lang]
+	if !exists {
+		return fe
+	}
+
+	localized := make(FieldErrors, len(fe))
+	for i, f := range fe {
+		localized[i] = f
+		if f.verr != nil {
+			localized[i].Err = f.verr.Translate(trans)
+		}
+	}
+
+	return localized
+}
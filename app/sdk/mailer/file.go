@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// File delivers email by logging it and, when a directory is configured,
+// writing each message to a file in that directory. It is meant for
+// development and tests where no mail server is available.
+type File struct {
+	log  *logger.Logger
+	dir  string
+	from mail.Address
+}
+
+// NewFile constructs a mailer that logs messages and writes them to the
+// specified directory. An empty directory only logs the messages.
+func NewFile(log *logger.Logger, dir string, from mail.Address) *File {
+	return &File{
+		log:  log,
+		dir:  dir,
+		from: from,
+	}
+}
+
+// Send implements the Mailer interface.
+func (f *File) Send(ctx context.Context, msg Message) error {
+	f.log.Info(ctx, "mailer", "to", msg.To.Address, "subject", msg.Subject, "body", msg.Body)
+
+	if f.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString())
+
+	if err := os.WriteFile(filepath.Join(f.dir, name), encode(f.from, msg), 0o644); err != nil {
+		return fmt.Errorf("writefile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+// Package mailer provides support for delivering email.
+package mailer
+
+import (
+	"context"
+	"net/mail"
+)
+
+// Message represents an email to be delivered.
+type Message struct {
+	To      mail.Address
+	Subject string
+	Body    string
+}
+
+// Mailer represents behavior for delivering email.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig represents the settings required to deliver email through an
+// SMTP server.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     mail.Address
+}
+
+// SMTP delivers email through an SMTP server.
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from mail.Address
+}
+
+// NewSMTP constructs a mailer that delivers email through an SMTP server.
+func NewSMTP(cfg SMTPConfig) *SMTP {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return &SMTP{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		auth: auth,
+		from: cfg.From,
+	}
+}
+
+// Send implements the Mailer interface.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if err := smtp.SendMail(s.addr, s.auth, s.from.Address, []string{msg.To.Address}, encode(s.from, msg)); err != nil {
+		return fmt.Errorf("sendmail: %w", err)
+	}
+
+	return nil
+}
+
+// encode renders the message in the RFC 5322 format.
+func encode(from mail.Address, msg Message) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	fmt.Fprintf(&buf, "\r\n%s\r\n", msg.Body)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/mail"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/kamogelosekhukhune777/lms/business/types/locale"
+)
+
+// Set of templates messages can be rendered from.
+const (
+	TemplatePasswordReset     = "password_reset"
+	TemplateEmailVerification = "email_verification"
+	TemplateMagicLink         = "magic_link"
+	TemplateCourseReview      = "course_review"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// templates holds the parsed templates keyed by locale and template name.
+// Each template defines a "subject" and a "body".
+var templates = parseTemplates()
+
+func parseTemplates() map[string]*template.Template {
+	files, err := fs.Glob(templateFS, "templates/*/*.tmpl")
+	if err != nil {
+		panic(err)
+	}
+
+	tmpls := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		lcl := path.Base(path.Dir(file))
+		name := strings.TrimSuffix(path.Base(file), ".tmpl")
+
+		tmpls[lcl+"/"+name] = template.Must(template.ParseFS(templateFS, file))
+	}
+
+	return tmpls
+}
+
+// Render builds the message for the template in the specified locale.
+// Templates that are not translated to the locale are rendered in the
+// default locale.
+func Render(to mail.Address, name string, lcl locale.Locale, data any) (Message, error) {
+	tmpl, exists := templates[lcl.String()+"/"+name]
+	if !exists {
+		tmpl, exists = templates[locale.Default.String()+"/"+name]
+		if !exists {
+			return Message{}, fmt.Errorf("template %q not found", name)
+		}
+	}
+
+	var subject bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("execute subject: %s: %w", name, err)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return Message{}, fmt.Errorf("execute body: %s: %w", name, err)
+	}
+
+	msg := Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()),
+	}
+
+	return msg, nil
+}
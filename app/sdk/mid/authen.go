@@ -0,0 +1,171 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+// Authenticate validates the bearer token found in the authorization header
+// and stores the claims and user id in the context for later use. The
+// organization the token was issued for becomes the tenant of the request,
+// and the token is refused when the request names a different organization.
+// Requests made with an impersonation token are written to the audit log,
+// and are refused if that fails. Users who have not accepted the current
+// legal documents are refused with a failed precondition until they do. The
+// locale and time zone of the user replace the ones of the request. API keys
+// are refused, see AuthenticateWithScope.
+func Authenticate(ath *auth.Auth) web.MidFunc {
+	return authenticate(ath, true, nil)
+}
+
+// AuthenticateWithoutConsent authenticates like Authenticate but lets users
+// through who have not accepted the current legal documents. It is used on
+// the endpoints a user needs to review and accept them.
+func AuthenticateWithoutConsent(ath *auth.Auth) web.MidFunc {
+	return authenticate(ath, false, nil)
+}
+
+// AuthenticateWithScope authenticates like Authenticate and also accepts an
+// API key in the authorization header, as long as the key was granted the
+// scope. It is used on the endpoints other systems integrate with.
+func AuthenticateWithScope(ath *auth.Auth, scope apikeybus.Scope) web.MidFunc {
+	return authenticate(ath, true, &scope)
+}
+
+// AuthenticateOptional authenticates like Authenticate when the request
+// carries an authorization header and lets anonymous requests through
+// without claims. It is used on public endpoints that show more to users
+// who are signed in.
+func AuthenticateOptional(ath *auth.Auth) web.MidFunc {
+	authen := authenticate(ath, true, nil)
+
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		authenNext := authen(next)
+
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if r.Header.Get("authorization") == "" {
+				return next(ctx, r)
+			}
+
+			return authenNext(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+func authenticate(ath *auth.Auth, checkConsent bool, scope *apikeybus.Scope) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			claims, appErr := authenticateRequest(ctx, ath, r.Header.Get("authorization"), scope)
+			if appErr != nil {
+				return appErr
+			}
+
+			userID, err := uuid.Parse(claims.Subject)
+			if err != nil {
+				return errs.Newf(errs.Unauthenticated, "parsing subject: %s", err)
+			}
+
+			tenantID, err := claims.Tenant()
+			if err != nil {
+				return errs.Newf(errs.Unauthenticated, "parsing tenant: %s", err)
+			}
+
+			if tenant.IsSet(ctx) && tenant.Get(ctx) != tenantID {
+				return errs.Newf(errs.Unauthenticated, "token was issued for another organization")
+			}
+
+			if claims.IsImpersonation() {
+				if err := ath.AuditImpersonation(ctx, claims, r.Method, r.URL.Path, web.ClientIP(r)); err != nil {
+					return errs.Newf(errs.Internal, "audit impersonation: %s", err)
+				}
+			}
+
+			ctx = tenant.Set(ctx, tenantID)
+
+			if checkConsent {
+				if err := ath.CheckConsent(ctx, claims); err != nil {
+					if errors.Is(err, consentbus.ErrConsentRequired) {
+						return errs.New(errs.FailedPrecondition, err)
+					}
+					return errs.Newf(errs.Internal, "check consent: %s", err)
+				}
+			}
+
+			prefs, err := ath.QueryPreferences(ctx, userID)
+			if err != nil {
+				return errs.Newf(errs.Internal, "query preferences: %s", err)
+			}
+
+			ctx = setClaims(ctx, claims)
+			ctx = setUserID(ctx, userID)
+			ctx = setLocale(ctx, prefs.Locale)
+			ctx = setLocation(ctx, prefs.TimeZone)
+
+			return localize(next(ctx, r), prefs.Locale)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// authenticateRequest validates the bearer token or, when the endpoint takes
+// them, the API key the request was made with.
+func authenticateRequest(ctx context.Context, ath *auth.Auth, authorization string, scope *apikeybus.Scope) (auth.Claims, *errs.Error) {
+	if !auth.IsAPIKey(authorization) {
+		claims, err := ath.Authenticate(ctx, authorization)
+		if err != nil {
+			return auth.Claims{}, errs.New(errs.Unauthenticated, err)
+		}
+
+		return claims, nil
+	}
+
+	if scope == nil {
+		return auth.Claims{}, errs.Newf(errs.PermissionDenied, "api keys are not accepted on this endpoint")
+	}
+
+	claims, err := ath.AuthenticateAPIKey(ctx, authorization)
+	if err != nil {
+		return auth.Claims{}, errs.New(errs.Unauthenticated, err)
+	}
+
+	if !claims.HasScope(scope.String()) {
+		return auth.Claims{}, errs.Newf(errs.PermissionDenied, "api key was not granted the %s scope", scope)
+	}
+
+	return claims, nil
+}
+
+// DenyImpersonation refuses requests made with an impersonation token. It is
+// used on endpoints that take payments or change credentials, and must run
+// after Authenticate.
+func DenyImpersonation() web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if GetClaims(ctx).IsImpersonation() {
+				return errs.Newf(errs.PermissionDenied, "not allowed while impersonating a user")
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
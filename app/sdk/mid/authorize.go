@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
@@ -15,29 +16,65 @@ import (
 // ErrInvalidID represents a condition where the id is not a uuid.
 var ErrInvalidID = errors.New("ID is not in its proper form")
 
-func GetCourseByID(courseBus *coursebus.Business) web.MidFunc {
+// Authorize validates that the authenticated user satisfies the rule.
+func Authorize(ath *auth.Auth, rule string) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
-			id := web.Param(r, "course_id")
+			userID, err := GetUserID(ctx)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			claims := GetClaims(ctx)
+
+			if err := ath.Authorize(ctx, claims, userID, rule); err != nil {
+				return errs.Newf(errs.PermissionDenied, "authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", claims.Roles, rule, err)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// AuthorizeUser executes the specified rule and extracts the specified
+// user from the DB if a user id is specified in the call. Depending on the
+// rule specified, the user id from the claims may be compared with the
+// specified user id.
+func AuthorizeUser(ath *auth.Auth, userBus *userbus.Business, rule string) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			id := web.Param(r, "user_id")
+
+			var userID uuid.UUID
 
 			if id != "" {
 				var err error
-				courseID, err := uuid.Parse(id)
+				userID, err = uuid.Parse(id)
 				if err != nil {
 					return errs.New(errs.Unauthenticated, ErrInvalidID)
 				}
 
-				prd, err := courseBus.QueryByID(ctx, courseID)
+				usr, err := userBus.QueryByID(ctx, userID)
 				if err != nil {
 					switch {
-					case errors.Is(err, coursebus.ErrNotFound):
+					case errors.Is(err, userbus.ErrNotFound):
 						return errs.New(errs.Unauthenticated, err)
 					default:
-						return errs.Newf(errs.Internal, "querybyid: courseID[%s]: %s", courseID, err)
+						return errs.Newf(errs.Unauthenticated, "querybyid: userID[%s]: %s", userID, err)
 					}
 				}
 
-				ctx = setCourse(ctx, prd)
+				ctx = setUser(ctx, usr)
+			}
+
+			claims := GetClaims(ctx)
+
+			if err := ath.Authorize(ctx, claims, userID, rule); err != nil {
+				return errs.Newf(errs.PermissionDenied, "authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", claims.Roles, rule, err)
 			}
 
 			return next(ctx, r)
@@ -49,31 +86,42 @@ func GetCourseByID(courseBus *coursebus.Business) web.MidFunc {
 	return m
 }
 
-func GetUserByID(userBus *userbus.Business) web.MidFunc {
+// AuthorizeCourse executes the specified rule and extracts the specified
+// course from the DB if a course id is specified in the call. Depending on
+// the rule specified, the user id from the claims may be compared with the
+// instructor id of the course.
+func AuthorizeCourse(ath *auth.Auth, courseBus *coursebus.Business, rule string) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
-			id := web.Param(r, "user_id")
+			id := web.Param(r, "course_id")
 
-			var userID uuid.UUID
+			var instructorID uuid.UUID
 
 			if id != "" {
 				var err error
-				userID, err = uuid.Parse(id)
+				courseID, err := uuid.Parse(id)
 				if err != nil {
 					return errs.New(errs.Unauthenticated, ErrInvalidID)
 				}
 
-				usr, err := userBus.QueryByID(ctx, userID)
+				cor, err := courseBus.QueryByID(ctx, courseID)
 				if err != nil {
 					switch {
-					case errors.Is(err, userbus.ErrNotFound):
+					case errors.Is(err, coursebus.ErrNotFound):
 						return errs.New(errs.Unauthenticated, err)
 					default:
-						return errs.Newf(errs.Unauthenticated, "querybyid: userID[%s]: %s", userID, err)
+						return errs.Newf(errs.Internal, "querybyid: courseID[%s]: %s", courseID, err)
 					}
 				}
 
-				ctx = setUser(ctx, usr)
+				instructorID = cor.InstructorID
+				ctx = setCourse(ctx, cor)
+			}
+
+			claims := GetClaims(ctx)
+
+			if err := ath.Authorize(ctx, claims, instructorID, rule); err != nil {
+				return errs.Newf(errs.PermissionDenied, "authorize: you are not authorized for that action, claims[%v] rule[%v]: %s", claims.Roles, rule, err)
 			}
 
 			return next(ctx, r)
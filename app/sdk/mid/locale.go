@@ -0,0 +1,57 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/types/locale"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+	"golang.org/x/text/language"
+)
+
+// Locale resolves the locale of a request from the Accept-Language header,
+// picking the most preferred language that is supported, and localizes the
+// field errors of a failed request. Authenticate replaces it with the locale
+// the user chose in their preferences.
+func Locale() web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			loc := acceptLocale(r.Header.Get("Accept-Language"))
+			ctx = setLocale(ctx, loc)
+
+			return localize(next(ctx, r), loc)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// acceptLocale returns the first supported locale in the Accept-Language
+// header, which lists languages in order of preference.
+func acceptLocale(header string) locale.Locale {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return locale.Default
+	}
+
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		if loc, err := locale.Parse(base.String()); err == nil {
+			return loc
+		}
+	}
+
+	return locale.Default
+}
+
+// localize translates the field errors of the response, if it is an error.
+func localize(resp web.Encoder, loc locale.Locale) web.Encoder {
+	if appErr, ok := resp.(*errs.Error); ok {
+		return appErr.Localize(loc.String())
+	}
+
+	return resp
+}
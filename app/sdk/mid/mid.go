@@ -4,11 +4,14 @@ package mid
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/types/locale"
 	"github.com/kamogelosekhukhune777/lms/foundation/web"
 )
 
@@ -25,12 +28,29 @@ func isError(e web.Encoder) error {
 type ctxKey int
 
 const (
-	userIDKey ctxKey = iota + 1
+	claimKey ctxKey = iota + 1
+	userIDKey
 	userKey
 	courseKey
 	trKey
+	afterCommitKey
+	localeKey
+	locationKey
 )
 
+func setClaims(ctx context.Context, claims auth.Claims) context.Context {
+	return context.WithValue(ctx, claimKey, claims)
+}
+
+// GetClaims returns the claims from the context.
+func GetClaims(ctx context.Context) auth.Claims {
+	v, ok := ctx.Value(claimKey).(auth.Claims)
+	if !ok {
+		return auth.Claims{}
+	}
+	return v
+}
+
 func setUserID(ctx context.Context, userID uuid.UUID) context.Context {
 	return context.WithValue(ctx, userIDKey, userID)
 }
@@ -86,3 +106,50 @@ func GetTran(ctx context.Context) (sqldb.CommitRollbacker, error) {
 
 	return v, nil
 }
+
+func setAfterCommit(ctx context.Context, fns *[]func(context.Context)) context.Context {
+	return context.WithValue(ctx, afterCommitKey, fns)
+}
+
+// AfterCommit runs the function once the transaction of the request has been
+// committed, and not at all when it's rolled back. Outside of a transaction
+// the function runs straight away.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	fns, ok := ctx.Value(afterCommitKey).(*[]func(context.Context))
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	*fns = append(*fns, fn)
+}
+
+func setLocale(ctx context.Context, loc locale.Locale) context.Context {
+	return context.WithValue(ctx, localeKey, loc)
+}
+
+// GetLocale returns the locale responses to the request are written in,
+// which is the default locale when none was resolved.
+func GetLocale(ctx context.Context) locale.Locale {
+	v, ok := ctx.Value(localeKey).(locale.Locale)
+	if !ok {
+		return locale.Default
+	}
+
+	return v
+}
+
+func setLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey, loc)
+}
+
+// GetLocation returns the time zone timestamps in responses to the request
+// are formatted in, which is the local time zone for anonymous requests.
+func GetLocation(ctx context.Context) *time.Location {
+	v, ok := ctx.Value(locationKey).(*time.Location)
+	if !ok {
+		return time.Local
+	}
+
+	return v
+}
@@ -0,0 +1,93 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/domain/orgbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/business/types/slug"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+// TenantHeader names the header a client can use to select the organization
+// a request is made for, by slug or id.
+const TenantHeader = "X-Tenant"
+
+// Tenant resolves the organization a request is made for and stores it as
+// the tenant of the request. The organization is taken from the X-Tenant
+// header, or else from the subdomain of the base domain the request was sent
+// to. Requests that name neither are left for Authenticate to resolve from
+// the token, and otherwise belong to the default organization.
+func Tenant(orgBus *orgbus.Business, baseDomain string) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			value := r.Header.Get(TenantHeader)
+			if value == "" {
+				value = subdomain(r.Host, baseDomain)
+			}
+
+			if value == "" {
+				return next(ctx, r)
+			}
+
+			org, err := queryOrganization(ctx, orgBus, value)
+			if err != nil {
+				if errors.Is(err, orgbus.ErrNotFound) {
+					return errs.Newf(errs.NotFound, "organization %q not found", value)
+				}
+				return errs.Newf(errs.Internal, "query organization: %s", err)
+			}
+
+			ctx = tenant.Set(ctx, org.ID)
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// queryOrganization looks the organization up by id, or by slug when the
+// value is not an id.
+func queryOrganization(ctx context.Context, orgBus *orgbus.Business, value string) (orgbus.Organization, error) {
+	if id, err := uuid.Parse(value); err == nil {
+		return orgBus.QueryByID(ctx, id)
+	}
+
+	slg, err := slug.Parse(strings.ToLower(value))
+	if err != nil {
+		return orgbus.Organization{}, orgbus.ErrNotFound
+	}
+
+	return orgBus.QueryBySlug(ctx, slg)
+}
+
+// subdomain returns the label in front of the base domain, so a request to
+// acme.lms.example.com with a base domain of lms.example.com returns acme.
+// Hosts outside the base domain, www and nested subdomains return an empty
+// string.
+func subdomain(host string, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	host = strings.ToLower(host)
+	label, found := strings.CutSuffix(host, "."+strings.ToLower(baseDomain))
+	if !found || label == "" || label == "www" || strings.Contains(label, ".") {
+		return ""
+	}
+
+	return label
+}
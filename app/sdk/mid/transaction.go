@@ -37,7 +37,10 @@ func BeginCommitRollback(log *logger.Logger, bgn sqldb.Beginner) web.MidFunc {
 				}
 			}()
 
+			var afterCommit []func(context.Context)
+
 			ctx = setTran(ctx, tx)
+			ctx = setAfterCommit(ctx, &afterCommit)
 
 			resp := next(ctx, r)
 
@@ -52,6 +55,10 @@ func BeginCommitRollback(log *logger.Logger, bgn sqldb.Beginner) web.MidFunc {
 
 			hasCommitted = true
 
+			for _, fn := range afterCommit {
+				fn(ctx)
+			}
+
 			return resp
 		}
 
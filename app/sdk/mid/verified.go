@@ -0,0 +1,43 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/kamogelosekhukhune777/lms/app/sdk/errs"
+	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/foundation/web"
+)
+
+// RequireVerifiedEmail rejects the request when the authenticated user has
+// not verified their email address. When required is false the middleware
+// lets every request through.
+func RequireVerifiedEmail(userBus *userbus.Business, required bool) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		if !required {
+			return next
+		}
+
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			userID, err := GetUserID(ctx)
+			if err != nil {
+				return errs.New(errs.Unauthenticated, err)
+			}
+
+			usr, err := userBus.QueryByID(ctx, userID)
+			if err != nil {
+				return errs.Newf(errs.Unauthenticated, "querybyid: userID[%s]: %s", userID, err)
+			}
+
+			if !usr.IsVerified() {
+				return errs.New(errs.FailedPrecondition, userbus.ErrEmailNotVerified)
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
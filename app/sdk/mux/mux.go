@@ -6,17 +6,28 @@ import (
 	"context"
 	"embed"
 	"net/http"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/auth"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/cloudinary"
+	"github.com/kamogelosekhukhune777/lms/app/sdk/mailer"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/mid"
 	"github.com/kamogelosekhukhune777/lms/app/sdk/paypal"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/loginbus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/orderbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/orgbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/oidc"
 	"github.com/kamogelosekhukhune777/lms/foundation/web"
+	"github.com/kamogelosekhukhune777/lms/foundation/webauthn"
 )
 
 // StaticSite represents a static site to run.
@@ -52,10 +63,40 @@ func WithFileServer(react bool, static embed.FS, dir string, path string) func(o
 	}
 }
 
+// UserConfig contains the settings for the user account flows.
+type UserConfig struct {
+	AppURL               string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	RequireVerifiedEmail bool
+	ChallengeTTL         time.Duration
+	TOTPIssuer           string
+	OIDCProviders        map[string]*oidc.Provider
+	OIDCStateTTL         time.Duration
+	MagicLinkTTL         time.Duration
+	MagicLinkLimit       int
+	MagicLinkWindow      time.Duration
+	RelyingParty         webauthn.RelyingParty
+	WebAuthnTimeout      time.Duration
+}
+
+// PrivacyConfig contains the settings for data subject requests.
+type PrivacyConfig struct {
+	ErasureGracePeriod time.Duration
+}
+
+// BusConfig contains the business packages used by handlers.
 type BusConfig struct {
-	UserBus   *userbus.Business
-	CourseBus *coursebus.Business
-	OrderBus  *orderbus.Business
+	UserBus       *userbus.Business
+	CourseBus     *coursebus.Business
+	OrderBus      *orderbus.Business
+	InstructorBus *instructorbus.Business
+	AuditBus      *auditbus.Business
+	LoginBus      *loginbus.Business
+	OrgBus        *orgbus.Business
+	PrivacyBus    *privacybus.Business
+	ConsentBus    *consentbus.Business
+	APIKeyBus     *apikeybus.Business
 }
 
 // Config contains all the mandatory systems required by handlers.
@@ -66,7 +107,11 @@ type Config struct {
 	Auth             *auth.Auth
 	Paypal           *paypal.PayPalClient
 	CloudinaryClient *cloudinary.CloudinaryService
+	Mailer           mailer.Mailer
+	BaseDomain       string
 	BusConfig        BusConfig
+	UserConfig       UserConfig
+	PrivacyConfig    PrivacyConfig
 }
 
 // RouteAdder defines behavior that sets the routes to bind for an instance
@@ -87,6 +132,8 @@ func WebAPI(cfg Config, routeAdder RouteAdder, options ...func(opts *Options)) h
 		mid.Errors(cfg.Log),
 		mid.Metrics(),
 		mid.Panics(),
+		mid.Tenant(cfg.BusConfig.OrgBus, cfg.BaseDomain),
+		mid.Locale(),
 	)
 
 	var opts Options
@@ -0,0 +1,196 @@
+// Package apikeybus provides business access to the API keys other systems
+// use to call the API.
+package apikeybus
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Set of error variables for API keys.
+var (
+	ErrNotFound       = errors.New("api key not found")
+	ErrInvalidKey     = errors.New("api key is invalid, expired or revoked")
+	ErrAlreadyRevoked = errors.New("api key is already revoked")
+)
+
+// keyPrefix marks the values of API keys so they are easy to recognize, for
+// example by secret scanners.
+const keyPrefix = "lms_"
+
+// prefixLength is the number of characters of a key that are stored in the
+// clear, so users can tell their keys apart.
+const prefixLength = len(keyPrefix) + 8
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, key APIKey, hash []byte) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]APIKey, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, keyID uuid.UUID) (APIKey, error)
+	QueryByHash(ctx context.Context, hash []byte) (APIKey, error)
+	Touch(ctx context.Context, keyID uuid.UUID, usedAt time.Time) error
+	Revoke(ctx context.Context, keyID uuid.UUID, revokedAt time.Time) error
+}
+
+// Business manages the set of APIs for API key access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs an API key business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new API key to the organization of the request. The value of
+// the key is returned along with it and can't be retrieved again, as only a
+// hash of it is stored.
+func (b *Business) Create(ctx context.Context, nk NewAPIKey) (APIKey, string, error) {
+	value, err := generateKey()
+	if err != nil {
+		return APIKey{}, "", fmt.Errorf("generate key: %w", err)
+	}
+
+	key := APIKey{
+		ID:        uuid.New(),
+		TenantID:  tenant.Get(ctx),
+		UserID:    nk.UserID,
+		Name:      nk.Name,
+		Prefix:    value[:prefixLength],
+		Scopes:    nk.Scopes,
+		CreatedBy: nk.CreatedBy,
+		ExpiresAt: nk.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if err := b.storer.Create(ctx, key, hashKey(value)); err != nil {
+		return APIKey{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	return key, value, nil
+}
+
+// Query retrieves a list of API keys of the organization of the request.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]APIKey, error) {
+	keys, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Count returns the total number of API keys matching the filter.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByID finds the API key by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, keyID uuid.UUID) (APIKey, error) {
+	key, err := b.storer.QueryByID(ctx, keyID)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("query: keyID[%s]: %w", keyID, err)
+	}
+
+	return key, nil
+}
+
+// Authenticate finds the active API key with the specified value and records
+// that it was used. Unknown, expired and revoked keys all return
+// ErrInvalidKey.
+func (b *Business) Authenticate(ctx context.Context, value string) (APIKey, error) {
+	if !strings.HasPrefix(value, keyPrefix) {
+		return APIKey{}, ErrInvalidKey
+	}
+
+	key, err := b.storer.QueryByHash(ctx, hashKey(value))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return APIKey{}, ErrInvalidKey
+		}
+		return APIKey{}, fmt.Errorf("query: %w", err)
+	}
+
+	now := time.Now()
+
+	if !key.IsActive(now) {
+		return APIKey{}, ErrInvalidKey
+	}
+
+	if err := b.storer.Touch(ctx, key.ID, now); err != nil {
+		return APIKey{}, fmt.Errorf("touch: keyID[%s]: %w", key.ID, err)
+	}
+
+	key.LastUsedAt = now
+
+	return key, nil
+}
+
+// Revoke stops the API key from being accepted.
+func (b *Business) Revoke(ctx context.Context, key APIKey) (APIKey, error) {
+	if !key.RevokedAt.IsZero() {
+		return APIKey{}, ErrAlreadyRevoked
+	}
+
+	key.RevokedAt = time.Now()
+
+	if err := b.storer.Revoke(ctx, key.ID, key.RevokedAt); err != nil {
+		return APIKey{}, fmt.Errorf("revoke: keyID[%s]: %w", key.ID, err)
+	}
+
+	return key, nil
+}
+
+// =============================================================================
+
+// generateKey returns a random API key value.
+func generateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashKey returns the hash of an API key value as it is stored.
+func hashKey(value string) []byte {
+	h := sha256.Sum256([]byte(value))
+	return h[:]
+}
@@ -0,0 +1,10 @@
+package apikeybus
+
+import "github.com/google/uuid"
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	UserID           *uuid.UUID
+	OrganizationOnly bool
+	IncludeRevoked   bool
+}
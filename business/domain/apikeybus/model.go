@@ -0,0 +1,61 @@
+package apikeybus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey represents a key that lets another system call the API without a
+// user signing in. A user key acts as the user who owns it, while an
+// organization key has no owner and acts as an admin of the organization.
+// Either way the key can only reach the endpoints its scopes allow.
+type APIKey struct {
+	ID         uuid.UUID
+	TenantID   uuid.UUID
+	UserID     *uuid.UUID
+	Name       string
+	Prefix     string
+	Scopes     []Scope
+	CreatedBy  uuid.UUID
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  time.Time
+	CreatedAt  time.Time
+}
+
+// IsOrganizationKey reports whether the key belongs to the organization
+// rather than a user.
+func (k APIKey) IsOrganizationKey() bool {
+	return k.UserID == nil
+}
+
+// HasScope reports whether the key was granted the scope.
+func (k APIKey) HasScope(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsActive reports whether the key can be used at the specified time.
+func (k APIKey) IsActive(now time.Time) bool {
+	if !k.RevokedAt.IsZero() {
+		return false
+	}
+
+	return k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt)
+}
+
+// NewAPIKey contains information needed to create an API key. The key
+// belongs to the organization when UserID is nil.
+type NewAPIKey struct {
+	UserID    *uuid.UUID
+	Name      string
+	Scopes    []Scope
+	CreatedBy uuid.UUID
+	ExpiresAt time.Time
+}
@@ -0,0 +1,14 @@
+package apikeybus
+
+import "github.com/kamogelosekhukhune777/lms/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByCreatedAt, order.DESC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID         = "api_key_id"
+	OrderByName       = "name"
+	OrderByLastUsedAt = "last_used_at"
+	OrderByCreatedAt  = "created_at"
+)
@@ -0,0 +1,76 @@
+package apikeybus
+
+import "fmt"
+
+// The set of scopes an API key can be granted.
+var (
+	ScopeCoursesRead      = newScope("courses:read")
+	ScopeEnrollmentsRead  = newScope("enrollments:read")
+	ScopeEnrollmentsWrite = newScope("enrollments:write")
+	ScopeReportsRead      = newScope("reports:read")
+)
+
+// =============================================================================
+
+// Set of known scopes.
+var scopes = make(map[string]Scope)
+
+// Scope represents an area of the API a key is allowed to access.
+type Scope struct {
+	value string
+}
+
+func newScope(scope string) Scope {
+	s := Scope{scope}
+	scopes[scope] = s
+	return s
+}
+
+// String returns the name of the scope.
+func (s Scope) String() string {
+	return s.value
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (s Scope) Equal(s2 Scope) bool {
+	return s.value == s2.value
+}
+
+// MarshalText provides support for logging and any marshal needs.
+func (s Scope) MarshalText() ([]byte, error) {
+	return []byte(s.value), nil
+}
+
+// ParseScope parses the string value and returns a scope if one exists.
+func ParseScope(value string) (Scope, error) {
+	scope, exists := scopes[value]
+	if !exists {
+		return Scope{}, fmt.Errorf("invalid scope %q", value)
+	}
+
+	return scope, nil
+}
+
+// ParseScopes parses the string values and returns the scopes.
+func ParseScopes(values []string) ([]Scope, error) {
+	scps := make([]Scope, len(values))
+	for i, value := range values {
+		scope, err := ParseScope(value)
+		if err != nil {
+			return nil, err
+		}
+		scps[i] = scope
+	}
+
+	return scps, nil
+}
+
+// ScopesToString converts the scopes to their string values.
+func ScopesToString(scps []Scope) []string {
+	values := make([]string, len(scps))
+	for i, scope := range scps {
+		values[i] = scope.String()
+	}
+
+	return values
+}
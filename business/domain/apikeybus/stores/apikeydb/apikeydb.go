@@ -0,0 +1,245 @@
+// Package apikeydb contains API key related CRUD functionality.
+package apikeydb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// touchInterval limits how often the last used time of a key is written, so
+// a busy integration doesn't update the row on every request.
+const touchInterval = time.Minute
+
+// Store manages the set of APIs for API key database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (apikeybus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new API key into the database.
+func (s *Store) Create(ctx context.Context, key apikeybus.APIKey, hash []byte) error {
+	const q = `
+	INSERT INTO APIKeys
+		(api_key_id, tenant_id, user_id, name, prefix, key_hash, scopes, created_by, last_used_at, expires_at, revoked_at, created_at)
+	VALUES
+		(:api_key_id, :tenant_id, :user_id, :name, :prefix, :key_hash, :scopes, :created_by, :last_used_at, :expires_at, :revoked_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAPIKey(key, hash)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of API keys of the tenant from the database.
+func (s *Store) Query(ctx context.Context, filter apikeybus.QueryFilter, orderBy order.By, page page.Page) ([]apikeybus.APIKey, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		api_key_id, tenant_id, user_id, name, prefix, key_hash, scopes, created_by, last_used_at, expires_at, revoked_at, created_at
+	FROM
+		APIKeys`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(ctx, filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbKeys []apiKey
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbKeys); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusAPIKeys(dbKeys)
+}
+
+// Count returns the total number of API keys of the tenant in the DB.
+func (s *Store) Count(ctx context.Context, filter apikeybus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		APIKeys`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(ctx, filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified API key of the tenant from the database.
+func (s *Store) QueryByID(ctx context.Context, keyID uuid.UUID) (apikeybus.APIKey, error) {
+	data := struct {
+		ID       string `db:"api_key_id"`
+		TenantID string `db:"tenant_id"`
+	}{
+		ID:       keyID.String(),
+		TenantID: tenant.Get(ctx).String(),
+	}
+
+	const q = `
+	SELECT
+		api_key_id, tenant_id, user_id, name, prefix, key_hash, scopes, created_by, last_used_at, expires_at, revoked_at, created_at
+	FROM
+		APIKeys
+	WHERE
+		api_key_id = :api_key_id AND tenant_id = :tenant_id`
+
+	var dbKey apiKey
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbKey); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return apikeybus.APIKey{}, fmt.Errorf("db: %w", apikeybus.ErrNotFound)
+		}
+		return apikeybus.APIKey{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusAPIKey(dbKey)
+}
+
+// QueryByHash gets the API key with the specified hash from the database.
+// The lookup is not scoped to a tenant since the key decides the tenant of
+// the request.
+func (s *Store) QueryByHash(ctx context.Context, hash []byte) (apikeybus.APIKey, error) {
+	data := struct {
+		KeyHash []byte `db:"key_hash"`
+	}{
+		KeyHash: hash,
+	}
+
+	const q = `
+	SELECT
+		api_key_id, tenant_id, user_id, name, prefix, key_hash, scopes, created_by, last_used_at, expires_at, revoked_at, created_at
+	FROM
+		APIKeys
+	WHERE
+		key_hash = :key_hash`
+
+	var dbKey apiKey
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbKey); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return apikeybus.APIKey{}, fmt.Errorf("db: %w", apikeybus.ErrNotFound)
+		}
+		return apikeybus.APIKey{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusAPIKey(dbKey)
+}
+
+// Touch records the time the API key was used, unless it was already
+// recorded within the touch interval.
+func (s *Store) Touch(ctx context.Context, keyID uuid.UUID, usedAt time.Time) error {
+	data := struct {
+		ID         string    `db:"api_key_id"`
+		LastUsedAt time.Time `db:"last_used_at"`
+		Before     time.Time `db:"before"`
+	}{
+		ID:         keyID.String(),
+		LastUsedAt: usedAt.UTC(),
+		Before:     usedAt.Add(-touchInterval).UTC(),
+	}
+
+	const q = `
+	UPDATE
+		APIKeys
+	SET
+		last_used_at = :last_used_at
+	WHERE
+		api_key_id = :api_key_id AND (last_used_at IS NULL OR last_used_at < :before)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke records the revocation of an API key of the tenant. The update only
+// succeeds while the key is active so it can only be revoked once.
+func (s *Store) Revoke(ctx context.Context, keyID uuid.UUID, revokedAt time.Time) error {
+	data := struct {
+		ID        string    `db:"api_key_id"`
+		TenantID  string    `db:"tenant_id"`
+		RevokedAt time.Time `db:"revoked_at"`
+	}{
+		ID:        keyID.String(),
+		TenantID:  tenant.Get(ctx).String(),
+		RevokedAt: revokedAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		APIKeys
+	SET
+		revoked_at = :revoked_at
+	WHERE
+		api_key_id = :api_key_id AND tenant_id = :tenant_id AND revoked_at IS NULL
+	RETURNING
+		api_key_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"api_key_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", apikeybus.ErrAlreadyRevoked)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
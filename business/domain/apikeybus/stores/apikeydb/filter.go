@@ -0,0 +1,31 @@
+package apikeydb
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+)
+
+func (s *Store) applyFilter(ctx context.Context, filter apikeybus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	data["tenant_id"] = tenant.Get(ctx)
+	wc := []string{"tenant_id = :tenant_id"}
+
+	if filter.UserID != nil {
+		data["user_id"] = *filter.UserID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.OrganizationOnly {
+		wc = append(wc, "user_id IS NULL")
+	}
+
+	if !filter.IncludeRevoked {
+		wc = append(wc, "revoked_at IS NULL")
+	}
+
+	buf.WriteString(" WHERE ")
+	buf.WriteString(strings.Join(wc, " AND "))
+}
@@ -0,0 +1,107 @@
+package apikeydb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb/dbarray"
+)
+
+type apiKey struct {
+	ID         uuid.UUID      `db:"api_key_id"`
+	TenantID   uuid.UUID      `db:"tenant_id"`
+	UserID     uuid.NullUUID  `db:"user_id"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"prefix"`
+	KeyHash    []byte         `db:"key_hash"`
+	Scopes     dbarray.String `db:"scopes"`
+	CreatedBy  uuid.UUID      `db:"created_by"`
+	LastUsedAt sql.NullTime   `db:"last_used_at"`
+	ExpiresAt  sql.NullTime   `db:"expires_at"`
+	RevokedAt  sql.NullTime   `db:"revoked_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func toDBAPIKey(bus apikeybus.APIKey, hash []byte) apiKey {
+	db := apiKey{
+		ID:         bus.ID,
+		TenantID:   bus.TenantID,
+		Name:       bus.Name,
+		Prefix:     bus.Prefix,
+		KeyHash:    hash,
+		Scopes:     apikeybus.ScopesToString(bus.Scopes),
+		CreatedBy:  bus.CreatedBy,
+		LastUsedAt: toNullTime(bus.LastUsedAt),
+		ExpiresAt:  toNullTime(bus.ExpiresAt),
+		RevokedAt:  toNullTime(bus.RevokedAt),
+		CreatedAt:  bus.CreatedAt.UTC(),
+	}
+
+	if bus.UserID != nil {
+		db.UserID = uuid.NullUUID{UUID: *bus.UserID, Valid: true}
+	}
+
+	return db
+}
+
+func toBusAPIKey(db apiKey) (apikeybus.APIKey, error) {
+	scopes, err := apikeybus.ParseScopes(db.Scopes)
+	if err != nil {
+		return apikeybus.APIKey{}, fmt.Errorf("parse scopes: %w", err)
+	}
+
+	bus := apikeybus.APIKey{
+		ID:         db.ID,
+		TenantID:   db.TenantID,
+		Name:       db.Name,
+		Prefix:     db.Prefix,
+		Scopes:     scopes,
+		CreatedBy:  db.CreatedBy,
+		LastUsedAt: fromNullTime(db.LastUsedAt),
+		ExpiresAt:  fromNullTime(db.ExpiresAt),
+		RevokedAt:  fromNullTime(db.RevokedAt),
+		CreatedAt:  db.CreatedAt.In(time.Local),
+	}
+
+	if db.UserID.Valid {
+		userID := db.UserID.UUID
+		bus.UserID = &userID
+	}
+
+	return bus, nil
+}
+
+func toBusAPIKeys(dbs []apiKey) ([]apikeybus.APIKey, error) {
+	bus := make([]apikeybus.APIKey, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusAPIKey(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+func toNullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func fromNullTime(nt sql.NullTime) time.Time {
+	if !nt.Valid {
+		return time.Time{}
+	}
+
+	return nt.Time.In(time.Local)
+}
@@ -0,0 +1,24 @@
+package apikeydb
+
+import (
+	"fmt"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/apikeybus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	apikeybus.OrderByID:         "api_key_id",
+	apikeybus.OrderByName:       "name",
+	apikeybus.OrderByLastUsedAt: "last_used_at",
+	apikeybus.OrderByCreatedAt:  "created_at",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
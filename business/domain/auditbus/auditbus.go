@@ -0,0 +1,89 @@
+// Package auditbus provides business access to the audit log.
+package auditbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, adt Audit) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Audit, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+}
+
+// Business manages the set of APIs for audit access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs an audit business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create records a new event in the audit log.
+func (b *Business) Create(ctx context.Context, na NewAudit) (Audit, error) {
+	adt := Audit{
+		ID:        uuid.New(),
+		ActorID:   na.ActorID,
+		Action:    na.Action,
+		Subject:   na.Subject,
+		IP:        na.IP,
+		Details:   na.Details,
+		CreatedAt: time.Now(),
+	}
+
+	if err := b.storer.Create(ctx, adt); err != nil {
+		return Audit{}, fmt.Errorf("create: %w", err)
+	}
+
+	b.log.Info(ctx, "audit", "action", adt.Action, "actorID", adt.ActorID, "subject", adt.Subject, "ip", adt.IP)
+
+	return adt, nil
+}
+
+// Query retrieves a list of audit events.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Audit, error) {
+	adts, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return adts, nil
+}
+
+// Count returns the total number of audit events.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return b.storer.Count(ctx, filter)
+}
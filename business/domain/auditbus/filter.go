@@ -0,0 +1,16 @@
+package auditbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	ActorID          *uuid.UUID
+	Action           *string
+	Subject          *string
+	StartCreatedDate *time.Time
+	EndCreatedDate   *time.Time
+}
@@ -0,0 +1,28 @@
+package auditbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit represents a security relevant event recorded in the audit log.
+type Audit struct {
+	ID        uuid.UUID
+	ActorID   uuid.UUID
+	Action    string
+	Subject   string
+	IP        string
+	Details   map[string]string
+	CreatedAt time.Time
+}
+
+// NewAudit contains information needed to record an event. The ActorID is
+// left as the zero value for events raised by the system.
+type NewAudit struct {
+	ActorID uuid.UUID
+	Action  string
+	Subject string
+	IP      string
+	Details map[string]string
+}
@@ -0,0 +1,14 @@
+package auditbus
+
+import "github.com/kamogelosekhukhune777/lms/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort. The newest events are
+// returned first.
+var DefaultOrderBy = order.NewBy(OrderByCreatedAt, order.DESC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID        = "audit_id"
+	OrderByAction    = "action"
+	OrderByCreatedAt = "created_at"
+)
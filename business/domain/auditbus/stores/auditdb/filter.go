@@ -0,0 +1,42 @@
+package auditdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+)
+
+func (s *Store) applyFilter(filter auditbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ActorID != nil {
+		data["actor_id"] = *filter.ActorID
+		wc = append(wc, "actor_id = :actor_id")
+	}
+
+	if filter.Action != nil {
+		data["action"] = *filter.Action
+		wc = append(wc, "action = :action")
+	}
+
+	if filter.Subject != nil {
+		data["subject"] = *filter.Subject
+		wc = append(wc, "subject = :subject")
+	}
+
+	if filter.StartCreatedDate != nil {
+		data["start_created_date"] = filter.StartCreatedDate.UTC()
+		wc = append(wc, "created_at >= :start_created_date")
+	}
+
+	if filter.EndCreatedDate != nil {
+		data["end_created_date"] = filter.EndCreatedDate.UTC()
+		wc = append(wc, "created_at <= :end_created_date")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,80 @@
+package auditdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+)
+
+type audit struct {
+	ID        uuid.UUID     `db:"audit_id"`
+	ActorID   uuid.NullUUID `db:"actor_id"`
+	Action    string        `db:"action"`
+	Subject   string        `db:"subject"`
+	IP        string        `db:"ip_address"`
+	Details   string        `db:"details"`
+	CreatedAt time.Time     `db:"created_at"`
+}
+
+func toDBAudit(bus auditbus.Audit) (audit, error) {
+	details := bus.Details
+	if details == nil {
+		details = map[string]string{}
+	}
+
+	data, err := json.Marshal(details)
+	if err != nil {
+		return audit{}, fmt.Errorf("marshal details: %w", err)
+	}
+
+	db := audit{
+		ID:        bus.ID,
+		Action:    bus.Action,
+		Subject:   bus.Subject,
+		IP:        bus.IP,
+		Details:   string(data),
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+
+	if bus.ActorID != uuid.Nil {
+		db.ActorID = uuid.NullUUID{UUID: bus.ActorID, Valid: true}
+	}
+
+	return db, nil
+}
+
+func toBusAudit(db audit) (auditbus.Audit, error) {
+	var details map[string]string
+	if err := json.Unmarshal([]byte(db.Details), &details); err != nil {
+		return auditbus.Audit{}, fmt.Errorf("unmarshal details: %w", err)
+	}
+
+	bus := auditbus.Audit{
+		ID:        db.ID,
+		ActorID:   db.ActorID.UUID,
+		Action:    db.Action,
+		Subject:   db.Subject,
+		IP:        db.IP,
+		Details:   details,
+		CreatedAt: db.CreatedAt.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusAudits(dbs []audit) ([]auditbus.Audit, error) {
+	bus := make([]auditbus.Audit, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusAudit(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
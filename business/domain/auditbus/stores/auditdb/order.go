@@ -0,0 +1,23 @@
+package auditdb
+
+import (
+	"fmt"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	auditbus.OrderByID:        "audit_id",
+	auditbus.OrderByAction:    "action",
+	auditbus.OrderByCreatedAt: "created_at",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
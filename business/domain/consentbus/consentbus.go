@@ -0,0 +1,227 @@
+// Package consentbus provides business access to the legal documents users
+// have to accept and the record of their acceptance.
+package consentbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Set of error variables for legal documents and consent.
+var (
+	ErrNotFound         = errors.New("document not found")
+	ErrUniqueVersion    = errors.New("version already exists for this kind of document")
+	ErrAlreadyPublished = errors.New("document is already published")
+	ErrNotCurrent       = errors.New("document is not the current version")
+	ErrConsentRequired  = errors.New("the current terms must be accepted")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	CreateDocument(ctx context.Context, doc Document) error
+	PublishDocument(ctx context.Context, doc Document) error
+	QueryDocuments(ctx context.Context, orderBy order.By, page page.Page) ([]Document, error)
+	CountDocuments(ctx context.Context) (int, error)
+	QueryDocumentByID(ctx context.Context, documentID uuid.UUID) (Document, error)
+	QueryCurrent(ctx context.Context) ([]Document, error)
+	QueryOutstanding(ctx context.Context, userID uuid.UUID) ([]Document, error)
+	CreateAcceptances(ctx context.Context, acs []Acceptance) error
+	QueryAcceptances(ctx context.Context, userID uuid.UUID) ([]Acceptance, error)
+	QueryCoverage(ctx context.Context) ([]Coverage, error)
+}
+
+// Business manages the set of APIs for legal document and consent access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a consent business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// CreateDocument adds a new, unpublished version of a legal document.
+func (b *Business) CreateDocument(ctx context.Context, nd NewDocument) (Document, error) {
+	doc := Document{
+		ID:        uuid.New(),
+		Kind:      nd.Kind,
+		Version:   nd.Version,
+		Title:     nd.Title,
+		URL:       nd.URL,
+		Content:   nd.Content,
+		CreatedAt: time.Now(),
+	}
+
+	if err := b.storer.CreateDocument(ctx, doc); err != nil {
+		return Document{}, fmt.Errorf("create: %w", err)
+	}
+
+	return doc, nil
+}
+
+// PublishDocument makes the document the current version of its kind. Users
+// who haven't accepted it are asked to do so on their next request.
+func (b *Business) PublishDocument(ctx context.Context, doc Document) (Document, error) {
+	if doc.IsPublished() {
+		return Document{}, ErrAlreadyPublished
+	}
+
+	doc.PublishedAt = time.Now()
+
+	if err := b.storer.PublishDocument(ctx, doc); err != nil {
+		return Document{}, fmt.Errorf("publish: documentID[%s]: %w", doc.ID, err)
+	}
+
+	return doc, nil
+}
+
+// QueryDocuments retrieves a list of legal documents.
+func (b *Business) QueryDocuments(ctx context.Context, orderBy order.By, page page.Page) ([]Document, error) {
+	docs, err := b.storer.QueryDocuments(ctx, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return docs, nil
+}
+
+// CountDocuments returns the total number of legal documents.
+func (b *Business) CountDocuments(ctx context.Context) (int, error) {
+	return b.storer.CountDocuments(ctx)
+}
+
+// QueryDocumentByID finds the legal document by the specified ID.
+func (b *Business) QueryDocumentByID(ctx context.Context, documentID uuid.UUID) (Document, error) {
+	doc, err := b.storer.QueryDocumentByID(ctx, documentID)
+	if err != nil {
+		return Document{}, fmt.Errorf("query: documentID[%s]: %w", documentID, err)
+	}
+
+	return doc, nil
+}
+
+// QueryCurrent returns the latest published version of each kind of legal
+// document.
+func (b *Business) QueryCurrent(ctx context.Context) ([]Document, error) {
+	docs, err := b.storer.QueryCurrent(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querycurrent: %w", err)
+	}
+
+	return docs, nil
+}
+
+// QueryOutstanding returns the current documents the user has not accepted.
+func (b *Business) QueryOutstanding(ctx context.Context, userID uuid.UUID) ([]Document, error) {
+	docs, err := b.storer.QueryOutstanding(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryoutstanding: userID[%s]: %w", userID, err)
+	}
+
+	return docs, nil
+}
+
+// CheckConsent returns ErrConsentRequired when the user has not accepted
+// every current document.
+func (b *Business) CheckConsent(ctx context.Context, userID uuid.UUID) error {
+	docs, err := b.QueryOutstanding(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(docs) > 0 {
+		return ErrConsentRequired
+	}
+
+	return nil
+}
+
+// Accept records that the user accepted the specified documents. Only the
+// current versions can be accepted, and accepting a document twice keeps the
+// first acceptance.
+func (b *Business) Accept(ctx context.Context, userID uuid.UUID, documentIDs []uuid.UUID, ip string, userAgent string) ([]Acceptance, error) {
+	current, err := b.QueryCurrent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	isCurrent := make(map[uuid.UUID]bool, len(current))
+	for _, doc := range current {
+		isCurrent[doc.ID] = true
+	}
+
+	now := time.Now()
+
+	acs := make([]Acceptance, len(documentIDs))
+	for i, documentID := range documentIDs {
+		if !isCurrent[documentID] {
+			return nil, fmt.Errorf("documentID[%s]: %w", documentID, ErrNotCurrent)
+		}
+
+		acs[i] = Acceptance{
+			ID:         uuid.New(),
+			UserID:     userID,
+			DocumentID: documentID,
+			IPAddress:  ip,
+			UserAgent:  userAgent,
+			AcceptedAt: now,
+		}
+	}
+
+	if err := b.storer.CreateAcceptances(ctx, acs); err != nil {
+		return nil, fmt.Errorf("create: userID[%s]: %w", userID, err)
+	}
+
+	return acs, nil
+}
+
+// QueryAcceptances returns every document version the user has accepted.
+func (b *Business) QueryAcceptances(ctx context.Context, userID uuid.UUID) ([]Acceptance, error) {
+	acs, err := b.storer.QueryAcceptances(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("queryacceptances: userID[%s]: %w", userID, err)
+	}
+
+	return acs, nil
+}
+
+// QueryCoverage reports, for every published document, how many users of the
+// tenant have accepted it.
+func (b *Business) QueryCoverage(ctx context.Context) ([]Coverage, error) {
+	cvs, err := b.storer.QueryCoverage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querycoverage: %w", err)
+	}
+
+	return cvs, nil
+}
@@ -0,0 +1,55 @@
+package consentbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Document represents a version of a legal document users have to accept,
+// such as the terms of service or the privacy policy. A document can be
+// edited until it is published, after which the latest published version of
+// each kind is the one users have to accept.
+type Document struct {
+	ID          uuid.UUID
+	Kind        Kind
+	Version     string
+	Title       string
+	URL         string
+	Content     string
+	PublishedAt time.Time
+	CreatedAt   time.Time
+}
+
+// IsPublished reports whether the document has been published.
+func (d Document) IsPublished() bool {
+	return !d.PublishedAt.IsZero()
+}
+
+// NewDocument contains information needed to add a legal document.
+type NewDocument struct {
+	Kind    Kind
+	Version string
+	Title   string
+	URL     string
+	Content string
+}
+
+// Acceptance records that a user accepted a version of a legal document.
+type Acceptance struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	DocumentID uuid.UUID
+	IPAddress  string
+	UserAgent  string
+	AcceptedAt time.Time
+}
+
+// Coverage reports how many users of the tenant accepted a published
+// document.
+type Coverage struct {
+	Document Document
+	Current  bool
+	Users    int
+	Accepted int
+}
@@ -0,0 +1,15 @@
+package consentbus
+
+import "github.com/kamogelosekhukhune777/lms/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByCreatedAt, order.DESC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID          = "document_id"
+	OrderByKind        = "kind"
+	OrderByVersion     = "version"
+	OrderByPublishedAt = "published_at"
+	OrderByCreatedAt   = "created_at"
+)
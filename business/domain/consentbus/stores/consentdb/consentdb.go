@@ -0,0 +1,301 @@
+// Package consentdb contains legal document and consent related CRUD
+// functionality.
+package consentdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// currentDocuments selects the latest published version of each kind.
+const currentDocuments = `
+	SELECT DISTINCT ON (kind)
+		document_id, kind, version, title, url, content, published_at, created_at
+	FROM
+		LegalDocuments
+	WHERE
+		published_at IS NOT NULL
+	ORDER BY
+		kind, published_at DESC`
+
+// Store manages the set of APIs for consent database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (consentbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// CreateDocument inserts a new legal document into the database.
+func (s *Store) CreateDocument(ctx context.Context, doc consentbus.Document) error {
+	const q = `
+	INSERT INTO LegalDocuments
+		(document_id, kind, version, title, url, content, published_at, created_at)
+	VALUES
+		(:document_id, :kind, :version, :title, :url, :content, :published_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBDocument(doc)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", consentbus.ErrUniqueVersion)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// PublishDocument records the publication of a document. The update only
+// succeeds while the document is unpublished so it can only be published
+// once.
+func (s *Store) PublishDocument(ctx context.Context, doc consentbus.Document) error {
+	const q = `
+	UPDATE
+		LegalDocuments
+	SET
+		published_at = :published_at
+	WHERE
+		document_id = :document_id AND published_at IS NULL
+	RETURNING
+		document_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"document_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBDocument(doc), &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", consentbus.ErrAlreadyPublished)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// QueryDocuments retrieves a list of legal documents from the database.
+func (s *Store) QueryDocuments(ctx context.Context, orderBy order.By, page page.Page) ([]consentbus.Document, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		document_id, kind, version, title, url, content, published_at, created_at
+	FROM
+		LegalDocuments`
+
+	buf := bytes.NewBufferString(q)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbDocs []document
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbDocs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusDocuments(dbDocs)
+}
+
+// CountDocuments returns the total number of legal documents in the DB.
+func (s *Store) CountDocuments(ctx context.Context) (int, error) {
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		LegalDocuments`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, map[string]any{}, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryDocumentByID gets the specified legal document from the database.
+func (s *Store) QueryDocumentByID(ctx context.Context, documentID uuid.UUID) (consentbus.Document, error) {
+	data := struct {
+		ID string `db:"document_id"`
+	}{
+		ID: documentID.String(),
+	}
+
+	const q = `
+	SELECT
+		document_id, kind, version, title, url, content, published_at, created_at
+	FROM
+		LegalDocuments
+	WHERE
+		document_id = :document_id`
+
+	var dbDoc document
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbDoc); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return consentbus.Document{}, fmt.Errorf("db: %w", consentbus.ErrNotFound)
+		}
+		return consentbus.Document{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusDocument(dbDoc)
+}
+
+// QueryCurrent retrieves the latest published version of each kind of
+// document.
+func (s *Store) QueryCurrent(ctx context.Context) ([]consentbus.Document, error) {
+	var dbDocs []document
+	if err := sqldb.QuerySlice(ctx, s.log, s.db, currentDocuments, &dbDocs); err != nil {
+		return nil, fmt.Errorf("queryslice: %w", err)
+	}
+
+	return toBusDocuments(dbDocs)
+}
+
+// QueryOutstanding retrieves the current documents the user has not
+// accepted.
+func (s *Store) QueryOutstanding(ctx context.Context, userID uuid.UUID) ([]consentbus.Document, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		d.document_id, d.kind, d.version, d.title, d.url, d.content, d.published_at, d.created_at
+	FROM
+		(` + currentDocuments + `) d
+	WHERE
+		NOT EXISTS (
+			SELECT 1 FROM ConsentAcceptances a WHERE a.document_id = d.document_id AND a.user_id = :user_id
+		)
+	ORDER BY
+		d.kind`
+
+	var dbDocs []document
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbDocs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusDocuments(dbDocs)
+}
+
+// CreateAcceptances inserts the acceptances into the database. A document
+// the user already accepted keeps its original acceptance.
+func (s *Store) CreateAcceptances(ctx context.Context, acs []consentbus.Acceptance) error {
+	const q = `
+	INSERT INTO ConsentAcceptances
+		(acceptance_id, user_id, document_id, ip_address, user_agent, accepted_at)
+	VALUES
+		(:acceptance_id, :user_id, :document_id, :ip_address, :user_agent, :accepted_at)
+	ON CONFLICT (user_id, document_id) DO NOTHING`
+
+	for _, ac := range acs {
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBAcceptance(ac)); err != nil {
+			return fmt.Errorf("namedexeccontext: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// QueryAcceptances retrieves the acceptances of the user, newest first.
+func (s *Store) QueryAcceptances(ctx context.Context, userID uuid.UUID) ([]consentbus.Acceptance, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		acceptance_id, user_id, document_id, ip_address, user_agent, accepted_at
+	FROM
+		ConsentAcceptances
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		accepted_at DESC`
+
+	var dbAcs []acceptance
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbAcs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusAcceptances(dbAcs), nil
+}
+
+// QueryCoverage counts, for every published document, the users of the
+// tenant of the request and how many of them accepted it.
+func (s *Store) QueryCoverage(ctx context.Context) ([]consentbus.Coverage, error) {
+	data := struct {
+		TenantID string `db:"tenant_id"`
+	}{
+		TenantID: tenant.Get(ctx).String(),
+	}
+
+	const q = `
+	SELECT
+		d.document_id, d.kind, d.version, d.title, d.url, d.content, d.published_at, d.created_at,
+		d.document_id IN (SELECT document_id FROM (` + currentDocuments + `) c) AS current,
+		(SELECT count(1) FROM Users u WHERE u.tenant_id = :tenant_id) AS users,
+		(
+			SELECT count(1)
+			FROM ConsentAcceptances a
+			JOIN Users u ON u.user_id = a.user_id
+			WHERE a.document_id = d.document_id AND u.tenant_id = :tenant_id
+		) AS accepted
+	FROM
+		LegalDocuments d
+	WHERE
+		d.published_at IS NOT NULL
+	ORDER BY
+		d.kind, d.published_at DESC`
+
+	var dbCvs []coverage
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbCvs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusCoverages(dbCvs)
+}
@@ -0,0 +1,144 @@
+package consentdb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+)
+
+type document struct {
+	ID          uuid.UUID    `db:"document_id"`
+	Kind        string       `db:"kind"`
+	Version     string       `db:"version"`
+	Title       string       `db:"title"`
+	URL         string       `db:"url"`
+	Content     string       `db:"content"`
+	PublishedAt sql.NullTime `db:"published_at"`
+	CreatedAt   time.Time    `db:"created_at"`
+}
+
+func toDBDocument(bus consentbus.Document) document {
+	db := document{
+		ID:        bus.ID,
+		Kind:      bus.Kind.String(),
+		Version:   bus.Version,
+		Title:     bus.Title,
+		URL:       bus.URL,
+		Content:   bus.Content,
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+
+	if bus.IsPublished() {
+		db.PublishedAt = sql.NullTime{Time: bus.PublishedAt.UTC(), Valid: true}
+	}
+
+	return db
+}
+
+func toBusDocument(db document) (consentbus.Document, error) {
+	kind, err := consentbus.ParseKind(db.Kind)
+	if err != nil {
+		return consentbus.Document{}, fmt.Errorf("parse kind: %w", err)
+	}
+
+	bus := consentbus.Document{
+		ID:        db.ID,
+		Kind:      kind,
+		Version:   db.Version,
+		Title:     db.Title,
+		URL:       db.URL,
+		Content:   db.Content,
+		CreatedAt: db.CreatedAt.In(time.Local),
+	}
+
+	if db.PublishedAt.Valid {
+		bus.PublishedAt = db.PublishedAt.Time.In(time.Local)
+	}
+
+	return bus, nil
+}
+
+func toBusDocuments(dbs []document) ([]consentbus.Document, error) {
+	bus := make([]consentbus.Document, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusDocument(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+type acceptance struct {
+	ID         uuid.UUID `db:"acceptance_id"`
+	UserID     uuid.UUID `db:"user_id"`
+	DocumentID uuid.UUID `db:"document_id"`
+	IPAddress  string    `db:"ip_address"`
+	UserAgent  string    `db:"user_agent"`
+	AcceptedAt time.Time `db:"accepted_at"`
+}
+
+func toDBAcceptance(bus consentbus.Acceptance) acceptance {
+	return acceptance{
+		ID:         bus.ID,
+		UserID:     bus.UserID,
+		DocumentID: bus.DocumentID,
+		IPAddress:  bus.IPAddress,
+		UserAgent:  bus.UserAgent,
+		AcceptedAt: bus.AcceptedAt.UTC(),
+	}
+}
+
+func toBusAcceptances(dbs []acceptance) []consentbus.Acceptance {
+	bus := make([]consentbus.Acceptance, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = consentbus.Acceptance{
+			ID:         db.ID,
+			UserID:     db.UserID,
+			DocumentID: db.DocumentID,
+			IPAddress:  db.IPAddress,
+			UserAgent:  db.UserAgent,
+			AcceptedAt: db.AcceptedAt.In(time.Local),
+		}
+	}
+
+	return bus
+}
+
+// =============================================================================
+
+type coverage struct {
+	document
+	Current  bool `db:"current"`
+	Users    int  `db:"users"`
+	Accepted int  `db:"accepted"`
+}
+
+func toBusCoverages(dbs []coverage) ([]consentbus.Coverage, error) {
+	bus := make([]consentbus.Coverage, len(dbs))
+
+	for i, db := range dbs {
+		doc, err := toBusDocument(db.document)
+		if err != nil {
+			return nil, err
+		}
+
+		bus[i] = consentbus.Coverage{
+			Document: doc,
+			Current:  db.Current,
+			Users:    db.Users,
+			Accepted: db.Accepted,
+		}
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,25 @@
+package consentdb
+
+import (
+	"fmt"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/consentbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	consentbus.OrderByID:          "document_id",
+	consentbus.OrderByKind:        "kind",
+	consentbus.OrderByVersion:     "version",
+	consentbus.OrderByPublishedAt: "published_at",
+	consentbus.OrderByCreatedAt:   "created_at",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,15 +14,32 @@ import (
 	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/business/types/role"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
 )
 
 // Set of error variables for CRUD operations.
 var (
-	ErrNotFound    = errors.New("course not found")
-	ErrInvalidCost = errors.New("cost not valid")
+	ErrNotFound         = errors.New("course not found")
+	ErrInvalidCost      = errors.New("cost not valid")
+	ErrNotInstructor    = errors.New("user is not an instructor")
+	ErrEnrolled         = errors.New("student is already enrolled in the course")
+	ErrCurriculum       = errors.New("curriculum not valid")
+	ErrSectionNotFound  = errors.New("section not found")
+	ErrSectionNotEmpty  = errors.New("section still has lectures")
+	ErrTransition       = errors.New("course status transition not allowed")
+	ErrNotReady         = errors.New("course is not ready to be published")
+	ErrNotInReview      = errors.New("course is not in review")
+	ErrPublished        = errors.New("course is published")
+	ErrLectureNotFound  = errors.New("lecture not found")
+	ErrRevisionNotFound = errors.New("revision not found")
 )
 
+// DefaultSectionTitle is the title of the section created for lectures added
+// to a course that has no sections yet.
+const DefaultSectionTitle = "Course content"
+
 // Storer interface declares the behavior this package needs to persist and
 // retrieve data.
 type Storer interface {
@@ -28,7 +47,7 @@ type Storer interface {
 	Create(ctx context.Context, cor Course) error
 	Update(ctx context.Context, cor Course) error
 	QueryByID(ctx context.Context, courseID uuid.UUID) (Course, error)
-	QueryAll(ctx context.Context) ([]Course, error)
+	QueryAll(ctx context.Context, filter QueryFilter) ([]Course, error)
 	GetCoursesByStudentID(ctx context.Context, studentId uuid.UUID) ([]Course, error)
 	CheckCoursePurchaseInfo(ctx context.Context, courseID uuid.UUID, studentID uuid.UUID) (bool, error)
 	GetLectures(ctx context.Context, courseID uuid.UUID) ([]Lecture, error)
@@ -37,6 +56,35 @@ type Storer interface {
 	ResetCourseProgress(ctx context.Context, userID uuid.UUID, courseID uuid.UUID) error
 	MarkLectureAsViewed(ctx context.Context, userID, courseID, lectureID uuid.UUID) error
 	GetCourseProgress(ctx context.Context, userID uuid.UUID, courseID uuid.UUID) (CourseProgress, error)
+	CreateEnrollment(ctx context.Context, stu Student) error
+	QueryCompletions(ctx context.Context, filter CompletionFilter, page page.Page) ([]Completion, error)
+	CountCompletions(ctx context.Context, filter CompletionFilter) (int, error)
+	CreateLecture(ctx context.Context, lec Lecture) error
+	UpdateLecture(ctx context.Context, lec Lecture) error
+	DeleteLecture(ctx context.Context, lec Lecture) error
+	QueueMediaCleanup(ctx context.Context, publicID string, now time.Time) error
+	QueryMediaCleanup(ctx context.Context, limit int) ([]string, error)
+	DeleteMediaCleanup(ctx context.Context, publicID string) error
+	CreateSection(ctx context.Context, sec Section) error
+	UpdateSection(ctx context.Context, sec Section) error
+	DeleteSection(ctx context.Context, sec Section) error
+	QuerySections(ctx context.Context, courseID uuid.UUID) ([]Section, error)
+	QuerySectionByID(ctx context.Context, courseID uuid.UUID, sectionID uuid.UUID) (Section, error)
+	QueryInReview(ctx context.Context, page page.Page) ([]Course, error)
+	CountInReview(ctx context.Context) (int, error)
+	CreateReview(ctx context.Context, rv Review) error
+	CreateReviewComment(ctx context.Context, rc ReviewComment) error
+	QueryReviews(ctx context.Context, courseID uuid.UUID) ([]Review, error)
+	QueryReviewComments(ctx context.Context, courseID uuid.UUID) ([]ReviewComment, error)
+	CreateRevision(ctx context.Context, rv Revision) error
+	QueryRevisions(ctx context.Context, courseID uuid.UUID) ([]Revision, error)
+	QueryRevisionByNumber(ctx context.Context, courseID uuid.UUID, number int) (Revision, error)
+	QueryLatestRevisionNumber(ctx context.Context, courseID uuid.UUID) (int, error)
+}
+
+// MediaRemover declares the behavior needed to remove uploaded media.
+type MediaRemover interface {
+	DeleteMedia(publicID string) error
 }
 
 // Business manages the set of APIs for product access.
@@ -91,10 +139,15 @@ func (b *Business) Create(ctx context.Context, np NewCourse) (Course, error) {
 		return Course{}, fmt.Errorf("course.querybyid: %s: %w", np.InstructorID, err)
 	}
 
+	if !usr.HasRole(role.Instructor) {
+		return Course{}, fmt.Errorf("userID[%s]: %w", usr.ID, ErrNotInstructor)
+	}
+
 	now := time.Now()
 
 	cor := Course{
 		ID:              uuid.New(),
+		TenantID:        tenant.Get(ctx),
 		InstructorID:    usr.ID,
 		Title:           np.Title,
 		Category:        np.Category,
@@ -106,7 +159,7 @@ func (b *Business) Create(ctx context.Context, np NewCourse) (Course, error) {
 		WelcomeMessage:  np.WelcomeMessage,
 		Pricing:         np.Pricing,
 		Objectives:      np.Objectives,
-		IsPublished:     true,
+		Status:          StatusDraft,
 		CreatedAt:       now,
 	}
 
@@ -114,11 +167,65 @@ func (b *Business) Create(ctx context.Context, np NewCourse) (Course, error) {
 		return Course{}, fmt.Errorf("create: %w", err)
 	}
 
+	if len(np.Curriculum) == 0 {
+		if _, err := b.recordRevision(ctx, cor, usr.ID, 0); err != nil {
+			return Course{}, err
+		}
+
+		return cor, nil
+	}
+
+	sec, err := b.createDefaultSection(ctx, cor.ID)
+	if err != nil {
+		return Course{}, err
+	}
+
+	cor.Curriculum = make([]Lecture, len(np.Curriculum))
+	for i, lec := range np.Curriculum {
+		lec.ID = uuid.New()
+		lec.CourseID = cor.ID
+		lec.SectionID = &sec.ID
+		lec.Position = i
+
+		if err := b.storer.CreateLecture(ctx, lec); err != nil {
+			return Course{}, fmt.Errorf("createlecture: %w", err)
+		}
+
+		cor.Curriculum[i] = lec
+	}
+
+	sec.Lectures = cor.Curriculum
+	cor.Sections = []Section{sec}
+
+	if _, err := b.recordRevision(ctx, cor, usr.ID, 0); err != nil {
+		return Course{}, err
+	}
+
 	return cor, nil
 }
 
-// Update modifies information about a Course.
-func (b *Business) Update(ctx context.Context, cor Course, upc UpdateCourse) (Course, error) {
+// Update modifies information about a Course and records a revision of the
+// course and its curriculum made by the author.
+func (b *Business) Update(ctx context.Context, cor Course, upc UpdateCourse, authorID uuid.UUID) (Course, error) {
+	return b.update(ctx, cor, upc, authorID, 0)
+}
+
+func (b *Business) update(ctx context.Context, cor Course, upc UpdateCourse, authorID uuid.UUID, restoredFrom int) (Course, error) {
+	if err := checkEditable(cor); err != nil {
+		return Course{}, err
+	}
+
+	lecs, err := b.storer.GetLectures(ctx, cor.ID)
+	if err != nil {
+		return Course{}, fmt.Errorf("getlectures: courseID[%s]: %w", cor.ID, err)
+	}
+
+	cor.Curriculum = lecs
+
+	if err := b.recordBaseline(ctx, cor); err != nil {
+		return Course{}, err
+	}
+
 	if upc.Title != nil {
 		cor.Title = *upc.Title
 	}
@@ -159,9 +266,70 @@ func (b *Business) Update(ctx context.Context, cor Course, upc UpdateCourse) (Co
 		cor.Objectives = *upc.Objectives
 	}
 
-	/*if upc.IsPublished != nil {
-		cor.IsPublished = *upc.Ispublished
-	}*/
+	if err := b.storer.Update(ctx, cor); err != nil {
+		return Course{}, fmt.Errorf("update: %w", err)
+	}
+
+	if upc.Curriculum != nil {
+		lecs, err := b.replaceCurriculum(ctx, cor.ID, upc.Curriculum)
+		if err != nil {
+			return Course{}, err
+		}
+
+		cor.Curriculum = lecs
+	}
+
+	if _, err := b.recordRevision(ctx, cor, authorID, restoredFrom); err != nil {
+		return Course{}, err
+	}
+
+	return cor, nil
+}
+
+// ChangeStatus moves the course through its lifecycle. Only the transitions
+// from draft to in review to published to archived, back to draft from in
+// review and archived, are allowed. Courses have to be ready to be published
+// before they are submitted for review, and are published by approving their
+// review.
+func (b *Business) ChangeStatus(ctx context.Context, cor Course, cs ChangeStatus) (Course, error) {
+	if cs.Status == StatusPublished {
+		return Course{}, fmt.Errorf("courseID[%s]: published through review: %w", cor.ID, ErrTransition)
+	}
+
+	return b.changeStatus(ctx, cor, cs)
+}
+
+func (b *Business) changeStatus(ctx context.Context, cor Course, cs ChangeStatus) (Course, error) {
+	if !cor.Status.CanTransitionTo(cs.Status) {
+		return Course{}, fmt.Errorf("courseID[%s] from[%s] to[%s]: %w", cor.ID, cor.Status, cs.Status, ErrTransition)
+	}
+
+	now := time.Now()
+
+	switch cs.Status {
+	case StatusInReview:
+		if err := b.checkReady(ctx, cor); err != nil {
+			return Course{}, err
+		}
+
+		cor.SubmittedAt = now
+
+	case StatusPublished:
+		if err := b.checkReady(ctx, cor); err != nil {
+			return Course{}, err
+		}
+
+		cor.PublishAt = now
+		if cs.PublishAt.After(now) {
+			cor.PublishAt = cs.PublishAt
+		}
+
+	case StatusDraft:
+		cor.PublishAt = time.Time{}
+	}
+
+	cor.Status = cs.Status
+	cor.IsPublished = cs.Status == StatusPublished
 
 	if err := b.storer.Update(ctx, cor); err != nil {
 		return Course{}, fmt.Errorf("update: %w", err)
@@ -170,6 +338,796 @@ func (b *Business) Update(ctx context.Context, cor Course, upc UpdateCourse) (Co
 	return cor, nil
 }
 
+// checkEditable verifies the content of the course can be changed. Published
+// courses have to be archived and moved back to draft first, so changes made
+// to a live course go through review again.
+func checkEditable(cor Course) error {
+	if cor.Status == StatusPublished {
+		return fmt.Errorf("courseID[%s]: %w", cor.ID, ErrPublished)
+	}
+
+	return nil
+}
+
+// checkReady verifies the course has what students need before it's
+// published: at least one lecture, an image, a price and a description.
+func (b *Business) checkReady(ctx context.Context, cor Course) error {
+	lecs, err := b.storer.GetLectures(ctx, cor.ID)
+	if err != nil {
+		return fmt.Errorf("getlectures: courseID[%s]: %w", cor.ID, err)
+	}
+
+	var missing []string
+
+	if len(lecs) == 0 {
+		missing = append(missing, "lectures")
+	}
+
+	if cor.Image == "" {
+		missing = append(missing, "image")
+	}
+
+	if cor.Pricing.Value() <= 0 {
+		missing = append(missing, "pricing")
+	}
+
+	if strings.TrimSpace(cor.Description) == "" {
+		missing = append(missing, "description")
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing %s", ErrNotReady, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// QueryInReview returns the courses of the review queue, the ones submitted
+// first at the front.
+func (b *Business) QueryInReview(ctx context.Context, page page.Page) ([]Course, error) {
+	cors, err := b.storer.QueryInReview(ctx, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return cors, nil
+}
+
+// CountInReview returns the number of courses in the review queue.
+func (b *Business) CountInReview(ctx context.Context) (int, error) {
+	return b.storer.CountInReview(ctx)
+}
+
+// Review records the decision of a reviewer about a course in review. An
+// approved course is published and a course with changes requested goes
+// back to draft for the instructor.
+func (b *Business) Review(ctx context.Context, cor Course, nr NewReview) (Review, Course, error) {
+	if cor.Status != StatusInReview {
+		return Review{}, Course{}, fmt.Errorf("courseID[%s] status[%s]: %w", cor.ID, cor.Status, ErrNotInReview)
+	}
+
+	lecs, err := b.storer.GetLectures(ctx, cor.ID)
+	if err != nil {
+		return Review{}, Course{}, fmt.Errorf("getlectures: courseID[%s]: %w", cor.ID, err)
+	}
+
+	titles := make(map[uuid.UUID]string, len(lecs))
+	for _, lec := range lecs {
+		titles[lec.ID] = lec.Title
+	}
+
+	rv := Review{
+		ID:         uuid.New(),
+		CourseID:   cor.ID,
+		ReviewerID: nr.ReviewerID,
+		Decision:   nr.Decision,
+		Summary:    nr.Summary,
+		Comments:   make([]ReviewComment, len(nr.Comments)),
+		CreatedAt:  time.Now(),
+	}
+
+	for i, nc := range nr.Comments {
+		rc := ReviewComment{
+			ID:        uuid.New(),
+			ReviewID:  rv.ID,
+			LectureID: nc.LectureID,
+			Body:      nc.Body,
+			Position:  i,
+		}
+
+		if nc.LectureID != nil {
+			title, exists := titles[*nc.LectureID]
+			if !exists {
+				return Review{}, Course{}, fmt.Errorf("lectureID[%s]: %w", *nc.LectureID, ErrLectureNotFound)
+			}
+			rc.LectureTitle = title
+		}
+
+		rv.Comments[i] = rc
+	}
+
+	cs := ChangeStatus{
+		Status: StatusDraft,
+	}
+
+	if nr.Decision == DecisionApproved {
+		cs = ChangeStatus{
+			Status:    StatusPublished,
+			PublishAt: nr.PublishAt,
+		}
+	}
+
+	cor, err = b.changeStatus(ctx, cor, cs)
+	if err != nil {
+		return Review{}, Course{}, err
+	}
+
+	if err := b.storer.CreateReview(ctx, rv); err != nil {
+		return Review{}, Course{}, fmt.Errorf("createreview: %w", err)
+	}
+
+	for _, rc := range rv.Comments {
+		if err := b.storer.CreateReviewComment(ctx, rc); err != nil {
+			return Review{}, Course{}, fmt.Errorf("createreviewcomment: %w", err)
+		}
+	}
+
+	return rv, cor, nil
+}
+
+// QueryReviews returns the review history of the course, the latest review
+// first, each with its comments in order.
+func (b *Business) QueryReviews(ctx context.Context, courseID uuid.UUID) ([]Review, error) {
+	rvs, err := b.storer.QueryReviews(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("queryreviews: courseID[%s]: %w", courseID, err)
+	}
+
+	rcs, err := b.storer.QueryReviewComments(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("queryreviewcomments: courseID[%s]: %w", courseID, err)
+	}
+
+	index := make(map[uuid.UUID]int, len(rvs))
+	for i := range rvs {
+		rvs[i].Comments = []ReviewComment{}
+		index[rvs[i].ID] = i
+	}
+
+	for _, rc := range rcs {
+		if i, exists := index[rc.ReviewID]; exists {
+			rvs[i].Comments = append(rvs[i].Comments, rc)
+		}
+	}
+
+	return rvs, nil
+}
+
+// QueryRevisions returns the revisions of the course, the latest first.
+func (b *Business) QueryRevisions(ctx context.Context, courseID uuid.UUID) ([]Revision, error) {
+	rvs, err := b.storer.QueryRevisions(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("query: courseID[%s]: %w", courseID, err)
+	}
+
+	return rvs, nil
+}
+
+// QueryRevisionByNumber finds the revision of the course by its number.
+func (b *Business) QueryRevisionByNumber(ctx context.Context, courseID uuid.UUID, number int) (Revision, error) {
+	rv, err := b.storer.QueryRevisionByNumber(ctx, courseID, number)
+	if err != nil {
+		return Revision{}, fmt.Errorf("query: courseID[%s] number[%d]: %w", courseID, number, err)
+	}
+
+	return rv, nil
+}
+
+// RestoreRevision brings the course, its sections and its curriculum back to
+// the content of the revision, recorded as a new revision. Lectures and
+// sections deleted since the revision are added again. Media referenced by a
+// revision is never cleaned up, so restored lectures keep their media.
+func (b *Business) RestoreRevision(ctx context.Context, cor Course, rv Revision, authorID uuid.UUID) (Course, error) {
+	if err := checkEditable(cor); err != nil {
+		return Course{}, err
+	}
+
+	if err := b.recordBaseline(ctx, cor); err != nil {
+		return Course{}, err
+	}
+
+	// Revisions recorded before sections were part of the snapshot leave the
+	// sections as they are.
+	if len(rv.Snapshot.Sections) > 0 {
+		if err := b.restoreSections(ctx, cor.ID, rv.Snapshot.Sections); err != nil {
+			return Course{}, err
+		}
+	}
+
+	lecs, err := b.storer.GetLectures(ctx, cor.ID)
+	if err != nil {
+		return Course{}, fmt.Errorf("getlectures: courseID[%s]: %w", cor.ID, err)
+	}
+
+	current := make(map[uuid.UUID]bool, len(lecs))
+	for _, lec := range lecs {
+		current[lec.ID] = true
+	}
+
+	curriculum := make([]Lecture, len(rv.Snapshot.Curriculum))
+	for i, lec := range rv.Snapshot.Curriculum {
+		if !current[lec.ID] {
+			lec.ID = uuid.Nil
+		}
+		curriculum[i] = lec
+	}
+
+	snap := rv.Snapshot
+
+	upc := UpdateCourse{
+		Title:           &snap.Title,
+		Category:        &snap.Category,
+		Level:           &snap.Level,
+		PrimaryLanguage: &snap.PrimaryLanguage,
+		Subtitle:        &snap.Subtitle,
+		Description:     &snap.Description,
+		Image:           &snap.Image,
+		WelcomeMessage:  &snap.WelcomeMessage,
+		Curriculum:      curriculum,
+		Pricing:         &snap.Pricing,
+		Objectives:      &snap.Objectives,
+	}
+
+	cor, err = b.update(ctx, cor, upc, authorID, rv.Number)
+	if err != nil {
+		return Course{}, err
+	}
+
+	if len(rv.Snapshot.Sections) > 0 {
+		if err := b.dropSectionsAfter(ctx, cor.ID, rv.Snapshot.Sections, cor.Curriculum); err != nil {
+			return Course{}, err
+		}
+	}
+
+	return cor, nil
+}
+
+// restoreSections brings back the sections of a revision, adding the ones
+// deleted since with their original ID.
+func (b *Business) restoreSections(ctx context.Context, courseID uuid.UUID, sections []Section) error {
+	secs, err := b.storer.QuerySections(ctx, courseID)
+	if err != nil {
+		return fmt.Errorf("querysections: courseID[%s]: %w", courseID, err)
+	}
+
+	current := make(map[uuid.UUID]bool, len(secs))
+	for _, sec := range secs {
+		current[sec.ID] = true
+	}
+
+	for _, sec := range sections {
+		sec.CourseID = courseID
+
+		if current[sec.ID] {
+			if err := b.storer.UpdateSection(ctx, sec); err != nil {
+				return fmt.Errorf("updatesection: sectionID[%s]: %w", sec.ID, err)
+			}
+			continue
+		}
+
+		if err := b.storer.CreateSection(ctx, sec); err != nil {
+			return fmt.Errorf("createsection: sectionID[%s]: %w", sec.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// dropSectionsAfter deletes the sections added after a revision once its
+// curriculum has been restored and they no longer hold any lectures.
+func (b *Business) dropSectionsAfter(ctx context.Context, courseID uuid.UUID, sections []Section, lecs []Lecture) error {
+	keep := make(map[uuid.UUID]bool, len(sections))
+	for _, sec := range sections {
+		keep[sec.ID] = true
+	}
+
+	for _, lec := range lecs {
+		if lec.SectionID != nil {
+			keep[*lec.SectionID] = true
+		}
+	}
+
+	secs, err := b.storer.QuerySections(ctx, courseID)
+	if err != nil {
+		return fmt.Errorf("querysections: courseID[%s]: %w", courseID, err)
+	}
+
+	for _, sec := range secs {
+		if keep[sec.ID] {
+			continue
+		}
+
+		if err := b.storer.DeleteSection(ctx, sec); err != nil {
+			return fmt.Errorf("deletesection: sectionID[%s]: %w", sec.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// recordBaseline records the content of a course created before revisions
+// were recorded as its first revision, before the course is changed.
+func (b *Business) recordBaseline(ctx context.Context, cor Course) error {
+	latest, err := b.storer.QueryLatestRevisionNumber(ctx, cor.ID)
+	if err != nil {
+		return fmt.Errorf("querylatestrevisionnumber: courseID[%s]: %w", cor.ID, err)
+	}
+
+	if latest > 0 {
+		return nil
+	}
+
+	if _, err := b.recordRevision(ctx, cor, uuid.Nil, 0); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// recordRevision stores a snapshot of the course, its sections and its
+// curriculum as the next revision of the course.
+func (b *Business) recordRevision(ctx context.Context, cor Course, authorID uuid.UUID, restoredFrom int) (Revision, error) {
+	latest, err := b.storer.QueryLatestRevisionNumber(ctx, cor.ID)
+	if err != nil {
+		return Revision{}, fmt.Errorf("querylatestrevisionnumber: courseID[%s]: %w", cor.ID, err)
+	}
+
+	secs, err := b.storer.QuerySections(ctx, cor.ID)
+	if err != nil {
+		return Revision{}, fmt.Errorf("querysections: courseID[%s]: %w", cor.ID, err)
+	}
+
+	lecs, err := b.storer.GetLectures(ctx, cor.ID)
+	if err != nil {
+		return Revision{}, fmt.Errorf("getlectures: courseID[%s]: %w", cor.ID, err)
+	}
+
+	rv := Revision{
+		ID:           uuid.New(),
+		CourseID:     cor.ID,
+		Number:       latest + 1,
+		AuthorID:     authorID,
+		RestoredFrom: restoredFrom,
+		Snapshot:     newSnapshot(cor, secs, lecs),
+		CreatedAt:    time.Now(),
+	}
+
+	if err := b.storer.CreateRevision(ctx, rv); err != nil {
+		return Revision{}, fmt.Errorf("createrevision: courseID[%s]: %w", cor.ID, err)
+	}
+
+	return rv, nil
+}
+
+// replaceCurriculum makes the specified lectures the curriculum of the
+// course, in the order provided within each section. Lectures with an ID are
+// updated and keep their section, lectures without one are added to the
+// section of the lecture before them, or to a default section when the course
+// has none, and the ones left out are deleted. A lecture that names a section
+// of the course is placed in it instead. The media of the deleted or
+// replaced lectures is queued for cleanup, so it's only removed once the
+// transaction has been committed.
+func (b *Business) replaceCurriculum(ctx context.Context, courseID uuid.UUID, curriculum []Lecture) ([]Lecture, error) {
+	existing, err := b.storer.GetLectures(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("getlectures: courseID[%s]: %w", courseID, err)
+	}
+
+	secs, err := b.storer.QuerySections(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("querysections: courseID[%s]: %w", courseID, err)
+	}
+
+	current := make(map[uuid.UUID]Lecture, len(existing))
+	for _, lec := range existing {
+		current[lec.ID] = lec
+	}
+
+	if len(secs) == 0 && len(curriculum) > 0 {
+		sec, err := b.createDefaultSection(ctx, courseID)
+		if err != nil {
+			return nil, err
+		}
+		secs = append(secs, sec)
+	}
+
+	order := make(map[uuid.UUID]int, len(secs))
+	for _, sec := range secs {
+		order[sec.ID] = sec.Position
+	}
+
+	var sectionID *uuid.UUID
+	if len(secs) > 0 {
+		sectionID = &secs[0].ID
+	}
+
+	lecs := make([]Lecture, len(curriculum))
+	kept := make(map[uuid.UUID]bool, len(curriculum))
+	inUse := make(map[string]bool, len(curriculum))
+
+	for i, lec := range curriculum {
+		if lec.ID != uuid.Nil {
+			old, exists := current[lec.ID]
+			if !exists || kept[lec.ID] {
+				return nil, fmt.Errorf("lectureID[%s]: %w", lec.ID, ErrCurriculum)
+			}
+			kept[lec.ID] = true
+			if old.SectionID != nil {
+				sectionID = old.SectionID
+			}
+		}
+
+		if lec.SectionID != nil {
+			if _, exists := order[*lec.SectionID]; exists {
+				sectionID = lec.SectionID
+			}
+		}
+
+		lec.CourseID = courseID
+		lec.SectionID = sectionID
+		lecs[i] = lec
+
+		if lec.PublicID != "" {
+			inUse[lec.PublicID] = true
+		}
+	}
+
+	// Lectures are listed section by section, so the positions follow the
+	// order of the sections first and the order provided second.
+	sort.SliceStable(lecs, func(i, j int) bool {
+		return order[*lecs[i].SectionID] < order[*lecs[j].SectionID]
+	})
+
+	for i := range lecs {
+		lecs[i].Position = i
+	}
+
+	now := time.Now()
+
+	for _, old := range existing {
+		if kept[old.ID] {
+			continue
+		}
+
+		if err := b.storer.DeleteLecture(ctx, old); err != nil {
+			return nil, fmt.Errorf("deletelecture: lectureID[%s]: %w", old.ID, err)
+		}
+
+		if old.PublicID != "" && !inUse[old.PublicID] {
+			if err := b.storer.QueueMediaCleanup(ctx, old.PublicID, now); err != nil {
+				return nil, fmt.Errorf("queuemediacleanup: lectureID[%s]: %w", old.ID, err)
+			}
+		}
+	}
+
+	for i, lec := range lecs {
+		if lec.ID == uuid.Nil {
+			lec.ID = uuid.New()
+			if err := b.storer.CreateLecture(ctx, lec); err != nil {
+				return nil, fmt.Errorf("createlecture: %w", err)
+			}
+
+			lecs[i] = lec
+			continue
+		}
+
+		if err := b.storer.UpdateLecture(ctx, lec); err != nil {
+			return nil, fmt.Errorf("updatelecture: lectureID[%s]: %w", lec.ID, err)
+		}
+
+		old := current[lec.ID]
+		if old.PublicID != "" && !inUse[old.PublicID] {
+			if err := b.storer.QueueMediaCleanup(ctx, old.PublicID, now); err != nil {
+				return nil, fmt.Errorf("queuemediacleanup: lectureID[%s]: %w", old.ID, err)
+			}
+		}
+	}
+
+	return lecs, nil
+}
+
+// createDefaultSection adds the section lectures are placed in when the
+// course has no sections.
+func (b *Business) createDefaultSection(ctx context.Context, courseID uuid.UUID) (Section, error) {
+	sec := Section{
+		ID:        uuid.New(),
+		CourseID:  courseID,
+		Title:     DefaultSectionTitle,
+		Lectures:  []Lecture{},
+		CreatedAt: time.Now(),
+	}
+
+	if err := b.storer.CreateSection(ctx, sec); err != nil {
+		return Section{}, fmt.Errorf("createsection: courseID[%s]: %w", courseID, err)
+	}
+
+	return sec, nil
+}
+
+// CreateSection adds a new section at the end of the course and records a
+// revision of the course made by the author.
+func (b *Business) CreateSection(ctx context.Context, ns NewSection, authorID uuid.UUID) (Section, error) {
+	cor, err := b.beginCurriculumChange(ctx, ns.CourseID)
+	if err != nil {
+		return Section{}, err
+	}
+
+	secs, err := b.storer.QuerySections(ctx, ns.CourseID)
+	if err != nil {
+		return Section{}, fmt.Errorf("querysections: courseID[%s]: %w", ns.CourseID, err)
+	}
+
+	sec := Section{
+		ID:          uuid.New(),
+		CourseID:    ns.CourseID,
+		Title:       ns.Title,
+		Description: ns.Description,
+		Lectures:    []Lecture{},
+		CreatedAt:   time.Now(),
+	}
+
+	if len(secs) > 0 {
+		sec.Position = secs[len(secs)-1].Position + 1
+	}
+
+	if err := b.storer.CreateSection(ctx, sec); err != nil {
+		return Section{}, fmt.Errorf("create: %w", err)
+	}
+
+	if _, err := b.recordRevision(ctx, cor, authorID, 0); err != nil {
+		return Section{}, err
+	}
+
+	return sec, nil
+}
+
+// UpdateSection modifies information about a section and records a revision
+// of the course made by the author.
+func (b *Business) UpdateSection(ctx context.Context, sec Section, us UpdateSection, authorID uuid.UUID) (Section, error) {
+	cor, err := b.beginCurriculumChange(ctx, sec.CourseID)
+	if err != nil {
+		return Section{}, err
+	}
+
+	if us.Title != nil {
+		sec.Title = *us.Title
+	}
+
+	if us.Description != nil {
+		sec.Description = *us.Description
+	}
+
+	if err := b.storer.UpdateSection(ctx, sec); err != nil {
+		return Section{}, fmt.Errorf("update: %w", err)
+	}
+
+	if _, err := b.recordRevision(ctx, cor, authorID, 0); err != nil {
+		return Section{}, err
+	}
+
+	return sec, nil
+}
+
+// DeleteSection removes the section from the course. Sections that still
+// have lectures can't be deleted, the lectures have to be moved to another
+// section or removed first. A revision of the course made by the author is
+// recorded.
+func (b *Business) DeleteSection(ctx context.Context, sec Section, authorID uuid.UUID) error {
+	lecs, err := b.storer.GetLectures(ctx, sec.CourseID)
+	if err != nil {
+		return fmt.Errorf("getlectures: courseID[%s]: %w", sec.CourseID, err)
+	}
+
+	for _, lec := range lecs {
+		if lec.SectionID != nil && *lec.SectionID == sec.ID {
+			return fmt.Errorf("sectionID[%s]: %w", sec.ID, ErrSectionNotEmpty)
+		}
+	}
+
+	cor, err := b.beginCurriculumChange(ctx, sec.CourseID)
+	if err != nil {
+		return err
+	}
+
+	if err := b.storer.DeleteSection(ctx, sec); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	if _, err := b.recordRevision(ctx, cor, authorID, 0); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// QuerySectionByID finds the section of the course by the specified ID.
+func (b *Business) QuerySectionByID(ctx context.Context, courseID uuid.UUID, sectionID uuid.UUID) (Section, error) {
+	sec, err := b.storer.QuerySectionByID(ctx, courseID, sectionID)
+	if err != nil {
+		return Section{}, fmt.Errorf("query: sectionID[%s]: %w", sectionID, err)
+	}
+
+	return sec, nil
+}
+
+// QuerySections returns the sections of the course in order, each with its
+// lectures in order.
+func (b *Business) QuerySections(ctx context.Context, courseID uuid.UUID) ([]Section, error) {
+	secs, err := b.storer.QuerySections(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("querysections: courseID[%s]: %w", courseID, err)
+	}
+
+	lecs, err := b.storer.GetLectures(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("getlectures: courseID[%s]: %w", courseID, err)
+	}
+
+	index := make(map[uuid.UUID]int, len(secs))
+	for i := range secs {
+		secs[i].Lectures = []Lecture{}
+		index[secs[i].ID] = i
+	}
+
+	for _, lec := range lecs {
+		if lec.SectionID == nil {
+			continue
+		}
+
+		if i, exists := index[*lec.SectionID]; exists {
+			secs[i].Lectures = append(secs[i].Lectures, lec)
+		}
+	}
+
+	return secs, nil
+}
+
+// ReorderCurriculum moves the lectures of the course within and between its
+// sections and orders the sections. The layout must place every section and
+// every lecture of the course exactly once. A revision of the course made by
+// the author is recorded.
+func (b *Business) ReorderCurriculum(ctx context.Context, courseID uuid.UUID, layout []SectionLayout, authorID uuid.UUID) ([]Section, error) {
+	secs, err := b.storer.QuerySections(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("querysections: courseID[%s]: %w", courseID, err)
+	}
+
+	lecs, err := b.storer.GetLectures(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("getlectures: courseID[%s]: %w", courseID, err)
+	}
+
+	if len(layout) != len(secs) {
+		return nil, fmt.Errorf("sections[%d] layout[%d]: %w", len(secs), len(layout), ErrCurriculum)
+	}
+
+	sections := make(map[uuid.UUID]Section, len(secs))
+	for _, sec := range secs {
+		sections[sec.ID] = sec
+	}
+
+	lectures := make(map[uuid.UUID]Lecture, len(lecs))
+	for _, lec := range lecs {
+		lectures[lec.ID] = lec
+	}
+
+	placed := make(map[uuid.UUID]bool, len(lecs))
+	ordered := make([]Section, len(layout))
+	var position int
+
+	for i, sl := range layout {
+		sec, exists := sections[sl.SectionID]
+		if !exists {
+			return nil, fmt.Errorf("sectionID[%s]: %w", sl.SectionID, ErrCurriculum)
+		}
+		delete(sections, sl.SectionID)
+
+		sec.Position = i
+		sec.Lectures = make([]Lecture, len(sl.LectureIDs))
+
+		for j, lectureID := range sl.LectureIDs {
+			lec, exists := lectures[lectureID]
+			if !exists || placed[lectureID] {
+				return nil, fmt.Errorf("lectureID[%s]: %w", lectureID, ErrCurriculum)
+			}
+			placed[lectureID] = true
+
+			lec.SectionID = &sec.ID
+			lec.Position = position
+			sec.Lectures[j] = lec
+			position++
+		}
+
+		ordered[i] = sec
+	}
+
+	if len(placed) != len(lecs) {
+		return nil, fmt.Errorf("lectures[%d] placed[%d]: %w", len(lecs), len(placed), ErrCurriculum)
+	}
+
+	cor, err := b.beginCurriculumChange(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sec := range ordered {
+		if err := b.storer.UpdateSection(ctx, sec); err != nil {
+			return nil, fmt.Errorf("updatesection: sectionID[%s]: %w", sec.ID, err)
+		}
+
+		for _, lec := range sec.Lectures {
+			if err := b.storer.UpdateLecture(ctx, lec); err != nil {
+				return nil, fmt.Errorf("updatelecture: lectureID[%s]: %w", lec.ID, err)
+			}
+		}
+	}
+
+	if _, err := b.recordRevision(ctx, cor, authorID, 0); err != nil {
+		return nil, err
+	}
+
+	return ordered, nil
+}
+
+// beginCurriculumChange returns the course before its sections or the order
+// of its lectures change, recording its content first if it has no revisions.
+func (b *Business) beginCurriculumChange(ctx context.Context, courseID uuid.UUID) (Course, error) {
+	cor, err := b.storer.QueryByID(ctx, courseID)
+	if err != nil {
+		return Course{}, fmt.Errorf("querybyid: courseID[%s]: %w", courseID, err)
+	}
+
+	if err := checkEditable(cor); err != nil {
+		return Course{}, err
+	}
+
+	if err := b.recordBaseline(ctx, cor); err != nil {
+		return Course{}, err
+	}
+
+	return cor, nil
+}
+
+// PurgeMedia removes the media queued for cleanup from the media store. Media
+// that is in use by a lecture again, or kept by a revision a lecture can be
+// restored from, is left alone. It returns the number of
+// media removed.
+func (b *Business) PurgeMedia(ctx context.Context, remover MediaRemover, limit int) (int, error) {
+	publicIDs, err := b.storer.QueryMediaCleanup(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("querymediacleanup: %w", err)
+	}
+
+	var purged int
+	var errs []error
+
+	for _, publicID := range publicIDs {
+		if err := remover.DeleteMedia(publicID); err != nil {
+			errs = append(errs, fmt.Errorf("deletemedia: publicID[%s]: %w", publicID, err))
+			continue
+		}
+
+		if err := b.storer.DeleteMediaCleanup(ctx, publicID); err != nil {
+			errs = append(errs, fmt.Errorf("deletemediacleanup: publicID[%s]: %w", publicID, err))
+			continue
+		}
+
+		purged++
+	}
+
+	return purged, errors.Join(errs...)
+}
+
 // QueryByID finds the course by the specified ID.
 func (b *Business) QueryByID(ctx context.Context, courseID uuid.UUID) (Course, error) {
 
@@ -181,8 +1139,9 @@ func (b *Business) QueryByID(ctx context.Context, courseID uuid.UUID) (Course, e
 	return cor, nil
 }
 
-func (b *Business) QueryAll(ctx context.Context) ([]Course, error) {
-	cors, err := b.storer.QueryAll(ctx)
+// QueryAll returns the courses of the tenant that match the filter.
+func (b *Business) QueryAll(ctx context.Context, filter QueryFilter) ([]Course, error) {
+	cors, err := b.storer.QueryAll(ctx, filter)
 	if err != nil {
 		return []Course{}, fmt.Errorf("query: %w", err)
 	}
@@ -287,3 +1246,56 @@ func (b *Business) ResetCourseProgress(ctx context.Context, userID uuid.UUID, co
 
 	return cors, nil
 }
+
+//======================================================================================================================
+
+// Enroll enrolls the student in the course without an order. Both have to
+// belong to the organization of the request.
+func (b *Business) Enroll(ctx context.Context, ne NewEnrollment) (Student, error) {
+	if _, err := b.userBus.QueryByID(ctx, ne.StudentID); err != nil {
+		return Student{}, fmt.Errorf("query student: studentID[%s]: %w", ne.StudentID, err)
+	}
+
+	if _, err := b.storer.QueryByID(ctx, ne.CourseID); err != nil {
+		return Student{}, fmt.Errorf("query course: courseID[%s]: %w", ne.CourseID, err)
+	}
+
+	enrolled, err := b.storer.CheckCoursePurchaseInfo(ctx, ne.CourseID, ne.StudentID)
+	if err != nil {
+		return Student{}, fmt.Errorf("query enrollment: %w", err)
+	}
+
+	if enrolled {
+		return Student{}, ErrEnrolled
+	}
+
+	stu := Student{
+		ID:         uuid.New(),
+		StudentID:  ne.StudentID,
+		CourseID:   ne.CourseID,
+		EnrolledAt: time.Now(),
+	}
+
+	if err := b.storer.CreateEnrollment(ctx, stu); err != nil {
+		return Student{}, fmt.Errorf("create enrollment: %w", err)
+	}
+
+	return stu, nil
+}
+
+// QueryCompletions reports the progress of the students of the organization
+// through the courses they are enrolled in, most recent enrollments first.
+func (b *Business) QueryCompletions(ctx context.Context, filter CompletionFilter, page page.Page) ([]Completion, error) {
+	cmps, err := b.storer.QueryCompletions(ctx, filter, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return cmps, nil
+}
+
+// CountCompletions returns the total number of enrollments matching the
+// filter.
+func (b *Business) CountCompletions(ctx context.Context, filter CompletionFilter) (int, error) {
+	return b.storer.CountCompletions(ctx, filter)
+}
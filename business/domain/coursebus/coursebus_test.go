@@ -0,0 +1,185 @@
+package coursebus_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
+	"github.com/kamogelosekhukhune777/lms/business/types/money"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+func Test_Submit(t *testing.T) {
+	table := []struct {
+		name    string
+		course  func(cor *coursebus.Course)
+		lecs    int
+		wantErr error
+	}{
+		{
+			name: "ready",
+			lecs: 1,
+		},
+		{
+			name:    "no lectures",
+			wantErr: coursebus.ErrNotReady,
+		},
+		{
+			name:    "no image",
+			course:  func(cor *coursebus.Course) { cor.Image = "" },
+			lecs:    1,
+			wantErr: coursebus.ErrNotReady,
+		},
+		{
+			name:    "no description",
+			course:  func(cor *coursebus.Course) { cor.Description = " " },
+			lecs:    1,
+			wantErr: coursebus.ErrNotReady,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			cor := newCourse(t, coursebus.StatusDraft)
+			if tt.course != nil {
+				tt.course(&cor)
+			}
+
+			store := courseStore{cor: cor, lecs: make([]coursebus.Lecture, tt.lecs)}
+			bus := coursebus.NewBusiness(newLogger(), nil, &store)
+
+			got, err := bus.ChangeStatus(context.Background(), cor, coursebus.ChangeStatus{Status: coursebus.StatusInReview})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Should fail with %v: got %v", tt.wantErr, err)
+				}
+
+				if store.updated {
+					t.Fatal("Should not submit a course that isn't ready")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Should submit the course: %s", err)
+			}
+
+			if got.Status != coursebus.StatusInReview || got.SubmittedAt.IsZero() {
+				t.Errorf("Should put the course in review: got status[%s] submitted[%s]", got.Status, got.SubmittedAt)
+			}
+		})
+	}
+}
+
+func Test_EditPublished(t *testing.T) {
+	cor := newCourse(t, coursebus.StatusPublished)
+
+	store := courseStore{cor: cor, lecs: make([]coursebus.Lecture, 1)}
+	bus := coursebus.NewBusiness(newLogger(), nil, &store)
+
+	ctx := context.Background()
+	authorID := uuid.New()
+	title := "Changed"
+
+	edits := map[string]func() error{
+		"update": func() error {
+			_, err := bus.Update(ctx, cor, coursebus.UpdateCourse{Title: &title}, authorID)
+			return err
+		},
+		"restore revision": func() error {
+			_, err := bus.RestoreRevision(ctx, cor, coursebus.Revision{}, authorID)
+			return err
+		},
+		"create section": func() error {
+			_, err := bus.CreateSection(ctx, coursebus.NewSection{CourseID: cor.ID, Title: title}, authorID)
+			return err
+		},
+		"update section": func() error {
+			_, err := bus.UpdateSection(ctx, coursebus.Section{ID: uuid.New(), CourseID: cor.ID}, coursebus.UpdateSection{Title: &title}, authorID)
+			return err
+		},
+		"delete section": func() error {
+			return bus.DeleteSection(ctx, coursebus.Section{ID: uuid.New(), CourseID: cor.ID}, authorID)
+		},
+	}
+
+	for name, edit := range edits {
+		t.Run(name, func(t *testing.T) {
+			if err := edit(); !errors.Is(err, coursebus.ErrPublished) {
+				t.Fatalf("Should refuse to edit a published course: got %v", err)
+			}
+
+			if store.updated {
+				t.Fatal("Should not change a published course")
+			}
+		})
+	}
+}
+
+// =============================================================================
+
+// courseStore holds a single course and its lectures. Any write to the
+// course or its curriculum is recorded.
+type courseStore struct {
+	coursebus.Storer
+	cor     coursebus.Course
+	lecs    []coursebus.Lecture
+	updated bool
+}
+
+func (s *courseStore) QueryByID(ctx context.Context, courseID uuid.UUID) (coursebus.Course, error) {
+	if courseID != s.cor.ID {
+		return coursebus.Course{}, coursebus.ErrNotFound
+	}
+
+	return s.cor, nil
+}
+
+func (s *courseStore) GetLectures(ctx context.Context, courseID uuid.UUID) ([]coursebus.Lecture, error) {
+	return s.lecs, nil
+}
+
+func (s *courseStore) Update(ctx context.Context, cor coursebus.Course) error {
+	s.updated = true
+	return nil
+}
+
+func (s *courseStore) CreateSection(ctx context.Context, sec coursebus.Section) error {
+	s.updated = true
+	return nil
+}
+
+func (s *courseStore) UpdateSection(ctx context.Context, sec coursebus.Section) error {
+	s.updated = true
+	return nil
+}
+
+func (s *courseStore) DeleteSection(ctx context.Context, sec coursebus.Section) error {
+	s.updated = true
+	return nil
+}
+
+func newCourse(t *testing.T, status coursebus.Status) coursebus.Course {
+	price, err := money.Parse(10)
+	if err != nil {
+		t.Fatalf("Should be able to parse the price: %s", err)
+	}
+
+	return coursebus.Course{
+		ID:          uuid.New(),
+		Title:       "Course",
+		Image:       "course.png",
+		Description: "A course",
+		Pricing:     price,
+		Status:      status,
+	}
+}
+
+func newLogger() *logger.Logger {
+	var buf bytes.Buffer
+	return logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+}
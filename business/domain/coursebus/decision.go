@@ -0,0 +1,50 @@
+package coursebus
+
+import "fmt"
+
+// The set of decisions a reviewer can make about a course in review.
+var (
+	DecisionApproved         = newDecision("APPROVED")
+	DecisionChangesRequested = newDecision("CHANGES_REQUESTED")
+)
+
+// =============================================================================
+
+// Set of known decisions.
+var decisions = make(map[string]Decision)
+
+// Decision represents the outcome of the review of a course.
+type Decision struct {
+	value string
+}
+
+func newDecision(decision string) Decision {
+	d := Decision{decision}
+	decisions[decision] = d
+	return d
+}
+
+// String returns the name of the decision.
+func (d Decision) String() string {
+	return d.value
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (d Decision) Equal(d2 Decision) bool {
+	return d.value == d2.value
+}
+
+// MarshalText provides support for logging and any marshal needs.
+func (d Decision) MarshalText() ([]byte, error) {
+	return []byte(d.value), nil
+}
+
+// ParseDecision parses the string value and returns a decision if one exists.
+func ParseDecision(value string) (Decision, error) {
+	decision, exists := decisions[value]
+	if !exists {
+		return Decision{}, fmt.Errorf("invalid decision %q", value)
+	}
+
+	return decision, nil
+}
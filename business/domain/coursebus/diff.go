@@ -0,0 +1,114 @@
+package coursebus
+
+import (
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// Change represents the change of a field between two revisions. Changes to
+// a section or a lecture carry its ID; a section or lecture that was added or
+// removed is reported as a change of the "section" or "lecture" field with
+// its title.
+type Change struct {
+	Field     string
+	SectionID uuid.UUID
+	LectureID uuid.UUID
+	From      string
+	To        string
+}
+
+// Diff returns the field level changes from one snapshot to another, the
+// course fields first followed by the sections and the curriculum in their
+// order.
+func Diff(from Snapshot, to Snapshot) []Change {
+	changes := []Change{}
+
+	add := func(field string, lectureID uuid.UUID, fromValue string, toValue string) {
+		if fromValue != toValue {
+			changes = append(changes, Change{Field: field, LectureID: lectureID, From: fromValue, To: toValue})
+		}
+	}
+
+	addSection := func(field string, sectionID uuid.UUID, fromValue string, toValue string) {
+		if fromValue != toValue {
+			changes = append(changes, Change{Field: field, SectionID: sectionID, From: fromValue, To: toValue})
+		}
+	}
+
+	add("title", uuid.Nil, from.Title, to.Title)
+	add("category", uuid.Nil, from.Category, to.Category)
+	add("level", uuid.Nil, from.Level, to.Level)
+	add("primary_language", uuid.Nil, from.PrimaryLanguage, to.PrimaryLanguage)
+	add("subtitle", uuid.Nil, from.Subtitle, to.Subtitle)
+	add("description", uuid.Nil, from.Description, to.Description)
+	add("image", uuid.Nil, from.Image, to.Image)
+	add("welcome_message", uuid.Nil, from.WelcomeMessage, to.WelcomeMessage)
+	add("pricing", uuid.Nil, from.Pricing.String(), to.Pricing.String())
+	add("objectives", uuid.Nil, from.Objectives, to.Objectives)
+
+	toSecs := make(map[uuid.UUID]Section, len(to.Sections))
+	for _, sec := range to.Sections {
+		toSecs[sec.ID] = sec
+	}
+
+	fromSecs := make(map[uuid.UUID]Section, len(from.Sections))
+	for _, old := range from.Sections {
+		fromSecs[old.ID] = old
+
+		sec, exists := toSecs[old.ID]
+		if !exists {
+			addSection("section", old.ID, old.Title, "")
+			continue
+		}
+
+		addSection("section.title", sec.ID, old.Title, sec.Title)
+		addSection("section.description", sec.ID, old.Description, sec.Description)
+		addSection("section.position", sec.ID, strconv.Itoa(old.Position), strconv.Itoa(sec.Position))
+	}
+
+	for _, sec := range to.Sections {
+		if _, exists := fromSecs[sec.ID]; !exists {
+			addSection("section", sec.ID, "", sec.Title)
+		}
+	}
+
+	toLecs := make(map[uuid.UUID]Lecture, len(to.Curriculum))
+	for _, lec := range to.Curriculum {
+		toLecs[lec.ID] = lec
+	}
+
+	fromLecs := make(map[uuid.UUID]Lecture, len(from.Curriculum))
+	for _, old := range from.Curriculum {
+		fromLecs[old.ID] = old
+
+		lec, exists := toLecs[old.ID]
+		if !exists {
+			add("lecture", old.ID, old.Title, "")
+			continue
+		}
+
+		add("lecture.title", lec.ID, old.Title, lec.Title)
+		add("lecture.video_url", lec.ID, old.VideoURL, lec.VideoURL)
+		add("lecture.public_id", lec.ID, old.PublicID, lec.PublicID)
+		add("lecture.free_preview", lec.ID, strconv.FormatBool(old.FreePreview), strconv.FormatBool(lec.FreePreview))
+		add("lecture.section_id", lec.ID, sectionString(old.SectionID), sectionString(lec.SectionID))
+		add("lecture.position", lec.ID, strconv.Itoa(old.Position), strconv.Itoa(lec.Position))
+	}
+
+	for _, lec := range to.Curriculum {
+		if _, exists := fromLecs[lec.ID]; !exists {
+			add("lecture", lec.ID, "", lec.Title)
+		}
+	}
+
+	return changes
+}
+
+func sectionString(sectionID *uuid.UUID) string {
+	if sectionID == nil {
+		return ""
+	}
+
+	return sectionID.String()
+}
@@ -7,7 +7,16 @@ import (
 // QueryFilter holds the available fields a query can be filtered on.
 type QueryFilter struct {
 	ID              *uuid.UUID
+	InstructorID    *uuid.UUID
 	Category        *string
 	Level           *string
 	PrimaryLanguage *string
 }
+
+// CompletionFilter holds the available fields a completion report can be
+// filtered on.
+type CompletionFilter struct {
+	CourseID  *uuid.UUID
+	StudentID *uuid.UUID
+	Completed *bool
+}
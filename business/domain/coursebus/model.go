@@ -10,6 +10,7 @@ import (
 // Course represents an individual course.
 type Course struct {
 	ID              uuid.UUID
+	TenantID        uuid.UUID
 	InstructorID    uuid.UUID
 	Title           string
 	Category        string
@@ -21,12 +22,123 @@ type Course struct {
 	WelcomeMessage  string
 	Pricing         money.Money
 	Curriculum      []Lecture
+	Sections        []Section
 	Student         []Student
 	Objectives      string
+	Status          Status
+	PublishAt       time.Time
+	SubmittedAt     time.Time
 	IsPublished     bool
 	CreatedAt       time.Time
 }
 
+// IsLive reports whether the course is published and its publish date has
+// been reached, so it can be offered to students.
+func (c Course) IsLive(now time.Time) bool {
+	return c.Status == StatusPublished && !c.PublishAt.After(now)
+}
+
+// ChangeStatus defines the state a course moves to. PublishAt schedules the
+// publication of the course and is ignored for other states; the zero value
+// publishes the course immediately.
+type ChangeStatus struct {
+	Status    Status
+	PublishAt time.Time
+}
+
+// Review represents the decision of a reviewer about a course in review,
+// with the feedback for the instructor.
+type Review struct {
+	ID         uuid.UUID
+	CourseID   uuid.UUID
+	ReviewerID uuid.UUID
+	Decision   Decision
+	Summary    string
+	Comments   []ReviewComment
+	CreatedAt  time.Time
+}
+
+// ReviewComment represents a feedback comment of a review. Comments about a
+// lecture carry its ID and title; the others are about the whole course.
+type ReviewComment struct {
+	ID           uuid.UUID
+	ReviewID     uuid.UUID
+	LectureID    *uuid.UUID
+	LectureTitle string
+	Body         string
+	Position     int
+}
+
+// NewReview is what we require from reviewers when reviewing a course.
+// PublishAt schedules the publication of an approved course.
+type NewReview struct {
+	ReviewerID uuid.UUID
+	Decision   Decision
+	Summary    string
+	Comments   []NewReviewComment
+	PublishAt  time.Time
+}
+
+// NewReviewComment is a feedback comment of a new review.
+type NewReviewComment struct {
+	LectureID *uuid.UUID
+	Body      string
+}
+
+// Revision represents an immutable snapshot of a course and its curriculum
+// taken when the course was created or updated. RestoredFrom holds the number
+// of the revision it restores, if any.
+type Revision struct {
+	ID           uuid.UUID
+	CourseID     uuid.UUID
+	Number       int
+	AuthorID     uuid.UUID
+	RestoredFrom int
+	Snapshot     Snapshot
+	CreatedAt    time.Time
+}
+
+// Snapshot holds the content of a course at the time of a revision. The
+// sections are kept without their lectures, the curriculum places each
+// lecture in its section.
+type Snapshot struct {
+	Title           string
+	Category        string
+	Level           string
+	PrimaryLanguage string
+	Subtitle        string
+	Description     string
+	Image           string
+	WelcomeMessage  string
+	Pricing         money.Money
+	Objectives      string
+	Sections        []Section
+	Curriculum      []Lecture
+}
+
+func newSnapshot(cor Course, secs []Section, lecs []Lecture) Snapshot {
+	sections := make([]Section, len(secs))
+	for i, sec := range secs {
+		sec.Lectures = nil
+		sections[i] = sec
+	}
+
+	return Snapshot{
+		Title:           cor.Title,
+		Category:        cor.Category,
+		Level:           cor.Level,
+		PrimaryLanguage: cor.PrimaryLanguage,
+		Subtitle:        cor.Subtitle,
+		Description:     cor.Description,
+		Image:           cor.Image,
+		WelcomeMessage:  cor.WelcomeMessage,
+		Pricing:         cor.Pricing,
+		Objectives:      cor.Objectives,
+		Sections:        sections,
+		Curriculum:      lecs,
+	}
+}
+
 // NewCourse is what we require from clients when adding a Course.
 type NewCourse struct {
 	InstructorID    uuid.UUID
@@ -60,13 +172,51 @@ type UpdateCourse struct {
 //========================================================================================================================
 //========================================================================================================================
 
+// Lecture represents a lecture in the curriculum of a course. Position is
+// the zero based place of the lecture in the whole curriculum, counted across
+// the sections in their order.
 type Lecture struct {
 	ID          uuid.UUID
 	CourseID    uuid.UUID
+	SectionID   *uuid.UUID
 	Title       string
 	VideoURL    string
 	PublicID    string
 	FreePreview bool
+	Position    int
+}
+
+// Section represents a module of a course that groups lectures. Position is
+// the zero based place of the section in the course.
+type Section struct {
+	ID          uuid.UUID
+	CourseID    uuid.UUID
+	Title       string
+	Description string
+	Position    int
+	Lectures    []Lecture
+	CreatedAt   time.Time
+}
+
+// NewSection is what we require from clients when adding a Section.
+type NewSection struct {
+	CourseID    uuid.UUID
+	Title       string
+	Description string
+}
+
+// UpdateSection defines what information may be provided to modify an
+// existing Section.
+type UpdateSection struct {
+	Title       *string
+	Description *string
+}
+
+// SectionLayout places the lectures, in order, in a section. The layouts of a
+// course are provided in the order of the sections.
+type SectionLayout struct {
+	SectionID  uuid.UUID
+	LectureIDs []uuid.UUID
 }
 
 // Student(course Students)/(Enrollments)
@@ -89,6 +239,27 @@ type CourseProgress struct {
 	CompletionDate time.Time
 }
 
+// NewEnrollment contains information needed to enroll a student in a course
+// without an order, such as when an organization enrolls its employees.
+type NewEnrollment struct {
+	StudentID uuid.UUID
+	CourseID  uuid.UUID
+}
+
+// Completion reports how far a student got through a course they are
+// enrolled in.
+type Completion struct {
+	EnrollmentID   uuid.UUID
+	StudentID      uuid.UUID
+	StudentName    string
+	StudentEmail   string
+	CourseID       uuid.UUID
+	CourseTitle    string
+	EnrolledAt     time.Time
+	Completed      bool
+	CompletionDate time.Time
+}
+
 type LectureProgress struct {
 	ID         uuid.UUID
 	UserID     uuid.UUID
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -12,6 +13,7 @@ import (
 	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
 )
 
@@ -46,11 +48,14 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (coursebus.Storer, error) {
 func (s *Store) Create(ctx context.Context, cor coursebus.Course) error {
 	const q = `
 	INSERT INTO Courses
-		(course_id, instructor_id, title, category, level, primary_language, subtitle, description, image, welcome_message, pricing, objectives, is_published, created_at)
+		(course_id, tenant_id, instructor_id, title, category, level, primary_language, subtitle, description, image, welcome_message, pricing, objectives, status, publish_at, submitted_at, is_published, created_at)
 	VALUES
-		(:course_id, :instructor_id, :title, :category, :level, :primary_language, :subtitle, :description, :image, :welcome_message, :pricing, :objectives, :is_published, :created_at)`
+		(:course_id, :tenant_id, :instructor_id, :title, :category, :level, :primary_language, :subtitle, :description, :image, :welcome_message, :pricing, :objectives, :status, :publish_at, :submitted_at, :is_published, :created_at)`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBCourse(cor)); err != nil {
+	dbCor := toDBCourse(cor)
+	dbCor.TenantID = tenant.Get(ctx)
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, dbCor); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
 	}
 
@@ -71,12 +76,19 @@ func (s *Store) Update(ctx context.Context, cor coursebus.Course) error {
 		welcome_message = :welcome_message,
 		pricing = :pricing, 
 		objectives = :objectives,
+		status = :status,
+		publish_at = :publish_at,
+		submitted_at = :submitted_at,
 		is_published = :is_published,
 		created_at = :created_at
 	WHERE
-		course_id = :course_id`
+		course_id = :course_id AND
+		tenant_id = :tenant_id`
+
+	dbCor := toDBCourse(cor)
+	dbCor.TenantID = tenant.Get(ctx)
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBCourse(cor)); err != nil {
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, dbCor); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
 	}
 
@@ -85,18 +97,21 @@ func (s *Store) Update(ctx context.Context, cor coursebus.Course) error {
 
 func (s *Store) QueryByID(ctx context.Context, courseID uuid.UUID) (coursebus.Course, error) {
 	data := struct {
-		ID string `db:"course_id"`
+		ID       string `db:"course_id"`
+		TenantID string `db:"tenant_id"`
 	}{
-		ID: courseID.String(),
+		ID:       courseID.String(),
+		TenantID: tenant.Get(ctx).String(),
 	}
 
 	const q = `
 	SELECT
-	    course_id, instructor_id, title, category, level, primary_language, subtitle, description, image, welcome_message, pricing, objectives, is_published, created_at
+	    course_id, tenant_id, instructor_id, title, category, level, primary_language, subtitle, description, image, welcome_message, pricing, objectives, status, publish_at, submitted_at, is_published, created_at
 	FROM
 		Courses
 	WHERE
-		course_id = :course_id`
+		course_id = :course_id AND
+		tenant_id = :tenant_id`
 
 	var dbPrd course
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrd); err != nil {
@@ -110,15 +125,20 @@ func (s *Store) QueryByID(ctx context.Context, courseID uuid.UUID) (coursebus.Co
 
 }
 
-func (s *Store) QueryAll(ctx context.Context) ([]coursebus.Course, error) {
+func (s *Store) QueryAll(ctx context.Context, filter coursebus.QueryFilter) ([]coursebus.Course, error) {
+	data := map[string]any{}
+
 	const q = `
 	SELECT
-	    course_id, instructor_id, title, category, level, primary_language, subtitle, description, image, welcome_message, pricing, objectives, is_published, created_at
+	    course_id, tenant_id, instructor_id, title, category, level, primary_language, subtitle, description, image, welcome_message, pricing, objectives, status, publish_at, submitted_at, is_published, created_at
 	FROM
 		Courses`
 
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(ctx, filter, data, buf)
+
 	var dbPrds []course
-	if err := sqldb.QuerySlice(ctx, s.log, s.db, q, &dbPrds); err != nil {
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbPrds); err != nil {
 		return nil, fmt.Errorf("namedqueryslice: %w", err)
 	}
 
@@ -135,12 +155,17 @@ func (s *Store) QueryAllStudentViewCourses(ctx context.Context, filter coursebus
 
 	const q = `
 	SELECT
-	    product_id, user_id, name, cost, quantity, date_created, date_updated
+	    course_id, tenant_id, instructor_id, title, category, level, primary_language, subtitle, description, image, welcome_message, pricing, objectives, status, publish_at, submitted_at, is_published, created_at
 	FROM
 		Courses`
 
 	buf := bytes.NewBufferString(q)
-	s.applyFilter(filter, data, buf)
+	s.applyFilter(ctx, filter, data, buf)
+
+	// The catalog only offers the courses that are live.
+	data["status"] = coursebus.StatusPublished.String()
+	data["now"] = time.Now().UTC()
+	buf.WriteString(" AND status = :status AND (publish_at IS NULL OR publish_at <= :now)")
 
 	orderByClause, err := orderByClause(orderBy)
 	if err != nil {
@@ -160,11 +185,13 @@ func (s *Store) QueryAllStudentViewCourses(ctx context.Context, filter coursebus
 
 func (s *Store) CheckCoursePurchaseInfo(ctx context.Context, courseID uuid.UUID, studentID uuid.UUID) (bool, error) {
 	data := struct {
-		courseID  string `db:"course_id"`
+		CourseID  string `db:"course_id"`
 		StudentID string `db:"student_id"`
+		TenantID  string `db:"tenant_id"`
 	}{
-		courseID:  courseID.String(),
+		CourseID:  courseID.String(),
 		StudentID: studentID.String(),
+		TenantID:  tenant.Get(ctx).String(),
 	}
 
 	const q = `
@@ -173,31 +200,39 @@ func (s *Store) CheckCoursePurchaseInfo(ctx context.Context, courseID uuid.UUID,
 		FROM Enrollments 
 		WHERE student_id = :student_id 
 		AND course_id = :course_id
+		AND tenant_id = :tenant_id
 	) AS has_purchased`
 
-	var hasPurchased bool
-	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &hasPurchased); err != nil {
+	var result struct {
+		HasPurchased bool `db:"has_purchased"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &result); err != nil {
 		return false, fmt.Errorf("namedquerystruct: %w", err)
 	}
 
-	return hasPurchased, nil
+	return result.HasPurchased, nil
 }
 
 func (s *Store) GetLectures(ctx context.Context, courseID uuid.UUID) ([]coursebus.Lecture, error) {
 	data := struct {
-		CourseID string `json:"course_id"`
+		CourseID string `db:"course_id"`
+		TenantID string `db:"tenant_id"`
 	}{
 		CourseID: courseID.String(),
+		TenantID: tenant.Get(ctx).String(),
 	}
 
 	query := `
 	SELECT 
-		id, title 
+		l.lecture_id, l.course_id, l.title, l.video_url, COALESCE(l.public_id, '') AS public_id, COALESCE(l.free_preview, FALSE) AS free_preview, l.position, l.section_id
 	FROM 
-		lectures 
+		Lectures l
+	JOIN Courses c ON c.course_id = l.course_id
+	LEFT JOIN Sections s ON s.section_id = l.section_id
 	WHERE 
-		course_id = :course_id
-	ORDER BY id`
+		l.course_id = :course_id
+		AND c.tenant_id = :tenant_id
+	ORDER BY s.position NULLS LAST, l.position, l.lecture_id`
 
 	var lectures []lecture
 	err := sqldb.NamedQuerySlice(ctx, s.log, s.db, query, data, &lectures)
@@ -210,19 +245,21 @@ func (s *Store) GetLectures(ctx context.Context, courseID uuid.UUID) ([]coursebu
 func (s *Store) GetCoureStudents(ctx context.Context, courseID uuid.UUID) ([]coursebus.Student, error) {
 	data := struct {
 		CourseID string `db:"course_id"`
+		TenantID string `db:"tenant_id"`
 	}{
 		CourseID: courseID.String(),
+		TenantID: tenant.Get(ctx).String(),
 	}
 
 	const query = `
 	SELECT 
-		s.* 
+		enrollment_id, student_id, course_id, paid_amount, enrolled_at
 	FROM 
-		students s
-	JOIN enrollments e ON s.id = e.student_id
+		Enrollments
 	WHERE 
-		e.course_id = :course_id
-	ORDER BY s.id`
+		course_id = :course_id
+		AND tenant_id = :tenant_id
+	ORDER BY enrolled_at`
 
 	var students []student
 	err := sqldb.NamedQuerySlice(ctx, s.log, s.db, query, data, &students)
@@ -238,14 +275,18 @@ func (s *Store) GetCoureStudents(ctx context.Context, courseID uuid.UUID) ([]cou
 func (s *Store) GetCoursesByStudentID(ctx context.Context, studentID uuid.UUID) ([]coursebus.Course, error) {
 
 	data := struct {
-		ID string `db:"user_id"`
+		ID       string `db:"user_id"`
+		TenantID string `db:"tenant_id"`
 	}{
-		ID: studentID.String(),
+		ID:       studentID.String(),
+		TenantID: tenant.Get(ctx).String(),
 	}
 
 	const q = `
 	SELECT 
 		c.course_id,
+		c.tenant_id,
+		c.instructor_id,
 		c.title,
 		c.category,
 		c.level,
@@ -256,12 +297,15 @@ func (s *Store) GetCoursesByStudentID(ctx context.Context, studentID uuid.UUID)
 		c.welcome_message,
 		c.pricing,
 		c.objectives,
+		c.status,
+		c.publish_at,
+		c.submitted_at,
 		c.is_published,
-		c.created_at,
-		e.enrolled_at
+		c.created_at
 	FROM Enrollments e
 		JOIN Courses c ON e.course_id = c.course_id
-	WHERE e.student_id = :user_id`
+	WHERE e.student_id = :user_id
+		AND e.tenant_id = :tenant_id`
 
 	var dbPrds []course
 	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPrds); err != nil {
@@ -277,19 +321,25 @@ func (s *Store) GetCourseProgress(ctx context.Context, userID uuid.UUID, courseI
 	data := struct {
 		UserID   string `db:"user_id"`
 		CourseID string `db:"course_id"`
+		TenantID string `db:"tenant_id"`
 	}{
 		UserID:   userID.String(),
 		CourseID: courseID.String(),
+		TenantID: tenant.Get(ctx).String(),
 	}
 
 	const q = `
-	SELECT * 
+	SELECT
+		p.progress_id, p.user_id, p.course_id, p.completed, p.completion_date
 	FROM 
-		CourseProgress 
+		CourseProgress p
+	JOIN Courses c ON c.course_id = p.course_id
 	WHERE 
-		user_id = :user_id 
+		p.user_id = :user_id 
 	AND 
-		course_id = :course_id`
+		p.course_id = :course_id
+	AND
+		c.tenant_id = :tenant_id`
 
 	var corp courseProgress
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &corp); err != nil {
@@ -306,20 +356,28 @@ func (s *Store) MarkLectureAsViewed(ctx context.Context, userID, courseID, lectu
 		CourseID   string `db:"course_id"`
 		UserID     string `db:"user_id"`
 		LectureID  string `db:"lecture_id"`
+		TenantID   string `db:"tenant_id"`
 	}{
 		ID:         uuid.New().String(),
 		ProgressID: uuid.New().String(),
 		CourseID:   courseID.String(),
 		UserID:     userID.String(),
 		LectureID:  lectureID.String(),
+		TenantID:   tenant.Get(ctx).String(),
 	}
 
-	// Mark lecture as viewed
+	// Mark lecture as viewed, provided the lecture belongs to a course of
+	// the tenant.
 	const ql = `
 		INSERT INTO LectureProgress 
 			(lecture_progress_id, user_id, lecture_id, viewed, date_viewed)
-		VALUES 
-			(:lecture_progress_id, :user_id, :lecture_id, TRUE, NOW())
+		SELECT
+			:lecture_progress_id, :user_id, l.lecture_id, TRUE, NOW()
+		FROM Lectures l
+		JOIN Courses c ON c.course_id = l.course_id
+		WHERE l.lecture_id = :lecture_id
+		AND l.course_id = :course_id
+		AND c.tenant_id = :tenant_id
 		ON CONFLICT (user_id, lecture_id) DO UPDATE 
 			SET viewed = TRUE, date_viewed = NOW()`
 
@@ -336,13 +394,15 @@ func (s *Store) MarkLectureAsViewed(ctx context.Context, userID, courseID, lectu
 		WHERE l.course_id = :course_id 
 		AND (lp.viewed IS NULL OR lp.viewed = FALSE)) AS all_completed`
 
-	var allCompleted bool
-	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, qm, data, &allCompleted); err != nil {
+	var result struct {
+		AllCompleted bool `db:"all_completed"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, qm, data, &result); err != nil {
 		return fmt.Errorf("namedquerystruct: %w", err)
 	}
 
 	// If all lectures are viewed, mark the course as completed
-	if allCompleted {
+	if result.AllCompleted {
 		const qo = `
 		INSERT INTO CourseProgress 
 			(progress_id, user_id, course_id, completed, completion_date)
@@ -363,9 +423,11 @@ func (s *Store) ResetCourseProgress(ctx context.Context, userID uuid.UUID, cours
 	data := struct {
 		CourseID string `db:"course_id"`
 		UserID   string `db:"user_id"`
+		TenantID string `db:"tenant_id"`
 	}{
 		CourseID: courseID.String(),
 		UserID:   userID.String(),
+		TenantID: tenant.Get(ctx).String(),
 	}
 
 	const ql = `
@@ -373,7 +435,9 @@ func (s *Store) ResetCourseProgress(ctx context.Context, userID uuid.UUID, cours
 		WHERE 
 			user_id = :user_id
 		AND lecture_id IN 
-			(SELECT lecture_id FROM Lectures WHERE course_id = :course_id)`
+			(SELECT l.lecture_id FROM Lectures l
+			JOIN Courses c ON c.course_id = l.course_id
+			WHERE l.course_id = :course_id AND c.tenant_id = :tenant_id)`
 
 	if err := sqldb.NamedExecContext(ctx, s.log, s.db, ql, data); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
@@ -385,7 +449,7 @@ func (s *Store) ResetCourseProgress(ctx context.Context, userID uuid.UUID, cours
 		WHERE 
 			user_id = :user_id 
 		AND 
-			course_id = :course_id`
+			course_id IN (SELECT course_id FROM Courses WHERE course_id = :course_id AND tenant_id = :tenant_id)`
 
 	if err := sqldb.NamedExecContext(ctx, s.log, s.db, qp, data); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
@@ -393,3 +457,607 @@ func (s *Store) ResetCourseProgress(ctx context.Context, userID uuid.UUID, cours
 
 	return nil
 }
+
+//====================================================================================================================
+
+// completionsFrom joins every enrollment with the student, the course and
+// the progress of the student through the course, if any.
+const completionsFrom = `
+	FROM Enrollments e
+		JOIN Users u ON u.user_id = e.student_id
+		JOIN Courses c ON c.course_id = e.course_id
+		LEFT JOIN CourseProgress cp ON cp.user_id = e.student_id AND cp.course_id = e.course_id`
+
+// CreateEnrollment inserts a new enrollment into the database.
+func (s *Store) CreateEnrollment(ctx context.Context, stu coursebus.Student) error {
+	data := struct {
+		student
+		TenantID string `db:"tenant_id"`
+	}{
+		student:  toDBStudent(stu),
+		TenantID: tenant.Get(ctx).String(),
+	}
+	data.EnrolledAt = data.EnrolledAt.UTC()
+
+	const q = `
+	INSERT INTO Enrollments
+		(enrollment_id, tenant_id, student_id, course_id, paid_amount, enrolled_at)
+	VALUES
+		(:enrollment_id, :tenant_id, :student_id, :course_id, :paid_amount, :enrolled_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryCompletions retrieves the progress of the students of the tenant
+// through the courses they are enrolled in.
+func (s *Store) QueryCompletions(ctx context.Context, filter coursebus.CompletionFilter, page page.Page) ([]coursebus.Completion, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		e.enrollment_id,
+		e.student_id,
+		u.user_name,
+		u.user_email,
+		e.course_id,
+		c.title,
+		e.enrolled_at,
+		COALESCE(cp.completed, FALSE) AS completed,
+		cp.completion_date`
+
+	buf := bytes.NewBufferString(q + completionsFrom)
+	s.applyCompletionFilter(ctx, filter, data, buf)
+	buf.WriteString(" ORDER BY e.enrolled_at DESC, e.enrollment_id")
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbCmps []completion
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbCmps); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusCompletions(dbCmps), nil
+}
+
+// CountCompletions returns the total number of enrollments of the tenant
+// matching the filter.
+func (s *Store) CountCompletions(ctx context.Context, filter coursebus.CompletionFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1)`
+
+	buf := bytes.NewBufferString(q + completionsFrom)
+	s.applyCompletionFilter(ctx, filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// =============================================================================
+
+// CreateLecture inserts a new lecture into the database. An empty public id
+// is stored as NULL since public ids must be unique.
+func (s *Store) CreateLecture(ctx context.Context, lec coursebus.Lecture) error {
+	const q = `
+	INSERT INTO Lectures
+		(lecture_id, course_id, section_id, title, video_url, public_id, free_preview, position)
+	VALUES
+		(:lecture_id, :course_id, :section_id, :title, :video_url, NULLIF(:public_id, ''), :free_preview, :position)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBLecture(lec)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateLecture replaces a lecture document in the database.
+func (s *Store) UpdateLecture(ctx context.Context, lec coursebus.Lecture) error {
+	const q = `
+	UPDATE Lectures
+	SET
+		section_id = :section_id,
+		title = :title,
+		video_url = :video_url,
+		public_id = NULLIF(:public_id, ''),
+		free_preview = :free_preview,
+		position = :position
+	WHERE
+		lecture_id = :lecture_id AND
+		course_id = :course_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBLecture(lec)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteLecture removes a lecture from the database.
+func (s *Store) DeleteLecture(ctx context.Context, lec coursebus.Lecture) error {
+	const q = `
+	DELETE FROM
+		Lectures
+	WHERE
+		lecture_id = :lecture_id AND
+		course_id = :course_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBLecture(lec)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// CreateSection inserts a new section into the database.
+func (s *Store) CreateSection(ctx context.Context, sec coursebus.Section) error {
+	const q = `
+	INSERT INTO Sections
+		(section_id, course_id, title, description, position, created_at)
+	VALUES
+		(:section_id, :course_id, :title, :description, :position, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSection(sec)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSection replaces a section document in the database.
+func (s *Store) UpdateSection(ctx context.Context, sec coursebus.Section) error {
+	const q = `
+	UPDATE Sections
+	SET
+		title = :title,
+		description = :description,
+		position = :position
+	WHERE
+		section_id = :section_id AND
+		course_id = :course_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSection(sec)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSection removes a section from the database.
+func (s *Store) DeleteSection(ctx context.Context, sec coursebus.Section) error {
+	const q = `
+	DELETE FROM
+		Sections
+	WHERE
+		section_id = :section_id AND
+		course_id = :course_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSection(sec)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QuerySections retrieves the sections of the course in order.
+func (s *Store) QuerySections(ctx context.Context, courseID uuid.UUID) ([]coursebus.Section, error) {
+	data := struct {
+		CourseID string `db:"course_id"`
+		TenantID string `db:"tenant_id"`
+	}{
+		CourseID: courseID.String(),
+		TenantID: tenant.Get(ctx).String(),
+	}
+
+	const q = `
+	SELECT
+		s.section_id, s.course_id, s.title, s.description, s.position, s.created_at
+	FROM
+		Sections s
+	JOIN Courses c ON c.course_id = s.course_id
+	WHERE
+		s.course_id = :course_id AND
+		c.tenant_id = :tenant_id
+	ORDER BY
+		s.position, s.created_at`
+
+	var dbSecs []section
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbSecs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusSections(dbSecs), nil
+}
+
+// QuerySectionByID gets the specified section of the course from the
+// database.
+func (s *Store) QuerySectionByID(ctx context.Context, courseID uuid.UUID, sectionID uuid.UUID) (coursebus.Section, error) {
+	data := struct {
+		SectionID string `db:"section_id"`
+		CourseID  string `db:"course_id"`
+		TenantID  string `db:"tenant_id"`
+	}{
+		SectionID: sectionID.String(),
+		CourseID:  courseID.String(),
+		TenantID:  tenant.Get(ctx).String(),
+	}
+
+	const q = `
+	SELECT
+		s.section_id, s.course_id, s.title, s.description, s.position, s.created_at
+	FROM
+		Sections s
+	JOIN Courses c ON c.course_id = s.course_id
+	WHERE
+		s.section_id = :section_id AND
+		s.course_id = :course_id AND
+		c.tenant_id = :tenant_id`
+
+	var dbSec section
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbSec); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return coursebus.Section{}, fmt.Errorf("db: %w", coursebus.ErrSectionNotFound)
+		}
+		return coursebus.Section{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusSection(dbSec), nil
+}
+
+// QueryInReview retrieves the courses of the tenant that are in review, the
+// ones submitted first at the front.
+func (s *Store) QueryInReview(ctx context.Context, page page.Page) ([]coursebus.Course, error) {
+	data := map[string]any{
+		"tenant_id":     tenant.Get(ctx),
+		"status":        coursebus.StatusInReview.String(),
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+	    course_id, tenant_id, instructor_id, title, category, level, primary_language, subtitle, description, image, welcome_message, pricing, objectives, status, publish_at, submitted_at, is_published, created_at
+	FROM
+		Courses
+	WHERE
+		tenant_id = :tenant_id AND
+		status = :status
+	ORDER BY
+		submitted_at, created_at
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbCors []course
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbCors); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusCourses(dbCors)
+}
+
+// CountInReview returns the number of courses of the tenant that are in
+// review.
+func (s *Store) CountInReview(ctx context.Context) (int, error) {
+	data := map[string]any{
+		"tenant_id": tenant.Get(ctx),
+		"status":    coursebus.StatusInReview.String(),
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		Courses
+	WHERE
+		tenant_id = :tenant_id AND
+		status = :status`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// CreateReview inserts a new course review into the database.
+func (s *Store) CreateReview(ctx context.Context, rv coursebus.Review) error {
+	const q = `
+	INSERT INTO CourseReviews
+		(review_id, course_id, reviewer_id, decision, summary, created_at)
+	VALUES
+		(:review_id, :course_id, :reviewer_id, :decision, :summary, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBReview(rv)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// CreateReviewComment inserts a new review comment into the database.
+func (s *Store) CreateReviewComment(ctx context.Context, rc coursebus.ReviewComment) error {
+	const q = `
+	INSERT INTO CourseReviewComments
+		(comment_id, review_id, lecture_id, lecture_title, body, position)
+	VALUES
+		(:comment_id, :review_id, :lecture_id, :lecture_title, :body, :position)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBReviewComment(rc)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryReviews retrieves the reviews of the course, the latest first.
+func (s *Store) QueryReviews(ctx context.Context, courseID uuid.UUID) ([]coursebus.Review, error) {
+	data := struct {
+		CourseID string `db:"course_id"`
+		TenantID string `db:"tenant_id"`
+	}{
+		CourseID: courseID.String(),
+		TenantID: tenant.Get(ctx).String(),
+	}
+
+	const q = `
+	SELECT
+		r.review_id, r.course_id, r.reviewer_id, r.decision, r.summary, r.created_at
+	FROM
+		CourseReviews r
+	JOIN Courses c ON c.course_id = r.course_id
+	WHERE
+		r.course_id = :course_id AND
+		c.tenant_id = :tenant_id
+	ORDER BY
+		r.created_at DESC`
+
+	var dbRvs []review
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbRvs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusReviews(dbRvs)
+}
+
+// QueryReviewComments retrieves the comments of all the reviews of the
+// course in order.
+func (s *Store) QueryReviewComments(ctx context.Context, courseID uuid.UUID) ([]coursebus.ReviewComment, error) {
+	data := struct {
+		CourseID string `db:"course_id"`
+		TenantID string `db:"tenant_id"`
+	}{
+		CourseID: courseID.String(),
+		TenantID: tenant.Get(ctx).String(),
+	}
+
+	const q = `
+	SELECT
+		rc.comment_id, rc.review_id, rc.lecture_id, rc.lecture_title, rc.body, rc.position
+	FROM
+		CourseReviewComments rc
+	JOIN CourseReviews r ON r.review_id = rc.review_id
+	JOIN Courses c ON c.course_id = r.course_id
+	WHERE
+		r.course_id = :course_id AND
+		c.tenant_id = :tenant_id
+	ORDER BY
+		rc.review_id, rc.position`
+
+	var dbRcs []reviewComment
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbRcs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusReviewComments(dbRcs), nil
+}
+
+// CreateRevision inserts a new course revision into the database.
+func (s *Store) CreateRevision(ctx context.Context, rv coursebus.Revision) error {
+	dbRv, err := toDBRevision(rv)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+	INSERT INTO CourseRevisions
+		(revision_id, course_id, number, author_id, restored_from, snapshot, created_at)
+	VALUES
+		(:revision_id, :course_id, :number, :author_id, :restored_from, CAST(:snapshot AS JSONB), :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, dbRv); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryRevisions retrieves the revisions of the course, the latest first.
+func (s *Store) QueryRevisions(ctx context.Context, courseID uuid.UUID) ([]coursebus.Revision, error) {
+	data := struct {
+		CourseID string `db:"course_id"`
+		TenantID string `db:"tenant_id"`
+	}{
+		CourseID: courseID.String(),
+		TenantID: tenant.Get(ctx).String(),
+	}
+
+	const q = `
+	SELECT
+		r.revision_id, r.course_id, r.number, r.author_id, r.restored_from, CAST(r.snapshot AS TEXT) AS snapshot, r.created_at
+	FROM
+		CourseRevisions r
+	JOIN Courses c ON c.course_id = r.course_id
+	WHERE
+		r.course_id = :course_id AND
+		c.tenant_id = :tenant_id
+	ORDER BY
+		r.number DESC`
+
+	var dbRvs []revision
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbRvs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusRevisions(dbRvs)
+}
+
+// QueryRevisionByNumber gets the specified revision of the course from the
+// database.
+func (s *Store) QueryRevisionByNumber(ctx context.Context, courseID uuid.UUID, number int) (coursebus.Revision, error) {
+	data := struct {
+		CourseID string `db:"course_id"`
+		Number   int    `db:"number"`
+		TenantID string `db:"tenant_id"`
+	}{
+		CourseID: courseID.String(),
+		Number:   number,
+		TenantID: tenant.Get(ctx).String(),
+	}
+
+	const q = `
+	SELECT
+		r.revision_id, r.course_id, r.number, r.author_id, r.restored_from, CAST(r.snapshot AS TEXT) AS snapshot, r.created_at
+	FROM
+		CourseRevisions r
+	JOIN Courses c ON c.course_id = r.course_id
+	WHERE
+		r.course_id = :course_id AND
+		r.number = :number AND
+		c.tenant_id = :tenant_id`
+
+	var dbRv revision
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRv); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return coursebus.Revision{}, fmt.Errorf("db: %w", coursebus.ErrRevisionNotFound)
+		}
+		return coursebus.Revision{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusRevision(dbRv)
+}
+
+// QueryLatestRevisionNumber returns the number of the latest revision of the
+// course, zero if it has none.
+func (s *Store) QueryLatestRevisionNumber(ctx context.Context, courseID uuid.UUID) (int, error) {
+	data := struct {
+		CourseID string `db:"course_id"`
+	}{
+		CourseID: courseID.String(),
+	}
+
+	const q = `
+	SELECT
+		COALESCE(MAX(number), 0) AS number
+	FROM
+		CourseRevisions
+	WHERE
+		course_id = :course_id`
+
+	var latest struct {
+		Number int `db:"number"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &latest); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return latest.Number, nil
+}
+
+// QueueMediaCleanup adds the media to the cleanup queue.
+func (s *Store) QueueMediaCleanup(ctx context.Context, publicID string, now time.Time) error {
+	data := struct {
+		PublicID string    `db:"public_id"`
+		QueuedAt time.Time `db:"queued_at"`
+	}{
+		PublicID: publicID,
+		QueuedAt: now.UTC(),
+	}
+
+	const q = `
+	INSERT INTO MediaCleanup
+		(public_id, queued_at)
+	VALUES
+		(:public_id, :queued_at)
+	ON CONFLICT (public_id) DO UPDATE
+		SET queued_at = EXCLUDED.queued_at`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryMediaCleanup retrieves the oldest media in the cleanup queue that isn't
+// in use by a lecture or kept by a course revision.
+func (s *Store) QueryMediaCleanup(ctx context.Context, limit int) ([]string, error) {
+	data := map[string]any{
+		"limit": limit,
+	}
+
+	const q = `
+	SELECT
+		m.public_id
+	FROM
+		MediaCleanup m
+	WHERE
+		NOT EXISTS (SELECT 1 FROM Lectures l WHERE l.public_id = m.public_id) AND
+		NOT EXISTS (
+			SELECT 1 FROM CourseRevisions r
+			WHERE r.snapshot @> jsonb_build_object('curriculum', jsonb_build_array(jsonb_build_object('public_id', m.public_id)))
+		)
+	ORDER BY
+		m.queued_at
+	LIMIT :limit`
+
+	var dbMedia []struct {
+		PublicID string `db:"public_id"`
+	}
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbMedia); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	publicIDs := make([]string, len(dbMedia))
+	for i, m := range dbMedia {
+		publicIDs[i] = m.PublicID
+	}
+
+	return publicIDs, nil
+}
+
+// DeleteMediaCleanup removes the media from the cleanup queue.
+func (s *Store) DeleteMediaCleanup(ctx context.Context, publicID string) error {
+	data := struct {
+		PublicID string `db:"public_id"`
+	}{
+		PublicID: publicID,
+	}
+
+	const q = `
+	DELETE FROM
+		MediaCleanup
+	WHERE
+		public_id = :public_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package coursedb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus/stores/coursedb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/dbtest"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/business/types/money"
+)
+
+func Test_QueryAllByInstructor(t *testing.T) {
+	db := dbtest.New(t)
+	store := coursedb.NewStore(db.Log, db.DB)
+	ctx := tenant.Set(context.Background(), tenant.DefaultID)
+
+	instructorA := uuid.New()
+	instructorB := uuid.New()
+	draftID := uuid.New()
+
+	seed := []struct {
+		q    string
+		args []any
+	}{
+		{
+			q:    `INSERT INTO Users (user_id, user_name, user_email, password_hash, roles) VALUES ($1, 'Instructor A', 'a@example.com', 'hash', '{INSTRUCTOR}')`,
+			args: []any{instructorA},
+		},
+		{
+			q:    `INSERT INTO Users (user_id, user_name, user_email, password_hash, roles) VALUES ($1, 'Instructor B', 'b@example.com', 'hash', '{INSTRUCTOR}')`,
+			args: []any{instructorB},
+		},
+	}
+
+	for _, s := range seed {
+		if _, err := db.DB.ExecContext(ctx, s.q, s.args...); err != nil {
+			t.Fatalf("Should be able to seed the database: %s", err)
+		}
+	}
+
+	price, err := money.Parse(10)
+	if err != nil {
+		t.Fatalf("Should be able to parse the price: %s", err)
+	}
+
+	draft := coursebus.Course{
+		ID:           draftID,
+		TenantID:     tenant.DefaultID,
+		InstructorID: instructorA,
+		Title:        "Draft",
+		Pricing:      price,
+		Status:       coursebus.StatusDraft,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := store.Create(ctx, draft); err != nil {
+		t.Fatalf("Should be able to create the draft: %s", err)
+	}
+
+	table := []struct {
+		name   string
+		filter coursebus.QueryFilter
+		exp    []uuid.UUID
+	}{
+		{
+			name:   "instructor B",
+			filter: coursebus.QueryFilter{InstructorID: &instructorB},
+		},
+		{
+			name:   "instructor A",
+			filter: coursebus.QueryFilter{InstructorID: &instructorA},
+			exp:    []uuid.UUID{draftID},
+		},
+		{
+			name: "admin",
+			exp:  []uuid.UUID{draftID},
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			cors, err := store.QueryAll(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Should be able to query the courses: %s", err)
+			}
+
+			if len(cors) != len(tt.exp) {
+				t.Fatalf("Should get %d courses: got %d", len(tt.exp), len(cors))
+			}
+
+			for i, cor := range cors {
+				if cor.ID != tt.exp[i] {
+					t.Errorf("Should get course %s: got %s", tt.exp[i], cor.ID)
+				}
+			}
+		})
+	}
+}
@@ -2,13 +2,21 @@ package coursedb
 
 import (
 	"bytes"
+	"context"
 	"strings"
 
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
 )
 
-func (s *Store) applyFilter(filter coursebus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
-	var wc []string
+func (s *Store) applyFilter(ctx context.Context, filter coursebus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	data["tenant_id"] = tenant.Get(ctx)
+	wc := []string{"tenant_id = :tenant_id"}
+
+	if filter.InstructorID != nil {
+		data["instructor_id"] = *filter.InstructorID
+		wc = append(wc, "instructor_id = :instructor_id")
+	}
 
 	if filter.Category != nil {
 		data["category"] = *filter.Category
@@ -25,7 +33,27 @@ func (s *Store) applyFilter(filter coursebus.QueryFilter, data map[string]any, b
 		wc = append(wc, "primary_language = :primary_language")
 	}
 
-	if len(wc) > 0 {
-		buf.WriteString(" WHERE " + strings.Join(wc, " AND "))
+	buf.WriteString(" WHERE " + strings.Join(wc, " AND "))
+}
+
+func (s *Store) applyCompletionFilter(ctx context.Context, filter coursebus.CompletionFilter, data map[string]any, buf *bytes.Buffer) {
+	data["tenant_id"] = tenant.Get(ctx)
+	wc := []string{"e.tenant_id = :tenant_id"}
+
+	if filter.CourseID != nil {
+		data["course_id"] = *filter.CourseID
+		wc = append(wc, "e.course_id = :course_id")
+	}
+
+	if filter.StudentID != nil {
+		data["student_id"] = *filter.StudentID
+		wc = append(wc, "e.student_id = :student_id")
 	}
+
+	if filter.Completed != nil {
+		data["completed"] = *filter.Completed
+		wc = append(wc, "COALESCE(cp.completed, FALSE) = :completed")
+	}
+
+	buf.WriteString(" WHERE " + strings.Join(wc, " AND "))
 }
@@ -1,6 +1,8 @@
 package coursedb
 
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -10,25 +12,30 @@ import (
 )
 
 type course struct {
-	ID              uuid.UUID `db:"course_id"`
-	InstructorID    uuid.UUID `db:"instructor_id"`
-	Title           string    `db:"title"`
-	Category        string    `db:"category"`
-	Level           string    `db:"level"`
-	PrimaryLanguage string    `db:"primary_language"`
-	Subtitle        string    `db:"subtitle"`
-	Description     string    `db:"description"`
-	Image           string    `db:"image"`
-	WelcomeMessage  string    `db:"welcome_message"`
-	Pricing         float64   `db:"pricing"`
-	Objectives      string    `db:"objectives"`
-	IsPublished     bool      `db:"is_published"`
-	CreatedAt       time.Time `db:"created_at"`
+	ID              uuid.UUID    `db:"course_id"`
+	TenantID        uuid.UUID    `db:"tenant_id"`
+	InstructorID    uuid.UUID    `db:"instructor_id"`
+	Title           string       `db:"title"`
+	Category        string       `db:"category"`
+	Level           string       `db:"level"`
+	PrimaryLanguage string       `db:"primary_language"`
+	Subtitle        string       `db:"subtitle"`
+	Description     string       `db:"description"`
+	Image           string       `db:"image"`
+	WelcomeMessage  string       `db:"welcome_message"`
+	Pricing         float64      `db:"pricing"`
+	Objectives      string       `db:"objectives"`
+	Status          string       `db:"status"`
+	PublishAt       sql.NullTime `db:"publish_at"`
+	SubmittedAt     sql.NullTime `db:"submitted_at"`
+	IsPublished     bool         `db:"is_published"`
+	CreatedAt       time.Time    `db:"created_at"`
 }
 
 func toDBCourse(bus coursebus.Course) course {
 	return course{
 		ID:              bus.ID,
+		TenantID:        bus.TenantID,
 		InstructorID:    bus.InstructorID,
 		Title:           bus.Title,
 		Category:        bus.Category,
@@ -40,6 +47,9 @@ func toDBCourse(bus coursebus.Course) course {
 		WelcomeMessage:  bus.WelcomeMessage,
 		Pricing:         bus.Pricing.Value(),
 		Objectives:      bus.Objectives,
+		Status:          bus.Status.String(),
+		PublishAt:       toNullTime(bus.PublishAt),
+		SubmittedAt:     toNullTime(bus.SubmittedAt),
 		IsPublished:     bus.IsPublished,
 		CreatedAt:       bus.CreatedAt.UTC(),
 	}
@@ -52,9 +62,15 @@ func toBusCourse(db course) (coursebus.Course, error) {
 		return coursebus.Course{}, fmt.Errorf("parse cost: %w", err)
 	}
 
+	status, err := coursebus.ParseStatus(db.Status)
+	if err != nil {
+		return coursebus.Course{}, fmt.Errorf("parse status: %w", err)
+	}
+
 	bus := coursebus.Course{
 		ID:              db.ID,
-		InstructorID:    db.ID,
+		TenantID:        db.TenantID,
+		InstructorID:    db.InstructorID,
 		Title:           db.Title,
 		Category:        db.Category,
 		Level:           db.Level,
@@ -65,6 +81,9 @@ func toBusCourse(db course) (coursebus.Course, error) {
 		WelcomeMessage:  db.WelcomeMessage,
 		Pricing:         price,
 		Objectives:      db.Objectives,
+		Status:          status,
+		PublishAt:       fromNullTime(db.PublishAt),
+		SubmittedAt:     fromNullTime(db.SubmittedAt),
 		IsPublished:     db.IsPublished,
 		CreatedAt:       db.CreatedAt.In(time.Local),
 	}
@@ -89,22 +108,31 @@ func toBusCourses(dbs []course) ([]coursebus.Course, error) {
 //=============================================================================================================================
 
 type lecture struct {
-	ID          uuid.UUID
-	CourseID    uuid.UUID
-	Title       string
-	VideoURL    string
-	PublicID    string
-	FreePreview bool
+	ID          uuid.UUID     `db:"lecture_id"`
+	CourseID    uuid.UUID     `db:"course_id"`
+	SectionID   uuid.NullUUID `db:"section_id"`
+	Title       string        `db:"title"`
+	VideoURL    string        `db:"video_url"`
+	PublicID    string        `db:"public_id"`
+	FreePreview bool          `db:"free_preview"`
+	Position    int           `db:"position"`
 }
 
 func toDBLecture(bus coursebus.Lecture) lecture {
+	var sectionID uuid.NullUUID
+	if bus.SectionID != nil {
+		sectionID = uuid.NullUUID{UUID: *bus.SectionID, Valid: true}
+	}
+
 	return lecture{
 		ID:          bus.ID,
 		CourseID:    bus.CourseID,
+		SectionID:   sectionID,
 		Title:       bus.Title,
 		VideoURL:    bus.VideoURL,
 		PublicID:    bus.PublicID,
 		FreePreview: bus.FreePreview,
+		Position:    bus.Position,
 	}
 }
 
@@ -117,11 +145,62 @@ func toBusLecture(db lecture) (coursebus.Lecture, error) {
 		VideoURL:    db.VideoURL,
 		PublicID:    db.PublicID,
 		FreePreview: db.FreePreview,
+		Position:    db.Position,
+	}
+
+	if db.SectionID.Valid {
+		sectionID := db.SectionID.UUID
+		bus.SectionID = &sectionID
 	}
 
 	return bus, nil
 }
 
+//=============================================================================================================================
+
+type section struct {
+	ID          uuid.UUID      `db:"section_id"`
+	CourseID    uuid.UUID      `db:"course_id"`
+	Title       string         `db:"title"`
+	Description sql.NullString `db:"description"`
+	Position    int            `db:"position"`
+	CreatedAt   time.Time      `db:"created_at"`
+}
+
+func toDBSection(bus coursebus.Section) section {
+	return section{
+		ID:       bus.ID,
+		CourseID: bus.CourseID,
+		Title:    bus.Title,
+		Description: sql.NullString{
+			String: bus.Description,
+			Valid:  bus.Description != "",
+		},
+		Position:  bus.Position,
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+}
+
+func toBusSection(db section) coursebus.Section {
+	return coursebus.Section{
+		ID:          db.ID,
+		CourseID:    db.CourseID,
+		Title:       db.Title,
+		Description: db.Description.String,
+		Position:    db.Position,
+		CreatedAt:   db.CreatedAt.In(time.Local),
+	}
+}
+
+func toBusSections(dbs []section) []coursebus.Section {
+	bus := make([]coursebus.Section, len(dbs))
+	for i, db := range dbs {
+		bus[i] = toBusSection(db)
+	}
+
+	return bus
+}
+
 func toBusLectures(dbs []lecture) ([]coursebus.Lecture, error) {
 
 	bus := make([]coursebus.Lecture, len(dbs))
@@ -138,11 +217,11 @@ func toBusLectures(dbs []lecture) ([]coursebus.Lecture, error) {
 }
 
 type student struct {
-	ID         uuid.UUID
-	StudentID  uuid.UUID
-	CourseID   uuid.UUID
-	PaidAmount float64
-	EnrolledAt time.Time
+	ID         uuid.UUID `db:"enrollment_id"`
+	StudentID  uuid.UUID `db:"student_id"`
+	CourseID   uuid.UUID `db:"course_id"`
+	PaidAmount float64   `db:"paid_amount"`
+	EnrolledAt time.Time `db:"enrolled_at"`
 }
 
 func toDBStudent(bus coursebus.Student) student {
@@ -208,3 +287,339 @@ func toBusCourseProgress(dbcp courseProgress) coursebus.CourseProgress {
 		CompletionDate: dbcp.CompletionDate,
 	}
 }
+
+//==========================================================================================================
+
+type completion struct {
+	EnrollmentID   uuid.UUID    `db:"enrollment_id"`
+	StudentID      uuid.UUID    `db:"student_id"`
+	StudentName    string       `db:"user_name"`
+	StudentEmail   string       `db:"user_email"`
+	CourseID       uuid.UUID    `db:"course_id"`
+	CourseTitle    string       `db:"title"`
+	EnrolledAt     time.Time    `db:"enrolled_at"`
+	Completed      bool         `db:"completed"`
+	CompletionDate sql.NullTime `db:"completion_date"`
+}
+
+func toBusCompletion(db completion) coursebus.Completion {
+	bus := coursebus.Completion{
+		EnrollmentID: db.EnrollmentID,
+		StudentID:    db.StudentID,
+		StudentName:  db.StudentName,
+		StudentEmail: db.StudentEmail,
+		CourseID:     db.CourseID,
+		CourseTitle:  db.CourseTitle,
+		EnrolledAt:   db.EnrolledAt.In(time.Local),
+		Completed:    db.Completed,
+	}
+
+	if db.CompletionDate.Valid {
+		bus.CompletionDate = db.CompletionDate.Time.In(time.Local)
+	}
+
+	return bus
+}
+
+func toBusCompletions(dbs []completion) []coursebus.Completion {
+	bus := make([]coursebus.Completion, len(dbs))
+	for i, db := range dbs {
+		bus[i] = toBusCompletion(db)
+	}
+
+	return bus
+}
+
+//=============================================================================================================================
+
+type review struct {
+	ID         uuid.UUID     `db:"review_id"`
+	CourseID   uuid.UUID     `db:"course_id"`
+	ReviewerID uuid.NullUUID `db:"reviewer_id"`
+	Decision   string        `db:"decision"`
+	Summary    string        `db:"summary"`
+	CreatedAt  time.Time     `db:"created_at"`
+}
+
+func toDBReview(bus coursebus.Review) review {
+	return review{
+		ID:         bus.ID,
+		CourseID:   bus.CourseID,
+		ReviewerID: uuid.NullUUID{UUID: bus.ReviewerID, Valid: bus.ReviewerID != uuid.Nil},
+		Decision:   bus.Decision.String(),
+		Summary:    bus.Summary,
+		CreatedAt:  bus.CreatedAt.UTC(),
+	}
+}
+
+func toBusReview(db review) (coursebus.Review, error) {
+	decision, err := coursebus.ParseDecision(db.Decision)
+	if err != nil {
+		return coursebus.Review{}, fmt.Errorf("parse decision: %w", err)
+	}
+
+	bus := coursebus.Review{
+		ID:         db.ID,
+		CourseID:   db.CourseID,
+		ReviewerID: db.ReviewerID.UUID,
+		Decision:   decision,
+		Summary:    db.Summary,
+		CreatedAt:  db.CreatedAt.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusReviews(dbs []review) ([]coursebus.Review, error) {
+	bus := make([]coursebus.Review, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusReview(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+type reviewComment struct {
+	ID           uuid.UUID     `db:"comment_id"`
+	ReviewID     uuid.UUID     `db:"review_id"`
+	LectureID    uuid.NullUUID `db:"lecture_id"`
+	LectureTitle string        `db:"lecture_title"`
+	Body         string        `db:"body"`
+	Position     int           `db:"position"`
+}
+
+func toDBReviewComment(bus coursebus.ReviewComment) reviewComment {
+	var lectureID uuid.NullUUID
+	if bus.LectureID != nil {
+		lectureID = uuid.NullUUID{UUID: *bus.LectureID, Valid: true}
+	}
+
+	return reviewComment{
+		ID:           bus.ID,
+		ReviewID:     bus.ReviewID,
+		LectureID:    lectureID,
+		LectureTitle: bus.LectureTitle,
+		Body:         bus.Body,
+		Position:     bus.Position,
+	}
+}
+
+func toBusReviewComments(dbs []reviewComment) []coursebus.ReviewComment {
+	bus := make([]coursebus.ReviewComment, len(dbs))
+	for i, db := range dbs {
+		bus[i] = coursebus.ReviewComment{
+			ID:           db.ID,
+			ReviewID:     db.ReviewID,
+			LectureTitle: db.LectureTitle,
+			Body:         db.Body,
+			Position:     db.Position,
+		}
+
+		if db.LectureID.Valid {
+			lectureID := db.LectureID.UUID
+			bus[i].LectureID = &lectureID
+		}
+	}
+
+	return bus
+}
+
+//=============================================================================================================================
+
+type revision struct {
+	ID           uuid.UUID     `db:"revision_id"`
+	CourseID     uuid.UUID     `db:"course_id"`
+	Number       int           `db:"number"`
+	AuthorID     uuid.NullUUID `db:"author_id"`
+	RestoredFrom sql.NullInt64 `db:"restored_from"`
+	Snapshot     string        `db:"snapshot"`
+	CreatedAt    time.Time     `db:"created_at"`
+}
+
+// snapshot is the document stored for the content of a revision.
+type snapshot struct {
+	Title           string            `json:"title"`
+	Category        string            `json:"category"`
+	Level           string            `json:"level"`
+	PrimaryLanguage string            `json:"primary_language"`
+	Subtitle        string            `json:"subtitle"`
+	Description     string            `json:"description"`
+	Image           string            `json:"image"`
+	WelcomeMessage  string            `json:"welcome_message"`
+	Pricing         float64           `json:"pricing"`
+	Objectives      string            `json:"objectives"`
+	Sections        []snapshotSection `json:"sections"`
+	Curriculum      []snapshotLecture `json:"curriculum"`
+}
+
+type snapshotSection struct {
+	ID          uuid.UUID `json:"section_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Position    int       `json:"position"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type snapshotLecture struct {
+	ID          uuid.UUID  `json:"lecture_id"`
+	SectionID   *uuid.UUID `json:"section_id,omitempty"`
+	Title       string     `json:"title"`
+	VideoURL    string     `json:"video_url"`
+	PublicID    string     `json:"public_id"`
+	FreePreview bool       `json:"free_preview"`
+	Position    int        `json:"position"`
+}
+
+func toDBRevision(bus coursebus.Revision) (revision, error) {
+	snap := snapshot{
+		Title:           bus.Snapshot.Title,
+		Category:        bus.Snapshot.Category,
+		Level:           bus.Snapshot.Level,
+		PrimaryLanguage: bus.Snapshot.PrimaryLanguage,
+		Subtitle:        bus.Snapshot.Subtitle,
+		Description:     bus.Snapshot.Description,
+		Image:           bus.Snapshot.Image,
+		WelcomeMessage:  bus.Snapshot.WelcomeMessage,
+		Pricing:         bus.Snapshot.Pricing.Value(),
+		Objectives:      bus.Snapshot.Objectives,
+		Sections:        make([]snapshotSection, len(bus.Snapshot.Sections)),
+		Curriculum:      make([]snapshotLecture, len(bus.Snapshot.Curriculum)),
+	}
+
+	for i, sec := range bus.Snapshot.Sections {
+		snap.Sections[i] = snapshotSection{
+			ID:          sec.ID,
+			Title:       sec.Title,
+			Description: sec.Description,
+			Position:    sec.Position,
+			CreatedAt:   sec.CreatedAt.UTC(),
+		}
+	}
+
+	for i, lec := range bus.Snapshot.Curriculum {
+		snap.Curriculum[i] = snapshotLecture{
+			ID:          lec.ID,
+			SectionID:   lec.SectionID,
+			Title:       lec.Title,
+			VideoURL:    lec.VideoURL,
+			PublicID:    lec.PublicID,
+			FreePreview: lec.FreePreview,
+			Position:    lec.Position,
+		}
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return revision{}, fmt.Errorf("marshal snapshot: %w", err)
+	}
+
+	db := revision{
+		ID:           bus.ID,
+		CourseID:     bus.CourseID,
+		Number:       bus.Number,
+		AuthorID:     uuid.NullUUID{UUID: bus.AuthorID, Valid: bus.AuthorID != uuid.Nil},
+		RestoredFrom: sql.NullInt64{Int64: int64(bus.RestoredFrom), Valid: bus.RestoredFrom != 0},
+		Snapshot:     string(data),
+		CreatedAt:    bus.CreatedAt.UTC(),
+	}
+
+	return db, nil
+}
+
+func toBusRevision(db revision) (coursebus.Revision, error) {
+	var snap snapshot
+	if err := json.Unmarshal([]byte(db.Snapshot), &snap); err != nil {
+		return coursebus.Revision{}, fmt.Errorf("unmarshal snapshot: %w", err)
+	}
+
+	price, err := money.Parse(snap.Pricing)
+	if err != nil {
+		return coursebus.Revision{}, fmt.Errorf("parse cost: %w", err)
+	}
+
+	bus := coursebus.Revision{
+		ID:           db.ID,
+		CourseID:     db.CourseID,
+		Number:       db.Number,
+		AuthorID:     db.AuthorID.UUID,
+		RestoredFrom: int(db.RestoredFrom.Int64),
+		Snapshot: coursebus.Snapshot{
+			Title:           snap.Title,
+			Category:        snap.Category,
+			Level:           snap.Level,
+			PrimaryLanguage: snap.PrimaryLanguage,
+			Subtitle:        snap.Subtitle,
+			Description:     snap.Description,
+			Image:           snap.Image,
+			WelcomeMessage:  snap.WelcomeMessage,
+			Pricing:         price,
+			Objectives:      snap.Objectives,
+			Sections:        make([]coursebus.Section, len(snap.Sections)),
+			Curriculum:      make([]coursebus.Lecture, len(snap.Curriculum)),
+		},
+		CreatedAt: db.CreatedAt.In(time.Local),
+	}
+
+	for i, sec := range snap.Sections {
+		bus.Snapshot.Sections[i] = coursebus.Section{
+			ID:          sec.ID,
+			CourseID:    db.CourseID,
+			Title:       sec.Title,
+			Description: sec.Description,
+			Position:    sec.Position,
+			CreatedAt:   sec.CreatedAt.In(time.Local),
+		}
+	}
+
+	for i, lec := range snap.Curriculum {
+		bus.Snapshot.Curriculum[i] = coursebus.Lecture{
+			ID:          lec.ID,
+			CourseID:    db.CourseID,
+			SectionID:   lec.SectionID,
+			Title:       lec.Title,
+			VideoURL:    lec.VideoURL,
+			PublicID:    lec.PublicID,
+			FreePreview: lec.FreePreview,
+			Position:    lec.Position,
+		}
+	}
+
+	return bus, nil
+}
+
+func toBusRevisions(dbs []revision) ([]coursebus.Revision, error) {
+	bus := make([]coursebus.Revision, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusRevision(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+func toNullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func fromNullTime(nt sql.NullTime) time.Time {
+	if !nt.Valid {
+		return time.Time{}
+	}
+
+	return nt.Time.In(time.Local)
+}
@@ -0,0 +1,10 @@
+package instructorbus
+
+import "github.com/google/uuid"
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	ID     *uuid.UUID
+	UserID *uuid.UUID
+	Status *Status
+}
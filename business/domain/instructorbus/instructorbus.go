@@ -0,0 +1,176 @@
+// Package instructorbus provides business access to the instructor
+// onboarding domain.
+package instructorbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/types/role"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound           = errors.New("application not found")
+	ErrPendingApplication = errors.New("user already has a pending application")
+	ErrAlreadyInstructor  = errors.New("user is already an instructor")
+	ErrAlreadyReviewed    = errors.New("application has already been reviewed")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, app Application) error
+	Update(ctx context.Context, app Application) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Application, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, applicationID uuid.UUID) (Application, error)
+}
+
+// Business manages the set of APIs for instructor application access.
+type Business struct {
+	log     *logger.Logger
+	userBus *userbus.Business
+	storer  Storer
+}
+
+// NewBusiness constructs an instructor business API for use.
+func NewBusiness(log *logger.Logger, userBus *userbus.Business, storer Storer) *Business {
+	return &Business{
+		log:     log,
+		userBus: userBus,
+		storer:  storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	userBus, err := b.userBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:     b.log,
+		userBus: userBus,
+		storer:  storer,
+	}
+
+	return &bus, nil
+}
+
+// Create submits a new application for the user to become an instructor.
+// A user can only have one pending application at a time.
+func (b *Business) Create(ctx context.Context, na NewApplication) (Application, error) {
+	usr, err := b.userBus.QueryByID(ctx, na.UserID)
+	if err != nil {
+		return Application{}, fmt.Errorf("user.querybyid: %s: %w", na.UserID, err)
+	}
+
+	if usr.HasRole(role.Instructor) {
+		return Application{}, fmt.Errorf("userID[%s]: %w", usr.ID, ErrAlreadyInstructor)
+	}
+
+	app := Application{
+		ID:        uuid.New(),
+		UserID:    usr.ID,
+		Bio:       na.Bio,
+		Expertise: na.Expertise,
+		Links:     na.Links,
+		Status:    StatusPending,
+		CreatedAt: time.Now(),
+	}
+
+	if err := b.storer.Create(ctx, app); err != nil {
+		return Application{}, fmt.Errorf("create: %w", err)
+	}
+
+	return app, nil
+}
+
+// Approve accepts the application and grants the applicant the instructor
+// role.
+func (b *Business) Approve(ctx context.Context, app Application, rv Review) (Application, error) {
+	if !app.Status.Equal(StatusPending) {
+		return Application{}, fmt.Errorf("applicationID[%s]: %w", app.ID, ErrAlreadyReviewed)
+	}
+
+	usr, err := b.userBus.QueryByID(ctx, app.UserID)
+	if err != nil {
+		return Application{}, fmt.Errorf("user.querybyid: %s: %w", app.UserID, err)
+	}
+
+	if !usr.HasRole(role.Instructor) {
+		roles := append(usr.Roles, role.Instructor)
+
+		if _, err := b.userBus.Update(ctx, usr, userbus.UpdateUser{Roles: roles}); err != nil {
+			return Application{}, fmt.Errorf("user.update: %s: %w", usr.ID, err)
+		}
+	}
+
+	return b.review(ctx, app, StatusApproved, rv)
+}
+
+// Reject declines the application.
+func (b *Business) Reject(ctx context.Context, app Application, rv Review) (Application, error) {
+	if !app.Status.Equal(StatusPending) {
+		return Application{}, fmt.Errorf("applicationID[%s]: %w", app.ID, ErrAlreadyReviewed)
+	}
+
+	return b.review(ctx, app, StatusRejected, rv)
+}
+
+// Query retrieves a list of existing applications.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Application, error) {
+	apps, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return apps, nil
+}
+
+// Count returns the total number of applications.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByID finds the application by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, applicationID uuid.UUID) (Application, error) {
+	app, err := b.storer.QueryByID(ctx, applicationID)
+	if err != nil {
+		return Application{}, fmt.Errorf("query: applicationID[%s]: %w", applicationID, err)
+	}
+
+	return app, nil
+}
+
+// =============================================================================
+
+func (b *Business) review(ctx context.Context, app Application, status Status, rv Review) (Application, error) {
+	app.Status = status
+	app.ReviewerID = rv.ReviewerID
+	app.ReviewNote = rv.Note
+	app.ReviewedAt = time.Now()
+
+	if err := b.storer.Update(ctx, app); err != nil {
+		return Application{}, fmt.Errorf("update: %w", err)
+	}
+
+	return app, nil
+}
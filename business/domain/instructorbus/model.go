@@ -0,0 +1,35 @@
+package instructorbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Application represents a request from a user to become an instructor.
+type Application struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Bio        string
+	Expertise  []string
+	Links      []string
+	Status     Status
+	ReviewerID uuid.UUID
+	ReviewNote string
+	ReviewedAt time.Time
+	CreatedAt  time.Time
+}
+
+// NewApplication is what we require from users when applying.
+type NewApplication struct {
+	UserID    uuid.UUID
+	Bio       string
+	Expertise []string
+	Links     []string
+}
+
+// Review contains the decision an admin makes about an application.
+type Review struct {
+	ReviewerID uuid.UUID
+	Note       string
+}
@@ -0,0 +1,15 @@
+package instructorbus
+
+import "github.com/kamogelosekhukhune777/lms/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort. The oldest applications
+// are reviewed first.
+var DefaultOrderBy = order.NewBy(OrderByCreatedAt, order.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID        = "application_id"
+	OrderByUserID    = "user_id"
+	OrderByStatus    = "status"
+	OrderByCreatedAt = "created_at"
+)
@@ -0,0 +1,33 @@
+package instructordb
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+)
+
+func (s *Store) applyFilter(ctx context.Context, filter instructorbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	data["tenant_id"] = tenant.Get(ctx)
+	wc := []string{"user_id IN (SELECT user_id FROM Users WHERE tenant_id = :tenant_id)"}
+
+	if filter.ID != nil {
+		data["application_id"] = *filter.ID
+		wc = append(wc, "application_id = :application_id")
+	}
+
+	if filter.UserID != nil {
+		data["user_id"] = *filter.UserID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.Status != nil {
+		data["status"] = filter.Status.String()
+		wc = append(wc, "status = :status")
+	}
+
+	buf.WriteString(" WHERE ")
+	buf.WriteString(strings.Join(wc, " AND "))
+}
@@ -0,0 +1,183 @@
+// Package instructordb contains instructor application related CRUD
+// functionality.
+package instructordb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Store manages the set of APIs for instructor application database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (instructorbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new application into the database. Only one pending
+// application per user is allowed by a unique index.
+func (s *Store) Create(ctx context.Context, app instructorbus.Application) error {
+	const q = `
+	INSERT INTO InstructorApplications
+		(application_id, user_id, bio, expertise, links, status, reviewer_id, review_note, reviewed_at, created_at)
+	VALUES
+		(:application_id, :user_id, :bio, :expertise, :links, :status, :reviewer_id, :review_note, :reviewed_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBApplication(app)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", instructorbus.ErrPendingApplication)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update records the review of a pending application. The update only
+// succeeds while the application is still pending so an application can
+// only be reviewed once.
+func (s *Store) Update(ctx context.Context, app instructorbus.Application) error {
+	const q = `
+	UPDATE
+		InstructorApplications
+	SET
+		status = :status,
+		reviewer_id = :reviewer_id,
+		review_note = :review_note,
+		reviewed_at = :reviewed_at
+	WHERE
+		application_id = :application_id AND status = 'PENDING'
+	RETURNING
+		application_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"application_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBApplication(app), &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", instructorbus.ErrAlreadyReviewed)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing applications from the database.
+func (s *Store) Query(ctx context.Context, filter instructorbus.QueryFilter, orderBy order.By, page page.Page) ([]instructorbus.Application, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		application_id, user_id, bio, expertise, links, status, reviewer_id, review_note, reviewed_at, created_at
+	FROM
+		InstructorApplications`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(ctx, filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbApps []application
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbApps); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusApplications(dbApps)
+}
+
+// Count returns the total number of applications in the DB.
+func (s *Store) Count(ctx context.Context, filter instructorbus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		InstructorApplications`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(ctx, filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified application from the database.
+func (s *Store) QueryByID(ctx context.Context, applicationID uuid.UUID) (instructorbus.Application, error) {
+	data := struct {
+		ID       string `db:"application_id"`
+		TenantID string `db:"tenant_id"`
+	}{
+		ID:       applicationID.String(),
+		TenantID: tenant.Get(ctx).String(),
+	}
+
+	const q = `
+	SELECT
+		application_id, user_id, bio, expertise, links, status, reviewer_id, review_note, reviewed_at, created_at
+	FROM
+		InstructorApplications
+	WHERE
+		application_id = :application_id AND
+		user_id IN (SELECT user_id FROM Users WHERE tenant_id = :tenant_id)`
+
+	var dbApp application
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbApp); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return instructorbus.Application{}, fmt.Errorf("db: %w", instructorbus.ErrNotFound)
+		}
+		return instructorbus.Application{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusApplication(dbApp)
+}
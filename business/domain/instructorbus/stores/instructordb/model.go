@@ -0,0 +1,94 @@
+package instructordb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb/dbarray"
+)
+
+type application struct {
+	ID         uuid.UUID      `db:"application_id"`
+	UserID     uuid.UUID      `db:"user_id"`
+	Bio        string         `db:"bio"`
+	Expertise  dbarray.String `db:"expertise"`
+	Links      dbarray.String `db:"links"`
+	Status     string         `db:"status"`
+	ReviewerID uuid.NullUUID  `db:"reviewer_id"`
+	ReviewNote string         `db:"review_note"`
+	ReviewedAt sql.NullTime   `db:"reviewed_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func toDBApplication(bus instructorbus.Application) application {
+	db := application{
+		ID:         bus.ID,
+		UserID:     bus.UserID,
+		Bio:        bus.Bio,
+		Expertise:  dbarray.String{},
+		Links:      dbarray.String{},
+		Status:     bus.Status.String(),
+		ReviewNote: bus.ReviewNote,
+		CreatedAt:  bus.CreatedAt.UTC(),
+	}
+
+	if bus.Expertise != nil {
+		db.Expertise = bus.Expertise
+	}
+
+	if bus.Links != nil {
+		db.Links = bus.Links
+	}
+
+	if bus.ReviewerID != uuid.Nil {
+		db.ReviewerID = uuid.NullUUID{UUID: bus.ReviewerID, Valid: true}
+	}
+
+	if !bus.ReviewedAt.IsZero() {
+		db.ReviewedAt = sql.NullTime{Time: bus.ReviewedAt.UTC(), Valid: true}
+	}
+
+	return db
+}
+
+func toBusApplication(db application) (instructorbus.Application, error) {
+	status, err := instructorbus.ParseStatus(db.Status)
+	if err != nil {
+		return instructorbus.Application{}, fmt.Errorf("parse status: %w", err)
+	}
+
+	bus := instructorbus.Application{
+		ID:         db.ID,
+		UserID:     db.UserID,
+		Bio:        db.Bio,
+		Expertise:  db.Expertise,
+		Links:      db.Links,
+		Status:     status,
+		ReviewerID: db.ReviewerID.UUID,
+		ReviewNote: db.ReviewNote,
+		CreatedAt:  db.CreatedAt.In(time.Local),
+	}
+
+	if db.ReviewedAt.Valid {
+		bus.ReviewedAt = db.ReviewedAt.Time.In(time.Local)
+	}
+
+	return bus, nil
+}
+
+func toBusApplications(dbs []application) ([]instructorbus.Application, error) {
+	bus := make([]instructorbus.Application, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusApplication(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
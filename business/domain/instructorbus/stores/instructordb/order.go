@@ -0,0 +1,24 @@
+package instructordb
+
+import (
+	"fmt"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/instructorbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	instructorbus.OrderByID:        "application_id",
+	instructorbus.OrderByUserID:    "user_id",
+	instructorbus.OrderByStatus:    "status",
+	instructorbus.OrderByCreatedAt: "created_at",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
@@ -0,0 +1,346 @@
+// Package loginbus provides throttling of login attempts on top of user
+// authentication.
+package loginbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/webauthn"
+)
+
+// ErrTooManyAttempts is returned when an account or IP has to wait before
+// another login attempt is accepted. It is returned for unknown accounts as
+// well so it does not reveal whether an account exists.
+var ErrTooManyAttempts = errors.New("too many login attempts")
+
+// Set of actions recorded in the audit log.
+const (
+	ActionLockout   = "login.lockout"
+	ActionIPBlocked = "login.ip_blocked"
+	ActionUnlock    = "login.unlock"
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, att Attempt) error
+	QueryAccountFailures(ctx context.Context, email string, factor string, since time.Time) (Failures, error)
+	QueryIPFailures(ctx context.Context, ip string, since time.Time) (Failures, error)
+	DeleteAccountFailures(ctx context.Context, email string) error
+}
+
+// Business manages the set of APIs for login access.
+type Business struct {
+	log      *logger.Logger
+	userBus  *userbus.Business
+	auditBus *auditbus.Business
+	storer   Storer
+	policy   Policy
+}
+
+// NewBusiness constructs a login business API for use.
+func NewBusiness(log *logger.Logger, userBus *userbus.Business, auditBus *auditbus.Business, storer Storer, policy Policy) *Business {
+	return &Business{
+		log:      log,
+		userBus:  userBus,
+		auditBus: auditBus,
+		storer:   storer,
+		policy:   policy,
+	}
+}
+
+// Authenticate verifies the email and password like userbus.Authenticate,
+// refusing the attempt while the account or IP is throttled. Unknown emails
+// fail with userbus.ErrAuthenticationFailure, the same as a wrong password.
+// Accounts are throttled within the organization of the request, the same
+// email in another organization is a different account.
+func (b *Business) Authenticate(ctx context.Context, email mail.Address, password string, ip string) (userbus.User, error) {
+	key := strings.ToLower(email.Address)
+	now := time.Now()
+
+	acct, ipFails, err := b.checkThrottle(ctx, key, FactorPrimary, ip, now)
+	if err != nil {
+		return userbus.User{}, err
+	}
+
+	usr, err := b.userBus.Authenticate(ctx, email, password)
+	if err != nil {
+		if !errors.Is(err, userbus.ErrNotFound) && !errors.Is(err, userbus.ErrAuthenticationFailure) {
+			return userbus.User{}, err
+		}
+
+		// The account is only looked up when it is about to be locked, so
+		// the lockout can be audited against it.
+		var userID uuid.UUID
+		if b.locksOut(acct) {
+			if usr, err := b.userBus.QueryByEmail(ctx, email); err == nil {
+				userID = usr.ID
+			}
+		}
+
+		if err := b.recordFailure(ctx, userID, key, FactorPrimary, ip, acct, ipFails, now); err != nil {
+			return userbus.User{}, err
+		}
+
+		return userbus.User{}, fmt.Errorf("authenticate: %w", userbus.ErrAuthenticationFailure)
+	}
+
+	if err := b.recordSuccess(ctx, key, FactorPrimary, ip, now); err != nil {
+		return userbus.User{}, err
+	}
+
+	return usr, nil
+}
+
+// AuthenticatePasskey completes a passwordless login with a passkey like
+// userbus.AuthenticatePasskey, refusing the attempt while the IP is throttled
+// or the account the passkey belongs to is locked. Failed assertions count
+// towards the IP limit since the account isn't known.
+func (b *Business) AuthenticatePasskey(ctx context.Context, rp webauthn.RelyingParty, challengeID uuid.UUID, pa userbus.PasskeyAssertion, ip string) (userbus.User, error) {
+	now := time.Now()
+
+	ipFails, err := b.checkIPThrottle(ctx, ip, now)
+	if err != nil {
+		return userbus.User{}, err
+	}
+
+	usr, err := b.userBus.AuthenticatePasskey(ctx, rp, challengeID, pa)
+	if err != nil {
+		if !errors.Is(err, userbus.ErrAuthenticationFailure) {
+			return userbus.User{}, err
+		}
+
+		if err := b.recordFailure(ctx, uuid.Nil, "", FactorPrimary, ip, Failures{}, ipFails, now); err != nil {
+			return userbus.User{}, err
+		}
+
+		return userbus.User{}, fmt.Errorf("authenticatepasskey: %w", userbus.ErrAuthenticationFailure)
+	}
+
+	ctx = tenant.Set(ctx, usr.TenantID)
+	key := strings.ToLower(usr.UserEmail.Address)
+
+	if _, err := b.checkAccountThrottle(ctx, key, FactorPrimary, now); err != nil {
+		return userbus.User{}, err
+	}
+
+	if err := b.recordSuccess(ctx, key, FactorPrimary, ip, now); err != nil {
+		return userbus.User{}, err
+	}
+
+	return usr, nil
+}
+
+// VerifySecondFactor checks the TOTP or recovery code of a user who passed
+// the password step of a login. Wrong codes are counted separately from wrong
+// passwords and only a verified second factor clears them.
+func (b *Business) VerifySecondFactor(ctx context.Context, usr userbus.User, code string, ip string) error {
+	verify := func() error {
+		return b.userBus.VerifySecondFactor(ctx, usr.ID, code)
+	}
+
+	return b.verifySecondFactor(ctx, usr, ip, verify, userbus.ErrInvalidCode)
+}
+
+// VerifyPasskey checks the passkey assertion of a user who passed the
+// password step of a login. Failed assertions count towards the same limits
+// as wrong codes.
+func (b *Business) VerifyPasskey(ctx context.Context, rp webauthn.RelyingParty, usr userbus.User, challengeID uuid.UUID, pa userbus.PasskeyAssertion, ip string) error {
+	verify := func() error {
+		return b.userBus.VerifyPasskey(ctx, rp, usr.ID, challengeID, pa)
+	}
+
+	return b.verifySecondFactor(ctx, usr, ip, verify, userbus.ErrAuthenticationFailure)
+}
+
+// Unlock clears the failed attempts recorded for the account so the user can
+// log in again straight away. Accounts with the same email in other
+// organizations are left alone.
+func (b *Business) Unlock(ctx context.Context, usr userbus.User, actorID uuid.UUID, ip string) error {
+	ctx = tenant.Set(ctx, usr.TenantID)
+	key := strings.ToLower(usr.UserEmail.Address)
+
+	if err := b.storer.DeleteAccountFailures(ctx, key); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	na := auditbus.NewAudit{
+		ActorID: actorID,
+		Action:  ActionUnlock,
+		Subject: usr.ID.String(),
+		IP:      ip,
+	}
+
+	if _, err := b.auditBus.Create(ctx, na); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// verifySecondFactor runs the second factor check of a login under the
+// throttling of the second factor. The check fails with the failure error
+// when the user presented a wrong second factor.
+func (b *Business) verifySecondFactor(ctx context.Context, usr userbus.User, ip string, verify func() error, failure error) error {
+	ctx = tenant.Set(ctx, usr.TenantID)
+	key := strings.ToLower(usr.UserEmail.Address)
+	now := time.Now()
+
+	acct, ipFails, err := b.checkThrottle(ctx, key, FactorSecond, ip, now)
+	if err != nil {
+		return err
+	}
+
+	if err := verify(); err != nil {
+		if !errors.Is(err, failure) {
+			return err
+		}
+
+		if err := b.recordFailure(ctx, usr.ID, key, FactorSecond, ip, acct, ipFails, now); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("verify: %w", failure)
+	}
+
+	return b.recordSuccess(ctx, key, FactorSecond, ip, now)
+}
+
+// checkThrottle returns ErrTooManyAttempts while the account or IP has to
+// wait, otherwise the failures recorded for both.
+func (b *Business) checkThrottle(ctx context.Context, key string, factor string, ip string, now time.Time) (Failures, Failures, error) {
+	acct, err := b.checkAccountThrottle(ctx, key, factor, now)
+	if err != nil {
+		return Failures{}, Failures{}, err
+	}
+
+	ipFails, err := b.checkIPThrottle(ctx, ip, now)
+	if err != nil {
+		return Failures{}, Failures{}, err
+	}
+
+	return acct, ipFails, nil
+}
+
+// checkAccountThrottle returns ErrTooManyAttempts while the account has to
+// wait, otherwise the failures recorded against the factor.
+func (b *Business) checkAccountThrottle(ctx context.Context, key string, factor string, now time.Time) (Failures, error) {
+	acct, err := b.storer.QueryAccountFailures(ctx, key, factor, now.Add(-b.policy.LockoutWindow))
+	if err != nil {
+		return Failures{}, fmt.Errorf("query account failures: %w", err)
+	}
+
+	if wait, _ := b.policy.retryAfter(acct, now); wait > 0 {
+		return Failures{}, fmt.Errorf("account throttled for %s: %w", wait.Round(time.Second), ErrTooManyAttempts)
+	}
+
+	return acct, nil
+}
+
+// checkIPThrottle returns ErrTooManyAttempts while the IP is blocked,
+// otherwise the failures recorded for it.
+func (b *Business) checkIPThrottle(ctx context.Context, ip string, now time.Time) (Failures, error) {
+	if ip == "" || b.policy.IPLimit <= 0 {
+		return Failures{}, nil
+	}
+
+	ipFails, err := b.storer.QueryIPFailures(ctx, ip, now.Add(-b.policy.LockoutWindow))
+	if err != nil {
+		return Failures{}, fmt.Errorf("query ip failures: %w", err)
+	}
+
+	if ipFails.Count >= b.policy.IPLimit {
+		return Failures{}, fmt.Errorf("ip[%s] blocked: %w", ip, ErrTooManyAttempts)
+	}
+
+	return ipFails, nil
+}
+
+func (b *Business) recordSuccess(ctx context.Context, key string, factor string, ip string, now time.Time) error {
+	att := Attempt{
+		ID:        uuid.New(),
+		Email:     key,
+		IP:        ip,
+		Factor:    factor,
+		Succeeded: true,
+		CreatedAt: now,
+	}
+
+	if err := b.storer.Create(ctx, att); err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	return nil
+}
+
+// locksOut reports whether one more failure locks the account.
+func (b *Business) locksOut(acct Failures) bool {
+	return b.policy.LockoutAfter > 0 && acct.Count+1 == b.policy.LockoutAfter
+}
+
+// recordFailure records a failed attempt and audits the lockout of the
+// account or the block of the IP it causes. Lockouts are audited against the
+// user, so they are only audited for known accounts.
+func (b *Business) recordFailure(ctx context.Context, userID uuid.UUID, key string, factor string, ip string, acct Failures, ipFails Failures, now time.Time) error {
+	att := Attempt{
+		ID:        uuid.New(),
+		Email:     key,
+		IP:        ip,
+		Factor:    factor,
+		CreatedAt: now,
+	}
+
+	if err := b.storer.Create(ctx, att); err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	if userID != uuid.Nil && b.locksOut(acct) {
+		na := auditbus.NewAudit{
+			Action:  ActionLockout,
+			Subject: userID.String(),
+			IP:      ip,
+			Details: map[string]string{
+				"tenant_id": tenant.Get(ctx).String(),
+				"factor":    factor,
+				"failures":  strconv.Itoa(acct.Count + 1),
+				"until":     now.Add(b.policy.LockoutWindow).UTC().Format(time.RFC3339),
+			},
+		}
+
+		if _, err := b.auditBus.Create(ctx, na); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+	}
+
+	if ip != "" && b.policy.IPLimit > 0 && ipFails.Count+1 == b.policy.IPLimit {
+		na := auditbus.NewAudit{
+			Action:  ActionIPBlocked,
+			Subject: ip,
+			IP:      ip,
+			Details: map[string]string{
+				"failures": strconv.Itoa(ipFails.Count + 1),
+			},
+		}
+
+		if _, err := b.auditBus.Create(ctx, na); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+	}
+
+	return nil
+}
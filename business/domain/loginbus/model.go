@@ -0,0 +1,101 @@
+package loginbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of factors a login attempt can be made against. Failures are counted
+// per factor so a successful password can't clear failed codes.
+const (
+	FactorPrimary = "PRIMARY"
+	FactorSecond  = "SECOND"
+)
+
+// Attempt represents a single login attempt. Attempts are keyed by the
+// organization and the email address that was presented so unknown accounts
+// are throttled the same way as known ones.
+type Attempt struct {
+	ID        uuid.UUID
+	TenantID  uuid.UUID
+	Email     string
+	IP        string
+	Factor    string
+	Succeeded bool
+	CreatedAt time.Time
+}
+
+// Failures summarizes the failed attempts recorded for an account or an IP.
+type Failures struct {
+	Count int
+	Last  time.Time
+}
+
+// Policy defines how failed login attempts are throttled.
+type Policy struct {
+	// DelayAfter is the number of failures on an account before each new
+	// attempt has to wait for a progressively longer delay.
+	DelayAfter int
+
+	// BaseDelay is the first delay applied, it doubles with every failure.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the progressive delay.
+	MaxDelay time.Duration
+
+	// LockoutAfter is the number of failures on an account that locks it.
+	LockoutAfter int
+
+	// LockoutWindow is how far back failures are counted and how long a
+	// locked account stays locked after the last failure.
+	LockoutWindow time.Duration
+
+	// IPLimit is the number of failures from a single IP within the lockout
+	// window before further attempts from that IP are refused.
+	IPLimit int
+}
+
+// retryAfter returns how long the caller has to wait before the account
+// accepts another attempt, and whether the account is locked.
+func (p Policy) retryAfter(f Failures, now time.Time) (time.Duration, bool) {
+	if f.Count == 0 {
+		return 0, false
+	}
+
+	var until time.Time
+	var locked bool
+
+	switch {
+	case p.LockoutAfter > 0 && f.Count >= p.LockoutAfter:
+		until = f.Last.Add(p.LockoutWindow)
+		locked = true
+
+	case p.DelayAfter > 0 && f.Count >= p.DelayAfter:
+		until = f.Last.Add(p.delay(f.Count))
+
+	default:
+		return 0, false
+	}
+
+	if wait := until.Sub(now); wait > 0 {
+		return wait, locked
+	}
+
+	return 0, false
+}
+
+// delay returns the progressive delay for the specified number of failures.
+func (p Policy) delay(failures int) time.Duration {
+	shift := failures - p.DelayAfter
+	if shift > 30 {
+		shift = 30
+	}
+
+	d := p.BaseDelay << shift
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d <= 0) {
+		d = p.MaxDelay
+	}
+
+	return d
+}
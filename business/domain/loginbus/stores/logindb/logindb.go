@@ -0,0 +1,156 @@
+// Package logindb contains login attempt related CRUD functionality.
+package logindb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/business/domain/loginbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Store manages the set of APIs for login attempt database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (loginbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new login attempt into the database.
+func (s *Store) Create(ctx context.Context, att loginbus.Attempt) error {
+	const q = `
+	INSERT INTO LoginAttempts
+		(attempt_id, tenant_id, user_email, ip_address, factor, succeeded, created_at)
+	VALUES
+		(:attempt_id, :tenant_id, :user_email, :ip_address, :factor, :succeeded, :created_at)`
+
+	dbAtt := toDBAttempt(att)
+	dbAtt.TenantID = tenant.Get(ctx)
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, dbAtt); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryAccountFailures summarizes the failed attempts against the factor for
+// the email in the organization of the request since the specified time. Failures before the last successful
+// attempt against the same factor are ignored.
+func (s *Store) QueryAccountFailures(ctx context.Context, email string, factor string, since time.Time) (loginbus.Failures, error) {
+	data := struct {
+		TenantID string    `db:"tenant_id"`
+		Email    string    `db:"user_email"`
+		Factor   string    `db:"factor"`
+		Since    time.Time `db:"since"`
+	}{
+		TenantID: tenant.Get(ctx).String(),
+		Email:    email,
+		Factor:   factor,
+		Since:    since.UTC(),
+	}
+
+	const q = `
+	SELECT
+		count(1) AS failures, max(created_at) AS last_failure
+	FROM
+		LoginAttempts
+	WHERE
+		tenant_id = :tenant_id AND
+		user_email = :user_email AND
+		factor = :factor AND
+		succeeded = FALSE AND
+		created_at > :since AND
+		created_at > COALESCE((
+			SELECT max(created_at)
+			FROM LoginAttempts
+			WHERE tenant_id = :tenant_id AND user_email = :user_email AND factor = :factor AND succeeded = TRUE
+		), '-infinity')`
+
+	var dest failures
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		return loginbus.Failures{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toBusFailures(dest), nil
+}
+
+// QueryIPFailures summarizes the failed attempts from the IP since the
+// specified time.
+func (s *Store) QueryIPFailures(ctx context.Context, ip string, since time.Time) (loginbus.Failures, error) {
+	data := struct {
+		IP    string    `db:"ip_address"`
+		Since time.Time `db:"since"`
+	}{
+		IP:    ip,
+		Since: since.UTC(),
+	}
+
+	const q = `
+	SELECT
+		count(1) AS failures, max(created_at) AS last_failure
+	FROM
+		LoginAttempts
+	WHERE
+		ip_address = :ip_address AND
+		succeeded = FALSE AND
+		created_at > :since`
+
+	var dest failures
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		return loginbus.Failures{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toBusFailures(dest), nil
+}
+
+// DeleteAccountFailures removes the failed attempts recorded for the email
+// in the organization of the request.
+func (s *Store) DeleteAccountFailures(ctx context.Context, email string) error {
+	data := struct {
+		TenantID string `db:"tenant_id"`
+		Email    string `db:"user_email"`
+	}{
+		TenantID: tenant.Get(ctx).String(),
+		Email:    email,
+	}
+
+	const q = `
+	DELETE FROM
+		LoginAttempts
+	WHERE
+		tenant_id = :tenant_id AND user_email = :user_email AND succeeded = FALSE`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
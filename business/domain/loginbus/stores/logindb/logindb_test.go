@@ -0,0 +1,77 @@
+package logindb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/loginbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/loginbus/stores/logindb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/dbtest"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+)
+
+func Test_AccountFailuresPerTenant(t *testing.T) {
+	db := dbtest.New(t)
+	store := logindb.NewStore(db.Log, db.DB)
+
+	orgID := uuid.New()
+
+	const q = `INSERT INTO Organizations (organization_id, name, slug) VALUES ($1, 'Acme', 'acme')`
+	if _, err := db.DB.Exec(q, orgID); err != nil {
+		t.Fatalf("Should be able to seed the organization: %s", err)
+	}
+
+	ctxA := tenant.Set(context.Background(), tenant.DefaultID)
+	ctxB := tenant.Set(context.Background(), orgID)
+
+	const email = "jill@example.com"
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		att := loginbus.Attempt{
+			ID:        uuid.New(),
+			Email:     email,
+			IP:        "127.0.0.1",
+			Factor:    loginbus.FactorPrimary,
+			CreatedAt: now,
+		}
+
+		if err := store.Create(ctxA, att); err != nil {
+			t.Fatalf("Should be able to record a failure: %s", err)
+		}
+	}
+
+	if err := store.Create(ctxB, loginbus.Attempt{ID: uuid.New(), Email: email, Factor: loginbus.FactorPrimary, CreatedAt: now}); err != nil {
+		t.Fatalf("Should be able to record a failure: %s", err)
+	}
+
+	count := func(ctx context.Context) int {
+		f, err := store.QueryAccountFailures(ctx, email, loginbus.FactorPrimary, now.Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("Should be able to query failures: %s", err)
+		}
+		return f.Count
+	}
+
+	if got := count(ctxA); got != 3 {
+		t.Errorf("Should count the failures of the organization: got %d, exp 3", got)
+	}
+
+	if got := count(ctxB); got != 1 {
+		t.Errorf("Should not count failures of another organization: got %d, exp 1", got)
+	}
+
+	if err := store.DeleteAccountFailures(ctxB, email); err != nil {
+		t.Fatalf("Should be able to delete failures: %s", err)
+	}
+
+	if got := count(ctxB); got != 0 {
+		t.Errorf("Should delete the failures of the organization: got %d, exp 0", got)
+	}
+
+	if got := count(ctxA); got != 3 {
+		t.Errorf("Should keep the failures of another organization: got %d, exp 3", got)
+	}
+}
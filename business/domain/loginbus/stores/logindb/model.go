@@ -0,0 +1,48 @@
+package logindb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/loginbus"
+)
+
+type attempt struct {
+	ID        uuid.UUID `db:"attempt_id"`
+	TenantID  uuid.UUID `db:"tenant_id"`
+	Email     string    `db:"user_email"`
+	IP        string    `db:"ip_address"`
+	Factor    string    `db:"factor"`
+	Succeeded bool      `db:"succeeded"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func toDBAttempt(bus loginbus.Attempt) attempt {
+	return attempt{
+		ID:        bus.ID,
+		TenantID:  bus.TenantID,
+		Email:     bus.Email,
+		IP:        bus.IP,
+		Factor:    bus.Factor,
+		Succeeded: bus.Succeeded,
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+}
+
+type failures struct {
+	Count int          `db:"failures"`
+	Last  sql.NullTime `db:"last_failure"`
+}
+
+func toBusFailures(db failures) loginbus.Failures {
+	bus := loginbus.Failures{
+		Count: db.Count,
+	}
+
+	if db.Last.Valid {
+		bus.Last = db.Last.Time.In(time.Local)
+	}
+
+	return bus
+}
@@ -9,6 +9,7 @@ import (
 
 type Order struct {
 	ID             uuid.UUID
+	TenantID       uuid.UUID
 	UserID         uuid.UUID
 	UserName       string
 	UserEmail      string
@@ -3,15 +3,22 @@ package orderbus
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/kamogelosekhukhune777/lms/business/domain/coursebus"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
 )
 
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound = errors.New("order not found")
+)
+
 // Storer interface declares the behavior this package needs to persist and
 // retrieve data.
 type Storer interface {
@@ -84,6 +91,7 @@ func (b *Business) SaveOrder(ctx context.Context, no NewOrder) (Order, error) {
 
 	order := Order{
 		ID:             uuid.New(),
+		TenantID:       tenant.Get(ctx),
 		UserID:         usr.ID,
 		UserName:       no.UserName,
 		UserEmail:      no.UserEmail,
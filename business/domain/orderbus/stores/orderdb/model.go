@@ -11,6 +11,7 @@ import (
 
 type order struct {
 	ID            uuid.UUID `db:"order_id"`
+	TenantID      uuid.UUID `db:"tenant_id"`
 	UserID        uuid.UUID `db:"user_id"`
 	OrderStatus   string    `db:"order_status"`
 	PaymentMethod string    `db:"payment_method"`
@@ -26,6 +27,7 @@ type order struct {
 func toDBOrder(ord orderbus.Order) order {
 	return order{
 		ID:            ord.ID,
+		TenantID:      ord.TenantID,
 		UserID:        ord.UserID,
 		OrderStatus:   ord.OrderStatus,
 		PaymentMethod: ord.PaymentMethod,
@@ -47,6 +49,7 @@ func toBusOrder(db order) (orderbus.Order, error) {
 
 	bus := orderbus.Order{
 		ID:            db.ID,
+		TenantID:      db.TenantID,
 		UserID:        db.UserID,
 		OrderStatus:   db.OrderStatus,
 		PaymentMethod: db.PaymentMethod,
@@ -2,12 +2,14 @@ package orderdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/kamogelosekhukhune777/lms/business/domain/orderbus"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
 )
 
@@ -42,9 +44,16 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (orderbus.Storer, error) {
 }
 
 func (s *Store) Create(ctx context.Context, ord orderbus.Order) error {
-	const q = ``
+	const q = `
+	INSERT INTO Orders
+		(order_id, tenant_id, user_id, order_status, payment_method, payment_status, order_date, payment_id, payer_id, instructor_id, course_id, course_pricing)
+	VALUES
+		(:order_id, :tenant_id, :user_id, :order_status, :payment_method, :payment_status, :order_date, :payment_id, :payer_id, :instructor_id, :course_id, :course_pricing)`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBOrder(ord)); err != nil {
+	dbOrd := toDBOrder(ord)
+	dbOrd.TenantID = tenant.Get(ctx)
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, dbOrd); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
 	}
 
@@ -53,15 +62,29 @@ func (s *Store) Create(ctx context.Context, ord orderbus.Order) error {
 
 func (s *Store) QueryByID(ctx context.Context, orderID uuid.UUID) (orderbus.Order, error) {
 	data := struct {
-		ID string `db:"order_id"`
+		ID       string `db:"order_id"`
+		TenantID string `db:"tenant_id"`
 	}{
-		ID: orderID.String(),
+		ID:       orderID.String(),
+		TenantID: tenant.Get(ctx).String(),
 	}
 
-	const q = ``
+	const q = `
+	SELECT
+		order_id, tenant_id, user_id, order_status, payment_method, payment_status, order_date,
+		COALESCE(payment_id, '') AS payment_id, COALESCE(payer_id, '') AS payer_id,
+		instructor_id, course_id, course_pricing
+	FROM
+		Orders
+	WHERE
+		order_id = :order_id AND
+		tenant_id = :tenant_id`
 
 	var dbOrd order
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbOrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return orderbus.Order{}, fmt.Errorf("db: %w", orderbus.ErrNotFound)
+		}
 		return orderbus.Order{}, fmt.Errorf("db: %w", err)
 	}
 
@@ -69,9 +92,23 @@ func (s *Store) QueryByID(ctx context.Context, orderID uuid.UUID) (orderbus.Orde
 }
 
 func (s *Store) Update(ctx context.Context, ord orderbus.Order) error {
-	const q = ``
-
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBOrder(ord)); err != nil {
+	const q = `
+	UPDATE
+		Orders
+	SET
+		order_status = :order_status,
+		payment_method = :payment_method,
+		payment_status = :payment_status,
+		payment_id = :payment_id,
+		payer_id = :payer_id
+	WHERE
+		order_id = :order_id AND
+		tenant_id = :tenant_id`
+
+	dbOrd := toDBOrder(ord)
+	dbOrd.TenantID = tenant.Get(ctx)
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, dbOrd); err != nil {
 		return fmt.Errorf("namedexeccontext: %w", err)
 	}
 
@@ -0,0 +1,31 @@
+package orgbus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/types/slug"
+)
+
+// Organization represents a client company the platform is run for. Its id
+// is the tenant id that scopes the users, courses, enrollments and orders
+// of the company.
+type Organization struct {
+	ID        uuid.UUID
+	Name      string
+	Slug      slug.Slug
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewOrganization contains information needed to create a new organization.
+type NewOrganization struct {
+	Name string
+	Slug slug.Slug
+}
+
+// UpdateOrganization contains information needed to update an organization.
+// Fields that are not set are left unchanged.
+type UpdateOrganization struct {
+	Name *string
+}
@@ -0,0 +1,14 @@
+package orgbus
+
+import "github.com/kamogelosekhukhune777/lms/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByName, order.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID        = "organization_id"
+	OrderByName      = "name"
+	OrderBySlug      = "slug"
+	OrderByCreatedAt = "created_at"
+)
@@ -0,0 +1,133 @@
+// Package orgbus provides business access to the organization domain.
+package orgbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/types/slug"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound   = errors.New("organization not found")
+	ErrUniqueSlug = errors.New("slug is not unique")
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	Create(ctx context.Context, org Organization) error
+	Update(ctx context.Context, org Organization) error
+	Query(ctx context.Context, orderBy order.By, page page.Page) ([]Organization, error)
+	Count(ctx context.Context) (int, error)
+	QueryByID(ctx context.Context, orgID uuid.UUID) (Organization, error)
+	QueryBySlug(ctx context.Context, slg slug.Slug) (Organization, error)
+}
+
+// Business manages the set of APIs for organization access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs an organization business API for use.
+func NewBusiness(log *logger.Logger, storer Storer) *Business {
+	return &Business{
+		log:    log,
+		storer: storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:    b.log,
+		storer: storer,
+	}
+
+	return &bus, nil
+}
+
+// Create adds a new organization to the system.
+func (b *Business) Create(ctx context.Context, no NewOrganization) (Organization, error) {
+	now := time.Now()
+
+	org := Organization{
+		ID:        uuid.New(),
+		Name:      no.Name,
+		Slug:      no.Slug,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := b.storer.Create(ctx, org); err != nil {
+		return Organization{}, fmt.Errorf("create: %w", err)
+	}
+
+	return org, nil
+}
+
+// Update modifies information about an organization.
+func (b *Business) Update(ctx context.Context, org Organization, uo UpdateOrganization) (Organization, error) {
+	if uo.Name != nil {
+		org.Name = *uo.Name
+	}
+
+	org.UpdatedAt = time.Now()
+
+	if err := b.storer.Update(ctx, org); err != nil {
+		return Organization{}, fmt.Errorf("update: %w", err)
+	}
+
+	return org, nil
+}
+
+// Query retrieves a list of existing organizations.
+func (b *Business) Query(ctx context.Context, orderBy order.By, page page.Page) ([]Organization, error) {
+	orgs, err := b.storer.Query(ctx, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return orgs, nil
+}
+
+// Count returns the total number of organizations.
+func (b *Business) Count(ctx context.Context) (int, error) {
+	return b.storer.Count(ctx)
+}
+
+// QueryByID finds the organization by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, orgID uuid.UUID) (Organization, error) {
+	org, err := b.storer.QueryByID(ctx, orgID)
+	if err != nil {
+		return Organization{}, fmt.Errorf("query: orgID[%s]: %w", orgID, err)
+	}
+
+	return org, nil
+}
+
+// QueryBySlug finds the organization by the specified slug.
+func (b *Business) QueryBySlug(ctx context.Context, slg slug.Slug) (Organization, error) {
+	org, err := b.storer.QueryBySlug(ctx, slg)
+	if err != nil {
+		return Organization{}, fmt.Errorf("query: slug[%s]: %w", slg, err)
+	}
+
+	return org, nil
+}
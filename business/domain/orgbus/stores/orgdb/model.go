@@ -0,0 +1,59 @@
+package orgdb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/orgbus"
+	"github.com/kamogelosekhukhune777/lms/business/types/slug"
+)
+
+type organization struct {
+	ID        uuid.UUID `db:"organization_id"`
+	Name      string    `db:"name"`
+	Slug      string    `db:"slug"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func toDBOrganization(bus orgbus.Organization) organization {
+	return organization{
+		ID:        bus.ID,
+		Name:      bus.Name,
+		Slug:      bus.Slug.String(),
+		CreatedAt: bus.CreatedAt.UTC(),
+		UpdatedAt: bus.UpdatedAt.UTC(),
+	}
+}
+
+func toBusOrganization(db organization) (orgbus.Organization, error) {
+	slg, err := slug.Parse(db.Slug)
+	if err != nil {
+		return orgbus.Organization{}, fmt.Errorf("parse slug: %w", err)
+	}
+
+	bus := orgbus.Organization{
+		ID:        db.ID,
+		Name:      db.Name,
+		Slug:      slg,
+		CreatedAt: db.CreatedAt.In(time.Local),
+		UpdatedAt: db.UpdatedAt.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusOrganizations(dbs []organization) ([]orgbus.Organization, error) {
+	bus := make([]orgbus.Organization, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusOrganization(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
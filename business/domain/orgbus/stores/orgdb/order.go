@@ -0,0 +1,24 @@
+package orgdb
+
+import (
+	"fmt"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/orgbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	orgbus.OrderByID:        "organization_id",
+	orgbus.OrderByName:      "name",
+	orgbus.OrderBySlug:      "slug",
+	orgbus.OrderByCreatedAt: "created_at",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
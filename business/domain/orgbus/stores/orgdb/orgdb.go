@@ -0,0 +1,187 @@
+// Package orgdb contains organization related CRUD functionality.
+package orgdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/business/domain/orgbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/types/slug"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Store manages the set of APIs for organization database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (orgbus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new organization into the database.
+func (s *Store) Create(ctx context.Context, org orgbus.Organization) error {
+	const q = `
+	INSERT INTO Organizations
+		(organization_id, name, slug, created_at, updated_at)
+	VALUES
+		(:organization_id, :name, :slug, :created_at, :updated_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBOrganization(org)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", orgbus.ErrUniqueSlug)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces an organization document in the database.
+func (s *Store) Update(ctx context.Context, org orgbus.Organization) error {
+	const q = `
+	UPDATE
+		Organizations
+	SET
+		name = :name,
+		updated_at = :updated_at
+	WHERE
+		organization_id = :organization_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBOrganization(org)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing organizations from the database.
+func (s *Store) Query(ctx context.Context, orderBy order.By, page page.Page) ([]orgbus.Organization, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		organization_id, name, slug, created_at, updated_at
+	FROM
+		Organizations`
+
+	buf := bytes.NewBufferString(q)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbOrgs []organization
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbOrgs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusOrganizations(dbOrgs)
+}
+
+// Count returns the total number of organizations in the DB.
+func (s *Store) Count(ctx context.Context) (int, error) {
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		Organizations`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, map[string]any{}, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified organization from the database.
+func (s *Store) QueryByID(ctx context.Context, orgID uuid.UUID) (orgbus.Organization, error) {
+	data := struct {
+		ID string `db:"organization_id"`
+	}{
+		ID: orgID.String(),
+	}
+
+	const q = `
+	SELECT
+		organization_id, name, slug, created_at, updated_at
+	FROM
+		Organizations
+	WHERE
+		organization_id = :organization_id`
+
+	var dbOrg organization
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbOrg); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return orgbus.Organization{}, fmt.Errorf("db: %w", orgbus.ErrNotFound)
+		}
+		return orgbus.Organization{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusOrganization(dbOrg)
+}
+
+// QueryBySlug gets the specified organization from the database by slug.
+func (s *Store) QueryBySlug(ctx context.Context, slg slug.Slug) (orgbus.Organization, error) {
+	data := struct {
+		Slug string `db:"slug"`
+	}{
+		Slug: slg.String(),
+	}
+
+	const q = `
+	SELECT
+		organization_id, name, slug, created_at, updated_at
+	FROM
+		Organizations
+	WHERE
+		slug = :slug`
+
+	var dbOrg organization
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbOrg); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return orgbus.Organization{}, fmt.Errorf("db: %w", orgbus.ErrNotFound)
+		}
+		return orgbus.Organization{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusOrganization(dbOrg)
+}
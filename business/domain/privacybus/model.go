@@ -0,0 +1,123 @@
+package privacybus
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/types/money"
+)
+
+// Export represents everything the system holds about a user.
+type Export struct {
+	GeneratedAt     time.Time
+	Profile         Profile
+	Enrollments     []Enrollment
+	Orders          []Order
+	CourseProgress  []CourseProgress
+	LectureProgress []LectureProgress
+	Applications    []Application
+	Sessions        []Session
+	Identities      []Identity
+	Consents        []Consent
+}
+
+// Profile represents the account of the user.
+type Profile struct {
+	ID         uuid.UUID
+	TenantID   uuid.UUID
+	Name       string
+	Email      string
+	Roles      []string
+	VerifiedAt time.Time
+	CreatedAt  time.Time
+}
+
+// Enrollment represents a course the user is enrolled in.
+type Enrollment struct {
+	ID          uuid.UUID
+	CourseID    uuid.UUID
+	CourseTitle string
+	PaidAmount  money.Money
+	EnrolledAt  time.Time
+}
+
+// Order represents a purchase made by the user.
+type Order struct {
+	ID            uuid.UUID
+	CourseID      uuid.UUID
+	OrderStatus   string
+	PaymentMethod string
+	PaymentStatus string
+	PaymentID     string
+	CoursePricing money.Money
+	OrderDate     time.Time
+}
+
+// CourseProgress represents the completion state of a course.
+type CourseProgress struct {
+	CourseID       uuid.UUID
+	Completed      bool
+	CompletionDate time.Time
+}
+
+// LectureProgress represents a lecture the user has viewed.
+type LectureProgress struct {
+	LectureID  uuid.UUID
+	Viewed     bool
+	DateViewed time.Time
+}
+
+// Application represents an application of the user to become an
+// instructor, including the note left by the reviewer.
+type Application struct {
+	ID         uuid.UUID
+	Bio        string
+	Expertise  []string
+	Links      []string
+	Status     string
+	ReviewNote string
+	ReviewedAt time.Time
+	CreatedAt  time.Time
+}
+
+// Session represents a device the user signed in from.
+type Session struct {
+	ID         uuid.UUID
+	Device     string
+	IPAddress  string
+	UserAgent  string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  time.Time
+}
+
+// Identity represents an external account linked to the user.
+type Identity struct {
+	Provider  string
+	Email     string
+	CreatedAt time.Time
+}
+
+// Consent represents a version of a legal document the user accepted.
+type Consent struct {
+	Kind       string
+	Version    string
+	IPAddress  string
+	UserAgent  string
+	AcceptedAt time.Time
+}
+
+// =============================================================================
+
+// Erasure represents a request of a user to have their personal data
+// erased. The data is erased once the grace period has ended, and the
+// request can be canceled until then.
+type Erasure struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Status       Status
+	RequestedAt  time.Time
+	ScheduledFor time.Time
+	CompletedAt  time.Time
+	CanceledAt   time.Time
+}
@@ -0,0 +1,280 @@
+// Package privacybus provides business access to the data subject requests
+// of users: exporting everything held about them and erasing their personal
+// data.
+package privacybus
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Set of error variables for erasure requests.
+var (
+	ErrNotFound       = errors.New("erasure request not found")
+	ErrErasurePending = errors.New("an erasure request is already pending")
+)
+
+// These are the actions recorded in the audit log for erasures.
+const (
+	ActionErasureRequested = "user.erasure.requested"
+	ActionErasureCanceled  = "user.erasure.canceled"
+	ActionErasureCompleted = "user.erasure.completed"
+)
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
+	QueryExport(ctx context.Context, userID uuid.UUID) (Export, error)
+	CreateErasure(ctx context.Context, ers Erasure) error
+	UpdateErasure(ctx context.Context, ers Erasure) error
+	QueryPendingErasure(ctx context.Context, userID uuid.UUID) (Erasure, error)
+	QueryDueErasures(ctx context.Context, now time.Time, limit int) ([]Erasure, error)
+	Anonymize(ctx context.Context, userID uuid.UUID) error
+}
+
+// Business manages the set of APIs for data subject requests.
+type Business struct {
+	log      *logger.Logger
+	auditBus *auditbus.Business
+	storer   Storer
+}
+
+// NewBusiness constructs a privacy business API for use.
+func NewBusiness(log *logger.Logger, auditBus *auditbus.Business, storer Storer) *Business {
+	return &Business{
+		log:      log,
+		auditBus: auditBus,
+		storer:   storer,
+	}
+}
+
+// NewWithTx constructs a new business value that will use the
+// specified transaction in any store related calls.
+func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
+	storer, err := b.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	auditBus, err := b.auditBus.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := Business{
+		log:      b.log,
+		auditBus: auditBus,
+		storer:   storer,
+	}
+
+	return &bus, nil
+}
+
+// Export collects everything held about the user.
+func (b *Business) Export(ctx context.Context, userID uuid.UUID) (Export, error) {
+	exp, err := b.storer.QueryExport(ctx, userID)
+	if err != nil {
+		return Export{}, fmt.Errorf("queryexport: userID[%s]: %w", userID, err)
+	}
+
+	exp.GeneratedAt = time.Now()
+
+	return exp, nil
+}
+
+// =============================================================================
+
+// RequestErasure schedules the personal data of the user to be erased once
+// the grace period has ended. A user can only have one pending request.
+func (b *Business) RequestErasure(ctx context.Context, userID uuid.UUID, grace time.Duration, ip string) (Erasure, error) {
+	now := time.Now()
+
+	ers := Erasure{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Status:       StatusPending,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(grace),
+	}
+
+	if err := b.storer.CreateErasure(ctx, ers); err != nil {
+		return Erasure{}, fmt.Errorf("create: %w", err)
+	}
+
+	details := map[string]string{
+		"request_id":    ers.ID.String(),
+		"scheduled_for": ers.ScheduledFor.UTC().Format(time.RFC3339),
+	}
+
+	if err := b.audit(ctx, userID, ActionErasureRequested, userID, ip, details); err != nil {
+		return Erasure{}, err
+	}
+
+	return ers, nil
+}
+
+// QueryPendingErasure finds the pending erasure request of the user.
+func (b *Business) QueryPendingErasure(ctx context.Context, userID uuid.UUID) (Erasure, error) {
+	ers, err := b.storer.QueryPendingErasure(ctx, userID)
+	if err != nil {
+		return Erasure{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return ers, nil
+}
+
+// CancelErasure cancels the pending erasure request of the user.
+func (b *Business) CancelErasure(ctx context.Context, userID uuid.UUID, ip string) (Erasure, error) {
+	ers, err := b.QueryPendingErasure(ctx, userID)
+	if err != nil {
+		return Erasure{}, err
+	}
+
+	ers.Status = StatusCanceled
+	ers.CanceledAt = time.Now()
+
+	if err := b.storer.UpdateErasure(ctx, ers); err != nil {
+		return Erasure{}, fmt.Errorf("update: %w", err)
+	}
+
+	details := map[string]string{
+		"request_id": ers.ID.String(),
+	}
+
+	if err := b.audit(ctx, userID, ActionErasureCanceled, userID, ip, details); err != nil {
+		return Erasure{}, err
+	}
+
+	return ers, nil
+}
+
+// EraseDue erases the personal data of the users whose grace period has
+// ended. Each request is handled in its own transaction so a failing request
+// doesn't hold up the others. It returns the number of users erased.
+func (b *Business) EraseDue(ctx context.Context, beginner sqldb.Beginner, now time.Time, limit int) (int, error) {
+	due, err := b.storer.QueryDueErasures(ctx, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("querydue: %w", err)
+	}
+
+	var erased int
+	var errs []error
+
+	for _, ers := range due {
+		if err := b.eraseWithTx(ctx, beginner, ers, now); err != nil {
+			errs = append(errs, fmt.Errorf("erase: requestID[%s]: %w", ers.ID, err))
+			continue
+		}
+
+		erased++
+	}
+
+	return erased, errors.Join(errs...)
+}
+
+// Erase anonymizes the personal data of the user right away on behalf of an
+// admin, completing the pending erasure request of the user if there is one.
+// Orders are kept as they are financial records.
+func (b *Business) Erase(ctx context.Context, userID uuid.UUID, actorID uuid.UUID, ip string) error {
+	now := time.Now()
+
+	if err := b.storer.Anonymize(ctx, userID); err != nil {
+		return fmt.Errorf("anonymize: userID[%s]: %w", userID, err)
+	}
+
+	details := map[string]string{}
+
+	ers, err := b.storer.QueryPendingErasure(ctx, userID)
+	switch {
+	case err == nil:
+		ers.Status = StatusCompleted
+		ers.CompletedAt = now
+
+		if err := b.storer.UpdateErasure(ctx, ers); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		details["request_id"] = ers.ID.String()
+
+	case !errors.Is(err, ErrNotFound):
+		return fmt.Errorf("querypending: userID[%s]: %w", userID, err)
+	}
+
+	return b.audit(ctx, actorID, ActionErasureCompleted, userID, ip, details)
+}
+
+// =============================================================================
+
+func (b *Business) eraseWithTx(ctx context.Context, beginner sqldb.Beginner, ers Erasure, now time.Time) error {
+	tx, err := beginner.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			b.log.Error(ctx, "erase: rollback", "requestID", ers.ID, "err", err)
+		}
+	}()
+
+	bus, err := b.NewWithTx(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := bus.erase(ctx, ers, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// erase anonymizes the personal data of the user. Orders are kept as they
+// are financial records.
+func (b *Business) erase(ctx context.Context, ers Erasure, now time.Time) error {
+	if err := b.storer.Anonymize(ctx, ers.UserID); err != nil {
+		return fmt.Errorf("anonymize: userID[%s]: %w", ers.UserID, err)
+	}
+
+	ers.Status = StatusCompleted
+	ers.CompletedAt = now
+
+	if err := b.storer.UpdateErasure(ctx, ers); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	details := map[string]string{
+		"request_id": ers.ID.String(),
+	}
+
+	return b.audit(ctx, uuid.Nil, ActionErasureCompleted, ers.UserID, "", details)
+}
+
+func (b *Business) audit(ctx context.Context, actorID uuid.UUID, action string, userID uuid.UUID, ip string, details map[string]string) error {
+	na := auditbus.NewAudit{
+		ActorID: actorID,
+		Action:  action,
+		Subject: userID.String(),
+		IP:      ip,
+		Details: details,
+	}
+
+	if _, err := b.auditBus.Create(ctx, na); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return nil
+}
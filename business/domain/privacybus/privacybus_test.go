@@ -0,0 +1,156 @@
+package privacybus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/auditbus/stores/auditdb"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus/stores/privacydb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/dbtest"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+)
+
+func Test_Erase(t *testing.T) {
+	db := dbtest.New(t)
+	ctx := tenant.Set(context.Background(), tenant.DefaultID)
+
+	auditBus := auditbus.NewBusiness(db.Log, auditdb.NewStore(db.Log, db.DB))
+	privacyBus := privacybus.NewBusiness(db.Log, auditBus, privacydb.NewStore(db.Log, db.DB))
+
+	adminID := uuid.New()
+	studentID := uuid.New()
+	instructorID := uuid.New()
+	courseID := uuid.New()
+	orderID := uuid.New()
+	apiKeyID := uuid.New()
+	auditID := uuid.New()
+
+	seed := []struct {
+		q    string
+		args []any
+	}{
+		{
+			q:    `INSERT INTO Users (user_id, user_name, user_email, password_hash, roles) VALUES ($1, 'Admin', 'admin@example.com', 'hash', '{ADMIN}')`,
+			args: []any{adminID},
+		},
+		{
+			q:    `INSERT INTO Users (user_id, user_name, user_email, password_hash, roles) VALUES ($1, 'Student', 'student@example.com', 'hash', '{STUDENT}')`,
+			args: []any{studentID},
+		},
+		{
+			q:    `INSERT INTO Users (user_id, user_name, user_email, password_hash, roles) VALUES ($1, 'Instructor', 'instructor@example.com', 'hash', '{INSTRUCTOR}')`,
+			args: []any{instructorID},
+		},
+		{
+			q:    `INSERT INTO Courses (course_id, instructor_id, title, pricing) VALUES ($1, $2, 'Course', 10.00)`,
+			args: []any{courseID, instructorID},
+		},
+		{
+			q: `INSERT INTO Orders (order_id, user_id, order_status, payment_method, payment_status, payer_id, instructor_id, course_id, course_pricing)
+				VALUES ($1, $2, 'CONFIRMED', 'paypal', 'PAID', 'payer-1', $3, $4, 10.00)`,
+			args: []any{orderID, studentID, instructorID, courseID},
+		},
+		{
+			q: `INSERT INTO APIKeys (api_key_id, tenant_id, name, prefix, key_hash, scopes, created_by, created_at)
+				VALUES ($1, $2, 'CI', 'lms_ci', 'hash', '{courses:read}', $3, NOW())`,
+			args: []any{apiKeyID, tenant.DefaultID, instructorID},
+		},
+		{
+			q:    `INSERT INTO AuditLog (audit_id, action, subject) VALUES ($1, 'login.lockout', 'instructor@example.com')`,
+			args: []any{auditID},
+		},
+	}
+
+	for _, s := range seed {
+		if _, err := db.DB.ExecContext(ctx, s.q, s.args...); err != nil {
+			t.Fatalf("Should be able to seed the database: %s", err)
+		}
+	}
+
+	for _, userID := range []uuid.UUID{studentID, instructorID} {
+		if err := privacyBus.Erase(ctx, userID, adminID, "127.0.0.1"); err != nil {
+			t.Fatalf("Should be able to erase user %s: %s", userID, err)
+		}
+	}
+
+	t.Run("orders survive", func(t *testing.T) {
+		var order struct {
+			UserID       uuid.UUID `db:"user_id"`
+			InstructorID uuid.UUID `db:"instructor_id"`
+			CourseID     uuid.UUID `db:"course_id"`
+			PayerID      *string   `db:"payer_id"`
+		}
+
+		const q = `SELECT user_id, instructor_id, course_id, payer_id FROM Orders WHERE order_id = $1`
+		if err := db.DB.GetContext(ctx, &order, q, orderID); err != nil {
+			t.Fatalf("Should still find the order: %s", err)
+		}
+
+		if order.UserID != studentID || order.InstructorID != instructorID || order.CourseID != courseID {
+			t.Errorf("Should keep the order as it was: got %+v", order)
+		}
+
+		if order.PayerID != nil {
+			t.Errorf("Should clear the payer of the order: got %q", *order.PayerID)
+		}
+	})
+
+	t.Run("courses survive", func(t *testing.T) {
+		var count int
+		if err := db.DB.GetContext(ctx, &count, `SELECT COUNT(*) FROM Courses WHERE course_id = $1`, courseID); err != nil {
+			t.Fatalf("Should be able to count courses: %s", err)
+		}
+
+		if count != 1 {
+			t.Errorf("Should keep the course of the erased instructor: got %d courses", count)
+		}
+	})
+
+	t.Run("api keys revoked", func(t *testing.T) {
+		var revoked bool
+		if err := db.DB.GetContext(ctx, &revoked, `SELECT revoked_at IS NOT NULL FROM APIKeys WHERE api_key_id = $1`, apiKeyID); err != nil {
+			t.Fatalf("Should still find the api key: %s", err)
+		}
+
+		if !revoked {
+			t.Error("Should revoke the api keys the user created")
+		}
+	})
+
+	t.Run("lockouts redacted", func(t *testing.T) {
+		var subject string
+		if err := db.DB.GetContext(ctx, &subject, `SELECT subject FROM AuditLog WHERE audit_id = $1`, auditID); err != nil {
+			t.Fatalf("Should still find the audit entry: %s", err)
+		}
+
+		if subject != "" {
+			t.Errorf("Should remove the email from the lockout: got %q", subject)
+		}
+	})
+
+	t.Run("users anonymized", func(t *testing.T) {
+		var users []struct {
+			Name  string `db:"user_name"`
+			Email string `db:"user_email"`
+			Roles int    `db:"roles"`
+		}
+
+		const q = `SELECT user_name, user_email, COALESCE(array_length(roles, 1), 0) AS roles FROM Users WHERE user_id IN ($1, $2)`
+		if err := db.DB.SelectContext(ctx, &users, q, studentID, instructorID); err != nil {
+			t.Fatalf("Should still find the users: %s", err)
+		}
+
+		if len(users) != 2 {
+			t.Fatalf("Should keep both users: got %d", len(users))
+		}
+
+		for _, usr := range users {
+			if usr.Name != "Deleted User" || usr.Email == "student@example.com" || usr.Email == "instructor@example.com" || usr.Roles != 0 {
+				t.Errorf("Should anonymize the user: got %+v", usr)
+			}
+		}
+	})
+}
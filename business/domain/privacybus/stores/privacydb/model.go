@@ -0,0 +1,315 @@
+package privacydb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb/dbarray"
+	"github.com/kamogelosekhukhune777/lms/business/types/money"
+)
+
+type profile struct {
+	ID         uuid.UUID      `db:"user_id"`
+	TenantID   uuid.UUID      `db:"tenant_id"`
+	Name       string         `db:"user_name"`
+	Email      string         `db:"user_email"`
+	Roles      dbarray.String `db:"roles"`
+	VerifiedAt sql.NullTime   `db:"verified_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func toBusProfile(db profile) privacybus.Profile {
+	return privacybus.Profile{
+		ID:         db.ID,
+		TenantID:   db.TenantID,
+		Name:       db.Name,
+		Email:      db.Email,
+		Roles:      db.Roles,
+		VerifiedAt: fromNullTime(db.VerifiedAt),
+		CreatedAt:  db.CreatedAt.In(time.Local),
+	}
+}
+
+type enrollment struct {
+	ID          uuid.UUID `db:"enrollment_id"`
+	CourseID    uuid.UUID `db:"course_id"`
+	CourseTitle string    `db:"course_title"`
+	PaidAmount  float64   `db:"paid_amount"`
+	EnrolledAt  time.Time `db:"enrolled_at"`
+}
+
+func toBusEnrollments(dbs []enrollment) ([]privacybus.Enrollment, error) {
+	bus := make([]privacybus.Enrollment, len(dbs))
+
+	for i, db := range dbs {
+		paid, err := money.Parse(db.PaidAmount)
+		if err != nil {
+			return nil, fmt.Errorf("parse paid amount: %w", err)
+		}
+
+		bus[i] = privacybus.Enrollment{
+			ID:          db.ID,
+			CourseID:    db.CourseID,
+			CourseTitle: db.CourseTitle,
+			PaidAmount:  paid,
+			EnrolledAt:  db.EnrolledAt.In(time.Local),
+		}
+	}
+
+	return bus, nil
+}
+
+type order struct {
+	ID            uuid.UUID `db:"order_id"`
+	CourseID      uuid.UUID `db:"course_id"`
+	OrderStatus   string    `db:"order_status"`
+	PaymentMethod string    `db:"payment_method"`
+	PaymentStatus string    `db:"payment_status"`
+	PaymentID     string    `db:"payment_id"`
+	CoursePricing float64   `db:"course_pricing"`
+	OrderDate     time.Time `db:"order_date"`
+}
+
+func toBusOrders(dbs []order) ([]privacybus.Order, error) {
+	bus := make([]privacybus.Order, len(dbs))
+
+	for i, db := range dbs {
+		price, err := money.Parse(db.CoursePricing)
+		if err != nil {
+			return nil, fmt.Errorf("parse cost: %w", err)
+		}
+
+		bus[i] = privacybus.Order{
+			ID:            db.ID,
+			CourseID:      db.CourseID,
+			OrderStatus:   db.OrderStatus,
+			PaymentMethod: db.PaymentMethod,
+			PaymentStatus: db.PaymentStatus,
+			PaymentID:     db.PaymentID,
+			CoursePricing: price,
+			OrderDate:     db.OrderDate.In(time.Local),
+		}
+	}
+
+	return bus, nil
+}
+
+type courseProgress struct {
+	CourseID       uuid.UUID    `db:"course_id"`
+	Completed      bool         `db:"completed"`
+	CompletionDate sql.NullTime `db:"completion_date"`
+}
+
+func toBusCourseProgress(dbs []courseProgress) []privacybus.CourseProgress {
+	bus := make([]privacybus.CourseProgress, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = privacybus.CourseProgress{
+			CourseID:       db.CourseID,
+			Completed:      db.Completed,
+			CompletionDate: fromNullTime(db.CompletionDate),
+		}
+	}
+
+	return bus
+}
+
+type lectureProgress struct {
+	LectureID  uuid.UUID    `db:"lecture_id"`
+	Viewed     bool         `db:"viewed"`
+	DateViewed sql.NullTime `db:"date_viewed"`
+}
+
+func toBusLectureProgress(dbs []lectureProgress) []privacybus.LectureProgress {
+	bus := make([]privacybus.LectureProgress, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = privacybus.LectureProgress{
+			LectureID:  db.LectureID,
+			Viewed:     db.Viewed,
+			DateViewed: fromNullTime(db.DateViewed),
+		}
+	}
+
+	return bus
+}
+
+type application struct {
+	ID         uuid.UUID      `db:"application_id"`
+	Bio        string         `db:"bio"`
+	Expertise  dbarray.String `db:"expertise"`
+	Links      dbarray.String `db:"links"`
+	Status     string         `db:"status"`
+	ReviewNote string         `db:"review_note"`
+	ReviewedAt sql.NullTime   `db:"reviewed_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func toBusApplications(dbs []application) []privacybus.Application {
+	bus := make([]privacybus.Application, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = privacybus.Application{
+			ID:         db.ID,
+			Bio:        db.Bio,
+			Expertise:  db.Expertise,
+			Links:      db.Links,
+			Status:     db.Status,
+			ReviewNote: db.ReviewNote,
+			ReviewedAt: fromNullTime(db.ReviewedAt),
+			CreatedAt:  db.CreatedAt.In(time.Local),
+		}
+	}
+
+	return bus
+}
+
+type session struct {
+	ID         uuid.UUID    `db:"session_id"`
+	Device     string       `db:"device"`
+	IPAddress  string       `db:"ip_address"`
+	UserAgent  string       `db:"user_agent"`
+	CreatedAt  time.Time    `db:"created_at"`
+	LastSeenAt time.Time    `db:"last_seen_at"`
+	RevokedAt  sql.NullTime `db:"revoked_at"`
+}
+
+func toBusSessions(dbs []session) []privacybus.Session {
+	bus := make([]privacybus.Session, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = privacybus.Session{
+			ID:         db.ID,
+			Device:     db.Device,
+			IPAddress:  db.IPAddress,
+			UserAgent:  db.UserAgent,
+			CreatedAt:  db.CreatedAt.In(time.Local),
+			LastSeenAt: db.LastSeenAt.In(time.Local),
+			RevokedAt:  fromNullTime(db.RevokedAt),
+		}
+	}
+
+	return bus
+}
+
+type identity struct {
+	Provider  string    `db:"provider"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func toBusIdentities(dbs []identity) []privacybus.Identity {
+	bus := make([]privacybus.Identity, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = privacybus.Identity{
+			Provider:  db.Provider,
+			Email:     db.Email,
+			CreatedAt: db.CreatedAt.In(time.Local),
+		}
+	}
+
+	return bus
+}
+
+type consent struct {
+	Kind       string    `db:"kind"`
+	Version    string    `db:"version"`
+	IPAddress  string    `db:"ip_address"`
+	UserAgent  string    `db:"user_agent"`
+	AcceptedAt time.Time `db:"accepted_at"`
+}
+
+func toBusConsents(dbs []consent) []privacybus.Consent {
+	bus := make([]privacybus.Consent, len(dbs))
+
+	for i, db := range dbs {
+		bus[i] = privacybus.Consent{
+			Kind:       db.Kind,
+			Version:    db.Version,
+			IPAddress:  db.IPAddress,
+			UserAgent:  db.UserAgent,
+			AcceptedAt: db.AcceptedAt.In(time.Local),
+		}
+	}
+
+	return bus
+}
+
+// =============================================================================
+
+type erasure struct {
+	ID           uuid.UUID    `db:"request_id"`
+	UserID       uuid.UUID    `db:"user_id"`
+	Status       string       `db:"status"`
+	RequestedAt  time.Time    `db:"requested_at"`
+	ScheduledFor time.Time    `db:"scheduled_for"`
+	CompletedAt  sql.NullTime `db:"completed_at"`
+	CanceledAt   sql.NullTime `db:"canceled_at"`
+}
+
+func toDBErasure(bus privacybus.Erasure) erasure {
+	return erasure{
+		ID:           bus.ID,
+		UserID:       bus.UserID,
+		Status:       bus.Status.String(),
+		RequestedAt:  bus.RequestedAt.UTC(),
+		ScheduledFor: bus.ScheduledFor.UTC(),
+		CompletedAt:  toNullTime(bus.CompletedAt),
+		CanceledAt:   toNullTime(bus.CanceledAt),
+	}
+}
+
+func toBusErasure(db erasure) (privacybus.Erasure, error) {
+	status, err := privacybus.ParseStatus(db.Status)
+	if err != nil {
+		return privacybus.Erasure{}, fmt.Errorf("parse status: %w", err)
+	}
+
+	bus := privacybus.Erasure{
+		ID:           db.ID,
+		UserID:       db.UserID,
+		Status:       status,
+		RequestedAt:  db.RequestedAt.In(time.Local),
+		ScheduledFor: db.ScheduledFor.In(time.Local),
+		CompletedAt:  fromNullTime(db.CompletedAt),
+		CanceledAt:   fromNullTime(db.CanceledAt),
+	}
+
+	return bus, nil
+}
+
+func toBusErasures(dbs []erasure) ([]privacybus.Erasure, error) {
+	bus := make([]privacybus.Erasure, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusErasure(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+func toNullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func fromNullTime(nt sql.NullTime) time.Time {
+	if !nt.Valid {
+		return time.Time{}
+	}
+
+	return nt.Time.In(time.Local)
+}
@@ -0,0 +1,376 @@
+// Package privacydb contains data subject request related CRUD
+// functionality.
+package privacydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/business/domain/privacybus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Store manages the set of APIs for privacy database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (privacybus.Storer, error) {
+	ec, err := sqldb.GetExtContext(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log: s.log,
+		db:  ec,
+	}
+
+	return &store, nil
+}
+
+// QueryExport gathers everything held about the user in the tenant of the
+// request.
+func (s *Store) QueryExport(ctx context.Context, userID uuid.UUID) (privacybus.Export, error) {
+	data := struct {
+		UserID   string `db:"user_id"`
+		TenantID string `db:"tenant_id"`
+	}{
+		UserID:   userID.String(),
+		TenantID: tenant.Get(ctx).String(),
+	}
+
+	const qProfile = `
+	SELECT
+		user_id, tenant_id, user_name, user_email, roles, verified_at, created_at
+	FROM
+		Users
+	WHERE
+		user_id = :user_id AND tenant_id = :tenant_id`
+
+	var dbProfile profile
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, qProfile, data, &dbProfile); err != nil {
+		return privacybus.Export{}, fmt.Errorf("namedquerystruct: profile: %w", err)
+	}
+
+	const qEnrollments = `
+	SELECT
+		e.enrollment_id, e.course_id, c.title AS course_title, e.paid_amount, e.enrolled_at
+	FROM
+		Enrollments e
+	JOIN
+		Courses c ON c.course_id = e.course_id
+	WHERE
+		e.student_id = :user_id AND e.tenant_id = :tenant_id
+	ORDER BY
+		e.enrolled_at`
+
+	var dbEnrollments []enrollment
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, qEnrollments, data, &dbEnrollments); err != nil {
+		return privacybus.Export{}, fmt.Errorf("namedqueryslice: enrollments: %w", err)
+	}
+
+	const qOrders = `
+	SELECT
+		order_id, course_id, order_status, payment_method, payment_status,
+		COALESCE(payment_id, '') AS payment_id, course_pricing, order_date
+	FROM
+		Orders
+	WHERE
+		user_id = :user_id AND tenant_id = :tenant_id
+	ORDER BY
+		order_date`
+
+	var dbOrders []order
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, qOrders, data, &dbOrders); err != nil {
+		return privacybus.Export{}, fmt.Errorf("namedqueryslice: orders: %w", err)
+	}
+
+	const qCourseProgress = `
+	SELECT
+		course_id, COALESCE(completed, FALSE) AS completed, completion_date
+	FROM
+		CourseProgress
+	WHERE
+		user_id = :user_id`
+
+	var dbCourseProgress []courseProgress
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, qCourseProgress, data, &dbCourseProgress); err != nil {
+		return privacybus.Export{}, fmt.Errorf("namedqueryslice: course progress: %w", err)
+	}
+
+	const qLectureProgress = `
+	SELECT
+		lecture_id, COALESCE(viewed, FALSE) AS viewed, date_viewed
+	FROM
+		LectureProgress
+	WHERE
+		user_id = :user_id`
+
+	var dbLectureProgress []lectureProgress
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, qLectureProgress, data, &dbLectureProgress); err != nil {
+		return privacybus.Export{}, fmt.Errorf("namedqueryslice: lecture progress: %w", err)
+	}
+
+	const qApplications = `
+	SELECT
+		application_id, bio, expertise, links, status, review_note, reviewed_at, created_at
+	FROM
+		InstructorApplications
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		created_at`
+
+	var dbApplications []application
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, qApplications, data, &dbApplications); err != nil {
+		return privacybus.Export{}, fmt.Errorf("namedqueryslice: applications: %w", err)
+	}
+
+	const qSessions = `
+	SELECT
+		session_id, device, ip_address, user_agent, created_at, last_seen_at, revoked_at
+	FROM
+		Sessions
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		created_at`
+
+	var dbSessions []session
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, qSessions, data, &dbSessions); err != nil {
+		return privacybus.Export{}, fmt.Errorf("namedqueryslice: sessions: %w", err)
+	}
+
+	const qIdentities = `
+	SELECT
+		provider, email, created_at
+	FROM
+		UserIdentities
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		created_at`
+
+	var dbIdentities []identity
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, qIdentities, data, &dbIdentities); err != nil {
+		return privacybus.Export{}, fmt.Errorf("namedqueryslice: identities: %w", err)
+	}
+
+	const qConsents = `
+	SELECT
+		d.kind, d.version, a.ip_address, a.user_agent, a.accepted_at
+	FROM
+		ConsentAcceptances a
+	JOIN
+		LegalDocuments d ON d.document_id = a.document_id
+	WHERE
+		a.user_id = :user_id
+	ORDER BY
+		a.accepted_at`
+
+	var dbConsents []consent
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, qConsents, data, &dbConsents); err != nil {
+		return privacybus.Export{}, fmt.Errorf("namedqueryslice: consents: %w", err)
+	}
+
+	enrollments, err := toBusEnrollments(dbEnrollments)
+	if err != nil {
+		return privacybus.Export{}, err
+	}
+
+	orders, err := toBusOrders(dbOrders)
+	if err != nil {
+		return privacybus.Export{}, err
+	}
+
+	exp := privacybus.Export{
+		Profile:         toBusProfile(dbProfile),
+		Enrollments:     enrollments,
+		Orders:          orders,
+		CourseProgress:  toBusCourseProgress(dbCourseProgress),
+		LectureProgress: toBusLectureProgress(dbLectureProgress),
+		Applications:    toBusApplications(dbApplications),
+		Sessions:        toBusSessions(dbSessions),
+		Identities:      toBusIdentities(dbIdentities),
+		Consents:        toBusConsents(dbConsents),
+	}
+
+	return exp, nil
+}
+
+// =============================================================================
+
+// CreateErasure inserts a new erasure request into the database. Only one
+// pending request per user is allowed by a unique index.
+func (s *Store) CreateErasure(ctx context.Context, ers privacybus.Erasure) error {
+	const q = `
+	INSERT INTO ErasureRequests
+		(request_id, user_id, status, requested_at, scheduled_for, completed_at, canceled_at)
+	VALUES
+		(:request_id, :user_id, :status, :requested_at, :scheduled_for, :completed_at, :canceled_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBErasure(ers)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", privacybus.ErrErasurePending)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateErasure records the outcome of a pending erasure request. The update
+// only succeeds while the request is still pending so a request can't be
+// both canceled and completed.
+func (s *Store) UpdateErasure(ctx context.Context, ers privacybus.Erasure) error {
+	const q = `
+	UPDATE
+		ErasureRequests
+	SET
+		status = :status,
+		completed_at = :completed_at,
+		canceled_at = :canceled_at
+	WHERE
+		request_id = :request_id AND status = 'PENDING'
+	RETURNING
+		request_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"request_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBErasure(ers), &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", privacybus.ErrNotFound)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// QueryPendingErasure gets the pending erasure request of the user.
+func (s *Store) QueryPendingErasure(ctx context.Context, userID uuid.UUID) (privacybus.Erasure, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		request_id, user_id, status, requested_at, scheduled_for, completed_at, canceled_at
+	FROM
+		ErasureRequests
+	WHERE
+		user_id = :user_id AND status = 'PENDING'`
+
+	var dbErs erasure
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbErs); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return privacybus.Erasure{}, fmt.Errorf("db: %w", privacybus.ErrNotFound)
+		}
+		return privacybus.Erasure{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusErasure(dbErs)
+}
+
+// QueryDueErasures retrieves the pending erasure requests of every tenant
+// whose grace period ended before now, oldest first.
+func (s *Store) QueryDueErasures(ctx context.Context, now time.Time, limit int) ([]privacybus.Erasure, error) {
+	data := map[string]any{
+		"now":   now.UTC(),
+		"limit": limit,
+	}
+
+	const q = `
+	SELECT
+		request_id, user_id, status, requested_at, scheduled_for, completed_at, canceled_at
+	FROM
+		ErasureRequests
+	WHERE
+		status = 'PENDING' AND scheduled_for <= :now
+	ORDER BY
+		scheduled_for
+	LIMIT :limit`
+
+	var dbErs []erasure
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbErs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusErasures(dbErs)
+}
+
+// Anonymize replaces the personal data of the user with placeholders and
+// removes the credentials, sessions and identities that could tie the
+// account back to a person. Orders are kept as financial records, only the
+// payer reference is removed, and consent records keep which versions were
+// accepted and when. The roles of the user are cleared and the API keys the
+// user created for the organization are revoked, so nothing issued by the
+// account keeps working after the erasure.
+func (s *Store) Anonymize(ctx context.Context, userID uuid.UUID) error {
+	data := struct {
+		UserID    string    `db:"user_id"`
+		RevokedAt time.Time `db:"revoked_at"`
+	}{
+		UserID:    userID.String(),
+		RevokedAt: time.Now().UTC(),
+	}
+
+	qs := []string{
+		`DELETE FROM LoginAttempts WHERE (tenant_id, user_email) = (SELECT tenant_id, LOWER(user_email) FROM Users WHERE user_id = :user_id)`,
+		`UPDATE AuditLog SET subject = '' WHERE action = 'login.lockout' AND subject = (SELECT LOWER(user_email) FROM Users WHERE user_id = :user_id)`,
+		`UPDATE
+			Users
+		SET
+			user_name = 'Deleted User',
+			user_email = CONCAT('erased-', user_id, '@invalid'),
+			password_hash = '',
+			roles = '{}',
+			verified_at = NULL
+		WHERE
+			user_id = :user_id`,
+		`DELETE FROM RefreshTokens WHERE user_id = :user_id`,
+		`DELETE FROM Sessions WHERE user_id = :user_id`,
+		`DELETE FROM PasswordResetTokens WHERE user_id = :user_id`,
+		`DELETE FROM EmailVerificationTokens WHERE user_id = :user_id`,
+		`DELETE FROM MagicLinkTokens WHERE user_id = :user_id`,
+		`DELETE FROM Passkeys WHERE user_id = :user_id`,
+		`DELETE FROM WebAuthnChallenges WHERE user_id = :user_id`,
+		`DELETE FROM UserTOTP WHERE user_id = :user_id`,
+		`DELETE FROM RecoveryCodes WHERE user_id = :user_id`,
+		`DELETE FROM UserIdentities WHERE user_id = :user_id`,
+		`DELETE FROM APIKeys WHERE user_id = :user_id`,
+		`UPDATE APIKeys SET revoked_at = :revoked_at WHERE created_by = :user_id AND revoked_at IS NULL`,
+		`UPDATE InstructorApplications SET bio = '', links = '{}', review_note = '' WHERE user_id = :user_id`,
+		`UPDATE Orders SET payer_id = NULL WHERE user_id = :user_id`,
+		`UPDATE ConsentAcceptances SET ip_address = '', user_agent = '' WHERE user_id = :user_id`,
+	}
+
+	for _, q := range qs {
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+			return fmt.Errorf("namedexeccontext: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package userbus
+
+import (
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/types/name"
+	"github.com/kamogelosekhukhune777/lms/business/types/role"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+type QueryFilter struct {
+	ID               *uuid.UUID
+	Name             *name.Name
+	Email            *mail.Address
+	Role             *role.Role
+	StartCreatedDate *time.Time
+	EndCreatedDate   *time.Time
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/types/locale"
 	"github.com/kamogelosekhukhune777/lms/business/types/name"
 	"github.com/kamogelosekhukhune777/lms/business/types/role"
 )
@@ -12,13 +13,31 @@ import (
 // User represents information about an individual user.
 type User struct {
 	ID           uuid.UUID
+	TenantID     uuid.UUID
 	UserName     name.Name
 	UserEmail    mail.Address
 	PasswordHash []byte
 	Roles        []role.Role
+	VerifiedAt   time.Time
 	CreatedAt    time.Time
 }
 
+// IsVerified reports whether the user has confirmed their email address.
+func (u User) IsVerified() bool {
+	return !u.VerifiedAt.IsZero()
+}
+
+// HasRole reports whether the user holds the specified role.
+func (u User) HasRole(r role.Role) bool {
+	for _, ur := range u.Roles {
+		if ur.Equal(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // NewUser contains information needed to create a new user.
 type NewUser struct {
 	UserName     name.Name
@@ -26,3 +45,239 @@ type NewUser struct {
 	PasswordHash string
 	Roles        []role.Role
 }
+
+// UpdateUser contains information needed to update a user. Fields that are
+// nil are left unchanged.
+type UpdateUser struct {
+	UserName  *name.Name
+	UserEmail *mail.Address
+	Roles     []role.Role
+	Password  *string
+}
+
+// Preferences represents how a user wants information presented to them and
+// which notifications they want to receive.
+type Preferences struct {
+	UserID              uuid.UUID
+	Locale              locale.Locale
+	TimeZone            *time.Location
+	NotifyCourseUpdates bool
+	NotifyMarketing     bool
+	UpdatedAt           time.Time
+}
+
+// Set of notification topics users can opt in or out of.
+const (
+	TopicCourseUpdates = "course_updates"
+	TopicMarketing     = "marketing"
+)
+
+// Wants reports whether the user wants to receive notifications about the
+// topic. Messages the user asked for, like a password reset, are always sent.
+func (p Preferences) Wants(topic string) bool {
+	switch topic {
+	case TopicCourseUpdates:
+		return p.NotifyCourseUpdates
+	case TopicMarketing:
+		return p.NotifyMarketing
+	}
+
+	return false
+}
+
+// DefaultPreferences returns the preferences of a user who has not set any.
+func DefaultPreferences(userID uuid.UUID) Preferences {
+	return Preferences{
+		UserID:              userID,
+		Locale:              locale.Default,
+		TimeZone:            time.UTC,
+		NotifyCourseUpdates: true,
+	}
+}
+
+// UpdatePreferences contains information needed to update the preferences
+// of a user. Fields that are nil are left unchanged.
+type UpdatePreferences struct {
+	Locale              *locale.Locale
+	TimeZone            *time.Location
+	NotifyCourseUpdates *bool
+	NotifyMarketing     *bool
+}
+
+// RefreshToken represents a refresh token issued to a user. Only a hash of
+// the token is stored. Tokens issued by rotating a refresh token share the
+// family id of the token they replaced, which also serves as the session id.
+type RefreshToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	FamilyID  uuid.UUID
+	TokenHash []byte
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	RevokedAt time.Time
+	CreatedAt time.Time
+}
+
+// Session represents a signed in client of a user. The session id is the
+// family id of the refresh tokens issued to the client and is carried in
+// the access tokens as well.
+type Session struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Device     string
+	IPAddress  string
+	UserAgent  string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  time.Time
+}
+
+// NewSession contains information about the client a session is started or
+// refreshed from.
+type NewSession struct {
+	IPAddress string
+	UserAgent string
+}
+
+// PasswordResetToken represents a single use token that allows a user to
+// set a new password. Only a hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash []byte
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	CreatedAt time.Time
+}
+
+// EmailVerificationToken represents a single use token that confirms the
+// user owns their email address. Only a hash of the token is stored.
+type EmailVerificationToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash []byte
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	CreatedAt time.Time
+}
+
+// MagicLinkToken represents a single use token that signs the user in
+// without a password. Only a hash of the token is stored.
+type MagicLinkToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash []byte
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	CreatedAt time.Time
+}
+
+// TOTP represents the time-based one-time password enrollment of a user. The
+// enrollment only protects the account once it has been confirmed.
+type TOTP struct {
+	UserID      uuid.UUID
+	Secret      string
+	ConfirmedAt time.Time
+	LastStep    int64
+	CreatedAt   time.Time
+}
+
+// IsConfirmed reports whether the user has proven they can generate codes.
+func (t TOTP) IsConfirmed() bool {
+	return !t.ConfirmedAt.IsZero()
+}
+
+// RecoveryCode represents a single use code that can be used in place of a
+// TOTP code. Only a hash of the code is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CodeHash  []byte
+	UsedAt    time.Time
+	CreatedAt time.Time
+}
+
+// TOTPEnrollment contains what a user needs to add the secret to an
+// authenticator app.
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// Identity links a user to their account at an external identity provider.
+type Identity struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+// ExternalIdentity represents the identity asserted by an external provider
+// after the user signed in with it.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         mail.Address
+	EmailVerified bool
+	UserName      name.Name
+}
+
+// OIDCState represents a sign in that was started with an external provider
+// and has not completed yet. Only a hash of the state is stored.
+type OIDCState struct {
+	ID           uuid.UUID
+	Provider     string
+	StateHash    []byte
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+	UsedAt       time.Time
+	CreatedAt    time.Time
+}
+
+// Passkey represents a WebAuthn credential registered by a user.
+type Passkey struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	CredentialID []byte
+	PublicKey    []byte
+	SignCount    uint32
+	Transports   []string
+	AAGUID       []byte
+	Name         string
+	LastUsedAt   time.Time
+	CreatedAt    time.Time
+}
+
+// NewPasskey contains the registration response needed to add a passkey.
+type NewPasskey struct {
+	Name              string
+	Transports        []string
+	ClientDataJSON    []byte
+	AttestationObject []byte
+}
+
+// PasskeyAssertion contains the response of an authenticator asked to sign
+// in with a passkey.
+type PasskeyAssertion struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	UserHandle        []byte
+}
+
+// WebAuthnChallenge represents a challenge issued for a WebAuthn ceremony.
+// The user is not known for a passwordless login.
+type WebAuthnChallenge struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Purpose   string
+	Challenge []byte
+	ExpiresAt time.Time
+	UsedAt    time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,15 @@
+package userbus
+
+import "github.com/kamogelosekhukhune777/lms/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByID, order.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID        = "user_id"
+	OrderByName      = "user_name"
+	OrderByEmail     = "user_email"
+	OrderByRoles     = "roles"
+	OrderByCreatedAt = "created_at"
+)
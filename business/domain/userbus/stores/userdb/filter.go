@@ -0,0 +1,49 @@
+package userdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+)
+
+func (s *Store) applyFilter(ctx context.Context, filter userbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	data["tenant_id"] = tenant.Get(ctx)
+	wc := []string{"tenant_id = :tenant_id"}
+
+	if filter.ID != nil {
+		data["user_id"] = *filter.ID
+		wc = append(wc, "user_id = :user_id")
+	}
+
+	if filter.Name != nil {
+		data["user_name"] = fmt.Sprintf("%%%s%%", filter.Name.String())
+		wc = append(wc, "user_name ILIKE :user_name")
+	}
+
+	if filter.Email != nil {
+		data["user_email"] = filter.Email.Address
+		wc = append(wc, "user_email = :user_email")
+	}
+
+	if filter.Role != nil {
+		data["role"] = filter.Role.String()
+		wc = append(wc, ":role = ANY(roles)")
+	}
+
+	if filter.StartCreatedDate != nil {
+		data["start_created_date"] = filter.StartCreatedDate.UTC()
+		wc = append(wc, "created_at >= :start_created_date")
+	}
+
+	if filter.EndCreatedDate != nil {
+		data["end_created_date"] = filter.EndCreatedDate.UTC()
+		wc = append(wc, "created_at <= :end_created_date")
+	}
+
+	buf.WriteString(" WHERE ")
+	buf.WriteString(strings.Join(wc, " AND "))
+}
@@ -1,6 +1,7 @@
 package userdb
 
 import (
+	"database/sql"
 	"fmt"
 	"net/mail"
 	"time"
@@ -8,26 +9,31 @@ import (
 	"github.com/google/uuid"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb/dbarray"
+	"github.com/kamogelosekhukhune777/lms/business/types/locale"
 	"github.com/kamogelosekhukhune777/lms/business/types/name"
 	"github.com/kamogelosekhukhune777/lms/business/types/role"
 )
 
 type user struct {
 	ID           uuid.UUID      `db:"user_id"`
+	TenantID     uuid.UUID      `db:"tenant_id"`
 	Name         string         `db:"user_name"`
 	Email        string         `db:"user_email"`
 	Roles        dbarray.String `db:"roles"`
 	PasswordHash []byte         `db:"password_hash"`
+	VerifiedAt   sql.NullTime   `db:"verified_at"`
 	CreatedAt    time.Time      `db:"created_at"`
 }
 
 func toDBUser(bus userbus.User) user {
 	return user{
 		ID:           bus.ID,
+		TenantID:     bus.TenantID,
 		Name:         bus.UserName.String(),
 		Email:        bus.UserEmail.Address,
 		Roles:        role.ParseToString(bus.Roles),
 		PasswordHash: bus.PasswordHash,
+		VerifiedAt:   toNullTime(bus.VerifiedAt),
 		CreatedAt:    bus.CreatedAt.UTC(),
 	}
 }
@@ -49,12 +55,500 @@ func toBusUser(db user) (userbus.User, error) {
 
 	bus := userbus.User{
 		ID:           db.ID,
+		TenantID:     db.TenantID,
 		UserName:     nme,
 		UserEmail:    addr,
 		Roles:        roles,
 		PasswordHash: db.PasswordHash,
+		VerifiedAt:   fromNullTime(db.VerifiedAt),
 		CreatedAt:    db.CreatedAt.In(time.Local),
 	}
 
 	return bus, nil
 }
+
+func toBusUsers(dbs []user) ([]userbus.User, error) {
+	bus := make([]userbus.User, len(dbs))
+
+	for i, db := range dbs {
+		var err error
+		bus[i], err = toBusUser(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+type refreshToken struct {
+	ID        uuid.UUID    `db:"token_id"`
+	UserID    uuid.UUID    `db:"user_id"`
+	FamilyID  uuid.UUID    `db:"family_id"`
+	TokenHash []byte       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	RevokedAt sql.NullTime `db:"revoked_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
+
+func toDBRefreshToken(bus userbus.RefreshToken) refreshToken {
+	return refreshToken{
+		ID:        bus.ID,
+		UserID:    bus.UserID,
+		FamilyID:  bus.FamilyID,
+		TokenHash: bus.TokenHash,
+		ExpiresAt: bus.ExpiresAt.UTC(),
+		UsedAt:    toNullTime(bus.UsedAt),
+		RevokedAt: toNullTime(bus.RevokedAt),
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+}
+
+func toBusRefreshToken(db refreshToken) userbus.RefreshToken {
+	return userbus.RefreshToken{
+		ID:        db.ID,
+		UserID:    db.UserID,
+		FamilyID:  db.FamilyID,
+		TokenHash: db.TokenHash,
+		ExpiresAt: db.ExpiresAt.In(time.Local),
+		UsedAt:    fromNullTime(db.UsedAt),
+		RevokedAt: fromNullTime(db.RevokedAt),
+		CreatedAt: db.CreatedAt.In(time.Local),
+	}
+}
+
+// =============================================================================
+
+type session struct {
+	ID         uuid.UUID    `db:"session_id"`
+	UserID     uuid.UUID    `db:"user_id"`
+	Device     string       `db:"device"`
+	IPAddress  string       `db:"ip_address"`
+	UserAgent  string       `db:"user_agent"`
+	ExpiresAt  time.Time    `db:"expires_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	LastSeenAt time.Time    `db:"last_seen_at"`
+	RevokedAt  sql.NullTime `db:"revoked_at"`
+}
+
+func toDBSession(bus userbus.Session) session {
+	return session{
+		ID:         bus.ID,
+		UserID:     bus.UserID,
+		Device:     bus.Device,
+		IPAddress:  bus.IPAddress,
+		UserAgent:  bus.UserAgent,
+		ExpiresAt:  bus.ExpiresAt.UTC(),
+		CreatedAt:  bus.CreatedAt.UTC(),
+		LastSeenAt: bus.LastSeenAt.UTC(),
+		RevokedAt:  toNullTime(bus.RevokedAt),
+	}
+}
+
+func toBusSession(db session) userbus.Session {
+	return userbus.Session{
+		ID:         db.ID,
+		UserID:     db.UserID,
+		Device:     db.Device,
+		IPAddress:  db.IPAddress,
+		UserAgent:  db.UserAgent,
+		ExpiresAt:  db.ExpiresAt.In(time.Local),
+		CreatedAt:  db.CreatedAt.In(time.Local),
+		LastSeenAt: db.LastSeenAt.In(time.Local),
+		RevokedAt:  fromNullTime(db.RevokedAt),
+	}
+}
+
+func toBusSessions(dbs []session) []userbus.Session {
+	sessions := make([]userbus.Session, len(dbs))
+	for i, db := range dbs {
+		sessions[i] = toBusSession(db)
+	}
+
+	return sessions
+}
+
+// =============================================================================
+
+type passwordResetToken struct {
+	ID        uuid.UUID    `db:"token_id"`
+	UserID    uuid.UUID    `db:"user_id"`
+	TokenHash []byte       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
+
+func toDBPasswordResetToken(bus userbus.PasswordResetToken) passwordResetToken {
+	return passwordResetToken{
+		ID:        bus.ID,
+		UserID:    bus.UserID,
+		TokenHash: bus.TokenHash,
+		ExpiresAt: bus.ExpiresAt.UTC(),
+		UsedAt:    toNullTime(bus.UsedAt),
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+}
+
+func toBusPasswordResetToken(db passwordResetToken) userbus.PasswordResetToken {
+	return userbus.PasswordResetToken{
+		ID:        db.ID,
+		UserID:    db.UserID,
+		TokenHash: db.TokenHash,
+		ExpiresAt: db.ExpiresAt.In(time.Local),
+		UsedAt:    fromNullTime(db.UsedAt),
+		CreatedAt: db.CreatedAt.In(time.Local),
+	}
+}
+
+// =============================================================================
+
+type emailVerificationToken struct {
+	ID        uuid.UUID    `db:"token_id"`
+	UserID    uuid.UUID    `db:"user_id"`
+	TokenHash []byte       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
+
+func toDBEmailVerificationToken(bus userbus.EmailVerificationToken) emailVerificationToken {
+	return emailVerificationToken{
+		ID:        bus.ID,
+		UserID:    bus.UserID,
+		TokenHash: bus.TokenHash,
+		ExpiresAt: bus.ExpiresAt.UTC(),
+		UsedAt:    toNullTime(bus.UsedAt),
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+}
+
+func toBusEmailVerificationToken(db emailVerificationToken) userbus.EmailVerificationToken {
+	return userbus.EmailVerificationToken{
+		ID:        db.ID,
+		UserID:    db.UserID,
+		TokenHash: db.TokenHash,
+		ExpiresAt: db.ExpiresAt.In(time.Local),
+		UsedAt:    fromNullTime(db.UsedAt),
+		CreatedAt: db.CreatedAt.In(time.Local),
+	}
+}
+
+// =============================================================================
+
+type magicLinkToken struct {
+	ID        uuid.UUID    `db:"token_id"`
+	UserID    uuid.UUID    `db:"user_id"`
+	TokenHash []byte       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
+
+func toDBMagicLinkToken(bus userbus.MagicLinkToken) magicLinkToken {
+	return magicLinkToken{
+		ID:        bus.ID,
+		UserID:    bus.UserID,
+		TokenHash: bus.TokenHash,
+		ExpiresAt: bus.ExpiresAt.UTC(),
+		UsedAt:    toNullTime(bus.UsedAt),
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+}
+
+func toBusMagicLinkToken(db magicLinkToken) userbus.MagicLinkToken {
+	return userbus.MagicLinkToken{
+		ID:        db.ID,
+		UserID:    db.UserID,
+		TokenHash: db.TokenHash,
+		ExpiresAt: db.ExpiresAt.In(time.Local),
+		UsedAt:    fromNullTime(db.UsedAt),
+		CreatedAt: db.CreatedAt.In(time.Local),
+	}
+}
+
+// =============================================================================
+
+type userTOTP struct {
+	UserID      uuid.UUID    `db:"user_id"`
+	Secret      string       `db:"secret"`
+	ConfirmedAt sql.NullTime `db:"confirmed_at"`
+	LastStep    int64        `db:"last_step"`
+	CreatedAt   time.Time    `db:"created_at"`
+}
+
+func toDBTOTP(bus userbus.TOTP) userTOTP {
+	return userTOTP{
+		UserID:      bus.UserID,
+		Secret:      bus.Secret,
+		ConfirmedAt: toNullTime(bus.ConfirmedAt),
+		LastStep:    bus.LastStep,
+		CreatedAt:   bus.CreatedAt.UTC(),
+	}
+}
+
+func toBusTOTP(db userTOTP) userbus.TOTP {
+	return userbus.TOTP{
+		UserID:      db.UserID,
+		Secret:      db.Secret,
+		ConfirmedAt: fromNullTime(db.ConfirmedAt),
+		LastStep:    db.LastStep,
+		CreatedAt:   db.CreatedAt.In(time.Local),
+	}
+}
+
+type recoveryCode struct {
+	ID        uuid.UUID    `db:"code_id"`
+	UserID    uuid.UUID    `db:"user_id"`
+	CodeHash  []byte       `db:"code_hash"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
+
+func toDBRecoveryCode(bus userbus.RecoveryCode) recoveryCode {
+	return recoveryCode{
+		ID:        bus.ID,
+		UserID:    bus.UserID,
+		CodeHash:  bus.CodeHash,
+		UsedAt:    toNullTime(bus.UsedAt),
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+}
+
+// =============================================================================
+
+type passkey struct {
+	ID           uuid.UUID      `db:"passkey_id"`
+	UserID       uuid.UUID      `db:"user_id"`
+	CredentialID []byte         `db:"credential_id"`
+	PublicKey    []byte         `db:"public_key"`
+	SignCount    int64          `db:"sign_count"`
+	Transports   dbarray.String `db:"transports"`
+	AAGUID       []byte         `db:"aaguid"`
+	Name         string         `db:"name"`
+	LastUsedAt   sql.NullTime   `db:"last_used_at"`
+	CreatedAt    time.Time      `db:"created_at"`
+}
+
+func toDBPasskey(bus userbus.Passkey) passkey {
+	return passkey{
+		ID:           bus.ID,
+		UserID:       bus.UserID,
+		CredentialID: bus.CredentialID,
+		PublicKey:    bus.PublicKey,
+		SignCount:    int64(bus.SignCount),
+		Transports:   bus.Transports,
+		AAGUID:       bus.AAGUID,
+		Name:         bus.Name,
+		LastUsedAt:   toNullTime(bus.LastUsedAt),
+		CreatedAt:    bus.CreatedAt.UTC(),
+	}
+}
+
+func toBusPasskey(db passkey) userbus.Passkey {
+	return userbus.Passkey{
+		ID:           db.ID,
+		UserID:       db.UserID,
+		CredentialID: db.CredentialID,
+		PublicKey:    db.PublicKey,
+		SignCount:    uint32(db.SignCount),
+		Transports:   db.Transports,
+		AAGUID:       db.AAGUID,
+		Name:         db.Name,
+		LastUsedAt:   fromNullTime(db.LastUsedAt),
+		CreatedAt:    db.CreatedAt.In(time.Local),
+	}
+}
+
+func toBusPasskeys(dbs []passkey) []userbus.Passkey {
+	bus := make([]userbus.Passkey, len(dbs))
+	for i, db := range dbs {
+		bus[i] = toBusPasskey(db)
+	}
+
+	return bus
+}
+
+type webAuthnChallenge struct {
+	ID        uuid.UUID     `db:"challenge_id"`
+	UserID    uuid.NullUUID `db:"user_id"`
+	Purpose   string        `db:"purpose"`
+	Challenge []byte        `db:"challenge"`
+	ExpiresAt time.Time     `db:"expires_at"`
+	UsedAt    sql.NullTime  `db:"used_at"`
+	CreatedAt time.Time     `db:"created_at"`
+}
+
+func toDBWebAuthnChallenge(bus userbus.WebAuthnChallenge) webAuthnChallenge {
+	db := webAuthnChallenge{
+		ID:        bus.ID,
+		Purpose:   bus.Purpose,
+		Challenge: bus.Challenge,
+		ExpiresAt: bus.ExpiresAt.UTC(),
+		UsedAt:    toNullTime(bus.UsedAt),
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+
+	if bus.UserID != uuid.Nil {
+		db.UserID = uuid.NullUUID{UUID: bus.UserID, Valid: true}
+	}
+
+	return db
+}
+
+func toBusWebAuthnChallenge(db webAuthnChallenge) userbus.WebAuthnChallenge {
+	return userbus.WebAuthnChallenge{
+		ID:        db.ID,
+		UserID:    db.UserID.UUID,
+		Purpose:   db.Purpose,
+		Challenge: db.Challenge,
+		ExpiresAt: db.ExpiresAt.In(time.Local),
+		UsedAt:    fromNullTime(db.UsedAt),
+		CreatedAt: db.CreatedAt.In(time.Local),
+	}
+}
+
+// =============================================================================
+
+type identity struct {
+	ID        uuid.UUID `db:"identity_id"`
+	UserID    uuid.UUID `db:"user_id"`
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func toDBIdentity(bus userbus.Identity) identity {
+	return identity{
+		ID:        bus.ID,
+		UserID:    bus.UserID,
+		Provider:  bus.Provider,
+		Subject:   bus.Subject,
+		Email:     bus.Email,
+		CreatedAt: bus.CreatedAt.UTC(),
+	}
+}
+
+func toBusIdentity(db identity) userbus.Identity {
+	return userbus.Identity{
+		ID:        db.ID,
+		UserID:    db.UserID,
+		Provider:  db.Provider,
+		Subject:   db.Subject,
+		Email:     db.Email,
+		CreatedAt: db.CreatedAt.In(time.Local),
+	}
+}
+
+func toBusIdentities(dbs []identity) []userbus.Identity {
+	bus := make([]userbus.Identity, len(dbs))
+	for i, db := range dbs {
+		bus[i] = toBusIdentity(db)
+	}
+
+	return bus
+}
+
+type oidcState struct {
+	ID           uuid.UUID    `db:"state_id"`
+	Provider     string       `db:"provider"`
+	StateHash    []byte       `db:"state_hash"`
+	CodeVerifier string       `db:"code_verifier"`
+	Nonce        string       `db:"nonce"`
+	ExpiresAt    time.Time    `db:"expires_at"`
+	UsedAt       sql.NullTime `db:"used_at"`
+	CreatedAt    time.Time    `db:"created_at"`
+}
+
+func toDBOIDCState(bus userbus.OIDCState) oidcState {
+	return oidcState{
+		ID:           bus.ID,
+		Provider:     bus.Provider,
+		StateHash:    bus.StateHash,
+		CodeVerifier: bus.CodeVerifier,
+		Nonce:        bus.Nonce,
+		ExpiresAt:    bus.ExpiresAt.UTC(),
+		UsedAt:       toNullTime(bus.UsedAt),
+		CreatedAt:    bus.CreatedAt.UTC(),
+	}
+}
+
+func toBusOIDCState(db oidcState) userbus.OIDCState {
+	return userbus.OIDCState{
+		ID:           db.ID,
+		Provider:     db.Provider,
+		StateHash:    db.StateHash,
+		CodeVerifier: db.CodeVerifier,
+		Nonce:        db.Nonce,
+		ExpiresAt:    db.ExpiresAt.In(time.Local),
+		UsedAt:       fromNullTime(db.UsedAt),
+		CreatedAt:    db.CreatedAt.In(time.Local),
+	}
+}
+
+// =============================================================================
+
+func toNullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func fromNullTime(nt sql.NullTime) time.Time {
+	if !nt.Valid {
+		return time.Time{}
+	}
+
+	return nt.Time.In(time.Local)
+}
+
+// =============================================================================
+
+type preferences struct {
+	UserID              uuid.UUID `db:"user_id"`
+	Locale              string    `db:"locale"`
+	TimeZone            string    `db:"time_zone"`
+	NotifyCourseUpdates bool      `db:"notify_course_updates"`
+	NotifyMarketing     bool      `db:"notify_marketing"`
+	UpdatedAt           time.Time `db:"updated_at"`
+}
+
+func toDBPreferences(bus userbus.Preferences) preferences {
+	return preferences{
+		UserID:              bus.UserID,
+		Locale:              bus.Locale.String(),
+		TimeZone:            bus.TimeZone.String(),
+		NotifyCourseUpdates: bus.NotifyCourseUpdates,
+		NotifyMarketing:     bus.NotifyMarketing,
+		UpdatedAt:           bus.UpdatedAt.UTC(),
+	}
+}
+
+func toBusPreferences(db preferences) (userbus.Preferences, error) {
+	lcl, err := locale.Parse(db.Locale)
+	if err != nil {
+		return userbus.Preferences{}, fmt.Errorf("parse locale: %w", err)
+	}
+
+	tz, err := time.LoadLocation(db.TimeZone)
+	if err != nil {
+		return userbus.Preferences{}, fmt.Errorf("load time zone: %w", err)
+	}
+
+	bus := userbus.Preferences{
+		UserID:              db.UserID,
+		Locale:              lcl,
+		TimeZone:            tz,
+		NotifyCourseUpdates: db.NotifyCourseUpdates,
+		NotifyMarketing:     db.NotifyMarketing,
+		UpdatedAt:           db.UpdatedAt.In(time.Local),
+	}
+
+	return bus, nil
+}
@@ -0,0 +1,25 @@
+package userdb
+
+import (
+	"fmt"
+
+	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	userbus.OrderByID:        "user_id",
+	userbus.OrderByName:      "user_name",
+	userbus.OrderByEmail:     "user_email",
+	userbus.OrderByRoles:     "roles",
+	userbus.OrderByCreatedAt: "created_at",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
@@ -2,15 +2,22 @@
 package userdb
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"net/mail"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb/dbarray"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/business/types/role"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
 )
 
@@ -47,11 +54,42 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
 func (s *Store) Create(ctx context.Context, usr userbus.User) error {
 	const q = `
 	INSERT INTO Users
-		(user_id, user_name, user_email, password_hash, roles, created_at)
+		(user_id, tenant_id, user_name, user_email, password_hash, roles, verified_at, created_at)
 	VALUES
-		(:user_id, :user_name, :user_email, :password_hash, :roles, :created_at)`
+		(:user_id, :tenant_id, :user_name, :user_email, :password_hash, :roles, :verified_at, :created_at)`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
+	dbUsr := toDBUser(usr)
+	dbUsr.TenantID = tenant.Get(ctx)
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, dbUsr); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", userbus.ErrUniqueEmail)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a user document in the database.
+func (s *Store) Update(ctx context.Context, usr userbus.User) error {
+	const q = `
+	UPDATE
+		Users
+	SET 
+		user_name = :user_name,
+		user_email = :user_email,
+		password_hash = :password_hash,
+		roles = :roles,
+		verified_at = :verified_at
+	WHERE
+		user_id = :user_id AND
+		tenant_id = :tenant_id`
+
+	dbUsr := toDBUser(usr)
+	dbUsr.TenantID = tenant.Get(ctx)
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, dbUsr); err != nil {
 		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
 			return fmt.Errorf("namedexeccontext: %w", userbus.ErrUniqueEmail)
 		}
@@ -61,20 +99,78 @@ func (s *Store) Create(ctx context.Context, usr userbus.User) error {
 	return nil
 }
 
+// Query retrieves a list of existing users from the database.
+func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		user_id, tenant_id, user_name, user_email, password_hash, roles, verified_at, created_at
+	FROM
+		Users`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(ctx, filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbUsrs []user
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbUsrs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusUsers(dbUsrs)
+}
+
+// Count returns the total number of users in the DB.
+func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		Users`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(ctx, filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
 func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
 	data := struct {
-		ID string `db:"user_id"`
+		ID       string `db:"user_id"`
+		TenantID string `db:"tenant_id"`
 	}{
-		ID: userID.String(),
+		ID:       userID.String(),
+		TenantID: tenant.Get(ctx).String(),
 	}
 
 	const q = `
 	SELECT
-        user_id, user_name, user_email, password_hash, roles, created_at
+        user_id, tenant_id, user_name, user_email, password_hash, roles, verified_at, created_at
 	FROM
 		Users
 	WHERE 
-		user_id = :user_id`
+		user_id = :user_id AND
+		tenant_id = :tenant_id`
 
 	var dbUsr user
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
@@ -87,21 +183,55 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User,
 	return toBusUser(dbUsr)
 }
 
+// QueryTenantID returns the organization the user belongs to. Unlike the
+// other queries it isn't scoped to the tenant of the request.
+func (s *Store) QueryTenantID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	data := struct {
+		ID string `db:"user_id"`
+	}{
+		ID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		tenant_id
+	FROM
+		Users
+	WHERE
+		user_id = :user_id`
+
+	var dest struct {
+		TenantID uuid.UUID `db:"tenant_id"`
+	}
+
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return uuid.Nil, fmt.Errorf("db: %w", userbus.ErrNotFound)
+		}
+		return uuid.Nil, fmt.Errorf("db: %w", err)
+	}
+
+	return dest.TenantID, nil
+}
+
 // QueryByEmail gets the specified user from the database by email.
 func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
 	data := struct {
-		Email string `db:"user_email"`
+		Email    string `db:"user_email"`
+		TenantID string `db:"tenant_id"`
 	}{
-		Email: email.Address,
+		Email:    email.Address,
+		TenantID: tenant.Get(ctx).String(),
 	}
 
 	const q = `
 	SELECT
-        user_id, user_name, user_email, password_hash, roles, created_at,
+        user_id, tenant_id, user_name, user_email, password_hash, roles, verified_at, created_at
 	FROM
 		Users
 	WHERE
-		user_email = :user_email`
+		user_email = :user_email AND
+		tenant_id = :tenant_id`
 
 	var dbUsr user
 	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
@@ -113,3 +243,1207 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.U
 
 	return toBusUser(dbUsr)
 }
+
+// =============================================================================
+
+// CreateRefreshToken inserts a new refresh token into the database.
+func (s *Store) CreateRefreshToken(ctx context.Context, rt userbus.RefreshToken) error {
+	const q = `
+	INSERT INTO RefreshTokens
+		(token_id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at)
+	VALUES
+		(:token_id, :user_id, :family_id, :token_hash, :expires_at, :used_at, :revoked_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRefreshToken(rt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryRefreshTokenByHash gets the refresh token with the specified hash.
+func (s *Store) QueryRefreshTokenByHash(ctx context.Context, hash []byte) (userbus.RefreshToken, error) {
+	data := struct {
+		TokenHash []byte `db:"token_hash"`
+	}{
+		TokenHash: hash,
+	}
+
+	const q = `
+	SELECT
+		token_id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+	FROM
+		RefreshTokens
+	WHERE
+		token_hash = :token_hash`
+
+	var dbRT refreshToken
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRT); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.RefreshToken{}, fmt.Errorf("db: %w", userbus.ErrInvalidToken)
+		}
+		return userbus.RefreshToken{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusRefreshToken(dbRT), nil
+}
+
+// MarkRefreshTokenUsed records that the refresh token has been exchanged. The
+// update only succeeds once per token so concurrent exchanges are detected.
+func (s *Store) MarkRefreshTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	data := struct {
+		ID     uuid.UUID `db:"token_id"`
+		UsedAt time.Time `db:"used_at"`
+	}{
+		ID:     tokenID,
+		UsedAt: usedAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		RefreshTokens
+	SET
+		used_at = :used_at
+	WHERE
+		token_id = :token_id AND used_at IS NULL
+	RETURNING
+		token_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"token_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrTokenReused)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every token in the specified family.
+func (s *Store) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, revokedAt time.Time) error {
+	data := struct {
+		FamilyID  uuid.UUID `db:"family_id"`
+		RevokedAt time.Time `db:"revoked_at"`
+	}{
+		FamilyID:  familyID,
+		RevokedAt: revokedAt.UTC(),
+	}
+
+	const q = `
+	WITH revoked_sessions AS (
+		UPDATE
+			Sessions
+		SET
+			revoked_at = :revoked_at
+		WHERE
+			session_id = :family_id AND revoked_at IS NULL
+	)
+	UPDATE
+		RefreshTokens
+	SET
+		revoked_at = :revoked_at
+	WHERE
+		family_id = :family_id AND revoked_at IS NULL`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every refresh token issued to the user and
+// ends their sessions. The ids of the revoked sessions are returned.
+func (s *Store) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error) {
+	data := struct {
+		UserID    uuid.UUID `db:"user_id"`
+		RevokedAt time.Time `db:"revoked_at"`
+	}{
+		UserID:    userID,
+		RevokedAt: revokedAt.UTC(),
+	}
+
+	const q = `
+	WITH revoked_tokens AS (
+		UPDATE
+			RefreshTokens
+		SET
+			revoked_at = :revoked_at
+		WHERE
+			user_id = :user_id AND revoked_at IS NULL
+	)
+	UPDATE
+		Sessions
+	SET
+		revoked_at = :revoked_at
+	WHERE
+		user_id = :user_id AND revoked_at IS NULL
+	RETURNING
+		session_id`
+
+	var dest []struct {
+		ID uuid.UUID `db:"session_id"`
+	}
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dest); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(dest))
+	for i, d := range dest {
+		ids[i] = d.ID
+	}
+
+	return ids, nil
+}
+
+// CreateSession inserts a new session into the database.
+func (s *Store) CreateSession(ctx context.Context, sess userbus.Session) error {
+	const q = `
+	INSERT INTO Sessions
+		(session_id, user_id, device, ip_address, user_agent, expires_at, created_at, last_seen_at, revoked_at)
+	VALUES
+		(:session_id, :user_id, :device, :ip_address, :user_agent, :expires_at, :created_at, :last_seen_at, :revoked_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSession(sess)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSession records the client a session was last used from and moves
+// its expiry to that of the latest refresh token.
+func (s *Store) UpdateSession(ctx context.Context, sess userbus.Session) error {
+	const q = `
+	UPDATE
+		Sessions
+	SET
+		device = :device,
+		ip_address = :ip_address,
+		user_agent = :user_agent,
+		expires_at = :expires_at,
+		last_seen_at = :last_seen_at
+	WHERE
+		session_id = :session_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBSession(sess)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// TouchSession updates the last seen time of a session that is still active.
+func (s *Store) TouchSession(ctx context.Context, sessionID uuid.UUID, seenAt time.Time) error {
+	data := struct {
+		ID     uuid.UUID `db:"session_id"`
+		SeenAt time.Time `db:"last_seen_at"`
+	}{
+		ID:     sessionID,
+		SeenAt: seenAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		Sessions
+	SET
+		last_seen_at = :last_seen_at
+	WHERE
+		session_id = :session_id AND revoked_at IS NULL AND expires_at > :last_seen_at
+	RETURNING
+		session_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"session_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrSessionNotFound)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// QuerySessionsByUser gets the sessions of the user that have not been
+// revoked or expired.
+func (s *Store) QuerySessionsByUser(ctx context.Context, userID uuid.UUID, now time.Time) ([]userbus.Session, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+		Now    time.Time `db:"now"`
+	}{
+		UserID: userID,
+		Now:    now.UTC(),
+	}
+
+	const q = `
+	SELECT
+		session_id, user_id, device, ip_address, user_agent, expires_at, created_at, last_seen_at, revoked_at
+	FROM
+		Sessions
+	WHERE
+		user_id = :user_id AND revoked_at IS NULL AND expires_at > :now
+	ORDER BY
+		last_seen_at DESC`
+
+	var dbSessions []session
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbSessions); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusSessions(dbSessions), nil
+}
+
+// RevokeSession revokes a session of the user along with its refresh tokens.
+func (s *Store) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, revokedAt time.Time) error {
+	data := struct {
+		UserID    uuid.UUID `db:"user_id"`
+		SessionID uuid.UUID `db:"session_id"`
+		RevokedAt time.Time `db:"revoked_at"`
+	}{
+		UserID:    userID,
+		SessionID: sessionID,
+		RevokedAt: revokedAt.UTC(),
+	}
+
+	const q = `
+	WITH revoked_tokens AS (
+		UPDATE
+			RefreshTokens
+		SET
+			revoked_at = :revoked_at
+		WHERE
+			family_id = :session_id AND user_id = :user_id AND revoked_at IS NULL
+	)
+	UPDATE
+		Sessions
+	SET
+		revoked_at = :revoked_at
+	WHERE
+		session_id = :session_id AND user_id = :user_id AND revoked_at IS NULL
+	RETURNING
+		session_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"session_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrSessionNotFound)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions revokes every session of the user except the one
+// specified, along with their refresh tokens.
+func (s *Store) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, keepID uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error) {
+	data := struct {
+		UserID    uuid.UUID `db:"user_id"`
+		KeepID    uuid.UUID `db:"keep_id"`
+		RevokedAt time.Time `db:"revoked_at"`
+	}{
+		UserID:    userID,
+		KeepID:    keepID,
+		RevokedAt: revokedAt.UTC(),
+	}
+
+	const q = `
+	WITH revoked_tokens AS (
+		UPDATE
+			RefreshTokens
+		SET
+			revoked_at = :revoked_at
+		WHERE
+			user_id = :user_id AND family_id <> :keep_id AND revoked_at IS NULL
+	)
+	UPDATE
+		Sessions
+	SET
+		revoked_at = :revoked_at
+	WHERE
+		user_id = :user_id AND session_id <> :keep_id AND revoked_at IS NULL
+	RETURNING
+		session_id`
+
+	var dest []struct {
+		ID uuid.UUID `db:"session_id"`
+	}
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dest); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(dest))
+	for i, d := range dest {
+		ids[i] = d.ID
+	}
+
+	return ids, nil
+}
+
+// =============================================================================
+
+// CreatePasswordResetToken inserts a new password reset token into the database.
+func (s *Store) CreatePasswordResetToken(ctx context.Context, prt userbus.PasswordResetToken) error {
+	const q = `
+	INSERT INTO PasswordResetTokens
+		(token_id, user_id, token_hash, expires_at, used_at, created_at)
+	VALUES
+		(:token_id, :user_id, :token_hash, :expires_at, :used_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBPasswordResetToken(prt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryPasswordResetTokenByHash gets the unused password reset token with the
+// specified hash.
+func (s *Store) QueryPasswordResetTokenByHash(ctx context.Context, hash []byte) (userbus.PasswordResetToken, error) {
+	data := struct {
+		TokenHash []byte `db:"token_hash"`
+	}{
+		TokenHash: hash,
+	}
+
+	const q = `
+	SELECT
+		token_id, user_id, token_hash, expires_at, used_at, created_at
+	FROM
+		PasswordResetTokens
+	WHERE
+		token_hash = :token_hash AND used_at IS NULL`
+
+	var dbPRT passwordResetToken
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPRT); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.PasswordResetToken{}, fmt.Errorf("db: %w", userbus.ErrInvalidToken)
+		}
+		return userbus.PasswordResetToken{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusPasswordResetToken(dbPRT), nil
+}
+
+// MarkPasswordResetTokenUsed records that the password reset token has been
+// used. The update only succeeds once per token.
+func (s *Store) MarkPasswordResetTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	data := struct {
+		ID     uuid.UUID `db:"token_id"`
+		UsedAt time.Time `db:"used_at"`
+	}{
+		ID:     tokenID,
+		UsedAt: usedAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		PasswordResetTokens
+	SET
+		used_at = :used_at
+	WHERE
+		token_id = :token_id AND used_at IS NULL
+	RETURNING
+		token_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"token_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrInvalidToken)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// CreateEmailVerificationToken inserts a new email verification token into
+// the database.
+func (s *Store) CreateEmailVerificationToken(ctx context.Context, evt userbus.EmailVerificationToken) error {
+	const q = `
+	INSERT INTO EmailVerificationTokens
+		(token_id, user_id, token_hash, expires_at, used_at, created_at)
+	VALUES
+		(:token_id, :user_id, :token_hash, :expires_at, :used_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBEmailVerificationToken(evt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryEmailVerificationTokenByHash gets the unused email verification token
+// with the specified hash.
+func (s *Store) QueryEmailVerificationTokenByHash(ctx context.Context, hash []byte) (userbus.EmailVerificationToken, error) {
+	data := struct {
+		TokenHash []byte `db:"token_hash"`
+	}{
+		TokenHash: hash,
+	}
+
+	const q = `
+	SELECT
+		token_id, user_id, token_hash, expires_at, used_at, created_at
+	FROM
+		EmailVerificationTokens
+	WHERE
+		token_hash = :token_hash AND used_at IS NULL`
+
+	var dbEVT emailVerificationToken
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbEVT); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.EmailVerificationToken{}, fmt.Errorf("db: %w", userbus.ErrInvalidToken)
+		}
+		return userbus.EmailVerificationToken{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusEmailVerificationToken(dbEVT), nil
+}
+
+// MarkEmailVerificationTokenUsed records that the email verification token
+// has been used. The update only succeeds once per token.
+func (s *Store) MarkEmailVerificationTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	data := struct {
+		ID     uuid.UUID `db:"token_id"`
+		UsedAt time.Time `db:"used_at"`
+	}{
+		ID:     tokenID,
+		UsedAt: usedAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		EmailVerificationTokens
+	SET
+		used_at = :used_at
+	WHERE
+		token_id = :token_id AND used_at IS NULL
+	RETURNING
+		token_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"token_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrInvalidToken)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// CreateMagicLinkToken inserts a new sign in token into the database.
+func (s *Store) CreateMagicLinkToken(ctx context.Context, mlt userbus.MagicLinkToken) error {
+	const q = `
+	INSERT INTO MagicLinkTokens
+		(token_id, user_id, token_hash, expires_at, used_at, created_at)
+	VALUES
+		(:token_id, :user_id, :token_hash, :expires_at, :used_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBMagicLinkToken(mlt)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// CountMagicLinkTokens returns the number of sign in tokens issued to the
+// user since the specified time.
+func (s *Store) CountMagicLinkTokens(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+		Since  time.Time `db:"since"`
+	}{
+		UserID: userID,
+		Since:  since.UTC(),
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		MagicLinkTokens
+	WHERE
+		user_id = :user_id AND created_at >= :since`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryMagicLinkTokenByHash gets the unused sign in token with the specified
+// hash.
+func (s *Store) QueryMagicLinkTokenByHash(ctx context.Context, hash []byte) (userbus.MagicLinkToken, error) {
+	data := struct {
+		TokenHash []byte `db:"token_hash"`
+	}{
+		TokenHash: hash,
+	}
+
+	const q = `
+	SELECT
+		token_id, user_id, token_hash, expires_at, used_at, created_at
+	FROM
+		MagicLinkTokens
+	WHERE
+		token_hash = :token_hash AND used_at IS NULL`
+
+	var dbMLT magicLinkToken
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbMLT); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.MagicLinkToken{}, fmt.Errorf("db: %w", userbus.ErrInvalidToken)
+		}
+		return userbus.MagicLinkToken{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusMagicLinkToken(dbMLT), nil
+}
+
+// MarkMagicLinkTokenUsed records that the sign in token has been used. The
+// update only succeeds once per token.
+func (s *Store) MarkMagicLinkTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	data := struct {
+		ID     uuid.UUID `db:"token_id"`
+		UsedAt time.Time `db:"used_at"`
+	}{
+		ID:     tokenID,
+		UsedAt: usedAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		MagicLinkTokens
+	SET
+		used_at = :used_at
+	WHERE
+		token_id = :token_id AND used_at IS NULL
+	RETURNING
+		token_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"token_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrInvalidToken)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertTOTP inserts or replaces the TOTP enrollment of a user.
+func (s *Store) UpsertTOTP(ctx context.Context, t userbus.TOTP) error {
+	const q = `
+	INSERT INTO UserTOTP
+		(user_id, secret, confirmed_at, last_step, created_at)
+	VALUES
+		(:user_id, :secret, :confirmed_at, :last_step, :created_at)
+	ON CONFLICT (user_id) DO UPDATE SET
+		secret = EXCLUDED.secret,
+		confirmed_at = EXCLUDED.confirmed_at,
+		last_step = EXCLUDED.last_step,
+		created_at = EXCLUDED.created_at`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBTOTP(t)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryTOTP gets the TOTP enrollment of a user.
+func (s *Store) QueryTOTP(ctx context.Context, userID uuid.UUID) (userbus.TOTP, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		user_id, secret, confirmed_at, last_step, created_at
+	FROM
+		UserTOTP
+	WHERE
+		user_id = :user_id`
+
+	var dbTOTP userTOTP
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbTOTP); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.TOTP{}, fmt.Errorf("db: %w", userbus.ErrTOTPNotEnabled)
+		}
+		return userbus.TOTP{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusTOTP(dbTOTP), nil
+}
+
+// DeleteTOTP removes the TOTP enrollment and recovery codes of a user.
+func (s *Store) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	WITH codes AS (
+		DELETE FROM RecoveryCodes WHERE user_id = :user_id
+	)
+	DELETE FROM
+		UserTOTP
+	WHERE
+		user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTOTPLastStep records the time step of the last accepted code. The
+// update fails when the step is not newer than the last one, which stops a
+// code from being replayed.
+func (s *Store) UpdateTOTPLastStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	data := struct {
+		UserID   uuid.UUID `db:"user_id"`
+		LastStep int64     `db:"last_step"`
+	}{
+		UserID:   userID,
+		LastStep: step,
+	}
+
+	const q = `
+	UPDATE
+		UserTOTP
+	SET
+		last_step = :last_step
+	WHERE
+		user_id = :user_id AND last_step < :last_step
+	RETURNING
+		user_id`
+
+	var dest struct {
+		UserID uuid.UUID `db:"user_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrInvalidCode)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes removes the existing recovery codes of a user and
+// stores the new set.
+func (s *Store) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []userbus.RecoveryCode) error {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const del = `
+	DELETE FROM
+		RecoveryCodes
+	WHERE
+		user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, del, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	const ins = `
+	INSERT INTO RecoveryCodes
+		(code_id, user_id, code_hash, used_at, created_at)
+	VALUES
+		(:code_id, :user_id, :code_hash, :used_at, :created_at)`
+
+	for _, rc := range codes {
+		if err := sqldb.NamedExecContext(ctx, s.log, s.db, ins, toDBRecoveryCode(rc)); err != nil {
+			return fmt.Errorf("namedexeccontext: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// MarkRecoveryCodeUsed records that the recovery code with the specified
+// hash has been used. The update only succeeds once per code.
+func (s *Store) MarkRecoveryCodeUsed(ctx context.Context, userID uuid.UUID, hash []byte, usedAt time.Time) error {
+	data := struct {
+		UserID   uuid.UUID `db:"user_id"`
+		CodeHash []byte    `db:"code_hash"`
+		UsedAt   time.Time `db:"used_at"`
+	}{
+		UserID:   userID,
+		CodeHash: hash,
+		UsedAt:   usedAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		RecoveryCodes
+	SET
+		used_at = :used_at
+	WHERE
+		user_id = :user_id AND code_hash = :code_hash AND used_at IS NULL
+	RETURNING
+		code_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"code_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrInvalidCode)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// QueryTwoFactorRoles gets the roles that must use two-factor
+// authentication.
+func (s *Store) QueryTwoFactorRoles(ctx context.Context) ([]role.Role, error) {
+	const q = `
+	SELECT
+		two_factor_roles
+	FROM
+		SecurityPolicy
+	WHERE
+		policy_id = 1`
+
+	var dest struct {
+		Roles dbarray.String `db:"two_factor_roles"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, struct{}{}, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("db: %w", err)
+	}
+
+	roles, err := role.ParseMany(dest.Roles)
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+
+	return roles, nil
+}
+
+// UpdateTwoFactorRoles replaces the roles that must use two-factor
+// authentication.
+func (s *Store) UpdateTwoFactorRoles(ctx context.Context, roles []role.Role) error {
+	data := struct {
+		Roles dbarray.String `db:"two_factor_roles"`
+	}{
+		Roles: dbarray.String(role.ParseToString(roles)),
+	}
+
+	const q = `
+	INSERT INTO SecurityPolicy
+		(policy_id, two_factor_roles)
+	VALUES
+		(1, :two_factor_roles)
+	ON CONFLICT (policy_id) DO UPDATE SET
+		two_factor_roles = EXCLUDED.two_factor_roles`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// CreatePasskey inserts a new passkey into the database.
+func (s *Store) CreatePasskey(ctx context.Context, pk userbus.Passkey) error {
+	const q = `
+	INSERT INTO Passkeys
+		(passkey_id, user_id, credential_id, public_key, sign_count, transports, aaguid, name, last_used_at, created_at)
+	VALUES
+		(:passkey_id, :user_id, :credential_id, :public_key, :sign_count, :transports, :aaguid, :name, :last_used_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBPasskey(pk)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("namedexeccontext: %w", userbus.ErrPasskeyExists)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryPasskeyByCredentialID gets the passkey with the specified credential
+// id.
+func (s *Store) QueryPasskeyByCredentialID(ctx context.Context, credentialID []byte) (userbus.Passkey, error) {
+	data := struct {
+		CredentialID []byte `db:"credential_id"`
+	}{
+		CredentialID: credentialID,
+	}
+
+	const q = `
+	SELECT
+		passkey_id, user_id, credential_id, public_key, sign_count, transports, aaguid, name, last_used_at, created_at
+	FROM
+		Passkeys
+	WHERE
+		credential_id = :credential_id`
+
+	var dbPK passkey
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPK); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.Passkey{}, fmt.Errorf("db: %w", userbus.ErrPasskeyNotFound)
+		}
+		return userbus.Passkey{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusPasskey(dbPK), nil
+}
+
+// QueryPasskeysByUser gets the passkeys registered by the user.
+func (s *Store) QueryPasskeysByUser(ctx context.Context, userID uuid.UUID) ([]userbus.Passkey, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		passkey_id, user_id, credential_id, public_key, sign_count, transports, aaguid, name, last_used_at, created_at
+	FROM
+		Passkeys
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		created_at`
+
+	var dbPKs []passkey
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbPKs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusPasskeys(dbPKs), nil
+}
+
+// UpdatePasskeyUsage records the signature counter and time of the last use
+// of a passkey.
+func (s *Store) UpdatePasskeyUsage(ctx context.Context, pk userbus.Passkey) error {
+	const q = `
+	UPDATE
+		Passkeys
+	SET
+		sign_count = :sign_count,
+		last_used_at = :last_used_at
+	WHERE
+		passkey_id = :passkey_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBPasskey(pk)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePasskey removes a passkey registered by the user.
+func (s *Store) DeletePasskey(ctx context.Context, userID uuid.UUID, passkeyID uuid.UUID) error {
+	data := struct {
+		ID     uuid.UUID `db:"passkey_id"`
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		ID:     passkeyID,
+		UserID: userID,
+	}
+
+	const q = `
+	DELETE FROM
+		Passkeys
+	WHERE
+		passkey_id = :passkey_id AND user_id = :user_id
+	RETURNING
+		passkey_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"passkey_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrPasskeyNotFound)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// CreateWebAuthnChallenge records a challenge issued for a WebAuthn
+// ceremony.
+func (s *Store) CreateWebAuthnChallenge(ctx context.Context, wc userbus.WebAuthnChallenge) error {
+	const q = `
+	INSERT INTO WebAuthnChallenges
+		(challenge_id, user_id, purpose, challenge, expires_at, used_at, created_at)
+	VALUES
+		(:challenge_id, :user_id, :purpose, :challenge, :expires_at, :used_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBWebAuthnChallenge(wc)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeWebAuthnChallenge marks the challenge as used and returns it. The
+// update only succeeds once per challenge.
+func (s *Store) ConsumeWebAuthnChallenge(ctx context.Context, challengeID uuid.UUID, usedAt time.Time) (userbus.WebAuthnChallenge, error) {
+	data := struct {
+		ID     uuid.UUID `db:"challenge_id"`
+		UsedAt time.Time `db:"used_at"`
+	}{
+		ID:     challengeID,
+		UsedAt: usedAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		WebAuthnChallenges
+	SET
+		used_at = :used_at
+	WHERE
+		challenge_id = :challenge_id AND used_at IS NULL
+	RETURNING
+		challenge_id, user_id, purpose, challenge, expires_at, used_at, created_at`
+
+	var dbWC webAuthnChallenge
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbWC); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.WebAuthnChallenge{}, fmt.Errorf("db: %w", userbus.ErrInvalidToken)
+		}
+		return userbus.WebAuthnChallenge{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusWebAuthnChallenge(dbWC), nil
+}
+
+// CreateIdentity links an external identity to a user.
+func (s *Store) CreateIdentity(ctx context.Context, idn userbus.Identity) error {
+	const q = `
+	INSERT INTO UserIdentities
+		(identity_id, user_id, provider, subject, email, created_at)
+	VALUES
+		(:identity_id, :user_id, :provider, :subject, :email, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBIdentity(idn)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryIdentity gets the identity with the specified provider and subject.
+func (s *Store) QueryIdentity(ctx context.Context, provider string, subject string) (userbus.Identity, error) {
+	data := struct {
+		Provider string `db:"provider"`
+		Subject  string `db:"subject"`
+	}{
+		Provider: provider,
+		Subject:  subject,
+	}
+
+	const q = `
+	SELECT
+		identity_id, user_id, provider, subject, email, created_at
+	FROM
+		UserIdentities
+	WHERE
+		provider = :provider AND subject = :subject`
+
+	var dbIdn identity
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbIdn); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.Identity{}, fmt.Errorf("db: %w", userbus.ErrIdentityNotFound)
+		}
+		return userbus.Identity{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusIdentity(dbIdn), nil
+}
+
+// QueryIdentitiesByUser gets the identities linked to the user.
+func (s *Store) QueryIdentitiesByUser(ctx context.Context, userID uuid.UUID) ([]userbus.Identity, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		identity_id, user_id, provider, subject, email, created_at
+	FROM
+		UserIdentities
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		created_at`
+
+	var dbIdns []identity
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbIdns); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusIdentities(dbIdns), nil
+}
+
+// DeleteIdentity removes an identity linked to the user.
+func (s *Store) DeleteIdentity(ctx context.Context, userID uuid.UUID, identityID uuid.UUID) error {
+	data := struct {
+		ID     uuid.UUID `db:"identity_id"`
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		ID:     identityID,
+		UserID: userID,
+	}
+
+	const q = `
+	DELETE FROM
+		UserIdentities
+	WHERE
+		identity_id = :identity_id AND user_id = :user_id
+	RETURNING
+		identity_id`
+
+	var dest struct {
+		ID uuid.UUID `db:"identity_id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", userbus.ErrIdentityNotFound)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// CreateOIDCState records a sign in started with an external provider.
+func (s *Store) CreateOIDCState(ctx context.Context, st userbus.OIDCState) error {
+	const q = `
+	INSERT INTO OIDCStates
+		(state_id, provider, state_hash, code_verifier, nonce, expires_at, used_at, created_at)
+	VALUES
+		(:state_id, :provider, :state_hash, :code_verifier, :nonce, :expires_at, :used_at, :created_at)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBOIDCState(st)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeOIDCState marks the state with the specified hash as used and
+// returns it. The update only succeeds once per state.
+func (s *Store) ConsumeOIDCState(ctx context.Context, hash []byte, usedAt time.Time) (userbus.OIDCState, error) {
+	data := struct {
+		StateHash []byte    `db:"state_hash"`
+		UsedAt    time.Time `db:"used_at"`
+	}{
+		StateHash: hash,
+		UsedAt:    usedAt.UTC(),
+	}
+
+	const q = `
+	UPDATE
+		OIDCStates
+	SET
+		used_at = :used_at
+	WHERE
+		state_hash = :state_hash AND used_at IS NULL
+	RETURNING
+		state_id, provider, state_hash, code_verifier, nonce, expires_at, used_at, created_at`
+
+	var dbSt oidcState
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbSt); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.OIDCState{}, fmt.Errorf("db: %w", userbus.ErrInvalidToken)
+		}
+		return userbus.OIDCState{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusOIDCState(dbSt), nil
+}
+
+// QueryPreferences gets the preferences of a user, falling back to the
+// defaults for a user who has not set any.
+func (s *Store) QueryPreferences(ctx context.Context, userID uuid.UUID) (userbus.Preferences, error) {
+	data := struct {
+		UserID uuid.UUID `db:"user_id"`
+	}{
+		UserID: userID,
+	}
+
+	const q = `
+	SELECT
+		user_id, locale, time_zone, notify_course_updates, notify_marketing, updated_at
+	FROM
+		UserPreferences
+	WHERE
+		user_id = :user_id`
+
+	var dbPrefs preferences
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPrefs); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.DefaultPreferences(userID), nil
+		}
+		return userbus.Preferences{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusPreferences(dbPrefs)
+}
+
+// UpsertPreferences stores the preferences of a user.
+func (s *Store) UpsertPreferences(ctx context.Context, prefs userbus.Preferences) error {
+	const q = `
+	INSERT INTO UserPreferences
+		(user_id, locale, time_zone, notify_course_updates, notify_marketing, updated_at)
+	VALUES
+		(:user_id, :locale, :time_zone, :notify_course_updates, :notify_marketing, :updated_at)
+	ON CONFLICT (user_id) DO UPDATE SET
+		locale = EXCLUDED.locale,
+		time_zone = EXCLUDED.time_zone,
+		notify_course_updates = EXCLUDED.notify_course_updates,
+		notify_marketing = EXCLUDED.notify_marketing,
+		updated_at = EXCLUDED.updated_at`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBPreferences(prefs)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
@@ -2,16 +2,28 @@
 package userbus
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/mail"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/order"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/page"
 	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/business/types/role"
 	"github.com/kamogelosekhukhune777/lms/foundation/logger"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/kamogelosekhukhune777/lms/foundation/password"
+	"github.com/kamogelosekhukhune777/lms/foundation/totp"
+	"github.com/kamogelosekhukhune777/lms/foundation/webauthn"
 )
 
 // Set of error variables for CRUD operations.
@@ -19,6 +31,21 @@ var (
 	ErrNotFound              = errors.New("user not found")
 	ErrUniqueEmail           = errors.New("email is not unique")
 	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrInvalidToken          = errors.New("token is not valid")
+	ErrTokenExpired          = errors.New("token has expired")
+	ErrTokenReused           = errors.New("token has already been used")
+	ErrEmailNotVerified      = errors.New("email address has not been verified")
+	ErrAlreadyVerified       = errors.New("email address is already verified")
+	ErrTOTPNotEnabled        = errors.New("two-factor authentication is not enabled")
+	ErrTOTPAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrInvalidCode           = errors.New("code is not valid")
+	ErrTwoFactorRequired     = errors.New("two-factor authentication is required for this account")
+	ErrIdentityNotFound      = errors.New("identity not found")
+	ErrIdentityNotVerified   = errors.New("email address has not been verified by the identity provider")
+	ErrTooManyLinks          = errors.New("too many sign in links requested")
+	ErrPasskeyNotFound       = errors.New("passkey not found")
+	ErrPasskeyExists         = errors.New("passkey is already registered")
+	ErrSessionNotFound       = errors.New("session not found")
 )
 
 // Storer interface declares the behavior this package needs to persist and
@@ -26,21 +53,76 @@ var (
 type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
 	Create(ctx context.Context, usr User) error
+	Update(ctx context.Context, usr User) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]User, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, userID uuid.UUID) (User, error)
 	QueryByEmail(ctx context.Context, email mail.Address) (User, error)
+	QueryTenantID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error)
+	CreateRefreshToken(ctx context.Context, rt RefreshToken) error
+	QueryRefreshTokenByHash(ctx context.Context, hash []byte) (RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, revokedAt time.Time) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error)
+	CreateSession(ctx context.Context, sess Session) error
+	UpdateSession(ctx context.Context, sess Session) error
+	TouchSession(ctx context.Context, sessionID uuid.UUID, seenAt time.Time) error
+	QuerySessionsByUser(ctx context.Context, userID uuid.UUID, now time.Time) ([]Session, error)
+	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, revokedAt time.Time) error
+	RevokeOtherSessions(ctx context.Context, userID uuid.UUID, keepID uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error)
+	CreatePasswordResetToken(ctx context.Context, prt PasswordResetToken) error
+	QueryPasswordResetTokenByHash(ctx context.Context, hash []byte) (PasswordResetToken, error)
+	MarkPasswordResetTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error
+	CreateEmailVerificationToken(ctx context.Context, evt EmailVerificationToken) error
+	QueryEmailVerificationTokenByHash(ctx context.Context, hash []byte) (EmailVerificationToken, error)
+	MarkEmailVerificationTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error
+	UpsertTOTP(ctx context.Context, t TOTP) error
+	QueryTOTP(ctx context.Context, userID uuid.UUID) (TOTP, error)
+	DeleteTOTP(ctx context.Context, userID uuid.UUID) error
+	UpdateTOTPLastStep(ctx context.Context, userID uuid.UUID, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []RecoveryCode) error
+	MarkRecoveryCodeUsed(ctx context.Context, userID uuid.UUID, hash []byte, usedAt time.Time) error
+	QueryTwoFactorRoles(ctx context.Context) ([]role.Role, error)
+	UpdateTwoFactorRoles(ctx context.Context, roles []role.Role) error
+	CreateIdentity(ctx context.Context, idn Identity) error
+	QueryIdentity(ctx context.Context, provider string, subject string) (Identity, error)
+	QueryIdentitiesByUser(ctx context.Context, userID uuid.UUID) ([]Identity, error)
+	DeleteIdentity(ctx context.Context, userID uuid.UUID, identityID uuid.UUID) error
+	CreateMagicLinkToken(ctx context.Context, mlt MagicLinkToken) error
+	CountMagicLinkTokens(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+	QueryMagicLinkTokenByHash(ctx context.Context, hash []byte) (MagicLinkToken, error)
+	MarkMagicLinkTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error
+	CreatePasskey(ctx context.Context, pk Passkey) error
+	QueryPasskeyByCredentialID(ctx context.Context, credentialID []byte) (Passkey, error)
+	QueryPasskeysByUser(ctx context.Context, userID uuid.UUID) ([]Passkey, error)
+	UpdatePasskeyUsage(ctx context.Context, pk Passkey) error
+	DeletePasskey(ctx context.Context, userID uuid.UUID, passkeyID uuid.UUID) error
+	CreateWebAuthnChallenge(ctx context.Context, wc WebAuthnChallenge) error
+	ConsumeWebAuthnChallenge(ctx context.Context, challengeID uuid.UUID, usedAt time.Time) (WebAuthnChallenge, error)
+	CreateOIDCState(ctx context.Context, st OIDCState) error
+	ConsumeOIDCState(ctx context.Context, hash []byte, usedAt time.Time) (OIDCState, error)
+	QueryPreferences(ctx context.Context, userID uuid.UUID) (Preferences, error)
+	UpsertPreferences(ctx context.Context, prefs Preferences) error
 }
 
 // Business manages the set of APIs for user access.
 type Business struct {
-	log    *logger.Logger
-	storer Storer
+	log       *logger.Logger
+	storer    Storer
+	hasher    *password.Hasher
+	policy    password.Policy
+	dummyHash func() []byte
 }
 
-// NewBusiness constructs a user business API for use.
-func NewBusiness(log *logger.Logger, storer Storer) *Business {
+// NewBusiness constructs a user business API for use. Passwords are hashed
+// with the hasher and must satisfy the policy.
+func NewBusiness(log *logger.Logger, storer Storer, hasher *password.Hasher, policy password.Policy) *Business {
 	return &Business{
-		log:    log,
-		storer: storer,
+		log:       log,
+		storer:    storer,
+		hasher:    hasher,
+		policy:    policy,
+		dummyHash: newDummyHash(hasher),
 	}
 }
 
@@ -53,8 +135,11 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 	}
 
 	bus := Business{
-		log:    b.log,
-		storer: storer,
+		log:       b.log,
+		storer:    storer,
+		hasher:    b.hasher,
+		policy:    b.policy,
+		dummyHash: b.dummyHash,
 	}
 
 	return &bus, nil
@@ -62,16 +147,20 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 
 // Create adds a new user to the system.
 func (b *Business) Create(ctx context.Context, nu NewUser) (User, error) {
+	if err := b.policy.Check(nu.PasswordHash, nu.UserName.String(), nu.UserEmail.Address); err != nil {
+		return User{}, fmt.Errorf("policy: %w", err)
+	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(nu.PasswordHash), bcrypt.DefaultCost)
+	hash, err := b.hasher.Hash(nu.PasswordHash)
 	if err != nil {
-		return User{}, fmt.Errorf("generatefrompassword: %w", err)
+		return User{}, fmt.Errorf("hash: %w", err)
 	}
 
 	now := time.Now()
 
 	usr := User{
 		ID:           uuid.New(),
+		TenantID:     tenant.Get(ctx),
 		UserName:     nu.UserName,
 		UserEmail:    nu.UserEmail,
 		PasswordHash: hash,
@@ -86,6 +175,56 @@ func (b *Business) Create(ctx context.Context, nu NewUser) (User, error) {
 	return usr, nil
 }
 
+// Update modifies information about a user. Changing the email address
+// clears the verified state of the account.
+func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, error) {
+	if uu.UserName != nil {
+		usr.UserName = *uu.UserName
+	}
+
+	if uu.UserEmail != nil && uu.UserEmail.Address != usr.UserEmail.Address {
+		usr.UserEmail = *uu.UserEmail
+		usr.VerifiedAt = time.Time{}
+	}
+
+	if uu.Roles != nil {
+		usr.Roles = uu.Roles
+	}
+
+	if uu.Password != nil {
+		if err := b.policy.Check(*uu.Password, usr.UserName.String(), usr.UserEmail.Address); err != nil {
+			return User{}, fmt.Errorf("policy: %w", err)
+		}
+
+		pw, err := b.hasher.Hash(*uu.Password)
+		if err != nil {
+			return User{}, fmt.Errorf("hash: %w", err)
+		}
+		usr.PasswordHash = pw
+	}
+
+	if err := b.storer.Update(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
+	}
+
+	return usr, nil
+}
+
+// Query retrieves a list of existing users.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]User, error) {
+	users, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return users, nil
+}
+
+// Count returns the total number of users.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return b.storer.Count(ctx, filter)
+}
+
 // QueryByID finds the user by the specified ID.
 func (b *Business) QueryByID(ctx context.Context, userID uuid.UUID) (User, error) {
 	user, err := b.storer.QueryByID(ctx, userID)
@@ -106,18 +245,1211 @@ func (b *Business) QueryByEmail(ctx context.Context, email mail.Address) (User,
 	return user, nil
 }
 
+// QueryPreferences returns the preferences of the user, or the defaults when
+// the user has not set any.
+func (b *Business) QueryPreferences(ctx context.Context, userID uuid.UUID) (Preferences, error) {
+	prefs, err := b.storer.QueryPreferences(ctx, userID)
+	if err != nil {
+		return Preferences{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreferences modifies the preferences of the user.
+func (b *Business) UpdatePreferences(ctx context.Context, userID uuid.UUID, up UpdatePreferences) (Preferences, error) {
+	prefs, err := b.QueryPreferences(ctx, userID)
+	if err != nil {
+		return Preferences{}, err
+	}
+
+	if up.Locale != nil {
+		prefs.Locale = *up.Locale
+	}
+
+	if up.TimeZone != nil {
+		prefs.TimeZone = up.TimeZone
+	}
+
+	if up.NotifyCourseUpdates != nil {
+		prefs.NotifyCourseUpdates = *up.NotifyCourseUpdates
+	}
+
+	if up.NotifyMarketing != nil {
+		prefs.NotifyMarketing = *up.NotifyMarketing
+	}
+
+	prefs.UpdatedAt = time.Now()
+
+	if err := b.storer.UpsertPreferences(ctx, prefs); err != nil {
+		return Preferences{}, fmt.Errorf("upsert: userID[%s]: %w", userID, err)
+	}
+
+	return prefs, nil
+}
+
 // Authenticate finds a user by their email and verifies their password. On
 // success it returns a Claims User representing this user. The claims can be
-// used to generate a token for future authentication.
-func (b *Business) Authenticate(ctx context.Context, email mail.Address, password string) (User, error) {
+// used to generate a token for future authentication. A password hashed
+// with an older algorithm or weaker parameters is hashed again.
+func (b *Business) Authenticate(ctx context.Context, email mail.Address, pass string) (User, error) {
 	usr, err := b.QueryByEmail(ctx, email)
 	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			b.hasher.Verify(b.dummyHash(), pass)
+		}
 		return User{}, fmt.Errorf("query: email[%s]: %w", email, err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(password)); err != nil {
-		return User{}, fmt.Errorf("comparehashandpassword: %w", ErrAuthenticationFailure)
+	rehash, err := b.hasher.Verify(usr.PasswordHash, pass)
+	if err != nil {
+		if !errors.Is(err, password.ErrMismatch) {
+			b.log.Error(ctx, "authenticate: verify", "userID", usr.ID, "err", err)
+		}
+		return User{}, fmt.Errorf("verify: %w", ErrAuthenticationFailure)
+	}
+
+	if rehash {
+		hash, err := b.hasher.Hash(pass)
+		if err != nil {
+			return User{}, fmt.Errorf("rehash: %w", err)
+		}
+
+		usr.PasswordHash = hash
+
+		if err := b.storer.Update(ctx, usr); err != nil {
+			return User{}, fmt.Errorf("update: %w", err)
+		}
+	}
+
+	return usr, nil
+}
+
+// =============================================================================
+
+// CreateRefreshToken starts a new session for the user and issues the first
+// refresh token of its token family. The token value is returned to the
+// caller and only its hash is stored.
+func (b *Business) CreateRefreshToken(ctx context.Context, userID uuid.UUID, ns NewSession, ttl time.Duration) (string, RefreshToken, error) {
+	now := time.Now()
+
+	sess := Session{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Device:     describeDevice(ns.UserAgent),
+		IPAddress:  ns.IPAddress,
+		UserAgent:  ns.UserAgent,
+		ExpiresAt:  now.Add(ttl),
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	if err := b.storer.CreateSession(ctx, sess); err != nil {
+		return "", RefreshToken{}, fmt.Errorf("createsession: %w", err)
+	}
+
+	return b.issueRefreshToken(ctx, userID, sess.ID, ttl)
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same
+// family. Presenting a token that has already been exchanged revokes the
+// entire family, since it means the token has been leaked. The session is
+// updated with the client the exchange was made from.
+func (b *Business) RotateRefreshToken(ctx context.Context, token string, ns NewSession, ttl time.Duration) (User, string, RefreshToken, error) {
+	rt, err := b.storer.QueryRefreshTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return User{}, "", RefreshToken{}, fmt.Errorf("query: %w", err)
+	}
+
+	now := time.Now()
+
+	if !rt.RevokedAt.IsZero() {
+		return User{}, "", RefreshToken{}, fmt.Errorf("revoked: familyID[%s]: %w", rt.FamilyID, ErrInvalidToken)
+	}
+
+	if now.After(rt.ExpiresAt) {
+		return User{}, "", RefreshToken{}, fmt.Errorf("expired: tokenID[%s]: %w", rt.ID, ErrTokenExpired)
+	}
+
+	ctx, usr, err := b.queryTokenUser(ctx, rt.UserID)
+	if err != nil {
+		return User{}, "", RefreshToken{}, err
+	}
+
+	if err := b.storer.MarkRefreshTokenUsed(ctx, rt.ID, now); err != nil {
+		if !errors.Is(err, ErrTokenReused) {
+			return User{}, "", RefreshToken{}, fmt.Errorf("markused: tokenID[%s]: %w", rt.ID, err)
+		}
+
+		b.log.Info(ctx, "refresh token reuse detected", "userID", rt.UserID, "familyID", rt.FamilyID)
+
+		if err := b.storer.RevokeRefreshTokenFamily(ctx, rt.FamilyID, now); err != nil {
+			return User{}, "", RefreshToken{}, fmt.Errorf("revoke: familyID[%s]: %w", rt.FamilyID, err)
+		}
+
+		return User{}, "", RefreshToken{}, fmt.Errorf("markused: tokenID[%s]: %w", rt.ID, ErrTokenReused)
+	}
+
+	newToken, newRT, err := b.issueRefreshToken(ctx, usr.ID, rt.FamilyID, ttl)
+	if err != nil {
+		return User{}, "", RefreshToken{}, err
+	}
+
+	sess := Session{
+		ID:         rt.FamilyID,
+		Device:     describeDevice(ns.UserAgent),
+		IPAddress:  ns.IPAddress,
+		UserAgent:  ns.UserAgent,
+		ExpiresAt:  newRT.ExpiresAt,
+		LastSeenAt: now,
+	}
+
+	if err := b.storer.UpdateSession(ctx, sess); err != nil {
+		return User{}, "", RefreshToken{}, fmt.Errorf("updatesession: sessionID[%s]: %w", sess.ID, err)
+	}
+
+	return usr, newToken, newRT, nil
+}
+
+// queryTokenUser finds the user a token was issued to, before the token is
+// redeemed. Tokens aren't bound to an organization, so when the request
+// doesn't name one the user is looked up in the organization they belong
+// to. The returned context carries the tenant of the user.
+func (b *Business) queryTokenUser(ctx context.Context, userID uuid.UUID) (context.Context, User, error) {
+	if !tenant.IsSet(ctx) {
+		tenantID, err := b.storer.QueryTenantID(ctx, userID)
+		if err != nil {
+			return ctx, User{}, fmt.Errorf("querytenant: userID[%s]: %w", userID, err)
+		}
+
+		ctx = tenant.Set(ctx, tenantID)
+	}
+
+	usr, err := b.storer.QueryByID(ctx, userID)
+	if err != nil {
+		return ctx, User{}, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return ctx, usr, nil
+}
+
+// RevokeRefreshToken revokes the family the specified refresh token belongs
+// to, ending the session. It returns the id of the session that was ended.
+func (b *Business) RevokeRefreshToken(ctx context.Context, token string) (uuid.UUID, error) {
+	rt, err := b.storer.QueryRefreshTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("query: %w", err)
+	}
+
+	if err := b.storer.RevokeRefreshTokenFamily(ctx, rt.FamilyID, time.Now()); err != nil {
+		return uuid.Nil, fmt.Errorf("revoke: familyID[%s]: %w", rt.FamilyID, err)
+	}
+
+	return rt.FamilyID, nil
+}
+
+// TouchSession records that the session identified by the session id is in
+// use. It returns false when the session has been revoked or has expired.
+func (b *Business) TouchSession(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	if err := b.storer.TouchSession(ctx, sessionID, time.Now()); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("touch: sessionID[%s]: %w", sessionID, err)
+	}
+
+	return true, nil
+}
+
+// QuerySessions returns the active sessions of the user, most recently used
+// first.
+func (b *Business) QuerySessions(ctx context.Context, userID uuid.UUID) ([]Session, error) {
+	sessions, err := b.storer.QuerySessionsByUser(ctx, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one of the user's sessions. Its refresh tokens stop
+// working immediately.
+func (b *Business) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	if err := b.storer.RevokeSession(ctx, userID, sessionID, time.Now()); err != nil {
+		return fmt.Errorf("revoke: sessionID[%s]: %w", sessionID, err)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions ends every session of the user except the one
+// specified, signing the user out everywhere else. The ids of the revoked
+// sessions are returned.
+func (b *Business) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, keepID uuid.UUID) ([]uuid.UUID, error) {
+	revoked, err := b.storer.RevokeOtherSessions(ctx, userID, keepID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("revoke: userID[%s]: %w", userID, err)
+	}
+
+	return revoked, nil
+}
+
+func (b *Business) issueRefreshToken(ctx context.Context, userID uuid.UUID, familyID uuid.UUID, ttl time.Duration) (string, RefreshToken, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", RefreshToken{}, fmt.Errorf("generatetoken: %w", err)
+	}
+
+	now := time.Now()
+
+	rt := RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	if err := b.storer.CreateRefreshToken(ctx, rt); err != nil {
+		return "", RefreshToken{}, fmt.Errorf("create: %w", err)
+	}
+
+	return token, rt, nil
+}
+
+// =============================================================================
+
+// CreatePasswordResetToken issues a single use password reset token for the
+// user with the specified email. The token value is returned to the caller
+// so it can be delivered to the user, only its hash is stored.
+func (b *Business) CreatePasswordResetToken(ctx context.Context, email mail.Address, ttl time.Duration) (User, string, error) {
+	usr, err := b.storer.QueryByEmail(ctx, email)
+	if err != nil {
+		return User{}, "", fmt.Errorf("query: email[%s]: %w", email.Address, err)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return User{}, "", fmt.Errorf("generatetoken: %w", err)
+	}
+
+	now := time.Now()
+
+	prt := PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    usr.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	if err := b.storer.CreatePasswordResetToken(ctx, prt); err != nil {
+		return User{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	return usr, token, nil
+}
+
+// ResetPassword uses a password reset token to set a new password for the
+// user the token was issued to. The token can only be used once and all of
+// the user's sessions are revoked, their ids are returned.
+func (b *Business) ResetPassword(ctx context.Context, token string, pass string) (User, []uuid.UUID, error) {
+	prt, err := b.storer.QueryPasswordResetTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return User{}, nil, fmt.Errorf("query: %w", err)
+	}
+
+	now := time.Now()
+
+	if now.After(prt.ExpiresAt) {
+		return User{}, nil, fmt.Errorf("expired: tokenID[%s]: %w", prt.ID, ErrTokenExpired)
+	}
+
+	ctx, usr, err := b.queryTokenUser(ctx, prt.UserID)
+	if err != nil {
+		return User{}, nil, err
+	}
+
+	// The policy is checked before the token is used up so the user can try
+	// again with a stronger password.
+	if err := b.policy.Check(pass, usr.UserName.String(), usr.UserEmail.Address); err != nil {
+		return User{}, nil, fmt.Errorf("policy: %w", err)
+	}
+
+	if err := b.storer.MarkPasswordResetTokenUsed(ctx, prt.ID, now); err != nil {
+		return User{}, nil, fmt.Errorf("markused: tokenID[%s]: %w", prt.ID, err)
+	}
+
+	hash, err := b.hasher.Hash(pass)
+	if err != nil {
+		return User{}, nil, fmt.Errorf("hash: %w", err)
+	}
+
+	usr.PasswordHash = hash
+
+	if err := b.storer.Update(ctx, usr); err != nil {
+		return User{}, nil, fmt.Errorf("update: %w", err)
+	}
+
+	revoked, err := b.storer.RevokeUserRefreshTokens(ctx, usr.ID, now)
+	if err != nil {
+		return User{}, nil, fmt.Errorf("revoke: userID[%s]: %w", usr.ID, err)
+	}
+
+	return usr, revoked, nil
+}
+
+// =============================================================================
+
+// CreateEmailVerificationToken issues a single use token the user can use to
+// confirm their email address. The token value is returned to the caller so
+// it can be delivered to the user, only its hash is stored.
+func (b *Business) CreateEmailVerificationToken(ctx context.Context, userID uuid.UUID, ttl time.Duration) (User, string, error) {
+	usr, err := b.storer.QueryByID(ctx, userID)
+	if err != nil {
+		return User{}, "", fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	if usr.IsVerified() {
+		return User{}, "", fmt.Errorf("userID[%s]: %w", userID, ErrAlreadyVerified)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return User{}, "", fmt.Errorf("generatetoken: %w", err)
+	}
+
+	now := time.Now()
+
+	evt := EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    usr.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	if err := b.storer.CreateEmailVerificationToken(ctx, evt); err != nil {
+		return User{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	return usr, token, nil
+}
+
+// VerifyEmail uses an email verification token to mark the email address of
+// the user the token was issued to as verified. The token can only be used
+// once.
+func (b *Business) VerifyEmail(ctx context.Context, token string) (User, error) {
+	evt, err := b.storer.QueryEmailVerificationTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return User{}, fmt.Errorf("query: %w", err)
+	}
+
+	now := time.Now()
+
+	if now.After(evt.ExpiresAt) {
+		return User{}, fmt.Errorf("expired: tokenID[%s]: %w", evt.ID, ErrTokenExpired)
+	}
+
+	ctx, usr, err := b.queryTokenUser(ctx, evt.UserID)
+	if err != nil {
+		return User{}, err
+	}
+
+	if err := b.storer.MarkEmailVerificationTokenUsed(ctx, evt.ID, now); err != nil {
+		return User{}, fmt.Errorf("markused: tokenID[%s]: %w", evt.ID, err)
+	}
+
+	if usr.IsVerified() {
+		return usr, nil
+	}
+
+	usr.VerifiedAt = now
+
+	if err := b.storer.Update(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
+	}
+
+	return usr, nil
+}
+
+// =============================================================================
+
+// CreateMagicLinkToken issues a single use sign in token for the user with
+// the specified email. No more than limit tokens are issued to a user within
+// the window. The token value is returned to the caller so it can be
+// delivered to the user, only its hash is stored.
+func (b *Business) CreateMagicLinkToken(ctx context.Context, email mail.Address, ttl time.Duration, limit int, window time.Duration) (User, string, error) {
+	usr, err := b.storer.QueryByEmail(ctx, email)
+	if err != nil {
+		return User{}, "", fmt.Errorf("query: email[%s]: %w", email.Address, err)
+	}
+
+	now := time.Now()
+
+	if limit > 0 {
+		n, err := b.storer.CountMagicLinkTokens(ctx, usr.ID, now.Add(-window))
+		if err != nil {
+			return User{}, "", fmt.Errorf("count: userID[%s]: %w", usr.ID, err)
+		}
+
+		if n >= limit {
+			return User{}, "", fmt.Errorf("userID[%s]: %w", usr.ID, ErrTooManyLinks)
+		}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return User{}, "", fmt.Errorf("generatetoken: %w", err)
+	}
+
+	mlt := MagicLinkToken{
+		ID:        uuid.New(),
+		UserID:    usr.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	if err := b.storer.CreateMagicLinkToken(ctx, mlt); err != nil {
+		return User{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	return usr, token, nil
+}
+
+// ConsumeMagicLink uses a sign in token and returns the user it was issued
+// to. The token can only be used once. Since following the link proves the
+// user owns the email address, an unverified address is marked verified.
+func (b *Business) ConsumeMagicLink(ctx context.Context, token string) (User, error) {
+	mlt, err := b.storer.QueryMagicLinkTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		return User{}, fmt.Errorf("query: %w", err)
+	}
+
+	now := time.Now()
+
+	if now.After(mlt.ExpiresAt) {
+		return User{}, fmt.Errorf("expired: tokenID[%s]: %w", mlt.ID, ErrTokenExpired)
+	}
+
+	ctx, usr, err := b.queryTokenUser(ctx, mlt.UserID)
+	if err != nil {
+		return User{}, err
+	}
+
+	if err := b.storer.MarkMagicLinkTokenUsed(ctx, mlt.ID, now); err != nil {
+		return User{}, fmt.Errorf("markused: tokenID[%s]: %w", mlt.ID, err)
+	}
+
+	if usr.IsVerified() {
+		return usr, nil
+	}
+
+	usr.VerifiedAt = now
+
+	if err := b.storer.Update(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
 	}
 
 	return usr, nil
 }
+
+// =============================================================================
+
+// Set of second factors a login can be completed with.
+const (
+	FactorTOTP    = "totp"
+	FactorPasskey = "webauthn"
+)
+
+// Set of purposes a WebAuthn challenge can be issued for.
+const (
+	PasskeyRegister     = "register"
+	PasskeyLogin        = "login"
+	PasskeySecondFactor = "second_factor"
+)
+
+// recoveryCodeCount is the number of recovery codes issued at a time.
+const recoveryCodeCount = 10
+
+// totpSkew is the number of time steps of clock drift accepted either side
+// of the current time.
+const totpSkew = 1
+
+// EnrollTOTP generates a new TOTP secret for the user. The secret does not
+// protect the account until it is confirmed with ConfirmTOTP.
+func (b *Business) EnrollTOTP(ctx context.Context, usr User, issuer string) (TOTPEnrollment, error) {
+	t, err := b.storer.QueryTOTP(ctx, usr.ID)
+	switch {
+	case err == nil:
+		if t.IsConfirmed() {
+			return TOTPEnrollment{}, fmt.Errorf("userID[%s]: %w", usr.ID, ErrTOTPAlreadyEnabled)
+		}
+	case !errors.Is(err, ErrTOTPNotEnabled):
+		return TOTPEnrollment{}, fmt.Errorf("query: userID[%s]: %w", usr.ID, err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("generatesecret: %w", err)
+	}
+
+	t = TOTP{
+		UserID:    usr.ID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+
+	if err := b.storer.UpsertTOTP(ctx, t); err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("upsert: %w", err)
+	}
+
+	enr := TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(secret, issuer, usr.UserEmail.Address),
+	}
+
+	return enr, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves they
+// can generate codes for the enrolled secret. The recovery codes are
+// returned to the caller so they can be shown to the user once.
+func (b *Business) ConfirmTOTP(ctx context.Context, usr User, code string) ([]string, error) {
+	t, err := b.storer.QueryTOTP(ctx, usr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", usr.ID, err)
+	}
+
+	if t.IsConfirmed() {
+		return nil, fmt.Errorf("userID[%s]: %w", usr.ID, ErrTOTPAlreadyEnabled)
+	}
+
+	now := time.Now()
+
+	step, ok := totp.Validate(t.Secret, code, now, totpSkew)
+	if !ok {
+		return nil, fmt.Errorf("validate: userID[%s]: %w", usr.ID, ErrInvalidCode)
+	}
+
+	t.ConfirmedAt = now
+	t.LastStep = step
+
+	if err := b.storer.UpsertTOTP(ctx, t); err != nil {
+		return nil, fmt.Errorf("upsert: %w", err)
+	}
+
+	return b.issueRecoveryCodes(ctx, usr.ID)
+}
+
+// DisableTOTP turns two-factor authentication off after checking a current
+// code. It is refused when one of the user's roles requires it.
+func (b *Business) DisableTOTP(ctx context.Context, usr User, code string) error {
+	required, err := b.RequiresTwoFactor(ctx, usr)
+	if err != nil {
+		return err
+	}
+
+	if required {
+		return fmt.Errorf("userID[%s]: %w", usr.ID, ErrTwoFactorRequired)
+	}
+
+	if err := b.VerifySecondFactor(ctx, usr.ID, code); err != nil {
+		return err
+	}
+
+	if err := b.storer.DeleteTOTP(ctx, usr.ID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking
+// a current code.
+func (b *Business) RegenerateRecoveryCodes(ctx context.Context, usr User, code string) ([]string, error) {
+	if err := b.VerifySecondFactor(ctx, usr.ID, code); err != nil {
+		return nil, err
+	}
+
+	return b.issueRecoveryCodes(ctx, usr.ID)
+}
+
+// TwoFactorEnabled reports whether the user has a second factor, either a
+// confirmed TOTP enrollment or a passkey.
+func (b *Business) TwoFactorEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	factors, err := b.SecondFactors(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return len(factors) > 0, nil
+}
+
+// SecondFactors returns the second factors the user can complete a login
+// with.
+func (b *Business) SecondFactors(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var factors []string
+
+	t, err := b.storer.QueryTOTP(ctx, userID)
+	switch {
+	case err == nil:
+		if t.IsConfirmed() {
+			factors = append(factors, FactorTOTP)
+		}
+	case !errors.Is(err, ErrTOTPNotEnabled):
+		return nil, fmt.Errorf("querytotp: userID[%s]: %w", userID, err)
+	}
+
+	pks, err := b.storer.QueryPasskeysByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querypasskeys: userID[%s]: %w", userID, err)
+	}
+
+	if len(pks) > 0 {
+		factors = append(factors, FactorPasskey)
+	}
+
+	return factors, nil
+}
+
+// VerifySecondFactor checks a TOTP code or, failing that, a recovery code
+// for the user. A TOTP code is only accepted once and a recovery code is
+// used up.
+func (b *Business) VerifySecondFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	t, err := b.storer.QueryTOTP(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	if !t.IsConfirmed() {
+		return fmt.Errorf("userID[%s]: %w", userID, ErrTOTPNotEnabled)
+	}
+
+	now := time.Now()
+
+	if step, ok := totp.Validate(t.Secret, code, now, totpSkew); ok {
+		if err := b.storer.UpdateTOTPLastStep(ctx, userID, step); err != nil {
+			return fmt.Errorf("laststep: userID[%s]: %w", userID, err)
+		}
+		return nil
+	}
+
+	if err := b.storer.MarkRecoveryCodeUsed(ctx, userID, hashToken(normalizeRecoveryCode(code)), now); err != nil {
+		return fmt.Errorf("recoverycode: userID[%s]: %w", userID, err)
+	}
+
+	b.log.Info(ctx, "recovery code used", "userID", userID)
+
+	return nil
+}
+
+// TwoFactorRoles returns the roles that must use two-factor authentication.
+func (b *Business) TwoFactorRoles(ctx context.Context) ([]role.Role, error) {
+	roles, err := b.storer.QueryTwoFactorRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return roles, nil
+}
+
+// SetTwoFactorRoles replaces the roles that must use two-factor
+// authentication.
+func (b *Business) SetTwoFactorRoles(ctx context.Context, roles []role.Role) error {
+	if err := b.storer.UpdateTwoFactorRoles(ctx, roles); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// RequiresTwoFactor reports whether any of the user's roles must use
+// two-factor authentication.
+func (b *Business) RequiresTwoFactor(ctx context.Context, usr User) (bool, error) {
+	roles, err := b.TwoFactorRoles(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, r := range roles {
+		if usr.HasRole(r) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (b *Business) issueRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	now := time.Now()
+
+	codes := make([]string, recoveryCodeCount)
+	rcs := make([]RecoveryCode, recoveryCodeCount)
+
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("generaterecoverycode: %w", err)
+		}
+
+		codes[i] = code
+		rcs[i] = RecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  hashToken(normalizeRecoveryCode(code)),
+			CreatedAt: now,
+		}
+	}
+
+	if err := b.storer.ReplaceRecoveryCodes(ctx, userID, rcs); err != nil {
+		return nil, fmt.Errorf("replace: userID[%s]: %w", userID, err)
+	}
+
+	return codes, nil
+}
+
+// =============================================================================
+
+// BeginPasskeyCeremony issues a challenge for a WebAuthn ceremony. The user
+// id is left as the zero value for a passwordless login.
+func (b *Business) BeginPasskeyCeremony(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (WebAuthnChallenge, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return WebAuthnChallenge{}, fmt.Errorf("newchallenge: %w", err)
+	}
+
+	now := time.Now()
+
+	wc := WebAuthnChallenge{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		Challenge: challenge,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	if err := b.storer.CreateWebAuthnChallenge(ctx, wc); err != nil {
+		return WebAuthnChallenge{}, fmt.Errorf("create: %w", err)
+	}
+
+	return wc, nil
+}
+
+// RegisterPasskey verifies a registration response and stores the new
+// passkey for the user.
+func (b *Business) RegisterPasskey(ctx context.Context, rp webauthn.RelyingParty, usr User, challengeID uuid.UUID, np NewPasskey) (Passkey, error) {
+	wc, err := b.consumeChallenge(ctx, challengeID, PasskeyRegister, usr.ID)
+	if err != nil {
+		return Passkey{}, err
+	}
+
+	cred, err := rp.VerifyRegistration(wc.Challenge, np.ClientDataJSON, np.AttestationObject)
+	if err != nil {
+		return Passkey{}, fmt.Errorf("verifyregistration: userID[%s]: %w", usr.ID, err)
+	}
+
+	transports := np.Transports
+	if transports == nil {
+		transports = []string{}
+	}
+
+	now := time.Now()
+
+	pk := Passkey{
+		ID:           uuid.New(),
+		UserID:       usr.ID,
+		CredentialID: cred.ID,
+		PublicKey:    cred.PublicKey,
+		SignCount:    cred.SignCount,
+		Transports:   transports,
+		AAGUID:       cred.AAGUID,
+		Name:         np.Name,
+		CreatedAt:    now,
+	}
+
+	if err := b.storer.CreatePasskey(ctx, pk); err != nil {
+		return Passkey{}, fmt.Errorf("create: %w", err)
+	}
+
+	return pk, nil
+}
+
+// AuthenticatePasskey signs a user in with a passkey in place of a password.
+// User verification is required since the passkey is the only factor.
+func (b *Business) AuthenticatePasskey(ctx context.Context, rp webauthn.RelyingParty, challengeID uuid.UUID, pa PasskeyAssertion) (User, error) {
+	wc, err := b.consumeChallenge(ctx, challengeID, PasskeyLogin, uuid.UUID{})
+	if err != nil {
+		return User{}, err
+	}
+
+	pk, err := b.storer.QueryPasskeyByCredentialID(ctx, pa.CredentialID)
+	if err != nil {
+		if errors.Is(err, ErrPasskeyNotFound) {
+			return User{}, fmt.Errorf("query: %w", ErrAuthenticationFailure)
+		}
+		return User{}, fmt.Errorf("query: %w", err)
+	}
+
+	if len(pa.UserHandle) > 0 && !bytes.Equal(pa.UserHandle, pk.UserID[:]) {
+		return User{}, fmt.Errorf("user handle mismatch: passkeyID[%s]: %w", pk.ID, ErrAuthenticationFailure)
+	}
+
+	rp.RequireUserVerification = true
+
+	if err := b.verifyAssertion(ctx, rp, wc, pk, pa); err != nil {
+		return User{}, err
+	}
+
+	usr, err := b.storer.QueryByID(ctx, pk.UserID)
+	if err != nil {
+		return User{}, fmt.Errorf("query: userID[%s]: %w", pk.UserID, err)
+	}
+
+	return usr, nil
+}
+
+// VerifyPasskey checks a passkey assertion made as the second factor of a
+// login.
+func (b *Business) VerifyPasskey(ctx context.Context, rp webauthn.RelyingParty, userID uuid.UUID, challengeID uuid.UUID, pa PasskeyAssertion) error {
+	wc, err := b.consumeChallenge(ctx, challengeID, PasskeySecondFactor, userID)
+	if err != nil {
+		return err
+	}
+
+	pk, err := b.storer.QueryPasskeyByCredentialID(ctx, pa.CredentialID)
+	if err != nil {
+		if errors.Is(err, ErrPasskeyNotFound) {
+			return fmt.Errorf("query: %w", ErrAuthenticationFailure)
+		}
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if pk.UserID != userID {
+		return fmt.Errorf("passkeyID[%s] not owned by userID[%s]: %w", pk.ID, userID, ErrAuthenticationFailure)
+	}
+
+	return b.verifyAssertion(ctx, rp, wc, pk, pa)
+}
+
+// QueryPasskeys returns the passkeys registered by the user.
+func (b *Business) QueryPasskeys(ctx context.Context, userID uuid.UUID) ([]Passkey, error) {
+	pks, err := b.storer.QueryPasskeysByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return pks, nil
+}
+
+// DeletePasskey removes a passkey registered by the user.
+func (b *Business) DeletePasskey(ctx context.Context, userID uuid.UUID, passkeyID uuid.UUID) error {
+	if err := b.storer.DeletePasskey(ctx, userID, passkeyID); err != nil {
+		return fmt.Errorf("delete: passkeyID[%s]: %w", passkeyID, err)
+	}
+
+	return nil
+}
+
+// consumeChallenge redeems a WebAuthn challenge issued for the purpose and
+// user.
+func (b *Business) consumeChallenge(ctx context.Context, challengeID uuid.UUID, purpose string, userID uuid.UUID) (WebAuthnChallenge, error) {
+	now := time.Now()
+
+	wc, err := b.storer.ConsumeWebAuthnChallenge(ctx, challengeID, now)
+	if err != nil {
+		return WebAuthnChallenge{}, fmt.Errorf("consume: %w", err)
+	}
+
+	if wc.Purpose != purpose || wc.UserID != userID {
+		return WebAuthnChallenge{}, fmt.Errorf("challengeID[%s]: %w", wc.ID, ErrInvalidToken)
+	}
+
+	if now.After(wc.ExpiresAt) {
+		return WebAuthnChallenge{}, fmt.Errorf("expired: challengeID[%s]: %w", wc.ID, ErrTokenExpired)
+	}
+
+	return wc, nil
+}
+
+// verifyAssertion checks the assertion against the passkey and records the
+// new signature counter.
+func (b *Business) verifyAssertion(ctx context.Context, rp webauthn.RelyingParty, wc WebAuthnChallenge, pk Passkey, pa PasskeyAssertion) error {
+	count, err := rp.VerifyAssertion(wc.Challenge, pk.PublicKey, pk.SignCount, pa.ClientDataJSON, pa.AuthenticatorData, pa.Signature)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSignCount) {
+			b.log.Info(ctx, "passkey counter did not increase, possible cloned authenticator", "passkeyID", pk.ID, "userID", pk.UserID)
+		}
+		return fmt.Errorf("verifyassertion: passkeyID[%s]: %w: %w", pk.ID, ErrAuthenticationFailure, err)
+	}
+
+	pk.SignCount = count
+	pk.LastUsedAt = time.Now()
+
+	if err := b.storer.UpdatePasskeyUsage(ctx, pk); err != nil {
+		return fmt.Errorf("updateusage: passkeyID[%s]: %w", pk.ID, err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// CreateOIDCState records a sign in started with an external provider and
+// returns the opaque state to send with the authorization request.
+func (b *Business) CreateOIDCState(ctx context.Context, provider string, codeVerifier string, nonce string, ttl time.Duration) (string, error) {
+	state, err := generateToken()
+	if err != nil {
+		return "", fmt.Errorf("generatetoken: %w", err)
+	}
+
+	now := time.Now()
+
+	st := OIDCState{
+		ID:           uuid.New(),
+		Provider:     provider,
+		StateHash:    hashToken(state),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    now.Add(ttl),
+		CreatedAt:    now,
+	}
+
+	if err := b.storer.CreateOIDCState(ctx, st); err != nil {
+		return "", fmt.Errorf("create: %w", err)
+	}
+
+	return state, nil
+}
+
+// ConsumeOIDCState redeems the state returned by a provider. A state can
+// only be redeemed once, before it expires and for the provider it was
+// issued for.
+func (b *Business) ConsumeOIDCState(ctx context.Context, provider string, state string) (OIDCState, error) {
+	now := time.Now()
+
+	st, err := b.storer.ConsumeOIDCState(ctx, hashToken(state), now)
+	if err != nil {
+		return OIDCState{}, fmt.Errorf("consume: %w", err)
+	}
+
+	if st.Provider != provider {
+		return OIDCState{}, fmt.Errorf("provider mismatch: stateID[%s]: %w", st.ID, ErrInvalidToken)
+	}
+
+	if now.After(st.ExpiresAt) {
+		return OIDCState{}, fmt.Errorf("expired: stateID[%s]: %w", st.ID, ErrTokenExpired)
+	}
+
+	return st, nil
+}
+
+// AuthenticateIdentity returns the user linked to the external identity. An
+// identity seen for the first time is linked to the verified account with
+// the same email address, or to a new account when there is none. The
+// provider must have verified the email address in both cases.
+func (b *Business) AuthenticateIdentity(ctx context.Context, ext ExternalIdentity) (User, error) {
+	idn, err := b.storer.QueryIdentity(ctx, ext.Provider, ext.Subject)
+	switch {
+	case err == nil:
+		usr, err := b.storer.QueryByID(ctx, idn.UserID)
+		if err != nil {
+			return User{}, fmt.Errorf("query: userID[%s]: %w", idn.UserID, err)
+		}
+		return usr, nil
+
+	case !errors.Is(err, ErrIdentityNotFound):
+		return User{}, fmt.Errorf("queryidentity: %w", err)
+	}
+
+	if !ext.EmailVerified {
+		return User{}, fmt.Errorf("provider[%s] subject[%s]: %w", ext.Provider, ext.Subject, ErrIdentityNotVerified)
+	}
+
+	usr, err := b.storer.QueryByEmail(ctx, ext.Email)
+	switch {
+	case err == nil:
+
+		// Linking to an account nobody has proven they own would let whoever
+		// registered it keep access alongside the identity's owner.
+		if !usr.IsVerified() {
+			return User{}, fmt.Errorf("userID[%s]: %w", usr.ID, ErrEmailNotVerified)
+		}
+
+	case errors.Is(err, ErrNotFound):
+		usr, err = b.createFromIdentity(ctx, ext)
+		if err != nil {
+			return User{}, err
+		}
+
+	default:
+		return User{}, fmt.Errorf("querybyemail: %w", err)
+	}
+
+	idn = Identity{
+		ID:        uuid.New(),
+		UserID:    usr.ID,
+		Provider:  ext.Provider,
+		Subject:   ext.Subject,
+		Email:     ext.Email.Address,
+		CreatedAt: time.Now(),
+	}
+
+	if err := b.storer.CreateIdentity(ctx, idn); err != nil {
+		return User{}, fmt.Errorf("createidentity: %w", err)
+	}
+
+	b.log.Info(ctx, "identity linked", "userID", usr.ID, "provider", ext.Provider)
+
+	return usr, nil
+}
+
+// QueryIdentities returns the external identities linked to the user.
+func (b *Business) QueryIdentities(ctx context.Context, userID uuid.UUID) ([]Identity, error) {
+	idns, err := b.storer.QueryIdentitiesByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return idns, nil
+}
+
+// DeleteIdentity unlinks an external identity from the user.
+func (b *Business) DeleteIdentity(ctx context.Context, userID uuid.UUID, identityID uuid.UUID) error {
+	if err := b.storer.DeleteIdentity(ctx, userID, identityID); err != nil {
+		return fmt.Errorf("delete: identityID[%s]: %w", identityID, err)
+	}
+
+	return nil
+}
+
+// createFromIdentity creates a verified account for an identity. The account
+// gets a random password the user can replace through a password reset.
+func (b *Business) createFromIdentity(ctx context.Context, ext ExternalIdentity) (User, error) {
+	password, err := generateToken()
+	if err != nil {
+		return User{}, fmt.Errorf("generatetoken: %w", err)
+	}
+
+	nu := NewUser{
+		UserName:     ext.UserName,
+		UserEmail:    ext.Email,
+		PasswordHash: password,
+		Roles:        []role.Role{role.Student},
+	}
+
+	usr, err := b.Create(ctx, nu)
+	if err != nil {
+		return User{}, err
+	}
+
+	usr.VerifiedAt = usr.CreatedAt
+
+	if err := b.storer.Update(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
+	}
+
+	return usr, nil
+}
+
+// =============================================================================
+
+// newDummyHash returns the hash that is compared against when the email is
+// unknown so the time taken to fail does not reveal whether an account
+// exists. It is computed on first use.
+func newDummyHash(hasher *password.Hasher) func() []byte {
+	return sync.OnceValue(func() []byte {
+		hash, _ := hasher.Hash("dummy-password")
+		return hash
+	})
+}
+
+// describeDevice returns a short description of the browser and operating
+// system found in the user agent, such as "Firefox on Windows".
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	var browser string
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	default:
+		product, _, _ := strings.Cut(userAgent, "/")
+		browser = strings.TrimSpace(product)
+	}
+
+	var platform string
+	switch {
+	case strings.Contains(userAgent, "Windows"):
+		platform = "Windows"
+	case strings.Contains(userAgent, "Android"):
+		platform = "Android"
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		platform = "iOS"
+	case strings.Contains(userAgent, "Mac OS X"):
+		platform = "macOS"
+	case strings.Contains(userAgent, "CrOS"):
+		platform = "ChromeOS"
+	case strings.Contains(userAgent, "Linux"):
+		platform = "Linux"
+	}
+
+	if platform == "" {
+		return browser
+	}
+
+	return browser + " on " + platform
+}
+
+// generateToken returns a random url safe token value.
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// generateRecoveryCode returns a random recovery code in the form
+// xxxxx-xxxxx.
+func generateRecoveryCode() (string, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+
+	return string(b[:5]) + "-" + string(b[5:]), nil
+}
+
+// normalizeRecoveryCode removes the formatting users may type along with a
+// recovery code.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// hashToken returns the hash of a token value as it is stored.
+func hashToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
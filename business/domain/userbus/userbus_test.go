@@ -0,0 +1,298 @@
+package userbus_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kamogelosekhukhune777/lms/business/domain/userbus"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/tenant"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+	"github.com/kamogelosekhukhune777/lms/foundation/password"
+)
+
+func Test_RotateRefreshToken(t *testing.T) {
+	orgID := uuid.New()
+
+	table := []struct {
+		name     string
+		tenantID *uuid.UUID
+		wantErr  error
+	}{
+		{
+			name: "no tenant in request",
+		},
+		{
+			name:     "tenant of the user",
+			tenantID: &orgID,
+		},
+		{
+			name:     "other tenant",
+			tenantID: &tenant.DefaultID,
+			wantErr:  userbus.ErrNotFound,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTokenStore(orgID)
+			bus := userbus.NewBusiness(newLogger(), store, password.NewHasher(password.NewBcrypt(4)), password.Policy{})
+
+			ctx := context.Background()
+			if tt.tenantID != nil {
+				ctx = tenant.Set(ctx, *tt.tenantID)
+			}
+
+			usr, _, _, err := bus.RotateRefreshToken(ctx, "refresh-token", userbus.NewSession{}, time.Hour)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Should fail with %v: got %v", tt.wantErr, err)
+				}
+
+				if store.refreshUsed {
+					t.Fatal("Should not use the token when the user can't be found")
+				}
+
+				// The client retries from the right organization.
+				if _, _, _, err := bus.RotateRefreshToken(tenant.Set(context.Background(), orgID), "refresh-token", userbus.NewSession{}, time.Hour); err != nil {
+					t.Fatalf("Should accept the retry: %s", err)
+				}
+
+				if store.familyRevoked {
+					t.Fatal("Should not treat the retry as reuse")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Should rotate the token: %s", err)
+			}
+
+			if usr.ID != store.usr.ID {
+				t.Errorf("Should return the user of the token: got %s, exp %s", usr.ID, store.usr.ID)
+			}
+
+			if !store.refreshUsed {
+				t.Error("Should use the token")
+			}
+		})
+	}
+}
+
+func Test_ConsumeMagicLink(t *testing.T) {
+	orgID := uuid.New()
+
+	table := []struct {
+		name     string
+		tenantID *uuid.UUID
+		wantErr  error
+	}{
+		{
+			name: "no tenant in request",
+		},
+		{
+			name:     "tenant of the user",
+			tenantID: &orgID,
+		},
+		{
+			name:     "other tenant",
+			tenantID: &tenant.DefaultID,
+			wantErr:  userbus.ErrNotFound,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTokenStore(orgID)
+			bus := userbus.NewBusiness(newLogger(), store, password.NewHasher(password.NewBcrypt(4)), password.Policy{})
+
+			ctx := context.Background()
+			if tt.tenantID != nil {
+				ctx = tenant.Set(ctx, *tt.tenantID)
+			}
+
+			usr, err := bus.ConsumeMagicLink(ctx, "magic-link-token")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Should fail with %v: got %v", tt.wantErr, err)
+				}
+
+				if store.magicLinkUsed {
+					t.Fatal("Should not use the token when the user can't be found")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Should consume the token: %s", err)
+			}
+
+			if !usr.IsVerified() {
+				t.Error("Should verify the user")
+			}
+
+			if store.updatedTenantID != orgID {
+				t.Errorf("Should update the user in their organization: got %s, exp %s", store.updatedTenantID, orgID)
+			}
+		})
+	}
+}
+
+func Test_ResetPassword(t *testing.T) {
+	store := newTokenStore(uuid.New())
+	store.sessions = []uuid.UUID{uuid.New(), uuid.New()}
+
+	bus := userbus.NewBusiness(newLogger(), store, password.NewHasher(password.NewBcrypt(4)), password.Policy{})
+
+	_, revoked, err := bus.ResetPassword(context.Background(), "reset-token", "new-password")
+	if err != nil {
+		t.Fatalf("Should reset the password: %s", err)
+	}
+
+	if len(revoked) != len(store.sessions) {
+		t.Fatalf("Should return the revoked sessions: got %d, exp %d", len(revoked), len(store.sessions))
+	}
+
+	for i, id := range revoked {
+		if id != store.sessions[i] {
+			t.Errorf("Should return session %s: got %s", store.sessions[i], id)
+		}
+	}
+
+	if !store.resetUsed {
+		t.Error("Should use the token")
+	}
+}
+
+// =============================================================================
+
+// tokenStore holds a single user of another organization with a refresh
+// token, a magic link token and a password reset token. Users are scoped to the tenant of the
+// request like the database store.
+type tokenStore struct {
+	userbus.Storer
+	usr             userbus.User
+	rt              userbus.RefreshToken
+	mlt             userbus.MagicLinkToken
+	prt             userbus.PasswordResetToken
+	sessions        []uuid.UUID
+	refreshUsed     bool
+	familyRevoked   bool
+	magicLinkUsed   bool
+	resetUsed       bool
+	updatedTenantID uuid.UUID
+}
+
+func newTokenStore(tenantID uuid.UUID) *tokenStore {
+	usr := userbus.User{
+		ID:       uuid.New(),
+		TenantID: tenantID,
+	}
+
+	return &tokenStore{
+		usr: usr,
+		rt: userbus.RefreshToken{
+			ID:        uuid.New(),
+			UserID:    usr.ID,
+			FamilyID:  uuid.New(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+		mlt: userbus.MagicLinkToken{
+			ID:        uuid.New(),
+			UserID:    usr.ID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+		prt: userbus.PasswordResetToken{
+			ID:        uuid.New(),
+			UserID:    usr.ID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}
+}
+
+func (s *tokenStore) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	if userID != s.usr.ID || tenant.Get(ctx) != s.usr.TenantID {
+		return userbus.User{}, userbus.ErrNotFound
+	}
+
+	return s.usr, nil
+}
+
+func (s *tokenStore) QueryTenantID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	if userID != s.usr.ID {
+		return uuid.Nil, userbus.ErrNotFound
+	}
+
+	return s.usr.TenantID, nil
+}
+
+func (s *tokenStore) Update(ctx context.Context, usr userbus.User) error {
+	s.updatedTenantID = tenant.Get(ctx)
+	return nil
+}
+
+func (s *tokenStore) QueryRefreshTokenByHash(ctx context.Context, hash []byte) (userbus.RefreshToken, error) {
+	return s.rt, nil
+}
+
+func (s *tokenStore) MarkRefreshTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	if s.refreshUsed {
+		return userbus.ErrTokenReused
+	}
+
+	s.refreshUsed = true
+	return nil
+}
+
+func (s *tokenStore) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, revokedAt time.Time) error {
+	s.familyRevoked = true
+	return nil
+}
+
+func (s *tokenStore) CreateRefreshToken(ctx context.Context, rt userbus.RefreshToken) error {
+	return nil
+}
+
+func (s *tokenStore) UpdateSession(ctx context.Context, sess userbus.Session) error {
+	return nil
+}
+
+func (s *tokenStore) QueryMagicLinkTokenByHash(ctx context.Context, hash []byte) (userbus.MagicLinkToken, error) {
+	return s.mlt, nil
+}
+
+func (s *tokenStore) MarkMagicLinkTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	if s.magicLinkUsed {
+		return userbus.ErrInvalidToken
+	}
+
+	s.magicLinkUsed = true
+	return nil
+}
+
+func (s *tokenStore) QueryPasswordResetTokenByHash(ctx context.Context, hash []byte) (userbus.PasswordResetToken, error) {
+	return s.prt, nil
+}
+
+func (s *tokenStore) MarkPasswordResetTokenUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	if s.resetUsed {
+		return userbus.ErrInvalidToken
+	}
+
+	s.resetUsed = true
+	return nil
+}
+
+func (s *tokenStore) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error) {
+	return s.sessions, nil
+}
+
+func newLogger() *logger.Logger {
+	var buf bytes.Buffer
+	return logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+}
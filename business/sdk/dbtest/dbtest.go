@@ -0,0 +1,114 @@
+// Package dbtest provides support for running tests against a database.
+package dbtest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/migrate"
+	"github.com/kamogelosekhukhune777/lms/business/sdk/sqldb"
+	"github.com/kamogelosekhukhune777/lms/foundation/logger"
+)
+
+// Database owns the state for running tests against a database. Every
+// database gets its own schema with all the migrations applied, which is
+// dropped when the test ends.
+type Database struct {
+	DB  *sqlx.DB
+	Log *logger.Logger
+}
+
+// New connects to the database in LMS_TEST_DB_HOST and creates a fresh schema
+// for the test. The test is skipped when no database is configured. The
+// credentials and database name default to the ones of the local compose
+// setup and can be overridden with LMS_TEST_DB_USER, LMS_TEST_DB_PASSWORD and
+// LMS_TEST_DB_NAME.
+func New(t *testing.T) *Database {
+	host := os.Getenv("LMS_TEST_DB_HOST")
+	if host == "" {
+		t.Skip("LMS_TEST_DB_HOST is not set, skipping database test")
+	}
+
+	cfg := sqldb.Config{
+		User:       env("LMS_TEST_DB_USER", "postgres"),
+		Password:   env("LMS_TEST_DB_PASSWORD", "postgres"),
+		Host:       host,
+		Name:       env("LMS_TEST_DB_NAME", "postgres"),
+		DisableTLS: true,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	admin, err := sqldb.Open(cfg)
+	if err != nil {
+		t.Fatalf("Should be able to open the database: %s", err)
+	}
+	defer admin.Close()
+
+	if err := sqldb.StatusCheck(ctx, admin); err != nil {
+		t.Fatalf("Should be able to reach the database: %s", err)
+	}
+
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("Should be able to generate a schema name: %s", err)
+	}
+	cfg.Schema = "test_" + hex.EncodeToString(b)
+
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+cfg.Schema); err != nil {
+		t.Fatalf("Should be able to create schema %s: %s", cfg.Schema, err)
+	}
+
+	db, err := sqldb.Open(cfg)
+	if err != nil {
+		t.Fatalf("Should be able to open schema %s: %s", cfg.Schema, err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+
+		admin, err := sqldb.Open(cfg)
+		if err != nil {
+			t.Errorf("Should be able to open the database to drop schema %s: %s", cfg.Schema, err)
+			return
+		}
+		defer admin.Close()
+
+		if _, err := admin.Exec("DROP SCHEMA " + cfg.Schema + " CASCADE"); err != nil {
+			t.Errorf("Should be able to drop schema %s: %s", cfg.Schema, err)
+		}
+	})
+
+	if err := migrate.Migrate(ctx, db); err != nil {
+		t.Fatalf("Should be able to migrate schema %s: %s", cfg.Schema, err)
+	}
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Log(buf.String())
+		}
+	})
+
+	return &Database{
+		DB:  db,
+		Log: log,
+	}
+}
+
+func env(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return fallback
+}
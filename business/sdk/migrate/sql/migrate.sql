@@ -95,3 +95,469 @@ CREATE TABLE LectureProgress (
     FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE,
     FOREIGN KEY (lecture_id) REFERENCES Lectures(lecture_id) ON DELETE CASCADE
 );
+
+-- Version: 1.08
+-- Description: Store user roles as an array
+ALTER TABLE Users RENAME COLUMN role TO roles;
+ALTER TABLE Users ALTER COLUMN roles TYPE TEXT[] USING ARRAY[roles];
+
+-- Version: 1.09
+-- Description: Create table refresh tokens
+CREATE TABLE RefreshTokens (
+    token_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    family_id UUID NOT NULL,
+    token_hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+CREATE INDEX refresh_tokens_family_id_idx ON RefreshTokens (family_id);
+
+-- Version: 1.10
+-- Description: Create table password reset tokens
+CREATE TABLE PasswordResetTokens (
+    token_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    token_hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.11
+-- Description: Track email verification
+ALTER TABLE Users ADD COLUMN verified_at TIMESTAMP;
+UPDATE Users SET verified_at = created_at;
+CREATE TABLE EmailVerificationTokens (
+    token_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    token_hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.12
+-- Description: Create table instructor applications
+CREATE TABLE InstructorApplications (
+    application_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    bio TEXT NOT NULL,
+    expertise TEXT[] NOT NULL DEFAULT '{}',
+    links TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL,
+    reviewer_id UUID,
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewer_id) REFERENCES Users(user_id) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX instructor_applications_pending_idx ON InstructorApplications (user_id) WHERE status = 'PENDING';
+
+-- Version: 1.13
+-- Description: Create tables for the audit log and login attempts
+CREATE TABLE AuditLog (
+    audit_id UUID PRIMARY KEY NOT NULL,
+    actor_id UUID,
+    action VARCHAR(100) NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX audit_log_created_at_idx ON AuditLog (created_at);
+CREATE INDEX audit_log_action_idx ON AuditLog (action);
+
+CREATE TABLE LoginAttempts (
+    attempt_id UUID PRIMARY KEY NOT NULL,
+    user_email TEXT NOT NULL,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    succeeded BOOLEAN NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX login_attempts_email_idx ON LoginAttempts (user_email, created_at);
+CREATE INDEX login_attempts_ip_idx ON LoginAttempts (ip_address, created_at);
+
+-- Version: 1.14
+-- Description: Create tables for two-factor authentication
+CREATE TABLE UserTOTP (
+    user_id UUID PRIMARY KEY NOT NULL,
+    secret TEXT NOT NULL,
+    confirmed_at TIMESTAMP,
+    last_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+
+CREATE TABLE RecoveryCodes (
+    code_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    code_hash BYTEA NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+CREATE INDEX recovery_codes_user_idx ON RecoveryCodes (user_id);
+
+CREATE TABLE SecurityPolicy (
+    policy_id INT PRIMARY KEY NOT NULL,
+    two_factor_roles TEXT[] NOT NULL DEFAULT '{}'
+);
+
+-- Version: 1.15
+-- Description: Create tables for external identities
+CREATE TABLE UserIdentities (
+    identity_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject),
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+CREATE INDEX user_identities_user_idx ON UserIdentities (user_id);
+
+CREATE TABLE OIDCStates (
+    state_id UUID PRIMARY KEY NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    state_hash BYTEA UNIQUE NOT NULL,
+    code_verifier TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Version: 1.16
+-- Description: Create table magic link tokens
+CREATE TABLE MagicLinkTokens (
+    token_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    token_hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+CREATE INDEX magic_link_tokens_user_idx ON MagicLinkTokens (user_id, created_at);
+
+-- Version: 1.17
+-- Description: Create tables for passkeys
+CREATE TABLE Passkeys (
+    passkey_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    credential_id BYTEA UNIQUE NOT NULL,
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    transports TEXT[] NOT NULL DEFAULT '{}',
+    aaguid BYTEA,
+    name TEXT NOT NULL DEFAULT '',
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+CREATE INDEX passkeys_user_idx ON Passkeys (user_id);
+
+CREATE TABLE WebAuthnChallenges (
+    challenge_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID,
+    purpose VARCHAR(20) NOT NULL,
+    challenge BYTEA NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.18
+-- Description: Create table sessions
+CREATE TABLE Sessions (
+    session_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    device TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+CREATE INDEX sessions_user_idx ON Sessions (user_id);
+
+INSERT INTO Sessions (session_id, user_id, device, expires_at, created_at, last_seen_at)
+SELECT family_id, user_id, 'Unknown device', MAX(expires_at), MIN(created_at), MAX(created_at)
+FROM RefreshTokens
+WHERE revoked_at IS NULL
+GROUP BY family_id, user_id;
+
+-- Version: 1.19
+-- Description: Create table organizations and scope users, courses, enrollments and orders to a tenant
+CREATE TABLE Organizations (
+    organization_id UUID PRIMARY KEY NOT NULL,
+    name TEXT NOT NULL,
+    slug VARCHAR(63) UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO Organizations (organization_id, name, slug)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default', 'default');
+
+ALTER TABLE Users ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES Organizations(organization_id);
+ALTER TABLE Courses ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES Organizations(organization_id);
+ALTER TABLE Enrollments ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES Organizations(organization_id);
+ALTER TABLE Orders ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES Organizations(organization_id);
+
+CREATE INDEX users_tenant_idx ON Users (tenant_id);
+CREATE INDEX courses_tenant_idx ON Courses (tenant_id);
+CREATE INDEX enrollments_tenant_idx ON Enrollments (tenant_id);
+CREATE INDEX orders_tenant_idx ON Orders (tenant_id);
+
+ALTER TABLE Users DROP CONSTRAINT users_user_email_key;
+ALTER TABLE Users ADD CONSTRAINT users_tenant_email_key UNIQUE (tenant_id, user_email);
+
+-- Version: 1.20
+-- Description: Create table erasure requests
+CREATE TABLE ErasureRequests (
+    request_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    requested_at TIMESTAMP NOT NULL,
+    scheduled_for TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    canceled_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX erasure_requests_pending_idx ON ErasureRequests (user_id) WHERE status = 'PENDING';
+CREATE INDEX erasure_requests_due_idx ON ErasureRequests (status, scheduled_for);
+
+-- Version: 1.21
+-- Description: Create tables for legal documents and consent
+CREATE TABLE LegalDocuments (
+    document_id UUID PRIMARY KEY NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    version VARCHAR(50) NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    published_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (kind, version)
+);
+
+CREATE INDEX legal_documents_published_idx ON LegalDocuments (kind, published_at);
+
+CREATE TABLE ConsentAcceptances (
+    acceptance_id UUID PRIMARY KEY NOT NULL,
+    user_id UUID NOT NULL,
+    document_id UUID NOT NULL,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    accepted_at TIMESTAMP NOT NULL,
+    UNIQUE (user_id, document_id),
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (document_id) REFERENCES LegalDocuments(document_id) ON DELETE CASCADE
+);
+
+CREATE INDEX consent_acceptances_document_idx ON ConsentAcceptances (document_id);
+
+-- Version: 1.22
+-- Description: Create table user preferences
+CREATE TABLE UserPreferences (
+    user_id UUID PRIMARY KEY NOT NULL,
+    locale VARCHAR(10) NOT NULL DEFAULT 'en',
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    notify_course_updates BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_marketing BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.23
+-- Description: Create table api keys
+CREATE TABLE APIKeys (
+    api_key_id UUID PRIMARY KEY NOT NULL,
+    tenant_id UUID NOT NULL,
+    user_id UUID,
+    name TEXT NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash BYTEA UNIQUE NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created_by UUID NOT NULL,
+    last_used_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (tenant_id) REFERENCES Organizations(organization_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES Users(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES Users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX api_keys_tenant_idx ON APIKeys (tenant_id);
+
+-- Version: 1.24
+-- Description: Add lecture positions and the media cleanup queue
+ALTER TABLE Lectures ADD COLUMN position INT NOT NULL DEFAULT 0;
+
+UPDATE Lectures l
+SET position = o.position
+FROM (
+    SELECT lecture_id, ROW_NUMBER() OVER (PARTITION BY course_id ORDER BY lecture_id) - 1 AS position
+    FROM Lectures
+) o
+WHERE l.lecture_id = o.lecture_id;
+
+CREATE INDEX lectures_course_position_idx ON Lectures (course_id, position);
+
+CREATE TABLE MediaCleanup (
+    public_id VARCHAR(255) PRIMARY KEY NOT NULL,
+    queued_at TIMESTAMP NOT NULL
+);
+
+-- Version: 1.25
+-- Description: Create table sections and attach lectures to them
+CREATE TABLE Sections (
+    section_id UUID PRIMARY KEY NOT NULL,
+    course_id UUID NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (course_id) REFERENCES Courses(course_id) ON DELETE CASCADE
+);
+
+CREATE INDEX sections_course_position_idx ON Sections (course_id, position);
+
+ALTER TABLE Lectures ADD COLUMN section_id UUID REFERENCES Sections(section_id) ON DELETE SET NULL;
+
+INSERT INTO Sections (section_id, course_id, title, position, created_at)
+SELECT gen_random_uuid(), c.course_id, 'Course content', 0, NOW() AT TIME ZONE 'UTC'
+FROM Courses c
+WHERE EXISTS (SELECT 1 FROM Lectures l WHERE l.course_id = c.course_id);
+
+UPDATE Lectures l
+SET section_id = s.section_id
+FROM Sections s
+WHERE s.course_id = l.course_id;
+
+CREATE INDEX lectures_section_idx ON Lectures (section_id);
+
+-- Version: 1.26
+-- Description: Add course status and scheduled publishing
+ALTER TABLE Courses
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'DRAFT',
+    ADD COLUMN publish_at TIMESTAMP;
+
+UPDATE Courses SET status = 'PUBLISHED', publish_at = created_at WHERE is_published;
+
+CREATE INDEX courses_tenant_status_idx ON Courses (tenant_id, status);
+
+-- Version: 1.27
+-- Description: Create tables for course reviews
+ALTER TABLE Courses ADD COLUMN submitted_at TIMESTAMP;
+
+UPDATE Courses SET submitted_at = NOW() AT TIME ZONE 'UTC' WHERE status = 'IN_REVIEW';
+
+CREATE TABLE CourseReviews (
+    review_id UUID PRIMARY KEY NOT NULL,
+    course_id UUID NOT NULL,
+    reviewer_id UUID,
+    decision VARCHAR(20) NOT NULL,
+    summary TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (course_id) REFERENCES Courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewer_id) REFERENCES Users(user_id) ON DELETE SET NULL
+);
+
+CREATE INDEX course_reviews_course_idx ON CourseReviews (course_id, created_at);
+
+CREATE TABLE CourseReviewComments (
+    comment_id UUID PRIMARY KEY NOT NULL,
+    review_id UUID NOT NULL,
+    lecture_id UUID,
+    lecture_title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    FOREIGN KEY (review_id) REFERENCES CourseReviews(review_id) ON DELETE CASCADE,
+    FOREIGN KEY (lecture_id) REFERENCES Lectures(lecture_id) ON DELETE SET NULL
+);
+
+CREATE INDEX course_review_comments_review_idx ON CourseReviewComments (review_id, position);
+
+-- Version: 1.28
+-- Description: Create table course revisions
+CREATE TABLE CourseRevisions (
+    revision_id UUID PRIMARY KEY NOT NULL,
+    course_id UUID NOT NULL,
+    number INT NOT NULL,
+    author_id UUID,
+    restored_from INT,
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (course_id, number),
+    FOREIGN KEY (course_id) REFERENCES Courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES Users(user_id) ON DELETE SET NULL
+);
+
+-- Version: 1.29
+-- Description: Count second factor login attempts separately
+ALTER TABLE LoginAttempts ADD COLUMN factor VARCHAR(16) NOT NULL DEFAULT 'PRIMARY';
+
+-- Version: 1.30
+-- Description: Move lectures without a section into one and number lectures across the course
+INSERT INTO Sections (section_id, course_id, title, position, created_at)
+SELECT gen_random_uuid(), c.course_id, 'Course content', COALESCE((SELECT max(s.position) + 1 FROM Sections s WHERE s.course_id = c.course_id), 0), NOW() AT TIME ZONE 'UTC'
+FROM Courses c
+WHERE EXISTS (SELECT 1 FROM Lectures l WHERE l.course_id = c.course_id AND l.section_id IS NULL);
+
+UPDATE Lectures l
+SET section_id = (
+    SELECT s.section_id FROM Sections s
+    WHERE s.course_id = l.course_id
+    ORDER BY s.position DESC, s.created_at DESC
+    LIMIT 1
+)
+WHERE l.section_id IS NULL;
+
+UPDATE Lectures l
+SET position = r.position
+FROM (
+    SELECT l.lecture_id, ROW_NUMBER() OVER (PARTITION BY l.course_id ORDER BY s.position, l.position, l.lecture_id) - 1 AS position
+    FROM Lectures l
+    JOIN Sections s ON s.section_id = l.section_id
+) r
+WHERE r.lecture_id = l.lecture_id;
+
+-- Version: 1.31
+-- Description: Index course revision snapshots for the media they reference
+CREATE INDEX course_revisions_snapshot_idx ON CourseRevisions USING GIN (snapshot jsonb_path_ops);
+
+-- Version: 1.32
+-- Description: Throttle login attempts per organization
+ALTER TABLE LoginAttempts ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES Organizations(organization_id);
+
+UPDATE LoginAttempts a
+SET tenant_id = u.tenant_id
+FROM Users u
+WHERE LOWER(u.user_email) = a.user_email AND
+    (SELECT count(1) FROM Users o WHERE LOWER(o.user_email) = a.user_email) = 1;
+
+DROP INDEX login_attempts_email_idx;
+CREATE INDEX login_attempts_email_idx ON LoginAttempts (tenant_id, user_email, created_at);
+
+-- Version: 1.33
+-- Description: Audit lockouts against the user instead of the email
+UPDATE AuditLog a
+SET subject = u.user_id::TEXT,
+    details = a.details || jsonb_build_object('tenant_id', u.tenant_id)
+FROM Users u
+WHERE a.action = 'login.lockout' AND LOWER(u.user_email) = a.subject AND
+    (SELECT count(1) FROM Users o WHERE LOWER(o.user_email) = a.subject) = 1;
@@ -0,0 +1,40 @@
+// Package tenant provides support for scoping data access to the
+// organization a request is made for.
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// DefaultID identifies the organization that owns data created before
+// multi-tenancy, and any request that doesn't name an organization. Admins
+// of the default organization manage the platform.
+var DefaultID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+type ctxKey int
+
+const key ctxKey = 1
+
+// Set stores the tenant id in the context.
+func Set(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, key, tenantID)
+}
+
+// Get returns the tenant id stored in the context, or the default tenant id
+// when none has been set.
+func Get(ctx context.Context) uuid.UUID {
+	v, ok := ctx.Value(key).(uuid.UUID)
+	if !ok {
+		return DefaultID
+	}
+
+	return v
+}
+
+// IsSet reports whether a tenant id has been stored in the context.
+func IsSet(ctx context.Context) bool {
+	_, ok := ctx.Value(key).(uuid.UUID)
+	return ok
+}
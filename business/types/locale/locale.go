@@ -0,0 +1,69 @@
+// Package locale represents the locale type in the system.
+package locale
+
+import "fmt"
+
+// The set of locales the system can present information in.
+var (
+	English    = newLocale("en")
+	Spanish    = newLocale("es")
+	French     = newLocale("fr")
+	Portuguese = newLocale("pt")
+)
+
+// Default is the locale used when a user has not chosen one.
+var Default = English
+
+// =============================================================================
+
+// Set of known locales.
+var locales = make(map[string]Locale)
+
+// Locale represents a language the system can present information in.
+type Locale struct {
+	value string
+}
+
+func newLocale(locale string) Locale {
+	l := Locale{locale}
+	locales[locale] = l
+	return l
+}
+
+// String returns the name of the locale.
+func (l Locale) String() string {
+	return l.value
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (l Locale) Equal(l2 Locale) bool {
+	return l.value == l2.value
+}
+
+// MarshalText provides support for logging and any marshal needs.
+func (l Locale) MarshalText() ([]byte, error) {
+	return []byte(l.value), nil
+}
+
+// =============================================================================
+
+// Parse parses the string value and returns a locale if one exists.
+func Parse(value string) (Locale, error) {
+	locale, exists := locales[value]
+	if !exists {
+		return Locale{}, fmt.Errorf("invalid locale %q", value)
+	}
+
+	return locale, nil
+}
+
+// MustParse parses the string value and returns a locale if one exists. If
+// an error occurs the function panics.
+func MustParse(value string) Locale {
+	locale, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return locale
+}
@@ -5,8 +5,10 @@ import "fmt"
 
 // The set of roles that can be used.
 var (
-	Admin = newRole("ADMIN")
-	User  = newRole("USER")
+	Admin      = newRole("ADMIN")
+	User       = newRole("USER")
+	Instructor = newRole("INSTRUCTOR")
+	Student    = newRole("STUDENT")
 )
 
 // =============================================================================
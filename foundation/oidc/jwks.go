@@ -0,0 +1,81 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// jwks represents a JSON Web Key Set as published by a provider.
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parse returns the signing keys of the set by key id. Keys of an
+// unsupported type are skipped.
+func (s jwks) parse() (map[string]any, error) {
+	keys := make(map[string]any, len(s.Keys))
+
+	for _, k := range s.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeInt(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("kid[%s]: n: %w", k.Kid, err)
+			}
+
+			e, err := decodeInt(k.E)
+			if err != nil {
+				return nil, fmt.Errorf("kid[%s]: e: %w", k.Kid, err)
+			}
+
+			keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+
+		case "EC":
+			if k.Crv != "P-256" {
+				continue
+			}
+
+			x, err := decodeInt(k.X)
+			if err != nil {
+				return nil, fmt.Errorf("kid[%s]: x: %w", k.Kid, err)
+			}
+
+			y, err := decodeInt(k.Y)
+			if err != nil {
+				return nil, fmt.Errorf("kid[%s]: y: %w", k.Kid, err)
+			}
+
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		}
+	}
+
+	return keys, nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,379 @@
+// Package oidc implements the relying party side of the OpenID Connect
+// authorization code flow with PKCE. Providers are configured generically
+// from their issuer URL, so any compliant provider, including a local mock
+// server, can be used.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ErrInvalidIDToken is returned when the ID token handed back by the
+// provider fails verification.
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// Config represents the settings for a single provider.
+type Config struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	Client       *http.Client
+}
+
+// Claims represents the identity asserted by the provider.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider performs the authorization code flow against a single provider.
+// The discovery document and signing keys are fetched on first use.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+	parser *jwt.Parser
+
+	mu   sync.Mutex
+	meta *metadata
+	keys map[string]any
+}
+
+// NewProvider constructs a provider from the configuration.
+func NewProvider(cfg Config) *Provider {
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Provider{
+		cfg:    cfg,
+		client: client,
+		parser: jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()})),
+	}
+}
+
+// Name returns the name the provider is registered under.
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL returns the URL the user is sent to in order to sign in with
+// the provider.
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	v := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return meta.AuthorizationEndpoint + sep + v.Encode(), nil
+}
+
+// Exchange redeems the authorization code and returns the verified claims of
+// the ID token. The nonce must match the one sent with the authorization
+// request.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (Claims, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"client_secret": {p.cfg.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, fmt.Errorf("token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tkn struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}
+	if err := p.do(req, &tkn); err != nil {
+		return Claims{}, fmt.Errorf("token exchange: %w", err)
+	}
+
+	if tkn.IDToken == "" {
+		return Claims{}, fmt.Errorf("token exchange: no id_token: %w", ErrInvalidIDToken)
+	}
+
+	claims, err := p.verify(ctx, tkn.IDToken, nonce)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if claims.Email == "" && tkn.AccessToken != "" && meta.UserinfoEndpoint != "" {
+		if err := p.userinfo(ctx, meta.UserinfoEndpoint, tkn.AccessToken, &claims); err != nil {
+			return Claims{}, err
+		}
+	}
+
+	return claims, nil
+}
+
+// =============================================================================
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// metadata returns the discovery document, fetching it on first use. A
+// failed fetch is retried on the next call.
+func (p *Provider) metadata(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	u := strings.TrimSuffix(p.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("discovery request: %w", err)
+	}
+
+	var meta metadata
+	if err := p.do(req, &meta); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+
+	if strings.TrimSuffix(meta.Issuer, "/") != strings.TrimSuffix(p.cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("discovery: issuer %q does not match %q", meta.Issuer, p.cfg.IssuerURL)
+	}
+
+	p.meta = &meta
+
+	return p.meta, nil
+}
+
+type idClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// verify checks the signature, issuer, audience, expiry and nonce of the ID
+// token.
+func (p *Provider) verify(ctx context.Context, raw string, nonce string) (Claims, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	keyFunc := func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, meta.JWKSURI, kid)
+	}
+
+	var claims idClaims
+	token, err := p.parser.ParseWithClaims(raw, &claims, keyFunc)
+	if err != nil || !token.Valid {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Issuer != meta.Issuer {
+		return Claims{}, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	}
+
+	if !claims.VerifyAudience(p.cfg.ClientID, true) {
+		return Claims{}, fmt.Errorf("%w: unexpected audience", ErrInvalidIDToken)
+	}
+
+	if claims.ExpiresAt == nil {
+		return Claims{}, fmt.Errorf("%w: missing expiry", ErrInvalidIDToken)
+	}
+
+	if claims.Nonce != nonce {
+		return Claims{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	if claims.Subject == "" {
+		return Claims{}, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	c := Claims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: isTrue(claims.EmailVerified),
+		Name:          claims.Name,
+	}
+
+	return c, nil
+}
+
+// userinfo fills in the email and name from the userinfo endpoint when the
+// provider leaves them out of the ID token.
+func (p *Provider) userinfo(ctx context.Context, endpoint string, accessToken string, claims *Claims) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("userinfo request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var info struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified any    `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := p.do(req, &info); err != nil {
+		return fmt.Errorf("userinfo: %w", err)
+	}
+
+	if info.Subject != claims.Subject {
+		return fmt.Errorf("userinfo: subject mismatch: %w", ErrInvalidIDToken)
+	}
+
+	claims.Email = info.Email
+	claims.EmailVerified = isTrue(info.EmailVerified)
+	if claims.Name == "" {
+		claims.Name = info.Name
+	}
+
+	return nil
+}
+
+// key returns the signing key with the specified id. The key set is fetched
+// again when the id is unknown so rotated keys are picked up.
+func (p *Provider) key(ctx context.Context, jwksURI string, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, exists := p.keys[kid]; exists {
+		return key, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("jwks request: %w", err)
+	}
+
+	var set jwks
+	if err := p.do(req, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	keys, err := set.parse()
+	if err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	p.keys = keys
+
+	key, exists := p.keys[kid]
+	if !exists {
+		return nil, fmt.Errorf("kid %q not found", kid)
+	}
+
+	return key, nil
+}
+
+// do sends the request and decodes the JSON response.
+func (p *Provider) do(req *http.Request, v any) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// RandomString returns a random URL safe string suitable for the state,
+// nonce and PKCE verifier.
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("read random: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge returns the S256 PKCE challenge for the verifier.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// isTrue handles providers that send email_verified as a string.
+func isTrue(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+
+	return false
+}
@@ -0,0 +1,402 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/kamogelosekhukhune777/lms/foundation/oidc"
+)
+
+const (
+	clientID     = "lms-client"
+	clientSecret = "lms-secret"
+	redirectURL  = "https://lms.example.com/v1/oidc/callback"
+	code         = "authorization-code"
+	verifier     = "code-verifier"
+	nonce        = "nonce-0123456789"
+	kid          = "key-1"
+)
+
+func Test_Discovery(t *testing.T) {
+	srv := newServer(t)
+
+	t.Run("auth code url", func(t *testing.T) {
+		p := srv.provider(srv.URL)
+
+		raw, err := p.AuthCodeURL(context.Background(), "state-1", nonce, oidc.CodeChallenge(verifier))
+		if err != nil {
+			t.Fatalf("Should build the auth code url: %s", err)
+		}
+
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("Should return a valid url: %s", err)
+		}
+
+		if got, exp := u.Scheme+"://"+u.Host+u.Path, srv.URL+"/authorize"; got != exp {
+			t.Errorf("Should use the discovered authorization endpoint: got %q, exp %q", got, exp)
+		}
+
+		exp := map[string]string{
+			"response_type":         "code",
+			"client_id":             clientID,
+			"redirect_uri":          redirectURL,
+			"scope":                 "openid email profile",
+			"state":                 "state-1",
+			"nonce":                 nonce,
+			"code_challenge":        oidc.CodeChallenge(verifier),
+			"code_challenge_method": "S256",
+		}
+
+		q := u.Query()
+		for k, v := range exp {
+			if got := q.Get(k); got != v {
+				t.Errorf("Should set %s: got %q, exp %q", k, got, v)
+			}
+		}
+	})
+
+	t.Run("issuer mismatch", func(t *testing.T) {
+		srv.issuer = "https://evil.example.com"
+		defer func() { srv.issuer = "" }()
+
+		p := srv.provider(srv.URL)
+
+		if _, err := p.AuthCodeURL(context.Background(), "state-1", nonce, oidc.CodeChallenge(verifier)); err == nil {
+			t.Fatal("Should reject a discovery document for another issuer")
+		}
+	})
+
+	t.Run("discovery retried after failure", func(t *testing.T) {
+		srv.discoveryStatus = http.StatusInternalServerError
+
+		p := srv.provider(srv.URL)
+
+		if _, err := p.AuthCodeURL(context.Background(), "state-1", nonce, oidc.CodeChallenge(verifier)); err == nil {
+			t.Fatal("Should fail when discovery fails")
+		}
+
+		srv.discoveryStatus = http.StatusOK
+
+		if _, err := p.AuthCodeURL(context.Background(), "state-1", nonce, oidc.CodeChallenge(verifier)); err != nil {
+			t.Fatalf("Should fetch the discovery document again: %s", err)
+		}
+	})
+}
+
+func Test_Exchange(t *testing.T) {
+	srv := newServer(t)
+	otherKey := newKey(t)
+
+	valid := func() jwt.MapClaims {
+		now := time.Now()
+
+		return jwt.MapClaims{
+			"iss":            srv.URL,
+			"sub":            "subject-1",
+			"aud":            clientID,
+			"exp":            now.Add(time.Minute).Unix(),
+			"iat":            now.Unix(),
+			"nonce":          nonce,
+			"email":          "jill@example.com",
+			"email_verified": true,
+			"name":           "Jill",
+		}
+	}
+
+	table := []struct {
+		name    string
+		idToken func() string
+		exp     oidc.Claims
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			idToken: func() string { return srv.sign(t, srv.key, kid, valid()) },
+			exp:     oidc.Claims{Subject: "subject-1", Email: "jill@example.com", EmailVerified: true, Name: "Jill"},
+		},
+		{
+			name: "email verified as string",
+			idToken: func() string {
+				c := valid()
+				c["email_verified"] = "true"
+				return srv.sign(t, srv.key, kid, c)
+			},
+			exp: oidc.Claims{Subject: "subject-1", Email: "jill@example.com", EmailVerified: true, Name: "Jill"},
+		},
+		{
+			name: "email from userinfo",
+			idToken: func() string {
+				c := valid()
+				delete(c, "email")
+				delete(c, "email_verified")
+				return srv.sign(t, srv.key, kid, c)
+			},
+			exp: oidc.Claims{Subject: "subject-1", Email: "userinfo@example.com", EmailVerified: true, Name: "Jill"},
+		},
+		{
+			name: "wrong issuer",
+			idToken: func() string {
+				c := valid()
+				c["iss"] = "https://evil.example.com"
+				return srv.sign(t, srv.key, kid, c)
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "wrong audience",
+			idToken: func() string {
+				c := valid()
+				c["aud"] = "another-client"
+				return srv.sign(t, srv.key, kid, c)
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "nonce mismatch",
+			idToken: func() string {
+				c := valid()
+				c["nonce"] = "another-nonce"
+				return srv.sign(t, srv.key, kid, c)
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "expired",
+			idToken: func() string {
+				c := valid()
+				c["exp"] = time.Now().Add(-time.Minute).Unix()
+				return srv.sign(t, srv.key, kid, c)
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "missing expiry",
+			idToken: func() string {
+				c := valid()
+				delete(c, "exp")
+				return srv.sign(t, srv.key, kid, c)
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "missing subject",
+			idToken: func() string {
+				c := valid()
+				delete(c, "sub")
+				return srv.sign(t, srv.key, kid, c)
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "unknown kid",
+			idToken: func() string { return srv.sign(t, srv.key, "key-unknown", valid()) },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "signed with another key",
+			idToken: func() string { return srv.sign(t, otherKey, kid, valid()) },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "missing id token",
+			idToken: func() string { return "" },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.idToken = tt.idToken()
+
+			p := srv.provider(srv.URL)
+
+			claims, err := p.Exchange(context.Background(), code, verifier, nonce)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Should fail with %v: got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Should exchange the code: %s", err)
+			}
+
+			if claims != tt.exp {
+				t.Errorf("Should return the claims of the id token: got %+v, exp %+v", claims, tt.exp)
+			}
+
+			exp := map[string]string{
+				"grant_type":    "authorization_code",
+				"code":          code,
+				"redirect_uri":  redirectURL,
+				"client_id":     clientID,
+				"client_secret": clientSecret,
+				"code_verifier": verifier,
+			}
+
+			for k, v := range exp {
+				if got := srv.tokenForm.Get(k); got != v {
+					t.Errorf("Should send %s to the token endpoint: got %q, exp %q", k, got, v)
+				}
+			}
+		})
+	}
+
+	t.Run("token endpoint error", func(t *testing.T) {
+		srv.idToken = srv.sign(t, srv.key, kid, valid())
+		srv.tokenStatus = http.StatusBadRequest
+		defer func() { srv.tokenStatus = http.StatusOK }()
+
+		p := srv.provider(srv.URL)
+
+		if _, err := p.Exchange(context.Background(), code, verifier, nonce); err == nil {
+			t.Fatal("Should fail when the token endpoint refuses the code")
+		}
+	})
+}
+
+// =============================================================================
+
+// server is a mock OIDC provider that publishes a discovery document, a key
+// set with a single RSA key, a token endpoint and a userinfo endpoint. The
+// issuer defaults to the URL of the server.
+type server struct {
+	*httptest.Server
+	key             *rsa.PrivateKey
+	issuer          string
+	idToken         string
+	tokenForm       url.Values
+	tokenStatus     int
+	discoveryStatus int
+}
+
+func newServer(t *testing.T) *server {
+	srv := server{
+		key:             newKey(t),
+		tokenStatus:     http.StatusOK,
+		discoveryStatus: http.StatusOK,
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		if srv.discoveryStatus != http.StatusOK {
+			w.WriteHeader(srv.discoveryStatus)
+			return
+		}
+
+		issuer := srv.issuer
+		if issuer == "" {
+			issuer = srv.URL
+		}
+
+		writeJSON(w, map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"userinfo_endpoint":      srv.URL + "/userinfo",
+			"jwks_uri":               srv.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"keys": []map[string]string{
+				{
+					"kid": kid,
+					"kty": "RSA",
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(srv.key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(srv.key.E)).Bytes()),
+				},
+			},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		srv.tokenForm = r.PostForm
+
+		if srv.tokenStatus != http.StatusOK {
+			w.WriteHeader(srv.tokenStatus)
+			return
+		}
+
+		writeJSON(w, map[string]string{
+			"access_token": "access-token",
+			"id_token":     srv.idToken,
+		})
+	})
+
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		writeJSON(w, map[string]any{
+			"sub":            "subject-1",
+			"email":          "userinfo@example.com",
+			"email_verified": true,
+			"name":           "Jill Userinfo",
+		})
+	})
+
+	srv.Server = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return &srv
+}
+
+func (s *server) provider(issuerURL string) *oidc.Provider {
+	return oidc.NewProvider(oidc.Config{
+		Name:         "mock",
+		IssuerURL:    issuerURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Client:       s.Client(),
+	})
+}
+
+func (s *server) sign(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("Should sign the id token: %s", err)
+	}
+
+	return raw
+}
+
+func newKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Should generate a key: %s", err)
+	}
+
+	return key
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,145 @@
+package password
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2Params represents the cost parameters of the argon2id algorithm.
+// Memory is expressed in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follows the OWASP recommendation for argon2id.
+var DefaultArgon2Params = Argon2Params{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Argon2id hashes passwords with argon2id. Hashes are stored in the PHC
+// string format so the parameters travel with the hash.
+type Argon2id struct {
+	params Argon2Params
+}
+
+// NewArgon2id constructs the argon2id algorithm with the specified
+// parameters.
+func NewArgon2id(params Argon2Params) Argon2id {
+	return Argon2id{
+		params: params,
+	}
+}
+
+const argon2Prefix = "$argon2id$"
+
+// Hash implements the Algorithm interface.
+func (a Argon2id) Hash(password []byte) ([]byte, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("read random: %w", err)
+	}
+
+	p := a.params
+	key := argon2.IDKey(password, salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	hash := fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2Prefix, argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+
+	return []byte(hash), nil
+}
+
+// Verify implements the Algorithm interface.
+func (a Argon2id) Verify(hash []byte, password []byte) error {
+	p, salt, key, err := decodeArgon2(hash)
+	if err != nil {
+		return err
+	}
+
+	other := argon2.IDKey(password, salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+// Recognizes implements the Algorithm interface.
+func (a Argon2id) Recognizes(hash []byte) bool {
+	return bytes.HasPrefix(hash, []byte(argon2Prefix))
+}
+
+// NeedsRehash implements the Algorithm interface. A hash needs to be
+// replaced when any of its cost parameters is weaker than the configured
+// ones.
+func (a Argon2id) NeedsRehash(hash []byte) bool {
+	p, salt, key, err := decodeArgon2(hash)
+	if err != nil {
+		return true
+	}
+
+	switch {
+	case p.Memory < a.params.Memory,
+		p.Iterations < a.params.Iterations,
+		p.Parallelism < a.params.Parallelism,
+		uint32(len(salt)) < a.params.SaltLength,
+		uint32(len(key)) < a.params.KeyLength:
+		return true
+	}
+
+	return false
+}
+
+// decodeArgon2 parses a hash in the form
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>.
+func decodeArgon2(hash []byte) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2Params{}, nil, nil, ErrUnknownFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, fmt.Errorf("version: %w", ErrUnknownFormat)
+	}
+
+	var p Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("params: %w", ErrUnknownFormat)
+	}
+
+	if p.Iterations == 0 || p.Parallelism == 0 {
+		return Argon2Params{}, nil, nil, fmt.Errorf("params: %w", ErrUnknownFormat)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("salt: %w", ErrUnknownFormat)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2Params{}, nil, nil, fmt.Errorf("key: %w", ErrUnknownFormat)
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,61 @@
+package password
+
+import (
+	"bytes"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultBcryptCost is the cost used by hashes created before argon2id
+// became the default.
+const DefaultBcryptCost = bcrypt.DefaultCost
+
+// Bcrypt hashes passwords with bcrypt. It is kept so hashes created before
+// argon2id became the default can still be verified.
+type Bcrypt struct {
+	cost int
+}
+
+// NewBcrypt constructs the bcrypt algorithm with the specified cost.
+func NewBcrypt(cost int) Bcrypt {
+	return Bcrypt{
+		cost: cost,
+	}
+}
+
+// Hash implements the Algorithm interface.
+func (b Bcrypt) Hash(password []byte) ([]byte, error) {
+	return bcrypt.GenerateFromPassword(password, b.cost)
+}
+
+// Verify implements the Algorithm interface.
+func (b Bcrypt) Verify(hash []byte, password []byte) error {
+	err := bcrypt.CompareHashAndPassword(hash, password)
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+
+	return err
+}
+
+// Recognizes implements the Algorithm interface.
+func (b Bcrypt) Recognizes(hash []byte) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if bytes.HasPrefix(hash, []byte(prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NeedsRehash implements the Algorithm interface.
+func (b Bcrypt) NeedsRehash(hash []byte) bool {
+	cost, err := bcrypt.Cost(hash)
+	if err != nil {
+		return true
+	}
+
+	return cost < b.cost
+}
@@ -0,0 +1,73 @@
+// Package password provides support for hashing passwords and checking them
+// against a strength policy. Hashes are produced with a primary algorithm,
+// while hashes made with legacy algorithms can still be verified so they
+// can be upgraded the next time the user signs in.
+package password
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Set of errors returned when verifying a password.
+var (
+	ErrMismatch      = errors.New("password does not match")
+	ErrUnknownFormat = errors.New("unknown hash format")
+)
+
+// Algorithm declares the behavior of a single hashing algorithm.
+type Algorithm interface {
+	Hash(password []byte) ([]byte, error)
+	Verify(hash []byte, password []byte) error
+	Recognizes(hash []byte) bool
+	NeedsRehash(hash []byte) bool
+}
+
+// Hasher hashes passwords with the primary algorithm and verifies hashes made
+// with the primary or any of the legacy algorithms.
+type Hasher struct {
+	primary Algorithm
+	legacy  []Algorithm
+}
+
+// NewHasher constructs a hasher that hashes with the primary algorithm and
+// can verify hashes of the legacy algorithms.
+func NewHasher(primary Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{
+		primary: primary,
+		legacy:  legacy,
+	}
+}
+
+// Hash returns the hash of the password made with the primary algorithm.
+func (h *Hasher) Hash(password string) ([]byte, error) {
+	hash, err := h.primary.Hash([]byte(password))
+	if err != nil {
+		return nil, fmt.Errorf("hash: %w", err)
+	}
+
+	return hash, nil
+}
+
+// Verify checks the password against the hash. On success it reports
+// whether the hash was made with a legacy algorithm or weaker parameters
+// and should be replaced by a new hash of the password.
+func (h *Hasher) Verify(hash []byte, password string) (bool, error) {
+	if h.primary.Recognizes(hash) {
+		if err := h.primary.Verify(hash, []byte(password)); err != nil {
+			return false, err
+		}
+		return h.primary.NeedsRehash(hash), nil
+	}
+
+	for _, alg := range h.legacy {
+		if alg.Recognizes(hash) {
+			if err := alg.Verify(hash, []byte(password)); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+	}
+
+	return false, ErrUnknownFormat
+}
@@ -0,0 +1,108 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//go:embed common.txt
+var commonFile string
+
+// common returns the bundled set of common and breached passwords.
+var common = sync.OnceValue(func() map[string]struct{} {
+	set := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(strings.NewReader(commonFile))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+
+	return set
+})
+
+// minIdentityLength is the shortest part of a name or email that is checked
+// for inside a password, so short names don't reject unrelated passwords.
+const minIdentityLength = 4
+
+// Policy represents the rules a new password has to satisfy.
+type Policy struct {
+	MinLength   int
+	MaxLength   int
+	CheckCommon bool
+}
+
+// PolicyError is returned when a password violates the policy. Every rule
+// the password breaks is listed.
+type PolicyError struct {
+	Violations []string
+}
+
+// Error implements the error interface.
+func (pe *PolicyError) Error() string {
+	return "password policy: " + strings.Join(pe.Violations, "; ")
+}
+
+// Check validates the password against the policy. The identity values,
+// such as the user's name and email address, must not appear in the
+// password.
+func (p Policy) Check(password string, identity ...string) error {
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+
+	if p.MinLength > 0 && length < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, fmt.Sprintf("must be at most %d characters", p.MaxLength))
+	}
+
+	lower := strings.ToLower(password)
+
+	if p.CheckCommon {
+		if _, exists := common()[lower]; exists {
+			violations = append(violations, "is too common")
+		}
+	}
+
+	if containsIdentity(lower, identity) {
+		violations = append(violations, "must not contain your name or email")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// containsIdentity reports whether the password contains any of the
+// identity values. Email addresses are also checked by their local part.
+func containsIdentity(password string, identity []string) bool {
+	for _, v := range identity {
+		v = strings.ToLower(strings.TrimSpace(v))
+
+		parts := []string{v}
+		if local, _, found := strings.Cut(v, "@"); found {
+			parts = append(parts, local)
+		}
+		parts = append(parts, strings.Fields(v)...)
+
+		for _, part := range parts {
+			if utf8.RuneCountInString(part) >= minIdentityLength && strings.Contains(password, part) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
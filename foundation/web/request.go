@@ -3,6 +3,7 @@ package web
 import (
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 )
 
@@ -11,6 +12,18 @@ func Param(r *http.Request, key string) string {
 	return r.PathValue(key)
 }
 
+// ClientIP returns the IP address of the client that made the request. The
+// remote address of the connection is used, forwarding headers are not
+// trusted.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
 // Decoder represents data that can be decoded.
 type Decoder interface {
 	Decode(data []byte) error
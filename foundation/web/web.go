@@ -75,7 +75,7 @@ func (a *App) corsHandler(webHandler HandlerFunc) HandlerFunc {
 		}
 
 		w.Header().Set("Access-Control-Allow-Methods", "POST, PATCH, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Tenant")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		return webHandler(ctx, r)
@@ -0,0 +1,172 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// errCBOR is returned when data can't be decoded as CBOR.
+var errCBOR = errors.New("malformed cbor")
+
+// maxDepth bounds the nesting of decoded values so hostile input can't
+// exhaust the stack.
+const maxDepth = 16
+
+// decodeCBOR decodes the first CBOR item in data and returns the item and
+// the number of bytes it used. Only the subset of CBOR used by WebAuthn is
+// supported: integers, byte and text strings, arrays, maps and the simple
+// values false, true and null. Map keys are int64 or string.
+func decodeCBOR(data []byte) (any, int, error) {
+	d := decoder{data: data}
+
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return v, d.pos, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) decode(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: nested too deep", errCBOR)
+	}
+
+	major, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if arg > 1<<63-1 {
+			return nil, fmt.Errorf("%w: integer overflow", errCBOR)
+		}
+		return int64(arg), nil
+
+	case 1:
+		if arg > 1<<63-1 {
+			return nil, fmt.Errorf("%w: integer overflow", errCBOR)
+		}
+		return -1 - int64(arg), nil
+
+	case 2:
+		return d.bytes(arg)
+
+	case 3:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+
+	case 4:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, fmt.Errorf("%w: array too long", errCBOR)
+		}
+
+		arr := make([]any, arg)
+		for i := range arr {
+			if arr[i], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+
+	case 5:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, fmt.Errorf("%w: map too long", errCBOR)
+		}
+
+		m := make(map[any]any, arg)
+		for range arg {
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, fmt.Errorf("%w: unsupported map key %T", errCBOR, k)
+			}
+
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			m[k] = v
+		}
+		return m, nil
+
+	case 7:
+		switch arg {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: unsupported simple value %d", errCBOR, arg)
+	}
+
+	return nil, fmt.Errorf("%w: unsupported major type %d", errCBOR, major)
+}
+
+// head reads the initial byte of an item and its argument.
+func (d *decoder) head() (byte, uint64, error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, fmt.Errorf("%w: unexpected end of data", errCBOR)
+	}
+
+	b := d.data[d.pos]
+	d.pos++
+
+	major := b >> 5
+	info := b & 0x1f
+
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, fmt.Errorf("%w: indefinite lengths are not supported", errCBOR)
+	}
+
+	if len(d.data)-d.pos < size {
+		return 0, 0, fmt.Errorf("%w: unexpected end of data", errCBOR)
+	}
+
+	buf := make([]byte, 8)
+	copy(buf[8-size:], d.data[d.pos:d.pos+size])
+	d.pos += size
+
+	return major, binary.BigEndian.Uint64(buf), nil
+}
+
+func (d *decoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("%w: unexpected end of data", errCBOR)
+	}
+
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return b, nil
+}
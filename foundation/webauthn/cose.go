@@ -0,0 +1,138 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Set of COSE algorithms accepted for credentials.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// Algorithms lists the accepted COSE algorithms in order of preference.
+var Algorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters used by the supported key types.
+const (
+	coseKty    = 1
+	coseAlg    = 3
+	coseCrv    = -1
+	coseX      = -2
+	coseY      = -3
+	coseRSAN   = -1
+	coseRSAE   = -2
+	ktyOKP     = 1
+	ktyEC2     = 2
+	ktyRSA     = 3
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+// publicKey is a credential public key decoded from its COSE form.
+type publicKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+// parsePublicKey decodes a COSE encoded public key.
+func parsePublicKey(cose []byte) (publicKey, error) {
+	v, _, err := decodeCBOR(cose)
+	if err != nil {
+		return publicKey{}, err
+	}
+
+	m, ok := v.(map[any]any)
+	if !ok {
+		return publicKey{}, errors.New("cose key is not a map")
+	}
+
+	kty, _ := m[int64(coseKty)].(int64)
+	alg, _ := m[int64(coseAlg)].(int64)
+
+	switch kty {
+	case ktyEC2:
+		crv, _ := m[int64(coseCrv)].(int64)
+		x, _ := m[int64(coseX)].([]byte)
+		y, _ := m[int64(coseY)].([]byte)
+
+		if alg != AlgES256 || crv != crvP256 || len(x) != 32 || len(y) != 32 {
+			return publicKey{}, errors.New("unsupported ec2 key")
+		}
+
+		key := ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return publicKey{}, errors.New("ec2 point is not on the curve")
+		}
+
+		return publicKey{alg: alg, key: &key}, nil
+
+	case ktyOKP:
+		crv, _ := m[int64(coseCrv)].(int64)
+		x, _ := m[int64(coseX)].([]byte)
+
+		if alg != AlgEdDSA || crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return publicKey{}, errors.New("unsupported okp key")
+		}
+
+		return publicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+
+	case ktyRSA:
+		n, _ := m[int64(coseRSAN)].([]byte)
+		e, _ := m[int64(coseRSAE)].([]byte)
+
+		if alg != AlgRS256 || len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return publicKey{}, errors.New("unsupported rsa key")
+		}
+
+		key := rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+
+		return publicKey{alg: alg, key: &key}, nil
+	}
+
+	return publicKey{}, fmt.Errorf("unsupported key type %d", kty)
+}
+
+// verify checks the signature over data was made with the key.
+func (pk publicKey) verify(data []byte, sig []byte) error {
+	switch key := pk.key.(type) {
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(key, sum[:], sig) {
+			return errors.New("ecdsa signature mismatch")
+		}
+
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, data, sig) {
+			return errors.New("ed25519 signature mismatch")
+		}
+
+	case *rsa.PublicKey:
+		sum := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig); err != nil {
+			return fmt.Errorf("rsa signature mismatch: %w", err)
+		}
+
+	default:
+		return fmt.Errorf("unsupported key %T", pk.key)
+	}
+
+	return nil
+}
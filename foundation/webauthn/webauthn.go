@@ -0,0 +1,242 @@
+// Package webauthn implements the relying party checks of the WebAuthn
+// registration and authentication ceremonies. The checks are pure functions
+// of the challenge and the authenticator response so they can be exercised
+// with synthetic responses. Attestation statements are not verified since
+// credentials are requested with the "none" conveyance preference.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrVerification is returned when an authenticator response fails one of
+// the relying party checks.
+var ErrVerification = errors.New("webauthn verification failed")
+
+// ErrSignCount is returned when the signature counter did not increase,
+// which suggests the authenticator has been cloned.
+var ErrSignCount = errors.New("webauthn signature counter did not increase")
+
+// Set of ceremony types found in the client data.
+const (
+	typeCreate = "webauthn.create"
+	typeGet    = "webauthn.get"
+)
+
+// Set of flags found in the authenticator data.
+const (
+	flagUserPresent    = 0x01
+	flagUserVerified   = 0x04
+	flagBackupEligible = 0x08
+	flagAttestedData   = 0x40
+)
+
+// RelyingParty represents the settings of the site credentials are scoped
+// to.
+type RelyingParty struct {
+	ID                      string
+	Name                    string
+	Origins                 []string
+	RequireUserVerification bool
+}
+
+// Credential represents a credential created by an authenticator during
+// registration.
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	SignCount      uint32
+	AAGUID         []byte
+	UserVerified   bool
+	BackupEligible bool
+}
+
+// NewChallenge returns a random challenge for a ceremony.
+func NewChallenge() ([]byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("read random: %w", err)
+	}
+
+	return b, nil
+}
+
+// VerifyRegistration checks the response of a registration ceremony and
+// returns the new credential.
+func (rp RelyingParty) VerifyRegistration(challenge []byte, clientDataJSON []byte, attestationObject []byte) (Credential, error) {
+	if err := rp.verifyClientData(clientDataJSON, typeCreate, challenge); err != nil {
+		return Credential{}, err
+	}
+
+	v, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return Credential{}, fmt.Errorf("%w: attestation object: %v", ErrVerification, err)
+	}
+
+	att, ok := v.(map[any]any)
+	if !ok {
+		return Credential{}, fmt.Errorf("%w: attestation object is not a map", ErrVerification)
+	}
+
+	authData, ok := att["authData"].([]byte)
+	if !ok {
+		return Credential{}, fmt.Errorf("%w: missing authenticator data", ErrVerification)
+	}
+
+	ad, err := rp.verifyAuthData(authData)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	if ad.flags&flagAttestedData == 0 {
+		return Credential{}, fmt.Errorf("%w: missing attested credential data", ErrVerification)
+	}
+
+	if _, err := parsePublicKey(ad.publicKey); err != nil {
+		return Credential{}, fmt.Errorf("%w: public key: %v", ErrVerification, err)
+	}
+
+	cred := Credential{
+		ID:             ad.credentialID,
+		PublicKey:      ad.publicKey,
+		SignCount:      ad.signCount,
+		AAGUID:         ad.aaguid,
+		UserVerified:   ad.flags&flagUserVerified != 0,
+		BackupEligible: ad.flags&flagBackupEligible != 0,
+	}
+
+	return cred, nil
+}
+
+// VerifyAssertion checks the response of an authentication ceremony against
+// the stored public key and signature counter of the credential. The new
+// signature counter is returned so it can be stored.
+func (rp RelyingParty) VerifyAssertion(challenge []byte, publicKeyCOSE []byte, signCount uint32, clientDataJSON []byte, authenticatorData []byte, signature []byte) (uint32, error) {
+	if err := rp.verifyClientData(clientDataJSON, typeGet, challenge); err != nil {
+		return 0, err
+	}
+
+	ad, err := rp.verifyAuthData(authenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	pk, err := parsePublicKey(publicKeyCOSE)
+	if err != nil {
+		return 0, fmt.Errorf("%w: stored public key: %v", ErrVerification, err)
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(bytes.Clone(authenticatorData), clientDataHash[:]...)
+
+	if err := pk.verify(signed, signature); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrVerification, err)
+	}
+
+	// Authenticators that don't implement a counter always report zero.
+	if (ad.signCount != 0 || signCount != 0) && ad.signCount <= signCount {
+		return 0, fmt.Errorf("stored[%d] received[%d]: %w", signCount, ad.signCount, ErrSignCount)
+	}
+
+	return ad.signCount, nil
+}
+
+// =============================================================================
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func (rp RelyingParty) verifyClientData(raw []byte, typ string, challenge []byte) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return fmt.Errorf("%w: client data: %v", ErrVerification, err)
+	}
+
+	if cd.Type != typ {
+		return fmt.Errorf("%w: unexpected type %q", ErrVerification, cd.Type)
+	}
+
+	got, err := base64.RawURLEncoding.DecodeString(cd.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(got, challenge) != 1 {
+		return fmt.Errorf("%w: challenge mismatch", ErrVerification)
+	}
+
+	if !slices.Contains(rp.Origins, cd.Origin) {
+		return fmt.Errorf("%w: unexpected origin %q", ErrVerification, cd.Origin)
+	}
+
+	return nil
+}
+
+type authData struct {
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+func (rp RelyingParty) verifyAuthData(raw []byte) (authData, error) {
+	if len(raw) < 37 {
+		return authData{}, fmt.Errorf("%w: authenticator data too short", ErrVerification)
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(raw[:32], rpIDHash[:]) != 1 {
+		return authData{}, fmt.Errorf("%w: rp id mismatch", ErrVerification)
+	}
+
+	ad := authData{
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+
+	if ad.flags&flagUserPresent == 0 {
+		return authData{}, fmt.Errorf("%w: user not present", ErrVerification)
+	}
+
+	if rp.RequireUserVerification && ad.flags&flagUserVerified == 0 {
+		return authData{}, fmt.Errorf("%w: user not verified", ErrVerification)
+	}
+
+	if ad.flags&flagAttestedData == 0 {
+		return ad, nil
+	}
+
+	rest := raw[37:]
+	if len(rest) < 18 {
+		return authData{}, fmt.Errorf("%w: attested credential data too short", ErrVerification)
+	}
+
+	ad.aaguid = rest[:16]
+	idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+
+	if idLen == 0 || len(rest) < idLen {
+		return authData{}, fmt.Errorf("%w: invalid credential id", ErrVerification)
+	}
+
+	ad.credentialID = rest[:idLen]
+	rest = rest[idLen:]
+
+	_, n, err := decodeCBOR(rest)
+	if err != nil {
+		return authData{}, fmt.Errorf("%w: credential public key: %v", ErrVerification, err)
+	}
+
+	ad.publicKey = rest[:n]
+
+	return ad, nil
+}
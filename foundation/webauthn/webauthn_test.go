@@ -0,0 +1,462 @@
+package webauthn_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kamogelosekhukhune777/lms/foundation/webauthn"
+)
+
+const (
+	rpID   = "lms.example.com"
+	origin = "https://lms.example.com"
+
+	flagUP = 0x01
+	flagUV = 0x04
+	flagAT = 0x40
+)
+
+var credentialID = []byte("credential-1")
+
+func Test_VerifyRegistration(t *testing.T) {
+	key := newKey(t)
+	challenge := []byte("registration-challenge-0123456789")
+
+	type response struct {
+		clientData  []byte
+		attestation []byte
+	}
+
+	valid := func() response {
+		return response{
+			clientData:  clientDataJSON("webauthn.create", challenge, origin),
+			attestation: attestationObject(authData(rpID, flagUP|flagUV|flagAT, 0, coseKey(&key.PublicKey))),
+		}
+	}
+
+	table := []struct {
+		name    string
+		rp      webauthn.RelyingParty
+		resp    func() response
+		wantErr error
+	}{
+		{
+			name: "valid",
+			rp:   relyingParty(true),
+			resp: valid,
+		},
+		{
+			name: "wrong type",
+			rp:   relyingParty(false),
+			resp: func() response {
+				r := valid()
+				r.clientData = clientDataJSON("webauthn.get", challenge, origin)
+				return r
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "challenge mismatch",
+			rp:   relyingParty(false),
+			resp: func() response {
+				r := valid()
+				r.clientData = clientDataJSON("webauthn.create", []byte("another-challenge"), origin)
+				return r
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "origin mismatch",
+			rp:   relyingParty(false),
+			resp: func() response {
+				r := valid()
+				r.clientData = clientDataJSON("webauthn.create", challenge, "https://evil.example.com")
+				return r
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "rp id hash mismatch",
+			rp:   relyingParty(false),
+			resp: func() response {
+				r := valid()
+				r.attestation = attestationObject(authData("evil.example.com", flagUP|flagUV|flagAT, 0, coseKey(&key.PublicKey)))
+				return r
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "user not present",
+			rp:   relyingParty(false),
+			resp: func() response {
+				r := valid()
+				r.attestation = attestationObject(authData(rpID, flagUV|flagAT, 0, coseKey(&key.PublicKey)))
+				return r
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "user not verified",
+			rp:   relyingParty(true),
+			resp: func() response {
+				r := valid()
+				r.attestation = attestationObject(authData(rpID, flagUP|flagAT, 0, coseKey(&key.PublicKey)))
+				return r
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "user verification not required",
+			rp:   relyingParty(false),
+			resp: func() response {
+				r := valid()
+				r.attestation = attestationObject(authData(rpID, flagUP|flagAT, 0, coseKey(&key.PublicKey)))
+				return r
+			},
+		},
+		{
+			name: "missing attested credential data",
+			rp:   relyingParty(false),
+			resp: func() response {
+				r := valid()
+				r.attestation = attestationObject(authData(rpID, flagUP|flagUV, 0, nil))
+				return r
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "malformed attestation object",
+			rp:   relyingParty(false),
+			resp: func() response {
+				r := valid()
+				r.attestation = []byte{0xff}
+				return r
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := tt.resp()
+
+			cred, err := tt.rp.VerifyRegistration(challenge, resp.clientData, resp.attestation)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Should fail with %v: got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Should verify the registration: %s", err)
+			}
+
+			if string(cred.ID) != string(credentialID) {
+				t.Errorf("Should return the credential id: got %q, exp %q", cred.ID, credentialID)
+			}
+		})
+	}
+}
+
+func Test_VerifyAssertion(t *testing.T) {
+	key := newKey(t)
+	other := newKey(t)
+	publicKey := coseKey(&key.PublicKey)
+	challenge := []byte("assertion-challenge-0123456789")
+
+	type response struct {
+		signCount  uint32
+		clientData []byte
+		authData   []byte
+		signature  []byte
+	}
+
+	// build signs the authenticator data and client data with the signer
+	// the same way an authenticator does.
+	build := func(signer *ecdsa.PrivateKey, stored uint32, clientData []byte, ad []byte) response {
+		return response{
+			signCount:  stored,
+			clientData: clientData,
+			authData:   ad,
+			signature:  sign(t, signer, ad, clientData),
+		}
+	}
+
+	valid := func() response {
+		return build(key, 4, clientDataJSON("webauthn.get", challenge, origin), authData(rpID, flagUP|flagUV, 5, nil))
+	}
+
+	table := []struct {
+		name      string
+		rp        webauthn.RelyingParty
+		resp      func() response
+		wantErr   error
+		wantCount uint32
+	}{
+		{
+			name:      "valid",
+			rp:        relyingParty(true),
+			resp:      valid,
+			wantCount: 5,
+		},
+		{
+			name: "counter not implemented",
+			rp:   relyingParty(true),
+			resp: func() response {
+				return build(key, 0, clientDataJSON("webauthn.get", challenge, origin), authData(rpID, flagUP|flagUV, 0, nil))
+			},
+			wantCount: 0,
+		},
+		{
+			name: "wrong type",
+			rp:   relyingParty(false),
+			resp: func() response {
+				return build(key, 4, clientDataJSON("webauthn.create", challenge, origin), authData(rpID, flagUP|flagUV, 5, nil))
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "challenge mismatch",
+			rp:   relyingParty(false),
+			resp: func() response {
+				return build(key, 4, clientDataJSON("webauthn.get", []byte("another-challenge"), origin), authData(rpID, flagUP|flagUV, 5, nil))
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "origin mismatch",
+			rp:   relyingParty(false),
+			resp: func() response {
+				return build(key, 4, clientDataJSON("webauthn.get", challenge, "https://evil.example.com"), authData(rpID, flagUP|flagUV, 5, nil))
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "rp id hash mismatch",
+			rp:   relyingParty(false),
+			resp: func() response {
+				return build(key, 4, clientDataJSON("webauthn.get", challenge, origin), authData("evil.example.com", flagUP|flagUV, 5, nil))
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "user not present",
+			rp:   relyingParty(false),
+			resp: func() response {
+				return build(key, 4, clientDataJSON("webauthn.get", challenge, origin), authData(rpID, flagUV, 5, nil))
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "user not verified",
+			rp:   relyingParty(true),
+			resp: func() response {
+				return build(key, 4, clientDataJSON("webauthn.get", challenge, origin), authData(rpID, flagUP, 5, nil))
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "sign count not increased",
+			rp:   relyingParty(true),
+			resp: func() response {
+				return build(key, 5, clientDataJSON("webauthn.get", challenge, origin), authData(rpID, flagUP|flagUV, 5, nil))
+			},
+			wantErr: webauthn.ErrSignCount,
+		},
+		{
+			name: "sign count went back",
+			rp:   relyingParty(true),
+			resp: func() response {
+				return build(key, 9, clientDataJSON("webauthn.get", challenge, origin), authData(rpID, flagUP|flagUV, 0, nil))
+			},
+			wantErr: webauthn.ErrSignCount,
+		},
+		{
+			name: "signed by another key",
+			rp:   relyingParty(true),
+			resp: func() response {
+				return build(other, 4, clientDataJSON("webauthn.get", challenge, origin), authData(rpID, flagUP|flagUV, 5, nil))
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "authenticator data changed after signing",
+			rp:   relyingParty(true),
+			resp: func() response {
+				r := valid()
+				r.authData = authData(rpID, flagUP|flagUV, 6, nil)
+				return r
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+		{
+			name: "malformed signature",
+			rp:   relyingParty(true),
+			resp: func() response {
+				r := valid()
+				r.signature = []byte("not a signature")
+				return r
+			},
+			wantErr: webauthn.ErrVerification,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := tt.resp()
+
+			count, err := tt.rp.VerifyAssertion(challenge, publicKey, resp.signCount, resp.clientData, resp.authData, resp.signature)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Should fail with %v: got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Should verify the assertion: %s", err)
+			}
+
+			if count != tt.wantCount {
+				t.Errorf("Should return the new sign count: got %d, exp %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+// =============================================================================
+
+func relyingParty(requireUV bool) webauthn.RelyingParty {
+	return webauthn.RelyingParty{
+		ID:                      rpID,
+		Name:                    "LMS",
+		Origins:                 []string{origin},
+		RequireUserVerification: requireUV,
+	}
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Should generate a key: %s", err)
+	}
+
+	return key
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, authData []byte, clientData []byte) []byte {
+	t.Helper()
+
+	clientDataHash := sha256.Sum256(clientData)
+	sum := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatalf("Should sign the assertion: %s", err)
+	}
+
+	return sig
+}
+
+func clientDataJSON(typ string, challenge []byte, origin string) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      typ,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    origin,
+	})
+
+	return data
+}
+
+// authData builds authenticator data, with attested credential data when a
+// public key is provided.
+func authData(rpID string, flags byte, signCount uint32, publicKey []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	ad := append([]byte{}, rpIDHash[:]...)
+	ad = append(ad, flags)
+	ad = binary.BigEndian.AppendUint32(ad, signCount)
+
+	if publicKey != nil {
+		ad = append(ad, make([]byte, 16)...)
+		ad = binary.BigEndian.AppendUint16(ad, uint16(len(credentialID)))
+		ad = append(ad, credentialID...)
+		ad = append(ad, publicKey...)
+	}
+
+	return ad
+}
+
+func attestationObject(authData []byte) []byte {
+	var b []byte
+	b = cborHead(b, 5, 3)
+	b = cborText(b, "fmt")
+	b = cborText(b, "none")
+	b = cborText(b, "attStmt")
+	b = cborHead(b, 5, 0)
+	b = cborText(b, "authData")
+	b = cborBytes(b, authData)
+
+	return b
+}
+
+func coseKey(pub *ecdsa.PublicKey) []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	pub.X.FillBytes(x)
+	pub.Y.FillBytes(y)
+
+	var b []byte
+	b = cborHead(b, 5, 5)
+	b = cborInt(b, 1)
+	b = cborInt(b, 2)
+	b = cborInt(b, 3)
+	b = cborInt(b, -7)
+	b = cborInt(b, -1)
+	b = cborInt(b, 1)
+	b = cborInt(b, -2)
+	b = cborBytes(b, x)
+	b = cborInt(b, -3)
+	b = cborBytes(b, y)
+
+	return b
+}
+
+func cborHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major<<5|byte(n))
+	case n <= 0xff:
+		return append(b, major<<5|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, major<<5|25), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, major<<5|26), uint32(n))
+	}
+}
+
+func cborInt(b []byte, v int64) []byte {
+	if v < 0 {
+		return cborHead(b, 1, uint64(-1-v))
+	}
+
+	return cborHead(b, 0, uint64(v))
+}
+
+func cborBytes(b []byte, v []byte) []byte {
+	return append(cborHead(b, 2, uint64(len(v))), v...)
+}
+
+func cborText(b []byte, v string) []byte {
+	return append(cborHead(b, 3, uint64(len(v))), v...)
+}